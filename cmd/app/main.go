@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	_ "github.com/Killazius/L0/docs"
 	"github.com/Killazius/L0/internal/application"
 	"github.com/Killazius/L0/internal/config"
@@ -10,6 +11,13 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+)
+
+var (
+	demo         = flag.Bool("demo", false, "run without PostgreSQL, Redis and Kafka on in-memory storage with generated orders")
+	demoOrders   = flag.Int("demo-orders", 20, "number of orders generated at start in demo mode")
+	demoInterval = flag.Duration("demo-interval", 5*time.Second, "interval between generated orders in demo mode, 0 to stop after start")
 )
 
 // @title WB L0 API
@@ -18,7 +26,15 @@ import (
 // @termsOfService http://swagger.io/terms/
 // @host localhost:8081
 // @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT bearer token, e.g. "Bearer eyJhbGciOi..."
 func main() {
+	flag.Parse()
 	ctx, cancel := context.WithCancel(context.Background())
 	cfg := config.MustLoad()
 	log, err := logger.LoadFromConfig(cfg.Logger.Path)
@@ -30,7 +46,13 @@ func main() {
 			panic(err)
 		}
 	}
-	app := application.New(log, cfg)
+	var app *application.Application
+	if *demo {
+		log.Info("running in demo mode, orders are kept in memory")
+		app = application.NewDemo(log, cfg, application.DemoConfig{Orders: *demoOrders, Interval: *demoInterval})
+	} else {
+		app = application.New(log, cfg)
+	}
 	app.Run(ctx)
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
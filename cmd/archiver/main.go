@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/logger"
+	"github.com/Killazius/L0/internal/repository/postgresql"
+	"os"
+	"path/filepath"
+)
+
+var (
+	dir  = flag.String("dir", "archive", "directory the partitions are archived to")
+	keep = flag.Bool("keep", false, "export the partitions without dropping them")
+)
+
+// archiver exports every detached orders partition with its items, deliveries,
+// payments and history to <dir>/<partition>.jsonl.gz, one order per line, and
+// drops it once the file is safely written.
+func main() {
+	flag.Parse()
+	cfg := config.MustLoad()
+	log, err := logger.LoadFromConfig(cfg.Logger.Path)
+	if err != nil {
+		if errors.Is(err, logger.ErrDefaultLogger) {
+			log.Warnw("using default logger because config file not found",
+				"config_path", cfg.Logger.Path)
+		} else {
+			log.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(*dir, 0o750); err != nil {
+		log.Fatalw("failed to create archive directory", "dir", *dir, "error", err)
+	}
+
+	pool, err := postgresql.CreatePool(cfg.Postgres)
+	if err != nil {
+		log.Fatalw("error creating postgres pool", "error", err)
+	}
+	defer pool.Close()
+	// The archive keeps the deliveries as they are stored, no keyring is needed.
+	repo := postgresql.New(pool, nil)
+
+	ctx := context.Background()
+	partitions, err := repo.DetachedPartitions(ctx)
+	if err != nil {
+		log.Fatalw("failed to get detached partitions", "error", err)
+	}
+	for _, partition := range partitions {
+		path := filepath.Join(*dir, partition+".jsonl.gz")
+		orders, err := archive(ctx, repo, partition, path)
+		if err != nil {
+			log.Fatalw("failed to archive partition", "partition", partition, "error", err)
+		}
+		log.Infow("partition archived", "partition", partition, "orders", orders, "path", path)
+		if *keep {
+			continue
+		}
+		if err := repo.DropPartition(ctx, partition); err != nil {
+			log.Fatalw("failed to drop partition", "partition", partition, "error", err)
+		}
+		log.Infow("partition dropped", "partition", partition)
+	}
+	log.Infow("archiving finished", "partitions", len(partitions))
+}
+
+// archive writes the partition to a temporary file next to path and renames it
+// to path once it is synced, so that path is either complete or missing.
+func archive(ctx context.Context, repo *postgresql.Repository, partition, path string) (int, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), partition+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	buffer := bufio.NewWriter(file)
+	compressed := gzip.NewWriter(buffer)
+	orders, err := repo.ExportPartition(ctx, partition, func(line []byte) error {
+		if _, err := compressed.Write(line); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := compressed.Write([]byte{'\n'}); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return orders, err
+	}
+	if err := compressed.Close(); err != nil {
+		return orders, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	if err := buffer.Flush(); err != nil {
+		return orders, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return orders, fmt.Errorf("failed to sync archive: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return orders, fmt.Errorf("failed to close archive: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return orders, fmt.Errorf("failed to rename archive: %w", err)
+	}
+	return orders, nil
+}
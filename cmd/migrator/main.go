@@ -1,19 +1,45 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"github.com/Killazius/L0/internal/config"
 	"github.com/Killazius/L0/internal/logger"
 	"github.com/Killazius/L0/internal/repository/postgresql"
-	"github.com/jackc/pgx/v5/stdlib"
-	"github.com/pressly/goose"
+	"github.com/pressly/goose/v3"
+	"math"
 	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
 )
 
-var command = flag.String("command", "up", "goose command (up, down, status, etc.)")
+var (
+	command = flag.String("command", "", "up, up-to <version>, down, down-to <version>, redo, status, version, validate or create <name>; the first argument when not set, up without arguments")
+	dryRun  = flag.Bool("dry-run", false, "print the SQL that up, up-to, down, down-to or redo would run instead of running it")
+)
+
+// step is a migration the command runs in one direction.
+type step struct {
+	migration postgresql.Migration
+	up        bool
+}
 
+// migrator runs the migrations embedded into the binary. Every command first
+// validates them; validate and create do not connect to the database.
 func main() {
+	flag.Parse()
+	args := flag.Args()
+	if *command == "" {
+		*command = "up"
+		if len(args) > 0 {
+			*command, args = args[0], args[1:]
+		}
+	}
+
 	cfg := config.MustLoad()
 	log, err := logger.LoadFromConfig(cfg.Logger.Path)
 	if err != nil {
@@ -24,15 +50,31 @@ func main() {
 			log.Fatal(err)
 		}
 	}
-	if _, err = os.Stat(cfg.Postgres.MigrationsPath); os.IsNotExist(err) {
-		log.Fatalw("migrations directory does not exist", "path", cfg.Postgres.MigrationsPath)
+
+	migrations, err := postgresql.ReadMigrations()
+	if err != nil {
+		log.Fatalw("invalid migrations", "error", err)
 	}
-	if *command == "" {
-		if len(flag.Args()) > 0 {
-			*command = flag.Args()[0]
-		} else {
-			log.Info("no goose command provided. Usage: -command <command> or provide command as argument")
+	switch *command {
+	case "validate":
+		log.Infow("migrations are valid", "count", len(migrations))
+		return
+	case "create":
+		name := argument(args, "name")
+		goose.SetSequential(true)
+		if err := goose.Create(nil, cfg.Postgres.MigrationsPath, name, "sql"); err != nil {
+			log.Fatalw("failed to create migration", "name", name, "error", err)
 		}
+		return
+	}
+	target := int64(math.MaxInt64)
+	switch *command {
+	case "up-to", "down-to":
+		target = version(args)
+	case "up", "down", "redo", "status", "version":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", *command)
+		os.Exit(2)
 	}
 
 	pool, err := postgresql.CreatePool(cfg.Postgres)
@@ -40,11 +82,179 @@ func main() {
 		log.Fatalw("error creating postgres pool", "error", err)
 	}
 	defer pool.Close()
-	sqlDB := stdlib.OpenDBFromPool(pool)
-	defer sqlDB.Close()
-	if err := goose.Run(*command, sqlDB, cfg.Postgres.MigrationsPath); err != nil {
-		log.Fatalw("failed to run goose command", "command", *command, "error", err)
+	provider, err := postgresql.NewMigrator(pool)
+	if err != nil {
+		log.Fatalw("error creating migrator", "error", err)
+	}
+	defer func() {
+		if err := provider.Close(); err != nil {
+			log.Warnw("failed to close migrator", "error", err)
+		}
+	}()
+
+	ctx := context.Background()
+	if *dryRun {
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			log.Fatalw("failed to get migration status", "error", err)
+		}
+		steps, err := plan(*command, target, statuses, migrations)
+		if err != nil {
+			log.Fatalw("failed to plan migrations", "command", *command, "error", err)
+		}
+		for _, step := range steps {
+			direction, sql := "up", step.migration.Up
+			if !step.up {
+				direction, sql = "down", step.migration.Down
+			}
+			fmt.Printf("-- %s %s\n%s\n", step.migration.Name, direction, sql)
+		}
+		log.Infow("dry run, nothing was applied", "command", *command, "migrations", len(steps))
+		return
 	}
 
-	log.Infow("goose command executed successfully", "command", *command)
+	if err := run(ctx, provider, *command, target); err != nil {
+		log.Fatalw("failed to run migration command", "command", *command, "error", err)
+	}
+	log.Infow("migration command executed successfully", "command", *command)
+}
+
+// run runs the command, target is the version of up-to and down-to.
+func run(ctx context.Context, provider *goose.Provider, command string, target int64) error {
+	var results []*goose.MigrationResult
+	var err error
+	switch command {
+	case "up":
+		results, err = provider.Up(ctx)
+	case "up-to":
+		results, err = provider.UpTo(ctx, target)
+	case "down":
+		var result *goose.MigrationResult
+		if result, err = provider.Down(ctx); result != nil {
+			results = append(results, result)
+		}
+	case "down-to":
+		results, err = provider.DownTo(ctx, target)
+	case "redo":
+		results, err = redo(ctx, provider)
+	case "status":
+		return printStatus(ctx, provider)
+	case "version":
+		current, err := provider.GetDBVersion(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(current)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		results = append(partial.Applied, partial.Failed)
+	}
+	for _, result := range results {
+		fmt.Println(result)
+	}
+	return err
+}
+
+// redo rolls back the last applied migration and applies it again.
+func redo(ctx context.Context, provider *goose.Provider) ([]*goose.MigrationResult, error) {
+	current, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, errors.New("no migration is applied")
+	}
+	down, err := provider.ApplyVersion(ctx, current, false)
+	if err != nil {
+		return nil, err
+	}
+	up, err := provider.ApplyVersion(ctx, current, true)
+	if err != nil {
+		return []*goose.MigrationResult{down}, err
+	}
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+func printStatus(ctx context.Context, provider *goose.Provider) error {
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATE\tAPPLIED AT\tMIGRATION")
+	for _, status := range statuses {
+		appliedAt := ""
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.UTC().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.State, appliedAt, status.Source.Path)
+	}
+	return w.Flush()
+}
+
+// plan returns the migrations the command would run, in order.
+func plan(command string, target int64, statuses []*goose.MigrationStatus, migrations []postgresql.Migration) ([]step, error) {
+	byVersion := make(map[int64]postgresql.Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+	var applied, pending []postgresql.Migration
+	for _, status := range statuses {
+		switch status.State {
+		case goose.StateApplied:
+			applied = append(applied, byVersion[status.Source.Version])
+		case goose.StatePending:
+			pending = append(pending, byVersion[status.Source.Version])
+		}
+	}
+	slices.Reverse(applied)
+
+	var steps []step
+	switch command {
+	case "up", "up-to":
+		for _, migration := range pending {
+			if migration.Version <= target {
+				steps = append(steps, step{migration: migration, up: true})
+			}
+		}
+	case "down", "redo":
+		if len(applied) == 0 {
+			return nil, errors.New("no migration is applied")
+		}
+		steps = append(steps, step{migration: applied[0]})
+		if command == "redo" {
+			steps = append(steps, step{migration: applied[0], up: true})
+		}
+	case "down-to":
+		for _, migration := range applied {
+			if migration.Version > target {
+				steps = append(steps, step{migration: migration})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("command %q has no dry run", command)
+	}
+	return steps, nil
+}
+
+// argument returns the first argument of the command or exits without one.
+func argument(args []string, name string) string {
+	if len(args) == 0 || args[0] == "" {
+		fmt.Fprintf(os.Stderr, "%s: missing %s\n", *command, name)
+		os.Exit(2)
+	}
+	return args[0]
+}
+
+func version(args []string) int64 {
+	v, err := strconv.ParseInt(argument(args, "version"), 10, 64)
+	if err != nil || v < 0 {
+		fmt.Fprintf(os.Stderr, "%s: invalid version %q\n", *command, args[0])
+		os.Exit(2)
+	}
+	return v
 }
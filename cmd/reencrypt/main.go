@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/Killazius/L0/internal/application"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/logger"
+	"github.com/Killazius/L0/internal/repository/postgresql"
+)
+
+var batchSize = flag.Int("batch", 500, "number of deliveries re-encrypted per transaction")
+
+// reencrypt moves every stored delivery to the active master key: plain text rows
+// are encrypted and rows sealed with retired keys get their data key rewrapped.
+func main() {
+	flag.Parse()
+	cfg := config.MustLoad()
+	log, err := logger.LoadFromConfig(cfg.Logger.Path)
+	if err != nil {
+		if errors.Is(err, logger.ErrDefaultLogger) {
+			log.Warnw("using default logger because config file not found",
+				"config_path", cfg.Logger.Path)
+		} else {
+			log.Fatal(err)
+		}
+	}
+	keyring, err := application.LoadKeyring(cfg.Encryption)
+	if err != nil {
+		log.Fatalw("error loading encryption keys", "error", err)
+	}
+	if !keyring.Enabled() {
+		log.Fatal("encryption key file is not configured")
+	}
+
+	pool, err := postgresql.CreatePool(cfg.Postgres)
+	if err != nil {
+		log.Fatalw("error creating postgres pool", "error", err)
+	}
+	defer pool.Close()
+	repo := postgresql.New(pool, keyring)
+
+	ctx := context.Background()
+	total := 0
+	for {
+		n, err := repo.ReencryptDeliveries(ctx, *batchSize)
+		if err != nil {
+			log.Fatalw("failed to re-encrypt deliveries", "processed", total, "error", err)
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+		log.Infow("batch re-encrypted", "rows", n, "total", total)
+	}
+	log.Infow("re-encryption finished", "total", total, "active_key_id", keyring.ActiveKeyID())
+}
@@ -16,43 +16,364 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/order/{order_uid}": {
+        "/admin/customers/{customer_id}/erase": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anonymise personal data of all orders of a customer and return the erasure audit record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "privacy"
+                ],
+                "summary": "Erase customer data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Erasure reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EraseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Erasure record",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Erasure"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/customers/{customer_id}/export": {
             "get": {
-                "description": "Get order details by order UID",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Export all orders of a customer as a JSON bundle (subject access request)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "privacy"
+                ],
+                "summary": "Export customer data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Customer data",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CustomerExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid customer ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exchange-rates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rates in the base currency effective on a UTC day, the latest rate of every currency dated on or before it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange rates"
+                ],
+                "summary": "Get exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "2021-11-26",
+                        "description": "Day, YYYY-MM-DD; today by default",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exchange rates",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExchangeRates"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query or no base currency",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Save dated rates in the base currency, replacing the rates of the same currencies and days, and convert the stored orders that had no rate so far. The body is a JSON array, dates as YYYY-MM-DD or RFC 3339, or with Content-Type text/csv the rows date,currency,rate",
                 "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "exchange rates"
+                ],
+                "summary": "Save exchange rates",
+                "parameters": [
+                    {
+                        "description": "Exchange rates",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ExchangeRate"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Saved rates and converted orders",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExchangeRatesUpdate"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rates or no base currency",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{customer_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the contact details of a customer and the address book of every delivery address used in their orders, most recently used first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Get order by UID",
+                "summary": "Get customer by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Order UID",
-                        "name": "order_uid",
+                        "description": "Customer ID",
+                        "name": "customer_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Order details",
+                        "description": "Customer",
                         "schema": {
-                            "$ref": "#/definitions/domain.Order"
+                            "$ref": "#/definitions/domain.Customer"
                         }
                     },
                     "400": {
-                        "description": "Invalid order UID",
+                        "description": "Invalid customer ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Order not found",
+                        "description": "Customer not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -65,9 +386,1510 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/customers/{customer_id}/orders": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the orders of a customer, newest first, with the order count and the lifetime value per currency and in the base currency of all of them. Deleted orders are not counted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "List orders of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "page_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of orders with aggregates",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CustomerOrders"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/{order_uid}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get order details by order UID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order by UID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "order_uid,track_number,items.name",
+                        "description": "Comma separated order fields, fields of a part as items.name",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "items,payment",
+                        "description": "Comma separated parts returned in full: delivery, payment, items",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached representation",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of a cached representation",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order details",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the order view, the masked and the full view have different tags"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last order update"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Order not modified"
+                    },
+                    "400": {
+                        "description": "Invalid order UID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the order. If-Match must hold the ETag of the order as last read, or *, so concurrent changes are not overwritten",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the order being replaced",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated order",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the updated order"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid order",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Order has changed",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Soft-delete the order. It is no longer returned by any endpoint, can be restored and is purged after the retention window",
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Delete order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Order deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/{order_uid}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every recorded change of the order with its actor and diff, a JSON merge patch. With as_of only the changes up to that time are returned together with the order as it was then. Contact fields of the delivery are recorded masked and are never recoverable, whatever the role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2021-11-26T06:22:19Z",
+                        "description": "Point in time, RFC 3339",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order history",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No history",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/{order_uid}:restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore a deleted order that has not been purged yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Restore order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored order",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No deleted order",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List orders matching the filters, newest first. Support agents find orders by track number or by the chrt_id/nm_id of an item",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "List orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "WBILMTESTTRACK",
+                        "description": "Track number",
+                        "name": "track_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 9934930,
+                        "description": "Item chrt_id",
+                        "name": "chrt_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2389212,
+                        "description": "Item nm_id",
+                        "name": "nm_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery service",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2021-11-01T00:00:00Z",
+                        "description": "Created at or after, RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2021-12-01T00:00:00Z",
+                        "description": "Created before, RFC 3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "page_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of orders",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/by-transaction/{transaction}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the order paid with the payment transaction",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order by payment transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment transaction",
+                        "name": "transaction",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order details",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid transaction",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/lookup": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get up to 1000 orders by UID in one request. Orders that do not exist are listed in missing",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Look up orders",
+                "parameters": [
+                    {
+                        "description": "Order UIDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LookupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Found orders and missing UIDs",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderLookup"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search over customer name, city, address, item brand and name, best matches first. Names and addresses of encrypted deliveries are not searchable",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Search orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "vivienne mascaras",
+                        "description": "Web search query: words, quoted phrases, or, -word",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "page_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ranked results with highlights",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SearchPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/stream": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of order changes with a domain.OrderEvent as data. The event type is order.created for a new order,\norder.updated for a replaced one, order.deleted for a soft-deleted one and order.restored for a restored one.\nA client resumes after a reconnect with the Last-Event-ID header (or last_event_id query parameter);\nonly the events still in the replay buffer are delivered. Clients that cannot keep up are disconnected.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Stream order changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only orders of the customer",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only orders of the delivery service",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event id",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event id",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event id",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/ws": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "WebSocket endpoint. The client sends TrackRequest messages to subscribe to order UIDs and receives TrackMessage messages:\nthe current state of every subscribed order, then every change of it. Browsers pass credentials in the api_key or access_token query parameter.",
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Track orders live",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key for clients that cannot set headers",
+                        "name": "api_key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token for clients that cannot set headers",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TrackMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/brands": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Brands with the most items sold on every UTC day, with the orders and the revenue per currency and in the base currency. Deleted orders are not counted. The statistics are refreshed periodically and lag behind the orders",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Top brands by day",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "2021-11-01",
+                        "description": "First day, YYYY-MM-DD; 30 days before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2021-12-01",
+                        "description": "Day after the last one, YYYY-MM-DD; tomorrow by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Brands per day, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Top brands",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TopBrandsReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/daily": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Order count, sums of amount and delivery cost and average basket size (items per order) of every UTC day, per currency and optionally per group, with the amounts converted to the base currency and their totals across currencies. Deleted orders are not counted; orders without an exchange rate are counted as unconverted. The statistics are refreshed periodically and lag behind the orders",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Daily order statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "2021-11-01",
+                        "description": "First day, YYYY-MM-DD; 30 days before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2021-12-01",
+                        "description": "Day after the last one, YYYY-MM-DD; tomorrow by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "provider",
+                            "bank",
+                            "delivery_service",
+                            "currency",
+                            "locale"
+                        ],
+                        "type": "string",
+                        "description": "Group by",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daily statistics",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DailyStatsReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Address": {
+            "description": "Delivery address of a customer",
+            "type": "object",
+            "required": [
+                "address",
+                "city",
+                "email",
+                "name",
+                "phone",
+                "region",
+                "zip"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "Ploshad Mira 15"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Kiryat Mozkin"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "test@gmail.com"
+                },
+                "first_used_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Test Testov"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+9720000000"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "Kraiot"
+                },
+                "zip": {
+                    "type": "string",
+                    "example": "2639809"
+                }
+            }
+        },
+        "domain.Amount": {
+            "description": "Amount in a currency",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 1817
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "domain.BaseTotal": {
+            "description": "Total in the base currency",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 1817
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "unconverted": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "domain.BrandStats": {
+            "description": "Sales of a brand",
+            "type": "object",
+            "properties": {
+                "base_revenue": {
+                    "$ref": "#/definitions/domain.BaseTotal"
+                },
+                "brand": {
+                    "type": "string",
+                    "example": "Vivienne Sabo"
+                },
+                "items": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "orders": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "revenue": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Amount"
+                    }
+                }
+            }
+        },
+        "domain.Customer": {
+            "description": "Customer information",
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Address"
+                    }
+                },
+                "customer_id": {
+                    "type": "string",
+                    "example": "test"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "test@gmail.com"
+                },
+                "first_order_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "last_order_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Test Testov"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+9720000000"
+                }
+            }
+        },
+        "domain.CustomerExport": {
+            "description": "All data stored about a customer",
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string",
+                    "example": "test"
+                },
+                "exported_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Order"
+                    }
+                }
+            }
+        },
+        "domain.CustomerOrders": {
+            "description": "Orders of a customer with aggregates",
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string",
+                    "example": "test"
+                },
+                "lifetime_value": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Amount"
+                    }
+                },
+                "lifetime_value_base": {
+                    "$ref": "#/definitions/domain.BaseTotal"
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MjAyMS0xMS0yNlQwNjoyMjoxOVp8YjU2M2ZlYjdiMmI4NGI2dGVzdA"
+                },
+                "order_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Order"
+                    }
+                }
+            }
+        },
+        "domain.DailyBrands": {
+            "description": "Top brands of a day",
+            "type": "object",
+            "properties": {
+                "brands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BrandStats"
+                    }
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2021-11-26T00:00:00Z"
+                }
+            }
+        },
+        "domain.DailyStats": {
+            "description": "Daily order statistics",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 21804
+                },
+                "avg_basket_size": {
+                    "type": "number",
+                    "example": 2.5
+                },
+                "base_amount": {
+                    "type": "number",
+                    "example": 21804
+                },
+                "base_delivery_cost": {
+                    "type": "number",
+                    "example": 18000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2021-11-26T00:00:00Z"
+                },
+                "delivery_cost": {
+                    "type": "number",
+                    "example": 18000
+                },
+                "group": {
+                    "type": "string",
+                    "example": "wbpay"
+                },
+                "items": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "orders": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "unconverted": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "domain.DailyStatsReport": {
+            "description": "Daily order statistics of a period",
+            "type": "object",
+            "properties": {
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2021-11-01T00:00:00Z"
+                },
+                "group_by": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StatsDimension"
+                        }
+                    ],
+                    "example": "provider"
+                },
+                "stats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DailyStats"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2021-12-01T00:00:00Z"
+                },
+                "totals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DailyTotal"
+                    }
+                }
+            }
+        },
+        "domain.DailyTotal": {
+            "description": "Daily order totals in the base currency",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 21804
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2021-11-26T00:00:00Z"
+                },
+                "delivery_cost": {
+                    "type": "number",
+                    "example": 18000
+                },
+                "group": {
+                    "type": "string",
+                    "example": "wbpay"
+                },
+                "items": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "orders": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "unconverted": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
         "domain.Delivery": {
             "type": "object",
             "required": [
@@ -110,6 +1932,146 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.Erasure": {
+            "description": "Erasure audit record",
+            "type": "object",
+            "properties": {
+                "customer_hash": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "erased_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "order_uids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pseudonym": {
+                    "type": "string",
+                    "example": "erased9f86d081884c7d65"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "GDPR art. 17 request #42"
+                },
+                "requested_by": {
+                    "type": "string",
+                    "example": "ops"
+                }
+            }
+        },
+        "domain.EventType": {
+            "type": "string",
+            "enum": [
+                "order.created",
+                "order.updated",
+                "order.erased",
+                "order.deleted",
+                "order.restored",
+                "order.snapshot"
+            ],
+            "x-enum-varnames": [
+                "EventOrderCreated",
+                "EventOrderUpdated",
+                "EventOrderErased",
+                "EventOrderDeleted",
+                "EventOrderRestored",
+                "EventOrderSnapshot"
+            ]
+        },
+        "domain.ExchangeRate": {
+            "description": "Exchange rate of a currency",
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2021-11-26T00:00:00Z"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 1.1317
+                }
+            }
+        },
+        "domain.ExchangeRates": {
+            "description": "Exchange rates of a day",
+            "type": "object",
+            "properties": {
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2021-11-26T00:00:00Z"
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ExchangeRate"
+                    }
+                }
+            }
+        },
+        "domain.ExchangeRatesUpdate": {
+            "description": "Result of saving exchange rates",
+            "type": "object",
+            "properties": {
+                "converted": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "saved": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "domain.HistoryEvent": {
+            "description": "Recorded change of an order",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "ops"
+                },
+                "diff": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EventType"
+                        }
+                    ],
+                    "example": "order.updated"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
         "domain.Item": {
             "type": "object",
             "required": [
@@ -203,6 +2165,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2021-11-26T06:22:19Z"
                 },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2021-11-27T06:22:19Z"
+                },
                 "delivery": {
                     "$ref": "#/definitions/domain.Delivery"
                 },
@@ -252,6 +2218,98 @@ const docTemplate = `{
                 "track_number": {
                     "type": "string",
                     "example": "WBILMTESTTRACK"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                }
+            }
+        },
+        "domain.OrderEvent": {
+            "description": "Order change event",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1732602139000001
+                },
+                "order": {
+                    "$ref": "#/definitions/domain.Order"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "type": {
+                    "enum": [
+                        "order.created",
+                        "order.updated",
+                        "order.deleted",
+                        "order.restored"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EventType"
+                        }
+                    ],
+                    "example": "order.created"
+                }
+            }
+        },
+        "domain.OrderHistory": {
+            "description": "Change history of an order",
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.HistoryEvent"
+                    }
+                },
+                "order": {
+                    "$ref": "#/definitions/domain.Order"
+                },
+                "order_uid": {
+                    "type": "string",
+                    "example": "b563feb7b2b84b6test"
+                }
+            }
+        },
+        "domain.OrderLookup": {
+            "type": "object",
+            "properties": {
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Order"
+                    }
+                }
+            }
+        },
+        "domain.OrderPage": {
+            "description": "Page of orders",
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MjAyMS0xMS0yNlQwNjoyMjoxOVp8YjU2M2ZlYjdiMmI4NGI2dGVzdA"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Order"
+                    }
                 }
             }
         },
@@ -276,6 +2334,14 @@ const docTemplate = `{
                     "type": "string",
                     "example": "alpha"
                 },
+                "base_amount": {
+                    "type": "number",
+                    "example": 1817
+                },
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
                 "currency": {
                     "type": "string",
                     "example": "USD"
@@ -289,6 +2355,10 @@ const docTemplate = `{
                     "type": "number",
                     "example": 1500
                 },
+                "exchange_rate": {
+                    "type": "number",
+                    "example": 1
+                },
                 "goods_total": {
                     "type": "integer",
                     "minimum": 0,
@@ -313,6 +2383,136 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.SearchHit": {
+            "description": "Search result",
+            "type": "object",
+            "properties": {
+                "highlights": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "\u003cmark\u003eVivienne\u003c/mark\u003e Sabo Mascaras"
+                    ]
+                },
+                "order": {
+                    "$ref": "#/definitions/domain.Order"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.0607927
+                }
+            }
+        },
+        "domain.SearchPage": {
+            "description": "Page of search results",
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MjA"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SearchHit"
+                    }
+                }
+            }
+        },
+        "domain.StatsDimension": {
+            "type": "string",
+            "enum": [
+                "provider",
+                "bank",
+                "delivery_service",
+                "currency",
+                "locale"
+            ],
+            "x-enum-varnames": [
+                "DimensionProvider",
+                "DimensionBank",
+                "DimensionDeliveryService",
+                "DimensionCurrency",
+                "DimensionLocale"
+            ]
+        },
+        "domain.TopBrandsReport": {
+            "description": "Top brands of a period",
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DailyBrands"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2021-11-01T00:00:00Z"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2021-12-01T00:00:00Z"
+                }
+            }
+        },
+        "handlers.EraseRequest": {
+            "description": "Erasure request",
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "GDPR art. 17 request #42"
+                }
+            }
+        },
+        "handlers.LookupRequest": {
+            "description": "Bulk order lookup request",
+            "type": "object",
+            "properties": {
+                "order_uids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "b563feb7b2b84b6test",
+                        "a1b2c3d4e5f6test"
+                    ]
+                }
+            }
+        },
+        "handlers.TrackMessage": {
+            "description": "WebSocket server message",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/domain.OrderEvent"
+                },
+                "order": {
+                    "$ref": "#/definitions/domain.Order"
+                },
+                "order_uid": {
+                    "type": "string",
+                    "example": "b563feb7b2b84b6test"
+                },
+                "order_uids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "event"
+                }
+            }
+        },
         "response.ErrorResponse": {
             "description": "Error response structure",
             "type": "object",
@@ -328,6 +2528,19 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT bearer token, e.g. \"Bearer eyJhbGciOi...\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -3,16 +3,24 @@ package application
 import (
 	"context"
 	"github.com/Killazius/L0/internal/application/kafka"
+	"github.com/Killazius/L0/internal/auth"
 	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/lib/broadcast"
+	"github.com/Killazius/L0/internal/lib/envelope"
+	"github.com/Killazius/L0/internal/lib/ratelimit"
+	"github.com/Killazius/L0/internal/lib/rates"
 	"github.com/Killazius/L0/internal/repository"
 	"github.com/Killazius/L0/internal/repository/cache"
 	"github.com/Killazius/L0/internal/repository/postgresql"
 	"github.com/Killazius/L0/internal/service"
+	"github.com/Killazius/L0/internal/transport/gql"
 	"github.com/Killazius/L0/internal/transport/rest"
 	"github.com/Killazius/L0/internal/transport/rest/handlers"
+	"github.com/Killazius/L0/internal/transport/rpc"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -20,8 +28,15 @@ import (
 type Application struct {
 	log         *zap.SugaredLogger
 	server      *rest.Server
+	grpcServer  *rpc.Server
 	consumer    *kafka.Consumer
+	tracker     *broadcast.Tracker
+	purger      *Purger
+	partitioner *Partitioner
+	refresher   *StatsRefresher
+	generator   *Generator
 	pool        *pgxpool.Pool
+	replicas    *postgresql.Replicas
 	cacheClient *redis.Client
 	wg          sync.WaitGroup
 }
@@ -31,34 +46,169 @@ func New(log *zap.SugaredLogger, cfg *config.Config) *Application {
 	if err != nil {
 		log.Fatalw("error creating postgres pool", "error", err)
 	}
+	if cfg.Postgres.AutoMigrate {
+		if err = postgresql.Migrate(context.Background(), log, pool); err != nil {
+			log.Fatalw("error migrating database", "error", err)
+		}
+	}
 	client, err := cache.CreateClient(cfg.Redis)
 	if err != nil {
 		log.Fatalw("error creating redis client", "error", err)
 	}
-	orderRepo := postgresql.New(pool)
-	orderCache := cache.New(client)
+	keyring, err := LoadKeyring(cfg.Encryption)
+	if err != nil {
+		log.Fatalw("error loading encryption keys", "error", err)
+	}
+	orderRepo := postgresql.New(pool, keyring)
+	var replicas *postgresql.Replicas
+	if len(cfg.Postgres.Replicas.DSNs) > 0 {
+		if cfg.Postgres.Replicas.CheckInterval <= 0 {
+			log.Fatal("replica check interval must be positive")
+		}
+		replicaPools, err := postgresql.CreateReplicaPools(cfg.Postgres)
+		if err != nil {
+			log.Fatalw("error creating replica pools", "error", err)
+		}
+		replicas = postgresql.NewReplicas(log, replicaPools, cfg.Postgres.Replicas)
+		replicas.Check(context.Background())
+		orderRepo.WithReplicas(replicas)
+	}
+	orderCache := cache.New(client, keyring)
 
 	if err = repository.Restore(context.Background(), orderRepo, orderCache, 10); err != nil {
 		log.Fatalw("error restoring order", "error", err)
 	}
 
-	orderService := service.New(orderRepo, orderCache)
-	handler := handlers.New(log, orderService)
+	var limiter rest.RateLimiter = ratelimit.NewMemory()
+	if cfg.HTTPServer.RateLimit.Redis {
+		limiter = ratelimit.NewRedis(client)
+	}
+
+	app, orderService := newApplication(log, cfg, orderRepo, orderCache, limiter)
+	app.consumer = kafka.NewConsumer(log, orderService, cfg.Kafka)
+	app.pool = pool
+	app.replicas = replicas
+	app.cacheClient = client
+	return app
+}
+
+// newApplication wires the service and its transports over the repository and
+// the cache.
+func newApplication(log *zap.SugaredLogger, cfg *config.Config, orderRepo service.OrderRepository, orderCache service.OrderCache, limiter rest.RateLimiter) (*Application, *service.Service) {
+	authenticator, err := auth.New(cfg.Auth)
+	if err != nil {
+		log.Fatalw("error creating authenticator", "error", err)
+	}
+
+	broadcaster := broadcast.New(cfg.Stream.ReplayBuffer, cfg.Stream.ClientBuffer)
+	orderService := service.New(orderRepo, orderCache, broadcaster).
+		WithBaseCurrency(cfg.Rates.BaseCurrency).
+		WithErasureSecret(cfg.Privacy.ErasureSecret)
+	if cfg.Rates.File != "" {
+		if err = loadRates(context.Background(), log, orderService, cfg.Rates.File); err != nil {
+			log.Fatalw("error loading exchange rates", "error", err)
+		}
+	}
+	tracker := broadcast.NewTracker(broadcaster, cfg.Stream.ClientBuffer, cfg.Stream.MaxTrackedOrders)
+	handler := handlers.New(log, orderService, broadcaster, tracker)
+
+	var graphQL http.Handler
+	if cfg.HTTPServer.GraphQL.Enabled {
+		graphQL, err = gql.NewHandler(log, orderService, cfg.HTTPServer.GraphQL)
+		if err != nil {
+			log.Fatalw("error creating graphql handler", "error", err)
+		}
+	}
+
+	var grpcServer *rpc.Server
+	if cfg.GRPCServer.Enabled {
+		grpcServer = rpc.NewServer(log, orderService, broadcaster, authenticator, cfg.GRPCServer)
+	}
+
+	var purger *Purger
+	if cfg.Retention.DeletedOrders > 0 && cfg.Retention.PurgeInterval > 0 {
+		purger = NewPurger(log, orderService, cfg.Retention)
+	}
+	var partitioner *Partitioner
+	if cfg.Partitions.Interval > 0 {
+		partitioner = NewPartitioner(log, orderService, cfg.Partitions)
+	}
+	var refresher *StatsRefresher
+	if cfg.Stats.RefreshInterval > 0 {
+		refresher = NewStatsRefresher(log, orderService, cfg.Stats)
+	}
 
 	return &Application{
 		log:         log,
-		server:      rest.NewServer(log, handler, cfg.HTTPServer),
-		consumer:    kafka.NewConsumer(log, orderService, cfg.Kafka),
-		pool:        pool,
-		cacheClient: client,
+		server:      rest.NewServer(log, handler, authenticator, limiter, graphQL, cfg.HTTPServer),
+		grpcServer:  grpcServer,
+		tracker:     tracker,
+		purger:      purger,
+		partitioner: partitioner,
+		refresher:   refresher,
+	}, orderService
+}
+
+// loadRates saves the exchange rates of the file.
+func loadRates(ctx context.Context, log *zap.SugaredLogger, orderService *service.Service, path string) error {
+	exchangeRates, err := rates.Load(path)
+	if err != nil {
+		return err
+	}
+	update, err := orderService.SaveExchangeRates(ctx, exchangeRates)
+	if err != nil {
+		return err
 	}
+	log.Infow("loaded exchange rates", "file", path, "saved", update.Saved, "converted", update.Converted)
+	return nil
+}
+
+// LoadKeyring returns nil when encryption is not configured.
+func LoadKeyring(cfg config.EncryptionConfig) (*envelope.Keyring, error) {
+	if cfg.KeyFile == "" {
+		return nil, nil
+	}
+	return envelope.Load(cfg.KeyFile)
 }
 
 func (a *Application) Run(ctx context.Context) {
 	a.wg.Go(a.server.MustRun)
+	if a.grpcServer != nil {
+		a.wg.Go(a.grpcServer.MustRun)
+	}
+	if a.consumer != nil {
+		a.wg.Go(func() {
+			a.consumer.Run(ctx)
+		})
+	}
+	if a.generator != nil {
+		a.wg.Go(func() {
+			a.generator.Run(ctx)
+		})
+	}
 	a.wg.Go(func() {
-		a.consumer.Run(ctx)
+		a.tracker.Run(ctx)
 	})
+	if a.replicas != nil {
+		a.wg.Go(func() {
+			a.replicas.Run(ctx)
+		})
+	}
+	if a.purger != nil {
+		a.wg.Go(func() {
+			a.purger.Run(ctx)
+		})
+	}
+	if a.partitioner != nil {
+		a.wg.Go(func() {
+			a.partitioner.Run(ctx)
+		})
+	}
+	if a.refresher != nil {
+		a.wg.Go(func() {
+			a.refresher.Run(ctx)
+		})
+	}
 }
 
 func (a *Application) Stop() {
@@ -68,13 +218,26 @@ func (a *Application) Stop() {
 	if err := a.server.Close(ctx); err != nil {
 		a.log.Errorw("failed to stop HTTP server gracefully", "error", err)
 	}
+	if a.grpcServer != nil {
+		a.log.Info("closing gRPC server")
+		if err := a.grpcServer.Close(ctx); err != nil {
+			a.log.Errorw("failed to stop gRPC server gracefully", "error", err)
+		}
+	}
 
-	a.log.Info("closing Kafka consumer")
-	if err := a.consumer.Close(); err != nil {
-		a.log.Errorw("failed to stop Kafka consumer gracefully", "error", err)
+	if a.consumer != nil {
+		a.log.Info("closing Kafka consumer")
+		if err := a.consumer.Close(); err != nil {
+			a.log.Errorw("failed to stop Kafka consumer gracefully", "error", err)
+		}
+	}
+	if a.pool != nil {
+		a.log.Info("closing database connections")
+		a.pool.Close()
+	}
+	if a.replicas != nil {
+		a.replicas.Close()
 	}
-	a.log.Info("closing database connections")
-	a.pool.Close()
 	done := make(chan struct{})
 	go func() {
 		a.wg.Wait()
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/ratelimit"
+	"github.com/Killazius/L0/internal/lib/test"
+	"github.com/Killazius/L0/internal/repository/memory"
+	"github.com/Killazius/L0/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+// demoActor is recorded as the author of the generated orders.
+const demoActor = "demo"
+
+// DemoConfig configures the demo mode: Orders orders are generated at start
+// and one more every Interval. Zero Interval generates none after the start.
+type DemoConfig struct {
+	Orders   int
+	Interval time.Duration
+}
+
+// NewDemo creates the application on the in-memory repository and cache with
+// generated orders instead of the Kafka consumer, so it runs without
+// PostgreSQL, Redis and Kafka. Orders are lost on exit.
+func NewDemo(log *zap.SugaredLogger, cfg *config.Config, demo DemoConfig) *Application {
+	app, orderService := newApplication(log, cfg, memory.New(), memory.NewCache(), ratelimit.NewMemory())
+	app.generator = NewGenerator(log, orderService, demo)
+	return app
+}
+
+// Generator stores generated orders, a stand-in for the Kafka producer.
+type Generator struct {
+	log     *zap.SugaredLogger
+	service *service.Service
+	cfg     DemoConfig
+}
+
+func NewGenerator(log *zap.SugaredLogger, service *service.Service, cfg DemoConfig) *Generator {
+	return &Generator{log: log, service: service, cfg: cfg}
+}
+
+// Run generates the initial orders and then one every interval until ctx is
+// done.
+func (g *Generator) Run(ctx context.Context) {
+	ctx = domain.WithActor(ctx, demoActor)
+	for range g.cfg.Orders {
+		g.generate(ctx)
+	}
+	g.log.Infow("demo orders generated", "orders", g.cfg.Orders)
+	if g.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(g.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.generate(ctx)
+		}
+	}
+}
+
+func (g *Generator) generate(ctx context.Context) {
+	order := test.GenerateOrder()
+	if err := g.service.CreateOrder(ctx, order); err != nil && ctx.Err() == nil {
+		g.log.Warnw("failed to store demo order", "order_uid", order.OrderUID, "error", err)
+	}
+}
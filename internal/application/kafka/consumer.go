@@ -12,10 +12,14 @@ import (
 	"time"
 )
 
+// actor is recorded in the order history for changes read from the topic.
+const actor = "kafka"
+
 type Consumer struct {
 	reader  *kafka.Reader
 	service *service.Service
 	log     *zap.SugaredLogger
+	upsert  bool
 }
 
 func NewConsumer(logger *zap.SugaredLogger, service *service.Service, cfg config.KafkaConfig) *Consumer {
@@ -46,6 +50,7 @@ func NewConsumer(logger *zap.SugaredLogger, service *service.Service, cfg config
 		reader:  reader,
 		service: service,
 		log:     logger,
+		upsert:  cfg.WriteMode == config.WriteModeUpsert,
 	}
 }
 
@@ -82,16 +87,25 @@ func (c *Consumer) Consume(ctx context.Context) error {
 	}
 	log := c.log.With(zap.String("order_uid", order.OrderUID))
 	log.Infow("read message")
-	if err = c.service.CreateOrder(ctx, order); err != nil {
+	ctx = domain.WithActor(ctx, actor)
+	if c.upsert {
+		err = c.service.UpsertOrder(ctx, order)
+	} else {
+		err = c.service.CreateOrder(ctx, order)
+	}
+	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrOrderAlreadyExists):
 			log.Warnw("order already exists")
 			return nil
+		case errors.Is(err, service.ErrStaleOrder):
+			log.Warnw("stale order version", "version", order.Version)
+			return nil
 		case errors.Is(err, service.ErrInvalidOrderData):
 			log.Warnw("invalid order data", "error", err)
 			return nil
 		default:
-			log.Errorw("create order", "error", err)
+			log.Errorw("write order", "error", err)
 			return err
 		}
 	}
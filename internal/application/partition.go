@@ -0,0 +1,41 @@
+package application
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Partitioner creates the future orders partitions and detaches the old ones.
+type Partitioner struct {
+	log     *zap.SugaredLogger
+	service *service.Service
+	cfg     config.PartitionConfig
+}
+
+func NewPartitioner(log *zap.SugaredLogger, service *service.Service, cfg config.PartitionConfig) *Partitioner {
+	return &Partitioner{log: log, service: service, cfg: cfg}
+}
+
+// Run maintains the partitions once at start and then every interval until ctx
+// is done.
+func (p *Partitioner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		created, detached, err := p.service.MaintainPartitions(ctx, p.cfg.Premake, p.cfg.RetainMonths)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			p.log.Errorw("failed to maintain order partitions", "error", err)
+		case created > 0 || len(detached) > 0:
+			p.log.Infow("maintained order partitions", "created", created, "detached", detached)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
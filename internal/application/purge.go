@@ -0,0 +1,40 @@
+package application
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Purger hard-deletes the orders deleted longer than the retention window.
+type Purger struct {
+	log     *zap.SugaredLogger
+	service *service.Service
+	cfg     config.RetentionConfig
+}
+
+func NewPurger(log *zap.SugaredLogger, service *service.Service, cfg config.RetentionConfig) *Purger {
+	return &Purger{log: log, service: service, cfg: cfg}
+}
+
+// Run purges once at start and then every purge interval until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		purged, err := p.service.PurgeDeletedOrders(ctx, p.cfg.DeletedOrders, p.cfg.PurgeBatch)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			p.log.Errorw("failed to purge deleted orders", "purged", purged, "error", err)
+		case purged > 0:
+			p.log.Infow("purged deleted orders", "purged", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
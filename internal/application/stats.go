@@ -0,0 +1,42 @@
+package application
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+// StatsRefresher refreshes the daily statistics.
+type StatsRefresher struct {
+	log     *zap.SugaredLogger
+	service *service.Service
+	cfg     config.StatsConfig
+}
+
+func NewStatsRefresher(log *zap.SugaredLogger, service *service.Service, cfg config.StatsConfig) *StatsRefresher {
+	return &StatsRefresher{log: log, service: service, cfg: cfg}
+}
+
+// Run refreshes the statistics once at start and then every refresh interval
+// until ctx is done. A refresh that another instance is running is skipped.
+func (s *StatsRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		refreshed, err := s.service.RefreshStats(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			s.log.Errorw("failed to refresh stats", "error", err)
+		case refreshed:
+			s.log.Debugw("refreshed stats", "duration", time.Since(start))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUnknownRole        = errors.New("unknown role")
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	MethodAPIKey    = "api_key"
+	MethodJWT       = "jwt"
+	MethodAnonymous = "anonymous"
+)
+
+type Role string
+
+const (
+	RoleViewer  Role = "viewer"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleViewer:  1,
+	RoleSupport: 2,
+	RoleAdmin:   3,
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRole, s)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants at least the privileges of required.
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Role    Role
+	Method  string
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+type apiKey struct {
+	name string
+	role Role
+}
+
+// Authenticator resolves a Principal from static API keys or JWT bearer tokens.
+// When authentication is disabled every request is treated as an anonymous
+// viewer: it can read orders with masked contact data, but cannot reach the
+// write, erase and admin endpoints, which only exist behind authentication.
+type Authenticator struct {
+	enabled   bool
+	apiKeys   map[string]apiKey
+	keys      *KeySet
+	parser    *jwt.Parser
+	roleClaim string
+}
+
+func New(cfg config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		enabled:   cfg.Enabled,
+		apiKeys:   make(map[string]apiKey, len(cfg.APIKeys)),
+		roleClaim: cfg.RoleClaim,
+	}
+	if !cfg.Enabled {
+		return a, nil
+	}
+	for _, k := range cfg.APIKeys {
+		role, err := ParseRole(k.Role)
+		if err != nil {
+			return nil, fmt.Errorf("api key %q: %w", k.Name, err)
+		}
+		hash := strings.ToLower(k.Hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("api key %q: hash must be a hex encoded sha256 digest", k.Name)
+		}
+		a.apiKeys[hash] = apiKey{name: k.Name, role: role}
+	}
+	if cfg.JWKSPath != "" {
+		keys, err := LoadKeySet(cfg.JWKSPath)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+		opts := []jwt.ParserOption{
+			jwt.WithValidMethods(supportedAlgorithms),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(cfg.Leeway),
+		}
+		if cfg.Issuer != "" {
+			opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+		}
+		if cfg.Audience != "" {
+			opts = append(opts, jwt.WithAudience(cfg.Audience))
+		}
+		a.parser = jwt.NewParser(opts...)
+	}
+	return a, nil
+}
+
+// HashAPIKey returns the digest under which an API key is stored in the config.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	return a.AuthenticateCredentials(r.Header.Get(APIKeyHeader), r.Header.Get("Authorization"))
+}
+
+// AuthenticateCredentials resolves the caller from an API key or the value of
+// an Authorization header, for transports other than HTTP.
+func (a *Authenticator) AuthenticateCredentials(key, authorization string) (Principal, error) {
+	if !a.enabled {
+		return Principal{Subject: "anonymous", Role: RoleViewer, Method: MethodAnonymous}, nil
+	}
+	if key != "" {
+		return a.AuthenticateAPIKey(key)
+	}
+	if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		return a.AuthenticateToken(strings.TrimSpace(token))
+	}
+	return Principal{}, ErrUnauthenticated
+}
+
+func (a *Authenticator) AuthenticateAPIKey(key string) (Principal, error) {
+	k, ok := a.apiKeys[HashAPIKey(key)]
+	if !ok {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return Principal{Subject: k.name, Role: k.role, Method: MethodAPIKey}, nil
+}
+
+func (a *Authenticator) AuthenticateToken(token string) (Principal, error) {
+	if a.parser == nil {
+		return Principal{}, fmt.Errorf("%w: bearer tokens are not configured", ErrInvalidCredentials)
+	}
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.keys.Keyfunc); err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	role, err := a.roleFromClaims(claims)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	return Principal{Subject: subject, Role: role, Method: MethodJWT}, nil
+}
+
+// roleFromClaims accepts either a single role or a list and picks the highest one.
+func (a *Authenticator) roleFromClaims(claims jwt.MapClaims) (Role, error) {
+	var values []string
+	switch v := claims[a.roleClaim].(type) {
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	var best Role
+	for _, v := range values {
+		role, err := ParseRole(v)
+		if err != nil {
+			continue
+		}
+		if role.Allows(best) {
+			best = role
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("%w: claim %q", ErrUnknownRole, a.roleClaim)
+	}
+	return best, nil
+}
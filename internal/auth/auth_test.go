@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Killazius/L0/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+	set := map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAuthenticator_APIKey(t *testing.T) {
+	t.Parallel()
+
+	a, err := New(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "dashboard", Hash: HashAPIKey("secret-viewer"), Role: "viewer"},
+			{Name: "ops", Hash: HashAPIKey("secret-admin"), Role: "ADMIN"},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		key      string
+		expected Principal
+		err      error
+	}{
+		{name: "viewer key", key: "secret-viewer", expected: Principal{Subject: "dashboard", Role: RoleViewer, Method: MethodAPIKey}},
+		{name: "admin key", key: "secret-admin", expected: Principal{Subject: "ops", Role: RoleAdmin, Method: MethodAPIKey}},
+		{name: "unknown key", key: "nope", err: ErrInvalidCredentials},
+		{name: "missing credentials", err: ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest("GET", "/order/1", nil)
+			require.NoError(t, err)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			p, err := a.Authenticate(req)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+}
+
+func TestAuthenticator_JWT(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	a, err := New(config.AuthConfig{
+		Enabled:   true,
+		JWKSPath:  writeJWKS(t, "k1", &key.PublicKey),
+		Issuer:    "https://issuer.local",
+		Audience:  "orders",
+		RoleClaim: "role",
+	})
+	require.NoError(t, err)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":  "agent-7",
+			"iss":  "https://issuer.local",
+			"aud":  "orders",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+			"role": "support",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		token    func() string
+		expected Role
+		err      error
+	}{
+		{
+			name:     "valid token",
+			token:    func() string { return signToken(t, key, "k1", valid()) },
+			expected: RoleSupport,
+		},
+		{
+			name: "highest role from list",
+			token: func() string {
+				c := valid()
+				c["role"] = []any{"viewer", "admin", "unknown"}
+				return signToken(t, key, "k1", c)
+			},
+			expected: RoleAdmin,
+		},
+		{
+			name: "expired token",
+			token: func() string {
+				c := valid()
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+				return signToken(t, key, "k1", c)
+			},
+			err: ErrInvalidCredentials,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := valid()
+				c["aud"] = "billing"
+				return signToken(t, key, "k1", c)
+			},
+			err: ErrInvalidCredentials,
+		},
+		{
+			name:  "signed by unknown key",
+			token: func() string { return signToken(t, otherKey, "k1", valid()) },
+			err:   ErrInvalidCredentials,
+		},
+		{
+			name:  "unknown kid",
+			token: func() string { return signToken(t, key, "k2", valid()) },
+			err:   ErrInvalidCredentials,
+		},
+		{
+			name: "missing role",
+			token: func() string {
+				c := valid()
+				delete(c, "role")
+				return signToken(t, key, "k1", c)
+			},
+			err: ErrInvalidCredentials,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest("GET", "/order/1", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+tt.token())
+			p, err := a.Authenticate(req)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "agent-7", p.Subject)
+			assert.Equal(t, tt.expected, p.Role)
+			assert.Equal(t, MethodJWT, p.Method)
+		})
+	}
+}
+
+func TestAuthenticator_Disabled(t *testing.T) {
+	t.Parallel()
+
+	a, err := New(config.AuthConfig{Enabled: false})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("GET", "/order/1", nil)
+	require.NoError(t, err)
+	p, err := a.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, RoleViewer, p.Role)
+	assert.Equal(t, MethodAnonymous, p.Method)
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{{Name: "bad", Hash: HashAPIKey("x"), Role: "root"}},
+	})
+	require.ErrorIs(t, err, ErrUnknownRole)
+
+	_, err = New(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{{Name: "bad", Hash: "plaintext", Role: "viewer"}},
+	})
+	require.Error(t, err)
+}
+
+func TestRole_Allows(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, RoleAdmin.Allows(RoleSupport))
+	assert.True(t, RoleSupport.Allows(RoleSupport))
+	assert.False(t, RoleViewer.Allows(RoleSupport))
+	assert.False(t, Role("").Allows(RoleViewer))
+}
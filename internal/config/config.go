@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
+	"io/fs"
 	"log"
 	"net"
 	"os"
@@ -11,20 +13,69 @@ import (
 )
 
 type Config struct {
-	Postgres   PostgresConfig `yaml:"postgres"`
-	Logger     LoggerConfig   `yaml:"logger"`
-	HTTPServer HTTPConfig     `yaml:"http_server"`
-	Kafka      KafkaConfig    `yaml:"kafka"`
-	Redis      RedisConfig    `yaml:"redis"`
+	Postgres   PostgresConfig   `yaml:"postgres"`
+	Logger     LoggerConfig     `yaml:"logger"`
+	HTTPServer HTTPConfig       `yaml:"http_server"`
+	GRPCServer GRPCConfig       `yaml:"grpc_server"`
+	Kafka      KafkaConfig      `yaml:"kafka"`
+	Redis      RedisConfig      `yaml:"redis"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Encryption EncryptionConfig `yaml:"encryption"`
+	Privacy    PrivacyConfig    `yaml:"privacy"`
+	Stream     StreamConfig     `yaml:"stream"`
+	Retention  RetentionConfig  `yaml:"retention"`
+	Partitions PartitionConfig  `yaml:"partitions"`
+	Stats      StatsConfig      `yaml:"stats"`
+	Rates      RatesConfig      `yaml:"exchange_rates"`
 }
 
 type HTTPConfig struct {
-	Port        string        `yaml:"port" env:"HTTP_PORT" env-default:"8080"`
-	Host        string        `yaml:"host" env:"HTTP_HOST" env-default:"localhost"`
-	Timeout     time.Duration `yaml:"timeout" env:"HTTP_TIMEOUT" env-default:"30s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" env-default:"60s"`
+	Port        string          `yaml:"port" env:"HTTP_PORT" env-default:"8080"`
+	Host        string          `yaml:"host" env:"HTTP_HOST" env-default:"localhost"`
+	Timeout     time.Duration   `yaml:"timeout" env:"HTTP_TIMEOUT" env-default:"30s"`
+	IdleTimeout time.Duration   `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" env-default:"60s"`
+	RateLimit   RateLimitConfig `yaml:"rate_limit"`
+	GraphQL     GraphQLConfig   `yaml:"graphql"`
 }
 
+// GraphQLConfig configures the /graphql endpoint. MaxComplexity is the budget
+// of one request: a list costs its page size times the selected fields.
+// GraphiQL serves the query editor at /graphiql and is meant for development.
+type GraphQLConfig struct {
+	Enabled       bool `yaml:"enabled" env:"GRAPHQL_ENABLED" env-default:"true"`
+	GraphiQL      bool `yaml:"graphiql" env:"GRAPHQL_GRAPHIQL" env-default:"false"`
+	MaxDepth      int  `yaml:"max_depth" env-default:"8"`
+	MaxComplexity int  `yaml:"max_complexity" env-default:"2500"`
+}
+
+// RateLimitConfig configures per-client token buckets. Rate and Burst apply to
+// every route without an entry in Routes, which is keyed by route name.
+// With Redis enabled the buckets are shared between replicas.
+type RateLimitConfig struct {
+	Enabled bool                  `yaml:"enabled" env:"HTTP_RATE_LIMIT_ENABLED" env-default:"false"`
+	Redis   bool                  `yaml:"redis" env:"HTTP_RATE_LIMIT_REDIS" env-default:"false"`
+	Rate    float64               `yaml:"rate" env-default:"20"`
+	Burst   int                   `yaml:"burst" env-default:"40"`
+	Routes  map[string]RouteLimit `yaml:"routes"`
+}
+
+// GRPCConfig configures the gRPC API for internal services. It shares the
+// authentication settings of the REST API.
+type GRPCConfig struct {
+	Enabled    bool   `yaml:"enabled" env:"GRPC_ENABLED" env-default:"true"`
+	Port       string `yaml:"port" env:"GRPC_PORT" env-default:"9090"`
+	Host       string `yaml:"host" env:"GRPC_HOST" env-default:"localhost"`
+	Reflection bool   `yaml:"reflection" env:"GRPC_REFLECTION" env-default:"true"`
+}
+
+type RouteLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// PostgresConfig configures the database. The migrations are embedded into the
+// binaries: MigrationsPath is only where the migrator creates new ones, and
+// AutoMigrate applies the pending ones when the service starts.
 type PostgresConfig struct {
 	Host     string `env:"POSTGRES_HOST" env-default:"localhost"`
 	Port     string `env:"POSTGRES_PORT" env-default:"5432"`
@@ -39,12 +90,104 @@ type PostgresConfig struct {
 	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env-default:"30m"`
 	Timeout         time.Duration `yaml:"timeout" env-default:"5s"`
 	MigrationsPath  string        `yaml:"migrations_path" env-default:"./migrations"`
+	AutoMigrate     bool          `yaml:"auto_migrate" env:"POSTGRES_AUTO_MIGRATE" env-default:"false"`
+	Replicas        ReplicaConfig `yaml:"replicas"`
+}
+
+// ReplicaConfig lists the read replicas of the database. Read-only order
+// queries go to a replica that passed the last health check, run every
+// CheckInterval, and lagged at most MaxLag behind the primary, otherwise to
+// the primary. An order written less than ReadYourWrites ago is always read
+// from the primary.
+type ReplicaConfig struct {
+	DSNs           []string      `yaml:"dsns" env:"POSTGRES_REPLICA_DSNS" env-separator:","`
+	CheckInterval  time.Duration `yaml:"check_interval" env-default:"5s"`
+	MaxLag         time.Duration `yaml:"max_lag" env-default:"5s"`
+	ReadYourWrites time.Duration `yaml:"read_your_writes" env-default:"10s"`
 }
+
+// RedisConfig configures the cache. Address and Password are required unless
+// the service runs in the demo mode, see cache.CreateClient.
 type RedisConfig struct {
-	Address  string `env:"REDIS_ADDR" env-required:"true"`
-	Password string `env:"REDIS_PASSWORD" env-required:"true"`
+	Address  string `env:"REDIS_ADDR"`
+	Password string `env:"REDIS_PASSWORD"`
 	DB       int    `env:"REDIS_DB" env-default:"0"`
 }
+type AuthConfig struct {
+	Enabled   bool           `yaml:"enabled" env:"AUTH_ENABLED" env-default:"false"`
+	APIKeys   []APIKeyConfig `yaml:"api_keys"`
+	JWKSPath  string         `yaml:"jwks_path" env:"AUTH_JWKS_PATH"`
+	Issuer    string         `yaml:"issuer" env:"AUTH_ISSUER"`
+	Audience  string         `yaml:"audience" env:"AUTH_AUDIENCE"`
+	RoleClaim string         `yaml:"role_claim" env-default:"role"`
+	Leeway    time.Duration  `yaml:"leeway" env-default:"30s"`
+}
+
+// APIKeyConfig describes a static API key. Hash is the hex encoded sha256 digest
+// of the key, so the key itself never appears in the config.
+type APIKeyConfig struct {
+	Name string `yaml:"name"`
+	Hash string `yaml:"hash"`
+	Role string `yaml:"role"`
+}
+
+// EncryptionConfig enables envelope encryption of customer contact data.
+// An empty KeyFile stores the data in plain text.
+type EncryptionConfig struct {
+	KeyFile string `yaml:"key_file" env:"ENCRYPTION_KEY_FILE"`
+}
+
+// PrivacyConfig configures customer erasure. ErasureSecret keys the customer
+// hashes of erasure records; without it a random secret is used per process
+// and the hashes cannot be matched against customer ids later.
+type PrivacyConfig struct {
+	ErasureSecret string `yaml:"erasure_secret" env:"ERASURE_SECRET"`
+}
+
+// StreamConfig sizes the in-process broadcaster of live order events.
+type StreamConfig struct {
+	ReplayBuffer int `yaml:"replay_buffer" env-default:"1000"`
+	ClientBuffer int `yaml:"client_buffer" env-default:"64"`
+	// MaxTrackedOrders limits the orders one WebSocket client can track.
+	MaxTrackedOrders int `yaml:"max_tracked_orders" env-default:"100"`
+}
+
+// RetentionConfig configures the purge of deleted orders. Orders deleted more
+// than DeletedOrders ago are hard-deleted every PurgeInterval, PurgeBatch per
+// transaction. Zero DeletedOrders or PurgeInterval keeps deleted orders forever.
+type RetentionConfig struct {
+	DeletedOrders time.Duration `yaml:"deleted_orders" env:"RETENTION_DELETED_ORDERS" env-default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"RETENTION_PURGE_INTERVAL" env-default:"1h"`
+	PurgeBatch    int           `yaml:"purge_batch" env:"RETENTION_PURGE_BATCH" env-default:"500"`
+}
+
+// PartitionConfig configures the maintenance of the monthly orders
+// partitions. Every Interval the partitions of the current month and the
+// Premake months after it are created. With a positive RetainMonths the
+// partitions older than that many months before the current one are detached
+// for cmd/archiver. Zero Interval disables the maintenance.
+type PartitionConfig struct {
+	Interval     time.Duration `yaml:"interval" env:"PARTITION_INTERVAL" env-default:"24h"`
+	Premake      int           `yaml:"premake" env:"PARTITION_PREMAKE" env-default:"3"`
+	RetainMonths int           `yaml:"retain_months" env:"PARTITION_RETAIN_MONTHS" env-default:"0"`
+}
+
+// StatsConfig configures the refresh of the daily statistics served by
+// /stats. They lag behind the orders by up to RefreshInterval. Zero
+// RefreshInterval disables the refresh.
+type StatsConfig struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"STATS_REFRESH_INTERVAL" env-default:"15m"`
+}
+
+// RatesConfig configures the conversion of payment amounts to BaseCurrency.
+// The dated rates of File, a CSV or JSON file, are saved on start; they can
+// also be saved with the admin API. An empty BaseCurrency disables the
+// conversion.
+type RatesConfig struct {
+	BaseCurrency string `yaml:"base_currency" env:"EXCHANGE_RATES_BASE_CURRENCY" env-default:"USD"`
+	File         string `yaml:"file" env:"EXCHANGE_RATES_FILE"`
+}
+
 type LoggerConfig struct {
 	Path string `yaml:"path"`
 }
@@ -62,10 +205,16 @@ type KafkaConfig struct {
 	RetryBackoff     time.Duration `yaml:"retry_backoff" env-default:"100ms"`
 	EnableAutoCommit bool          `yaml:"enable_auto_commit" env-default:"false"`
 	CommitInterval   time.Duration `yaml:"commit_interval" env-default:"1s"`
+	// WriteMode is insert to skip orders that already exist or upsert to
+	// replace them with newer versions.
+	WriteMode string `yaml:"write_mode" env-default:"insert"`
 }
 
 const (
 	defaultConfigPath = "config/config.yaml"
+
+	WriteModeInsert = "insert"
+	WriteModeUpsert = "upsert"
 )
 
 func MustLoad() *Config {
@@ -78,7 +227,7 @@ func MustLoad() *Config {
 
 func load() (*Config, error) {
 	err := godotenv.Load()
-	if err != nil {
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Fatal("Error loading .env file")
 	}
 	configPath := getConfigPath()
@@ -98,6 +247,10 @@ func load() (*Config, error) {
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to read env vars: %w", err)
 	}
+	// A mistyped write mode would otherwise silently drop corrected orders.
+	if cfg.Kafka.WriteMode != WriteModeInsert && cfg.Kafka.WriteMode != WriteModeUpsert {
+		return nil, fmt.Errorf("kafka write_mode must be %q or %q, got %q", WriteModeInsert, WriteModeUpsert, cfg.Kafka.WriteMode)
+	}
 
 	return &cfg, nil
 }
@@ -113,6 +266,18 @@ func getConfigPath() string {
 func (h *HTTPConfig) GetAddr() string {
 	return net.JoinHostPort(h.Host, h.Port)
 }
+
+func (g *GRPCConfig) GetAddr() string {
+	return net.JoinHostPort(g.Host, g.Port)
+}
+
+// Route returns the limit of the named route, falling back to the default one.
+func (c *RateLimitConfig) Route(name string) RouteLimit {
+	if l, ok := c.Routes[name]; ok {
+		return l
+	}
+	return RouteLimit{Rate: c.Rate, Burst: c.Burst}
+}
 func (p *PostgresConfig) GetURL() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", p.Username, p.Password, p.Host, p.Port, p.Database, p.SSLMode)
 }
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"slices"
+	"time"
+)
+
+// Customer is a customer with the contact details of the address used last
+// and the address book of every delivery address used in their orders
+// @Description Customer information
+type Customer struct {
+	CustomerID   string    `json:"customer_id" example:"test"`
+	Name         string    `json:"name" example:"Test Testov"`
+	Phone        string    `json:"phone" example:"+9720000000"`
+	Email        string    `json:"email" example:"test@gmail.com"`
+	FirstOrderAt time.Time `json:"first_order_at" example:"2021-11-26T06:22:19Z"`
+	LastOrderAt  time.Time `json:"last_order_at" example:"2021-11-26T06:22:19Z"`
+	Addresses    []Address `json:"addresses"`
+}
+
+// Address is an entry of the address book of a customer, most recently used
+// first.
+// @Description Delivery address of a customer
+type Address struct {
+	Delivery
+	FirstUsedAt time.Time `json:"first_used_at" example:"2021-11-26T06:22:19Z"`
+	LastUsedAt  time.Time `json:"last_used_at" example:"2021-11-26T06:22:19Z"`
+}
+
+// Amount is a sum of money in a currency
+// @Description Amount in a currency
+type Amount struct {
+	Currency string          `json:"currency" example:"USD"`
+	Amount   decimal.Decimal `json:"amount" example:"1817"`
+}
+
+// CustomerStats aggregates the orders of a customer that are not deleted.
+// LifetimeValue sums the payment amounts per currency, by currency, and
+// LifetimeValueBase sums them in the base currency when they are converted.
+type CustomerStats struct {
+	OrderCount        int        `json:"order_count" example:"1"`
+	LifetimeValue     []Amount   `json:"lifetime_value"`
+	LifetimeValueBase *BaseTotal `json:"lifetime_value_base,omitempty"`
+}
+
+// CustomerOrders is a page of the orders of a customer, newest first, with
+// the aggregates of all of them
+// @Description Orders of a customer with aggregates
+type CustomerOrders struct {
+	CustomerID string `json:"customer_id" example:"test"`
+	CustomerStats
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor,omitempty" example:"MjAyMS0xMS0yNlQwNjoyMjoxOVp8YjU2M2ZlYjdiMmI4NGI2dGVzdA"`
+}
+
+// AddAddress adds the delivery used at the given time to the address book, or
+// updates the entry of an equal address. The address book stays sorted.
+func (c *Customer) AddAddress(delivery Delivery, firstUsedAt, lastUsedAt time.Time) {
+	for i := range c.Addresses {
+		if c.Addresses[i].Delivery == delivery {
+			c.Addresses[i].FirstUsedAt = minTime(c.Addresses[i].FirstUsedAt, firstUsedAt)
+			c.Addresses[i].LastUsedAt = maxTime(c.Addresses[i].LastUsedAt, lastUsedAt)
+			c.sortAddresses()
+			return
+		}
+	}
+	c.Addresses = append(c.Addresses, Address{Delivery: delivery, FirstUsedAt: firstUsedAt, LastUsedAt: lastUsedAt})
+	c.sortAddresses()
+}
+
+// sortAddresses sorts the address book, most recently used first, and takes
+// the contact details from its first entry.
+func (c *Customer) sortAddresses() {
+	slices.SortStableFunc(c.Addresses, func(a, b Address) int {
+		return b.LastUsedAt.Compare(a.LastUsedAt)
+	})
+	if len(c.Addresses) > 0 {
+		c.Name, c.Phone, c.Email = c.Addresses[0].Name, c.Addresses[0].Phone, c.Addresses[0].Email
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
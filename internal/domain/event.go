@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+type EventType string
+
+const (
+	EventOrderCreated  EventType = "order.created"
+	EventOrderUpdated  EventType = "order.updated"
+	EventOrderErased   EventType = "order.erased"
+	EventOrderDeleted  EventType = "order.deleted"
+	EventOrderRestored EventType = "order.restored"
+	// EventOrderSnapshot records the state of an order stored before its
+	// history was kept, see HistoryEvent.
+	EventOrderSnapshot EventType = "order.snapshot"
+)
+
+// OrderEvent is a change of an order delivered to live subscribers
+// @Description Order change event
+type OrderEvent struct {
+	ID    uint64    `json:"id" example:"1732602139000001"`
+	Type  EventType `json:"type" example:"order.created" enums:"order.created,order.updated,order.deleted,order.restored"`
+	Time  time.Time `json:"time" example:"2021-11-26T06:22:19Z"`
+	Order *Order    `json:"order"`
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// SystemActor is the actor of changes made without a caller, such as the
+// history snapshot of an order stored before the history was kept.
+const SystemActor = "system"
+
+// HistoryEvent is a recorded change of an order. Diff is a JSON merge patch
+// (RFC 7396) from the previous state of the order to the new one; applied in
+// order, the diffs of all events rebuild the order. Contact fields of the
+// delivery are recorded masked.
+// @Description Recorded change of an order
+type HistoryEvent struct {
+	ID         int64          `json:"id" example:"1"`
+	Type       EventType      `json:"type" example:"order.updated"`
+	Version    int64          `json:"version" example:"2"`
+	Actor      string         `json:"actor" example:"ops"`
+	Diff       map[string]any `json:"diff" swaggertype:"object"`
+	OccurredAt time.Time      `json:"occurred_at" example:"2021-11-26T06:22:19Z"`
+}
+
+// OrderHistory lists the changes of an order, oldest first. Order is the order
+// as it was at the requested time, when one was requested.
+// @Description Change history of an order
+type OrderHistory struct {
+	OrderUID string         `json:"order_uid" example:"b563feb7b2b84b6test"`
+	Order    *Order         `json:"order,omitempty"`
+	Events   []HistoryEvent `json:"events"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context whose changes are recorded as made by actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or SystemActor.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// MergePatch returns the JSON merge patch that turns before into after.
+// Objects are compared member by member, arrays and other values as a whole.
+func MergePatch(before, after map[string]any) map[string]any {
+	patch := make(map[string]any)
+	for key, value := range after {
+		old, ok := before[key]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		oldObject, oldIsObject := old.(map[string]any)
+		object, isObject := value.(map[string]any)
+		if oldIsObject && isObject {
+			patch[key] = MergePatch(oldObject, object)
+		} else {
+			patch[key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
+
+// ApplyMergePatch applies the JSON merge patch to doc in place. The patch is
+// copied, doc does not share its objects.
+func ApplyMergePatch(doc, patch map[string]any) {
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(doc, key)
+		case map[string]any:
+			target, ok := doc[key].(map[string]any)
+			if !ok {
+				target = make(map[string]any, len(value))
+				doc[key] = target
+			}
+			ApplyMergePatch(target, value)
+		default:
+			doc[key] = value
+		}
+	}
+}
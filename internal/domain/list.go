@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OrderFilter narrows a list of orders, zero fields match every order.
+// CreatedFrom is inclusive, CreatedTo is exclusive. ChrtID and NmID match
+// orders with at least one such item.
+type OrderFilter struct {
+	CustomerID      string
+	DeliveryService string
+	TrackNumber     string
+	ChrtID          int
+	NmID            int
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+}
+
+// Cursor is the position of an order in a list sorted by date_created and
+// order_uid, both descending. Lists continue after the cursor, so a page is
+// stable while new orders arrive.
+type Cursor struct {
+	DateCreated time.Time
+	OrderUID    string
+}
+
+// Encode returns the opaque page token of the cursor.
+func (c Cursor) Encode() string {
+	raw := c.DateCreated.UTC().Format(time.RFC3339Nano) + "|" + c.OrderUID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	date, uid, ok := strings.Cut(string(raw), "|")
+	if !ok || uid == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	created, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{DateCreated: created, OrderUID: uid}, nil
+}
+
+// PageRequest selects up to Limit orders after the cursor, the first page has
+// no cursor. Ranked lists, such as search results, have no stable cursor and
+// skip Offset orders instead.
+type PageRequest struct {
+	Limit  int
+	After  *Cursor
+	Offset int
+}
+
+// OrderPage is a page of orders, NextCursor is empty on the last page
+// @Description Page of orders
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor,omitempty" example:"MjAyMS0xMS0yNlQwNjoyMjoxOVp8YjU2M2ZlYjdiMmI4NGI2dGVzdA"`
+}
+
+// OrderLookup is the result of a bulk lookup. Orders keep the order of the
+// requested UIDs, Missing lists the UIDs of orders that do not exist.
+type OrderLookup struct {
+	Orders  []Order  `json:"orders"`
+	Missing []string `json:"missing"`
+}
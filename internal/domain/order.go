@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"github.com/shopspring/decimal"
 	"time"
 )
@@ -8,43 +11,49 @@ import (
 // Order represents an order entity
 // @Description Order information
 type Order struct {
-	OrderUID          string    `json:"order_uid" validate:"required,alphanum" example:"b563feb7b2b84b6test"`
-	TrackNumber       string    `json:"track_number" validate:"required" example:"WBILMTESTTRACK"`
-	Entry             string    `json:"entry" validate:"required,alpha" example:"WBIL"`
-	Delivery          Delivery  `json:"delivery" validate:"required"`
-	Payment           Payment   `json:"payment" validate:"required"`
-	Items             []Item    `json:"items" validate:"required,min=1,dive"`
-	Locale            string    `json:"locale" validate:"required,alpha,len=2" example:"en"`
-	InternalSignature string    `json:"internal_signature" validate:"omitempty" example:""`
-	CustomerID        string    `json:"customer_id" validate:"required,alphanum" example:"test"`
-	DeliveryService   string    `json:"delivery_service" validate:"required,alpha" example:"meest"`
-	ShardKey          string    `json:"shardkey" validate:"required,alphanum" example:"9"`
-	SmID              int       `json:"sm_id" validate:"required,min=0" example:"99"`
-	DateCreated       time.Time `json:"date_created" validate:"required" example:"2021-11-26T06:22:19Z"`
-	OofShard          string    `json:"oof_shard" validate:"required,alphanum" example:"1"`
+	OrderUID          string     `json:"order_uid" validate:"required,alphanum" example:"b563feb7b2b84b6test"`
+	TrackNumber       string     `json:"track_number" validate:"required" example:"WBILMTESTTRACK"`
+	Entry             string     `json:"entry" validate:"required,alpha" example:"WBIL"`
+	Delivery          Delivery   `json:"delivery" validate:"required"`
+	Payment           Payment    `json:"payment" validate:"required"`
+	Items             []Item     `json:"items" validate:"required,min=1,dive"`
+	Locale            string     `json:"locale" validate:"required,alpha,len=2" example:"en"`
+	InternalSignature string     `json:"internal_signature" validate:"omitempty" example:""`
+	CustomerID        string     `json:"customer_id" validate:"required,alphanum" example:"test"`
+	DeliveryService   string     `json:"delivery_service" validate:"required,alpha" example:"meest"`
+	ShardKey          string     `json:"shardkey" validate:"required,alphanum" example:"9"`
+	SmID              int        `json:"sm_id" validate:"required,min=0" example:"99"`
+	DateCreated       time.Time  `json:"date_created" validate:"required" example:"2021-11-26T06:22:19Z"`
+	OofShard          string     `json:"oof_shard" validate:"required,alphanum" example:"1"`
+	UpdatedAt         time.Time  `json:"updated_at,omitzero" validate:"omitempty" example:"2021-11-26T06:22:19Z"`
+	Version           int64      `json:"version,omitzero" validate:"min=0" example:"1"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty" validate:"omitempty" example:"2021-11-27T06:22:19Z"`
 }
 
 type Delivery struct {
-	Name    string `json:"name" validate:"required" example:"Test Testov"`
-	Phone   string `json:"phone" validate:"required,e164" example:"+9720000000"`
-	Zip     string `json:"zip" validate:"required,numeric" example:"2639809"`
+	Name    string `json:"name" mask:"name" validate:"required" example:"Test Testov"`
+	Phone   string `json:"phone" mask:"phone" validate:"required,e164" example:"+9720000000"`
+	Zip     string `json:"zip" mask:"redact" validate:"required,numeric" example:"2639809"`
 	City    string `json:"city" validate:"required" example:"Kiryat Mozkin"`
-	Address string `json:"address" validate:"required" example:"Ploshad Mira 15"`
+	Address string `json:"address" mask:"redact" validate:"required" example:"Ploshad Mira 15"`
 	Region  string `json:"region" validate:"required,alphanum" example:"Kraiot"`
-	Email   string `json:"email" validate:"required,email" example:"test@gmail.com"`
+	Email   string `json:"email" mask:"email" validate:"required,email" example:"test@gmail.com"`
 }
 
 type Payment struct {
-	Transaction  string          `json:"transaction" validate:"required,alphanum" example:"b563feb7b2b84b6test"`
-	RequestID    string          `json:"request_id" validate:"omitempty,alphanum" example:""`
-	Currency     string          `json:"currency" validate:"required,alpha,len=3" example:"USD"`
-	Provider     string          `json:"provider" validate:"required,alpha" example:"wbpay"`
-	Amount       decimal.Decimal `json:"amount" validate:"required,decimal" example:"1817"`
-	PaymentDt    int64           `json:"payment_dt" validate:"required,min=0" example:"1637907727"`
-	Bank         string          `json:"bank" validate:"required,alpha" example:"alpha"`
-	DeliveryCost decimal.Decimal `json:"delivery_cost" validate:"required,decimal" example:"1500"`
-	GoodsTotal   int             `json:"goods_total" validate:"required,min=0" example:"317"`
-	CustomFee    int             `json:"custom_fee" validate:"min=0" example:"0"`
+	Transaction  string           `json:"transaction" validate:"required,alphanum" example:"b563feb7b2b84b6test"`
+	RequestID    string           `json:"request_id" validate:"omitempty,alphanum" example:""`
+	Currency     string           `json:"currency" validate:"required,alpha,len=3" example:"USD"`
+	Provider     string           `json:"provider" validate:"required,alpha" example:"wbpay"`
+	Amount       decimal.Decimal  `json:"amount" validate:"required,decimal" example:"1817"`
+	PaymentDt    int64            `json:"payment_dt" validate:"required,min=0" example:"1637907727"`
+	Bank         string           `json:"bank" validate:"required,alpha" example:"alpha"`
+	DeliveryCost decimal.Decimal  `json:"delivery_cost" validate:"required,decimal" example:"1500"`
+	GoodsTotal   int              `json:"goods_total" validate:"required,min=0" example:"317"`
+	CustomFee    int              `json:"custom_fee" validate:"min=0" example:"0"`
+	BaseCurrency string           `json:"base_currency,omitempty" validate:"-" example:"USD"`
+	ExchangeRate *decimal.Decimal `json:"exchange_rate,omitempty" validate:"-" example:"1"`
+	BaseAmount   *decimal.Decimal `json:"base_amount,omitempty" validate:"-" example:"1817"`
 }
 
 type Item struct {
@@ -60,3 +69,39 @@ type Item struct {
 	Brand       string          `json:"brand" validate:"required" example:"Vivienne Sabo"`
 	Status      int             `json:"status" validate:"required,min=0" example:"202"`
 }
+
+// IsNewerThan reports whether the order replaces a stored one with the version
+// and update time. An explicit version is compared when the order has one,
+// otherwise the update time; an order with neither is never newer.
+func (o *Order) IsNewerThan(version int64, updatedAt time.Time) bool {
+	if o.Version > 0 {
+		return o.Version > version
+	}
+	return !o.UpdatedAt.IsZero() && o.UpdatedAt.After(updatedAt)
+}
+
+// ETag returns a strong entity tag of the order. It is derived from the order
+// UID and version, which every write bumps, and from the base currency amount,
+// which is set without a new version when exchange rates arrive. It does not
+// depend on how the order was encoded, so an order read back from the
+// database keeps the tag it had when it was cached.
+func (o *Order) ETag() string {
+	baseAmount := ""
+	if o.Payment.BaseAmount != nil {
+		baseAmount = o.Payment.BaseAmount.String()
+	}
+	// Orders cached before versioning have no version, they are stored as 1.
+	return ContentETag(fmt.Appendf(nil, "%s\x00%d\x00%s\x00%s", o.OrderUID, max(o.Version, 1), o.Payment.BaseCurrency, baseAmount))
+}
+
+// MaskedETag returns the entity tag of the masked view of an order with the
+// entity tag etag. The views differ in content, so they must not share a tag.
+func MaskedETag(etag string) string {
+	return ContentETag([]byte(etag + "\x00masked"))
+}
+
+// ContentETag returns a strong entity tag for encoded content.
+func ContentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
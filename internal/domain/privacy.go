@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ErasedValue replaces personal data of erased customers.
+const ErasedValue = "erased"
+
+// CustomerExport is the subject access bundle with every order of a customer
+// @Description All data stored about a customer
+type CustomerExport struct {
+	CustomerID string    `json:"customer_id" example:"test"`
+	ExportedAt time.Time `json:"exported_at" example:"2021-11-26T06:22:19Z"`
+	Orders     []Order   `json:"orders"`
+}
+
+// Erasure is the audit record of a customer erasure request. It keeps only an
+// HMAC of the customer id, so the record itself holds no personal data and
+// cannot be linked to a customer without the secret.
+// @Description Erasure audit record
+type Erasure struct {
+	ID           int64     `json:"id" example:"1"`
+	CustomerHash string    `json:"customer_hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Pseudonym    string    `json:"pseudonym" example:"erased9f86d081884c7d65"`
+	OrderUIDs    []string  `json:"order_uids"`
+	RequestedBy  string    `json:"requested_by" example:"ops"`
+	Reason       string    `json:"reason,omitempty" example:"GDPR art. 17 request #42"`
+	ErasedAt     time.Time `json:"erased_at" example:"2021-11-26T06:22:19Z"`
+}
+
+// NewErasure prepares an erasure record for the customer. CustomerHash is the
+// HMAC-SHA256 of the customer id keyed with secret, so a list of candidate ids
+// cannot be matched against the record without it. The pseudonym replaces
+// customer_id in erased orders: it is random, keeps the orders erased together
+// grouped and is stored nowhere else than in the record.
+func NewErasure(customerID, requestedBy, reason string, secret []byte) *Erasure {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(customerID))
+	pseudonym := make([]byte, 8)
+	_, _ = rand.Read(pseudonym)
+	return &Erasure{
+		CustomerHash: hex.EncodeToString(mac.Sum(nil)),
+		Pseudonym:    ErasedValue + hex.EncodeToString(pseudonym),
+		RequestedBy:  requestedBy,
+		Reason:       reason,
+		ErasedAt:     time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var ErrInvalidProjection = errors.New("invalid projection")
+
+// Part is a set of the child resources of an order.
+type Part uint8
+
+const (
+	PartDelivery Part = 1 << iota
+	PartPayment
+	PartItems
+
+	AllParts = PartDelivery | PartPayment | PartItems
+)
+
+var partNames = map[string]Part{
+	"delivery": PartDelivery,
+	"payment":  PartPayment,
+	"items":    PartItems,
+}
+
+// Has reports whether all parts of p are in the set.
+func (s Part) Has(p Part) bool {
+	return s&p == p
+}
+
+// orderFields and partFields hold the JSON names accepted in ?fields=.
+var (
+	orderFields = jsonFields(reflect.TypeFor[Order]())
+	partFields  = map[string]map[string]bool{
+		"delivery": jsonFields(reflect.TypeFor[Delivery]()),
+		"payment":  jsonFields(reflect.TypeFor[Payment]()),
+		"items":    jsonFields(reflect.TypeFor[Item]()),
+	}
+)
+
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := make(map[string]bool, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// Projection selects the fields of an order returned to a client.
+type Projection struct {
+	parts Part
+	// fields maps the selected top-level fields to the selected fields of a
+	// part, nil selects all of them. A nil map selects every order field.
+	fields map[string]map[string]bool
+}
+
+// ParseProjection parses the ?fields= and ?include= query parameters. fields
+// is a comma separated list of order fields, fields of a part are written as
+// "items.name". include lists the parts returned in full. A part is loaded only
+// when it is included or one of its fields is selected; without fields all
+// order fields are returned.
+func ParseProjection(fields, include string) (Projection, error) {
+	if fields == "" && include == "" {
+		return Projection{parts: AllParts}, nil
+	}
+	var p Projection
+	for _, name := range split(include) {
+		part, ok := partNames[name]
+		if !ok {
+			return Projection{}, fmt.Errorf("%w: unknown include %q", ErrInvalidProjection, name)
+		}
+		p.parts |= part
+	}
+	if fields == "" {
+		return p, nil
+	}
+
+	p.fields = make(map[string]map[string]bool)
+	for name, part := range partNames {
+		if p.parts.Has(part) {
+			p.fields[name] = nil
+		}
+	}
+	for _, field := range split(fields) {
+		name, sub, nested := strings.Cut(field, ".")
+		if !orderFields[name] {
+			return Projection{}, fmt.Errorf("%w: unknown field %q", ErrInvalidProjection, field)
+		}
+		part, isPart := partNames[name]
+		if nested && (!isPart || !partFields[name][sub]) {
+			return Projection{}, fmt.Errorf("%w: unknown field %q", ErrInvalidProjection, field)
+		}
+		if !isPart {
+			p.fields[name] = nil
+			continue
+		}
+		subfields, selected := p.fields[name]
+		switch {
+		case !nested:
+			p.fields[name] = nil
+		case !selected:
+			p.fields[name] = map[string]bool{sub: true}
+		case subfields != nil:
+			subfields[sub] = true
+		}
+		p.parts |= part
+	}
+	return p, nil
+}
+
+func split(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Parts returns the parts of the order the projection needs.
+func (p Projection) Parts() Part {
+	return p.parts
+}
+
+// IsFull reports whether the projection selects the whole order.
+func (p Projection) IsFull() bool {
+	return p.fields == nil && p.parts == AllParts
+}
+
+// ETag returns the entity tag of the projection of an order with the entity
+// tag orderETag.
+func (p Projection) ETag(orderETag string) string {
+	// Maps are encoded with sorted keys, the same projection always gives the
+	// same key.
+	key, _ := json.Marshal(p.fields)
+	return ContentETag(fmt.Appendf(nil, "%s\x00%d\x00%s", orderETag, p.parts, key))
+}
+
+// Apply returns the JSON representation of the selected fields of the order.
+func (p Projection) Apply(order *Order) (map[string]any, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode order: %w", err)
+	}
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode order: %w", err)
+	}
+	for name := range doc {
+		part, isPart := partNames[name]
+		if isPart && !p.parts.Has(part) {
+			delete(doc, name)
+			continue
+		}
+		if p.fields == nil {
+			continue
+		}
+		subfields, selected := p.fields[name]
+		switch {
+		case !selected:
+			delete(doc, name)
+		case subfields != nil:
+			doc[name] = pick(doc[name], subfields)
+		}
+	}
+	return doc, nil
+}
+
+// pick keeps the selected fields of an object or of every object in an array.
+func pick(value any, fields map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for name := range v {
+			if !fields[name] {
+				delete(v, name)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = pick(v[i], fields)
+		}
+	}
+	return value
+}
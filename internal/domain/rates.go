@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// ExchangeRate is the price of one unit of Currency in the base currency,
+// effective from the UTC day Date until the next rate of the currency
+// @Description Exchange rate of a currency
+type ExchangeRate struct {
+	Currency string          `json:"currency" example:"EUR"`
+	Date     time.Time       `json:"date" example:"2021-11-26T00:00:00Z"`
+	Rate     decimal.Decimal `json:"rate" example:"1.1317"`
+}
+
+// ExchangeRates are the rates effective on Date, by currency
+// @Description Exchange rates of a day
+type ExchangeRates struct {
+	BaseCurrency string         `json:"base_currency" example:"USD"`
+	Date         time.Time      `json:"date" example:"2021-11-26T00:00:00Z"`
+	Rates        []ExchangeRate `json:"rates"`
+}
+
+// ExchangeRatesUpdate reports the rates saved and the stored orders converted
+// to the base currency with them
+// @Description Result of saving exchange rates
+type ExchangeRatesUpdate struct {
+	Saved     int `json:"saved" example:"2"`
+	Converted int `json:"converted" example:"12"`
+}
+
+// BaseTotal is a total converted to the base currency. Unconverted counts the
+// orders without an exchange rate, they are left out of Amount
+// @Description Total in the base currency
+type BaseTotal struct {
+	Currency    string          `json:"currency" example:"USD"`
+	Amount      decimal.Decimal `json:"amount" example:"1817"`
+	Unconverted int             `json:"unconverted" example:"0"`
+}
+
+// Convert sets the amount of the payment in baseCurrency, which is computed
+// when the order is stored and unset while no rate of the payment currency is
+// known. The rate is the price of one unit of the payment currency in
+// baseCurrency.
+func (p *Payment) Convert(baseCurrency string, rate decimal.Decimal) {
+	amount := p.Amount.Mul(rate).Round(2)
+	p.BaseCurrency, p.ExchangeRate, p.BaseAmount = baseCurrency, &rate, &amount
+}
+
+// ClearConversion unsets the base currency amount of the payment.
+func (p *Payment) ClearConversion() {
+	p.BaseCurrency, p.ExchangeRate, p.BaseAmount = "", nil, nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// SearchHit is an order found by a full-text search. Highlights are the matched
+// texts with the matching words wrapped in <mark> tags; the texts themselves
+// are not HTML escaped.
+// @Description Search result
+type SearchHit struct {
+	Order      Order    `json:"order"`
+	Rank       float32  `json:"rank" example:"0.0607927"`
+	Highlights []string `json:"highlights" example:"<mark>Vivienne</mark> Sabo Mascaras"`
+}
+
+// SearchPage is a page of search results, best matches first. NextCursor is
+// empty on the last page.
+// @Description Page of search results
+type SearchPage struct {
+	Results    []SearchHit `json:"results"`
+	NextCursor string      `json:"next_cursor,omitempty" example:"MjA"`
+}
+
+// EncodeOffset returns the opaque page token of a ranked list position.
+func EncodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func DecodeOffset(token string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StatsDimension is a field the daily statistics are grouped by.
+type StatsDimension string
+
+const (
+	DimensionProvider        StatsDimension = "provider"
+	DimensionBank            StatsDimension = "bank"
+	DimensionDeliveryService StatsDimension = "delivery_service"
+	DimensionCurrency        StatsDimension = "currency"
+	DimensionLocale          StatsDimension = "locale"
+)
+
+// StatsDimensions lists the dimensions the daily statistics can be grouped by.
+var StatsDimensions = []StatsDimension{
+	DimensionProvider,
+	DimensionBank,
+	DimensionDeliveryService,
+	DimensionCurrency,
+	DimensionLocale,
+}
+
+// Valid reports whether the statistics can be grouped by the dimension. The
+// empty dimension groups by day and currency only.
+func (d StatsDimension) Valid() bool {
+	return d == "" || slices.Contains(StatsDimensions, d)
+}
+
+// StatsQuery selects the days from From, inclusive, to To, exclusive. Both are
+// UTC midnights.
+type StatsQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy StatsDimension
+}
+
+// DailyStats aggregates the orders created on a UTC day that are not deleted,
+// per group and currency. AvgBasketSize is the average number of items per
+// order. BaseAmount and BaseDeliveryCost are converted to the base currency
+// and leave out the Unconverted orders without an exchange rate
+// @Description Daily order statistics
+type DailyStats struct {
+	Day              time.Time       `json:"day" example:"2021-11-26T00:00:00Z"`
+	Group            string          `json:"group,omitempty" example:"wbpay"`
+	Currency         string          `json:"currency" example:"USD"`
+	Orders           int             `json:"orders" example:"12"`
+	Amount           decimal.Decimal `json:"amount" example:"21804"`
+	DeliveryCost     decimal.Decimal `json:"delivery_cost" example:"18000"`
+	Items            int             `json:"items" example:"30"`
+	AvgBasketSize    decimal.Decimal `json:"avg_basket_size" example:"2.5"`
+	BaseAmount       decimal.Decimal `json:"base_amount" example:"21804"`
+	BaseDeliveryCost decimal.Decimal `json:"base_delivery_cost" example:"18000"`
+	Unconverted      int             `json:"unconverted" example:"0"`
+}
+
+// SetAvgBasketSize computes AvgBasketSize from Items and Orders.
+func (s *DailyStats) SetAvgBasketSize() {
+	s.AvgBasketSize = decimal.Zero
+	if s.Orders > 0 {
+		s.AvgBasketSize = decimal.NewFromInt(int64(s.Items)).DivRound(decimal.NewFromInt(int64(s.Orders)), 2)
+	}
+}
+
+// DailyTotal aggregates the daily statistics of a day and group across the
+// currencies. Amount and DeliveryCost are in the base currency and leave out
+// the Unconverted orders without an exchange rate
+// @Description Daily order totals in the base currency
+type DailyTotal struct {
+	Day          time.Time       `json:"day" example:"2021-11-26T00:00:00Z"`
+	Group        string          `json:"group,omitempty" example:"wbpay"`
+	Orders       int             `json:"orders" example:"12"`
+	Items        int             `json:"items" example:"30"`
+	Amount       decimal.Decimal `json:"amount" example:"21804"`
+	DeliveryCost decimal.Decimal `json:"delivery_cost" example:"18000"`
+	Unconverted  int             `json:"unconverted" example:"0"`
+}
+
+// DailyStatsReport are the daily statistics of the days from From, inclusive,
+// to To, exclusive. Totals are set when the amounts are converted to
+// BaseCurrency
+// @Description Daily order statistics of a period
+type DailyStatsReport struct {
+	From         time.Time      `json:"from" example:"2021-11-01T00:00:00Z"`
+	To           time.Time      `json:"to" example:"2021-12-01T00:00:00Z"`
+	GroupBy      StatsDimension `json:"group_by,omitempty" example:"provider"`
+	BaseCurrency string         `json:"base_currency,omitempty" example:"USD"`
+	Stats        []DailyStats   `json:"stats"`
+	Totals       []DailyTotal   `json:"totals,omitempty"`
+}
+
+// AddTotals sums the statistics of every day and group across the currencies
+// into Totals.
+func (r *DailyStatsReport) AddTotals() {
+	r.Totals = []DailyTotal{}
+	for _, s := range r.Stats {
+		n := len(r.Totals)
+		if n == 0 || !r.Totals[n-1].Day.Equal(s.Day) || r.Totals[n-1].Group != s.Group {
+			r.Totals = append(r.Totals, DailyTotal{Day: s.Day, Group: s.Group})
+			n++
+		}
+		t := &r.Totals[n-1]
+		t.Orders += s.Orders
+		t.Items += s.Items
+		t.Amount = t.Amount.Add(s.BaseAmount)
+		t.DeliveryCost = t.DeliveryCost.Add(s.BaseDeliveryCost)
+		t.Unconverted += s.Unconverted
+	}
+}
+
+// BrandStats counts the items of a brand sold on a day and the orders with
+// them. Revenue sums their total prices per currency, by currency.
+// BaseRevenue is set when the revenue is converted to the base currency
+// @Description Sales of a brand
+type BrandStats struct {
+	Brand       string     `json:"brand" example:"Vivienne Sabo"`
+	Items       int        `json:"items" example:"4"`
+	Orders      int        `json:"orders" example:"3"`
+	Revenue     []Amount   `json:"revenue"`
+	BaseRevenue *BaseTotal `json:"base_revenue,omitempty"`
+}
+
+// BrandSales are the sales of a brand in a currency. BaseRevenue leaves out
+// the Unconverted orders without an exchange rate.
+type BrandSales struct {
+	Brand       string
+	Currency    string
+	Items       int
+	Orders      int
+	Revenue     decimal.Decimal
+	BaseRevenue decimal.Decimal
+	Unconverted int
+}
+
+// DailyBrands are the brands sold on a UTC day, most items sold first
+// @Description Top brands of a day
+type DailyBrands struct {
+	Day    time.Time    `json:"day" example:"2021-11-26T00:00:00Z"`
+	Brands []BrandStats `json:"brands"`
+	// index maps the brands to their positions in Brands.
+	index map[string]int
+}
+
+// TopBrandsReport are the top brands of every day from From, inclusive, to
+// To, exclusive
+// @Description Top brands of a period
+type TopBrandsReport struct {
+	From time.Time     `json:"from" example:"2021-11-01T00:00:00Z"`
+	To   time.Time     `json:"to" example:"2021-12-01T00:00:00Z"`
+	Days []DailyBrands `json:"days"`
+}
+
+// AddBrand adds sales of a brand in a currency to the day.
+func (d *DailyBrands) AddBrand(sales BrandSales) {
+	if d.index == nil {
+		d.index = make(map[string]int, len(d.Brands))
+		for i, b := range d.Brands {
+			d.index[b.Brand] = i
+		}
+	}
+	i, ok := d.index[sales.Brand]
+	if !ok {
+		i = len(d.Brands)
+		d.index[sales.Brand] = i
+		d.Brands = append(d.Brands, BrandStats{Brand: sales.Brand, Revenue: []Amount{}, BaseRevenue: &BaseTotal{}})
+	}
+	b := &d.Brands[i]
+	b.Items += sales.Items
+	b.Orders += sales.Orders
+	b.BaseRevenue.Amount = b.BaseRevenue.Amount.Add(sales.BaseRevenue)
+	b.BaseRevenue.Unconverted += sales.Unconverted
+	if j := slices.IndexFunc(b.Revenue, func(a Amount) bool { return a.Currency == sales.Currency }); j >= 0 {
+		b.Revenue[j].Amount = b.Revenue[j].Amount.Add(sales.Revenue)
+		return
+	}
+	b.Revenue = append(b.Revenue, Amount{Currency: sales.Currency, Amount: sales.Revenue})
+	slices.SortFunc(b.Revenue, func(a, b Amount) int {
+		return strings.Compare(a.Currency, b.Currency)
+	})
+}
+
+// Top sorts the brands by the items sold, then by the orders and the name, and
+// keeps the first limit of them.
+func (d *DailyBrands) Top(limit int) {
+	slices.SortFunc(d.Brands, func(a, b BrandStats) int {
+		if a.Items != b.Items {
+			return b.Items - a.Items
+		}
+		if a.Orders != b.Orders {
+			return b.Orders - a.Orders
+		}
+		return strings.Compare(a.Brand, b.Brand)
+	})
+	if len(d.Brands) > limit {
+		d.Brands = d.Brands[:limit]
+	}
+	d.index = nil
+}
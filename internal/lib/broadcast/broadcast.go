@@ -0,0 +1,182 @@
+package broadcast
+
+import (
+	"github.com/Killazius/L0/internal/domain"
+	"sync"
+	"time"
+)
+
+// Filter selects the events of a subscription. Empty fields match everything.
+type Filter struct {
+	CustomerID      string
+	DeliveryService string
+}
+
+func (f Filter) Match(order *domain.Order) bool {
+	if order == nil {
+		return false
+	}
+	if f.CustomerID != "" && f.CustomerID != order.CustomerID {
+		return false
+	}
+	if f.DeliveryService != "" && f.DeliveryService != order.DeliveryService {
+		return false
+	}
+	return true
+}
+
+// Subscription receives the events matching its filter until it is closed by
+// the subscriber or dropped by the broadcaster.
+type Subscription struct {
+	events  chan domain.OrderEvent
+	filter  Filter
+	b       *Broadcaster
+	dropped bool
+}
+
+// Events is closed when the subscription ends.
+func (s *Subscription) Events() <-chan domain.OrderEvent {
+	return s.events
+}
+
+// Dropped reports whether the broadcaster ended the subscription because the
+// subscriber did not keep up with the events.
+func (s *Subscription) Dropped() bool {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	return s.dropped
+}
+
+func (s *Subscription) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.b.remove(s)
+}
+
+// Broadcaster fans order events out to in-process subscribers. Publish never
+// blocks: a subscriber whose buffer is full is dropped, so a slow client cannot
+// stall ingestion. The last events are kept in a bounded replay buffer for
+// subscribers resuming after a reconnect.
+type Broadcaster struct {
+	mu         sync.Mutex
+	lastID     uint64
+	replay     []domain.OrderEvent
+	next       int
+	full       bool
+	subs       map[*Subscription]struct{}
+	subsBuffer int
+}
+
+// New creates a broadcaster keeping replaySize events for resume and buffering
+// up to subscriberBuffer events per subscriber.
+// Event ids start from the current time in microseconds, so they keep growing
+// across restarts and a stale Last-Event-ID never matches a new event.
+func New(replaySize, subscriberBuffer int) *Broadcaster {
+	return &Broadcaster{
+		lastID:     uint64(time.Now().UnixMicro()),
+		replay:     make([]domain.OrderEvent, max(replaySize, 1)),
+		subs:       make(map[*Subscription]struct{}),
+		subsBuffer: subscriberBuffer,
+	}
+}
+
+func (b *Broadcaster) Publish(eventType domain.EventType, order *domain.Order) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := domain.OrderEvent{
+		ID:    b.lastID,
+		Type:  eventType,
+		Time:  time.Now().UTC(),
+		Order: order,
+	}
+	b.replay[b.next] = event
+	b.next = (b.next + 1) % len(b.replay)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for s := range b.subs {
+		if !s.filter.Match(order) {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			s.dropped = true
+			b.remove(s)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. When lastEventID is not zero, the buffered
+// events published after it are returned for replay; they are taken under the
+// same lock as the registration, so no event falls between replay and live.
+func (b *Broadcaster) Subscribe(filter Filter, lastEventID uint64) (*Subscription, []domain.OrderEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &Subscription{
+		events: make(chan domain.OrderEvent, b.subsBuffer),
+		filter: filter,
+		b:      b,
+	}
+	b.subs[s] = struct{}{}
+
+	if lastEventID == 0 {
+		return s, nil
+	}
+	var missed []domain.OrderEvent
+	for _, event := range b.buffered() {
+		if event.ID > lastEventID && filter.Match(event.Order) {
+			missed = append(missed, event)
+		}
+	}
+	return s, missed
+}
+
+// Forget removes the events of the customer's orders from the replay buffer, so
+// subscribers resuming later do not receive the personal data of an erased
+// customer. Events already delivered to subscribers are not recalled.
+func (b *Broadcaster) Forget(customerID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	buffered := b.buffered()
+	kept := make([]domain.OrderEvent, 0, len(buffered))
+	for _, event := range buffered {
+		if event.Order == nil || event.Order.CustomerID != customerID {
+			kept = append(kept, event)
+		}
+	}
+	if len(kept) == len(buffered) {
+		return
+	}
+	replay := make([]domain.OrderEvent, len(b.replay))
+	copy(replay, kept)
+	b.replay, b.next, b.full = replay, len(kept), false
+}
+
+// Subscribers returns the number of active subscriptions.
+func (b *Broadcaster) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// buffered returns the replay buffer oldest first.
+func (b *Broadcaster) buffered() []domain.OrderEvent {
+	if !b.full {
+		return b.replay[:b.next]
+	}
+	return append(append([]domain.OrderEvent(nil), b.replay[b.next:]...), b.replay[:b.next]...)
+}
+
+func (b *Broadcaster) remove(s *Subscription) {
+	if _, ok := b.subs[s]; !ok {
+		return
+	}
+	delete(b.subs, s)
+	close(s.events)
+}
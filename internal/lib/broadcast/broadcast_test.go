@@ -0,0 +1,107 @@
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func order(uid, customer, service string) *domain.Order {
+	return &domain.Order{OrderUID: uid, CustomerID: customer, DeliveryService: service}
+}
+
+func TestBroadcaster_Filter(t *testing.T) {
+	t.Parallel()
+
+	b := New(10, 10)
+	all, _ := b.Subscribe(Filter{}, 0)
+	byCustomer, _ := b.Subscribe(Filter{CustomerID: "c1"}, 0)
+	byService, _ := b.Subscribe(Filter{CustomerID: "c1", DeliveryService: "dhl"}, 0)
+
+	b.Publish(domain.EventOrderCreated, order("o1", "c1", "meest"))
+	b.Publish(domain.EventOrderCreated, order("o2", "c2", "dhl"))
+	b.Publish(domain.EventOrderCreated, order("o3", "c1", "dhl"))
+
+	assert.Len(t, all.Events(), 3)
+	assert.Len(t, byCustomer.Events(), 2)
+	require.Len(t, byService.Events(), 1)
+	event := <-byService.Events()
+	assert.Equal(t, "o3", event.Order.OrderUID)
+	assert.Equal(t, domain.EventOrderCreated, event.Type)
+}
+
+func TestBroadcaster_Replay(t *testing.T) {
+	t.Parallel()
+
+	b := New(3, 10)
+	var ids []uint64
+	for _, uid := range []string{"o1", "o2", "o3", "o4"} {
+		s, _ := b.Subscribe(Filter{}, 0)
+		b.Publish(domain.EventOrderCreated, order(uid, "c1", "dhl"))
+		ids = append(ids, (<-s.Events()).ID)
+		s.Close()
+	}
+	assert.Less(t, ids[0], ids[1])
+
+	_, missed := b.Subscribe(Filter{}, ids[1])
+	require.Len(t, missed, 2)
+	assert.Equal(t, "o3", missed[0].Order.OrderUID)
+	assert.Equal(t, "o4", missed[1].Order.OrderUID)
+
+	_, missed = b.Subscribe(Filter{}, ids[0]-1)
+	require.Len(t, missed, 3, "only the buffered events can be replayed")
+	assert.Equal(t, "o2", missed[0].Order.OrderUID)
+
+	_, missed = b.Subscribe(Filter{}, ids[3])
+	assert.Empty(t, missed)
+}
+
+func TestBroadcaster_DropsSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	b := New(10, 1)
+	slow, _ := b.Subscribe(Filter{}, 0)
+	fast, _ := b.Subscribe(Filter{}, 0)
+
+	b.Publish(domain.EventOrderCreated, order("o1", "c1", "dhl"))
+	<-fast.Events()
+	b.Publish(domain.EventOrderCreated, order("o2", "c1", "dhl"))
+
+	assert.True(t, slow.Dropped())
+	assert.False(t, fast.Dropped())
+	assert.Equal(t, 1, b.Subscribers())
+
+	_, ok := <-slow.Events()
+	assert.True(t, ok, "buffered event is still delivered")
+	_, ok = <-slow.Events()
+	assert.False(t, ok)
+
+	fast.Close()
+	fast.Close()
+	assert.Equal(t, 0, b.Subscribers())
+}
+
+func TestBroadcaster_Forget(t *testing.T) {
+	t.Parallel()
+
+	b := New(3, 10)
+	s, _ := b.Subscribe(Filter{}, 0)
+	for _, o := range []*domain.Order{order("o1", "c1", "dhl"), order("o2", "c2", "dhl"), order("o3", "c1", "dhl"), order("o4", "c2", "dhl")} {
+		b.Publish(domain.EventOrderCreated, o)
+	}
+	first := (<-s.Events()).ID
+
+	b.Forget("c1")
+	_, missed := b.Subscribe(Filter{}, first-1)
+	require.Len(t, missed, 2)
+	assert.Equal(t, "o2", missed[0].Order.OrderUID)
+	assert.Equal(t, "o4", missed[1].Order.OrderUID)
+
+	b.Publish(domain.EventOrderCreated, order("o5", "c1", "dhl"))
+	b.Publish(domain.EventOrderCreated, order("o6", "c2", "dhl"))
+	_, missed = b.Subscribe(Filter{}, first-1)
+	require.Len(t, missed, 3, "the buffer fills up again after forgetting")
+	assert.Equal(t, []string{"o4", "o5", "o6"}, []string{missed[0].Order.OrderUID, missed[1].Order.OrderUID, missed[2].Order.OrderUID})
+}
@@ -0,0 +1,174 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"github.com/Killazius/L0/internal/domain"
+	"sync"
+)
+
+var ErrTooManyOrders = errors.New("too many tracked orders")
+
+// Watcher receives the events of the orders it tracks. The set of orders can
+// change at any time, unlike the filter of a Subscription.
+type Watcher struct {
+	events  chan domain.OrderEvent
+	t       *Tracker
+	orders  map[string]struct{}
+	dropped bool
+	closed  bool
+}
+
+// Events is closed when the watcher is closed or dropped.
+func (w *Watcher) Events() <-chan domain.OrderEvent {
+	return w.events
+}
+
+// Add starts tracking the orders. It fails when the watcher would track more
+// orders than the tracker allows, no order is added then.
+func (w *Watcher) Add(orderUIDs ...string) error {
+	w.t.mu.Lock()
+	defer w.t.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	added := 0
+	for _, uid := range orderUIDs {
+		if _, ok := w.orders[uid]; !ok {
+			added++
+		}
+	}
+	if len(w.orders)+added > w.t.maxOrders {
+		return ErrTooManyOrders
+	}
+	for _, uid := range orderUIDs {
+		w.orders[uid] = struct{}{}
+		if w.t.watchers[uid] == nil {
+			w.t.watchers[uid] = make(map[*Watcher]struct{})
+		}
+		w.t.watchers[uid][w] = struct{}{}
+	}
+	return nil
+}
+
+func (w *Watcher) Remove(orderUIDs ...string) {
+	w.t.mu.Lock()
+	defer w.t.mu.Unlock()
+	for _, uid := range orderUIDs {
+		delete(w.orders, uid)
+		w.t.unregister(uid, w)
+	}
+}
+
+// Orders returns the number of tracked orders.
+func (w *Watcher) Orders() int {
+	w.t.mu.Lock()
+	defer w.t.mu.Unlock()
+	return len(w.orders)
+}
+
+// Dropped reports whether the tracker closed the watcher because it did not
+// keep up with the events.
+func (w *Watcher) Dropped() bool {
+	w.t.mu.Lock()
+	defer w.t.mu.Unlock()
+	return w.dropped
+}
+
+func (w *Watcher) Close() {
+	w.t.mu.Lock()
+	defer w.t.mu.Unlock()
+	w.t.remove(w)
+}
+
+// Tracker indexes watchers by order UID, so an event is delivered only to the
+// watchers of its order. It is fed by a Broadcaster subscription.
+type Tracker struct {
+	source    *Broadcaster
+	mu        sync.Mutex
+	watchers  map[string]map[*Watcher]struct{}
+	buffer    int
+	maxOrders int
+}
+
+// NewTracker creates a tracker buffering up to watcherBuffer events per watcher,
+// each watcher may track up to maxOrders orders.
+func NewTracker(source *Broadcaster, watcherBuffer, maxOrders int) *Tracker {
+	return &Tracker{
+		source:    source,
+		watchers:  make(map[string]map[*Watcher]struct{}),
+		buffer:    watcherBuffer,
+		maxOrders: maxOrders,
+	}
+}
+
+func (t *Tracker) Watch() *Watcher {
+	return &Watcher{
+		events: make(chan domain.OrderEvent, t.buffer),
+		t:      t,
+		orders: make(map[string]struct{}),
+	}
+}
+
+// Run dispatches the events of the source until ctx is done. If the source
+// drops the tracker, it resubscribes and replays the events it missed.
+func (t *Tracker) Run(ctx context.Context) {
+	var lastID uint64
+	for {
+		sub, missed := t.source.Subscribe(Filter{}, lastID)
+		for _, event := range missed {
+			t.Dispatch(event)
+			lastID = event.ID
+		}
+		for done := false; !done; {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.Events():
+				if !ok {
+					done = true
+					break
+				}
+				t.Dispatch(event)
+				lastID = event.ID
+			}
+		}
+	}
+}
+
+// Dispatch delivers the event to the watchers of its order without blocking,
+// a watcher with a full buffer is dropped.
+func (t *Tracker) Dispatch(event domain.OrderEvent) {
+	if event.Order == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for w := range t.watchers[event.Order.OrderUID] {
+		select {
+		case w.events <- event:
+		default:
+			w.dropped = true
+			t.remove(w)
+		}
+	}
+}
+
+func (t *Tracker) remove(w *Watcher) {
+	if w.closed {
+		return
+	}
+	for uid := range w.orders {
+		t.unregister(uid, w)
+	}
+	w.closed = true
+	close(w.events)
+}
+
+func (t *Tracker) unregister(uid string, w *Watcher) {
+	delete(t.watchers[uid], w)
+	if len(t.watchers[uid]) == 0 {
+		delete(t.watchers, uid)
+	}
+}
@@ -0,0 +1,86 @@
+package broadcast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	tr := NewTracker(New(10, 10), 10, 2)
+	w1 := tr.Watch()
+	w2 := tr.Watch()
+	require.NoError(t, w1.Add("o1", "o2"))
+	require.NoError(t, w2.Add("o2"))
+	require.ErrorIs(t, w1.Add("o3"), ErrTooManyOrders)
+	require.NoError(t, w1.Add("o1"), "already tracked orders do not count")
+
+	tr.Dispatch(domain.OrderEvent{ID: 1, Order: order("o1", "c1", "dhl")})
+	tr.Dispatch(domain.OrderEvent{ID: 2, Order: order("o2", "c1", "dhl")})
+	tr.Dispatch(domain.OrderEvent{ID: 3, Order: order("o3", "c1", "dhl")})
+
+	assert.Len(t, w1.Events(), 2)
+	require.Len(t, w2.Events(), 1)
+	assert.Equal(t, uint64(2), (<-w2.Events()).ID)
+
+	w2.Remove("o2")
+	tr.Dispatch(domain.OrderEvent{ID: 4, Order: order("o2", "c1", "dhl")})
+	assert.Empty(t, w2.Events())
+	assert.Equal(t, 0, w2.Orders())
+
+	w1.Close()
+	w1.Close()
+	assert.Empty(t, tr.watchers)
+}
+
+func TestTracker_DropsSlowWatcher(t *testing.T) {
+	t.Parallel()
+
+	tr := NewTracker(New(10, 10), 1, 10)
+	w := tr.Watch()
+	require.NoError(t, w.Add("o1"))
+
+	tr.Dispatch(domain.OrderEvent{ID: 1, Order: order("o1", "c1", "dhl")})
+	tr.Dispatch(domain.OrderEvent{ID: 2, Order: order("o1", "c1", "dhl")})
+
+	assert.True(t, w.Dropped())
+	<-w.Events()
+	_, ok := <-w.Events()
+	assert.False(t, ok)
+	assert.Empty(t, tr.watchers)
+}
+
+func TestTracker_Run(t *testing.T) {
+	t.Parallel()
+
+	b := New(10, 1)
+	tr := NewTracker(b, 10, 10)
+	w := tr.Watch()
+	require.NoError(t, w.Add("o1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tr.Run(ctx)
+	}()
+	require.Eventually(t, func() bool { return b.Subscribers() == 1 }, time.Second, time.Millisecond)
+
+	b.Publish(domain.EventOrderCreated, order("o1", "c1", "dhl"))
+	select {
+	case event := <-w.Events():
+		assert.Equal(t, "o1", event.Order.OrderUID)
+	case <-time.After(time.Second):
+		t.Fatal("event was not dispatched")
+	}
+
+	cancel()
+	<-done
+	assert.Equal(t, 0, b.Subscribers())
+}
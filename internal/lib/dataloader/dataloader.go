@@ -0,0 +1,112 @@
+// Package dataloader batches and deduplicates lookups by key. A Loader lives
+// for one request: it caches every result, including errors, for its lifetime.
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc loads the values of the keys at once. Keys missing from the
+// returned map are reported as not found.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+	timer   *time.Timer
+}
+
+// Loader collects the keys requested within the wait window, or up to
+// maxBatch keys, and loads them with one call of the batch function.
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending *batch[K, V]
+}
+
+func New[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  make(map[K]*result[V]),
+	}
+}
+
+// Load returns the value of the key, found is false when the batch function
+// did not return it.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (value V, found bool, err error) {
+	res := l.enqueue(ctx, key)
+	select {
+	case <-res.done:
+		return res.value, res.found, res.err
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	}
+}
+
+// Prime queues the keys without waiting, so keys known in advance go out in
+// one batch instead of being collected from concurrent Load calls.
+func (l *Loader[K, V]) Prime(ctx context.Context, keys ...K) {
+	for _, key := range keys {
+		l.enqueue(ctx, key)
+	}
+}
+
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if res, ok := l.results[key]; ok {
+		return res
+	}
+	res := &result[V]{done: make(chan struct{})}
+	l.results[key] = res
+
+	if l.pending == nil {
+		b := &batch[K, V]{}
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(ctx, b) })
+		l.pending = b
+	}
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, res)
+	if l.maxBatch > 0 && len(b.keys) >= l.maxBatch && b.timer.Stop() {
+		l.pending = nil
+		go l.dispatch(ctx, b)
+	}
+	return res
+}
+
+// dispatch runs the batch. The context is the one of the request that opened
+// the batch, a loader must not be shared between requests.
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(ctx, b.keys)
+	for i, key := range b.keys {
+		res := b.results[i]
+		if err != nil {
+			res.err = err
+		} else {
+			res.value, res.found = values[key]
+		}
+		close(res.done)
+	}
+}
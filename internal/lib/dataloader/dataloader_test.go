@@ -0,0 +1,97 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_Batches(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var mu sync.Mutex
+	var batches [][]int
+	l := New(func(_ context.Context, keys []int) (map[int]string, error) {
+		calls.Add(1)
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+		values := make(map[int]string)
+		for _, k := range keys {
+			if k%2 == 0 {
+				values[k] = "even"
+			}
+		}
+		return values, nil
+	}, 5*time.Millisecond, 0)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			value, found, err := l.Load(context.Background(), i%5)
+			require.NoError(t, err)
+			assert.Equal(t, i%5%2 == 0, found)
+			if found {
+				assert.Equal(t, "even", value)
+			}
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, batches[0], "keys are deduplicated")
+
+	_, _, err := l.Load(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "results are cached")
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	l := New(func(_ context.Context, keys []string) (map[string]int, error) {
+		calls.Add(1)
+		assert.LessOrEqual(t, len(keys), 2)
+		values := make(map[string]int)
+		for _, k := range keys {
+			values[k] = len(k)
+		}
+		return values, nil
+	}, time.Hour, 2)
+
+	l.Prime(context.Background(), "a", "bb", "ccc", "dddd")
+	value, found, err := l.Load(context.Background(), "bb")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 2, value)
+	_, _, err = l.Load(context.Background(), "dddd")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestLoader_Error(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	l := New(func(context.Context, []int) (map[int]int, error) {
+		return nil, boom
+	}, time.Millisecond, 0)
+
+	_, _, err := l.Load(context.Background(), 1)
+	require.ErrorIs(t, err, boom)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := New(func(context.Context, []int) (map[int]int, error) {
+		return nil, nil
+	}, time.Hour, 0)
+	_, _, err = slow.Load(ctx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+}
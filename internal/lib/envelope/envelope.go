@@ -0,0 +1,235 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown master key")
+	ErrDecrypt    = errors.New("failed to decrypt")
+)
+
+const keySize = 32
+
+// Sealed describes how a set of fields was encrypted: the data key that
+// encrypted them, wrapped by the master key with id KeyID.
+// A zero Sealed means the fields are stored in plain text.
+type Sealed struct {
+	KeyID      string
+	WrappedKey []byte
+}
+
+func (s Sealed) IsZero() bool {
+	return s.KeyID == ""
+}
+
+// Keyring holds the master keys loaded from a local keyfile. New data keys are
+// always wrapped with the active key, retired keys are kept only to unwrap
+// existing data until it is re-encrypted.
+// A nil Keyring disables encryption: Seal is a no-op and Open accepts only plain text.
+type Keyring struct {
+	activeID string
+	masters  map[string]cipher.AEAD
+}
+
+type keyFile struct {
+	ActiveKeyID string `json:"active_key_id"`
+	Keys        []struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	} `json:"keys"`
+}
+
+// Load reads a keyfile of the form
+//
+//	{"active_key_id": "k2", "keys": [{"id": "k1", "key": "<base64>"}, {"id": "k2", "key": "<base64>"}]}
+//
+// where every key is 32 random bytes.
+func Load(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyfile: %w", err)
+	}
+	var kf keyFile
+	if err = json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("failed to parse keyfile: %w", err)
+	}
+	keys := make(map[string][]byte, len(kf.Keys))
+	for _, k := range kf.Keys {
+		raw, err := base64.StdEncoding.DecodeString(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.ID, err)
+		}
+		keys[k.ID] = raw
+	}
+	return New(kf.ActiveKeyID, keys)
+}
+
+func New(activeID string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{activeID: activeID, masters: make(map[string]cipher.AEAD, len(keys))}
+	for id, raw := range keys {
+		if id == "" {
+			return nil, errors.New("key id is required")
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.masters[id] = aead
+	}
+	if _, ok := k.masters[activeID]; !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, activeID)
+	}
+	return k, nil
+}
+
+// GenerateKey returns a new random master or data key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (k *Keyring) Enabled() bool {
+	return k != nil
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.activeID
+}
+
+// Seal encrypts the fields in place with a fresh data key. aad binds the
+// ciphertexts to their owner (e.g. an order UID), and every field is also
+// bound to its position, so values cannot be swapped between rows or columns.
+func (k *Keyring) Seal(aad string, fields ...*string) (Sealed, error) {
+	if k == nil {
+		return Sealed{}, nil
+	}
+	dek, err := GenerateKey()
+	if err != nil {
+		return Sealed{}, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := seal(k.masters[k.activeID], dek, []byte(k.activeID))
+	if err != nil {
+		return Sealed{}, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return Sealed{}, err
+	}
+	for i, f := range fields {
+		ct, err := seal(aead, []byte(*f), fieldAAD(aad, i))
+		if err != nil {
+			return Sealed{}, fmt.Errorf("failed to encrypt field %d: %w", i, err)
+		}
+		*f = base64.StdEncoding.EncodeToString(ct)
+	}
+	return Sealed{KeyID: k.activeID, WrappedKey: wrapped}, nil
+}
+
+// Open decrypts fields sealed by Seal in place. Plain text fields (zero Sealed)
+// are left untouched.
+func (k *Keyring) Open(s Sealed, aad string, fields ...*string) error {
+	if s.IsZero() {
+		return nil
+	}
+	if k == nil {
+		return fmt.Errorf("%w: %q, encryption is not configured", ErrUnknownKey, s.KeyID)
+	}
+	dek, err := k.unwrap(s)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return err
+	}
+	for i, f := range fields {
+		ct, err := base64.StdEncoding.DecodeString(*f)
+		if err != nil {
+			return fmt.Errorf("%w: field %d: %w", ErrDecrypt, i, err)
+		}
+		pt, err := open(aead, ct, fieldAAD(aad, i))
+		if err != nil {
+			return fmt.Errorf("%w: field %d: %w", ErrDecrypt, i, err)
+		}
+		*f = string(pt)
+	}
+	return nil
+}
+
+// Rewrap re-encrypts the data key with the active master key. The fields
+// themselves stay unchanged, which makes master key rotation cheap.
+func (k *Keyring) Rewrap(s Sealed) (Sealed, error) {
+	if k == nil {
+		return Sealed{}, errors.New("encryption is not configured")
+	}
+	if s.KeyID == k.activeID {
+		return s, nil
+	}
+	dek, err := k.unwrap(s)
+	if err != nil {
+		return Sealed{}, err
+	}
+	wrapped, err := seal(k.masters[k.activeID], dek, []byte(k.activeID))
+	if err != nil {
+		return Sealed{}, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return Sealed{KeyID: k.activeID, WrappedKey: wrapped}, nil
+}
+
+func (k *Keyring) unwrap(s Sealed) ([]byte, error) {
+	master, ok := k.masters[s.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, s.KeyID)
+	}
+	dek, err := open(master, s.WrappedKey, []byte(s.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: data key: %w", ErrDecrypt, err)
+	}
+	return dek, nil
+}
+
+func fieldAAD(aad string, i int) []byte {
+	return []byte(aad + "#" + strconv.Itoa(i))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ct := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ct, aad)
+}
@@ -0,0 +1,118 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, active string, ids ...string) (*Keyring, map[string][]byte) {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		key, err := GenerateKey()
+		require.NoError(t, err)
+		keys[id] = key
+	}
+	k, err := New(active, keys)
+	require.NoError(t, err)
+	return k, keys
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	t.Parallel()
+
+	k, _ := newTestKeyring(t, "k1", "k1")
+
+	name, email := "Test Testov", "test@gmail.com"
+	sealed, err := k.Seal("order-1", &name, &email)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", sealed.KeyID)
+	assert.NotEqual(t, "Test Testov", name)
+	assert.NotEqual(t, "test@gmail.com", email)
+
+	gotName, gotEmail := name, email
+	require.NoError(t, k.Open(sealed, "order-1", &gotName, &gotEmail))
+	assert.Equal(t, "Test Testov", gotName)
+	assert.Equal(t, "test@gmail.com", gotEmail)
+
+	swappedName, swappedEmail := email, name
+	require.ErrorIs(t, k.Open(sealed, "order-1", &swappedName, &swappedEmail), ErrDecrypt)
+
+	otherName, otherEmail := name, email
+	require.ErrorIs(t, k.Open(sealed, "order-2", &otherName, &otherEmail), ErrDecrypt)
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	t.Parallel()
+
+	old, keys := newTestKeyring(t, "k1", "k1")
+
+	phone := "+9720000000"
+	sealed, err := old.Seal("order-1", &phone)
+	require.NoError(t, err)
+
+	keys["k2"], err = GenerateKey()
+	require.NoError(t, err)
+	rotated, err := New("k2", keys)
+	require.NoError(t, err)
+
+	plain := phone
+	require.NoError(t, rotated.Open(sealed, "order-1", &plain))
+	assert.Equal(t, "+9720000000", plain)
+
+	rewrapped, err := rotated.Rewrap(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rewrapped.KeyID)
+
+	delete(keys, "k1")
+	retired, err := New("k2", keys)
+	require.NoError(t, err)
+
+	plain = phone
+	require.ErrorIs(t, retired.Open(sealed, "order-1", &plain), ErrUnknownKey)
+	plain = phone
+	require.NoError(t, retired.Open(rewrapped, "order-1", &plain))
+	assert.Equal(t, "+9720000000", plain)
+}
+
+func TestKeyring_Disabled(t *testing.T) {
+	t.Parallel()
+
+	var k *Keyring
+	name := "Test"
+	sealed, err := k.Seal("order-1", &name)
+	require.NoError(t, err)
+	assert.True(t, sealed.IsZero())
+	assert.Equal(t, "Test", name)
+	require.NoError(t, k.Open(sealed, "order-1", &name))
+	require.ErrorIs(t, k.Open(Sealed{KeyID: "k1"}, "order-1", &name), ErrUnknownKey)
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	data, err := json.Marshal(map[string]any{
+		"active_key_id": "k1",
+		"keys":          []map[string]string{{"id": "k1", "key": base64.StdEncoding.EncodeToString(key)}},
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	k, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", k.ActiveKeyID())
+
+	_, err = New("missing", map[string][]byte{"k1": key})
+	require.ErrorIs(t, err, ErrUnknownKey)
+	_, err = New("k1", map[string][]byte{"k1": key[:16]})
+	require.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst tokens at most, refilled at Rate tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Window is the time an empty bucket needs to refill completely.
+func (l Limit) Window() time.Duration {
+	if l.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// Result describes the bucket after a request was counted.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when allowed.
+	RetryAfter time.Duration
+}
+
+func (l Limit) result(tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     l.Burst,
+		Remaining: int(math.Floor(tokens)),
+	}
+	if l.Rate > 0 {
+		res.Reset = time.Duration((float64(l.Burst) - tokens) / l.Rate * float64(time.Second))
+		if !allowed {
+			res.RetryAfter = time.Duration((1 - tokens) / l.Rate * float64(time.Second))
+		}
+	}
+	return res
+}
+
+// take refills the bucket for the elapsed time and takes one token from it.
+func (l Limit) take(tokens float64, elapsed time.Duration) (float64, bool) {
+	tokens = math.Min(float64(l.Burst), tokens+elapsed.Seconds()*l.Rate)
+	if tokens < 1 {
+		return tokens, false
+	}
+	return tokens - 1, true
+}
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Memory keeps buckets in process memory. Every replica limits on its own,
+// use Redis to share the limits between replicas.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:   make(map[string]*bucket),
+		now:       time.Now,
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	}
+	var allowed bool
+	b.tokens, allowed = limit.take(b.tokens, now.Sub(b.last))
+	b.last = now
+
+	if now.Sub(m.lastSweep) > sweepInterval {
+		m.sweep(now)
+	}
+	return limit.result(b.tokens, allowed), nil
+}
+
+// sweep drops buckets idle for longer than the sweep interval. Buckets are
+// refilled long before that with any sensible limit, so a dropped bucket is
+// indistinguishable from a full one.
+func (m *Memory) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if now.Sub(b.last) > sweepInterval {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 3; i > 0; i-- {
+		res, err := m.Allow(context.Background(), "client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, i-1, res.Remaining)
+	}
+
+	res, err := m.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, res.Reset)
+
+	other, err := m.Allow(context.Background(), "other", limit)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "buckets are per key")
+
+	now = now.Add(500 * time.Millisecond)
+	res, err = m.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(time.Hour)
+	res, err = m.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining, "bucket never exceeds burst")
+}
+
+func TestMemory_Sweep(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	m.lastSweep = now
+	limit := Limit{Rate: 1, Burst: 1}
+
+	_, err := m.Allow(context.Background(), "idle", limit)
+	require.NoError(t, err)
+
+	now = now.Add(2 * sweepInterval)
+	_, err = m.Allow(context.Background(), "active", limit)
+	require.NoError(t, err)
+	assert.NotContains(t, m.buckets, "idle")
+	assert.Contains(t, m.buckets, "active")
+}
+
+func TestLimit_Window(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 5*time.Second, Limit{Rate: 2, Burst: 10}.Window())
+	assert.Equal(t, time.Duration(0), Limit{Burst: 10}.Window())
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+)
+
+const keyPrefix = "ratelimit:"
+
+// tokenBucket refills and takes a token atomically. Redis server time is used,
+// so replicas with skewed clocks share one bucket correctly. Tokens are returned
+// as a string because Lua numbers are truncated to integers in replies.
+var tokenBucket = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// Redis keeps buckets in Redis, so all replicas share the same limits.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	ttl := limit.Window().Milliseconds() + 1000
+	res, err := tokenBucket.Run(ctx, r.client, []string{keyPrefix + key}, limit.Rate, limit.Burst, ttl).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(res) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply: %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	raw, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid token count %q: %w", raw, err)
+	}
+	return limit.result(tokens, allowed == 1), nil
+}
@@ -0,0 +1,99 @@
+// Package rates reads dated exchange rates from CSV and JSON.
+package rates
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/shopspring/decimal"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRates = errors.New("invalid exchange rates")
+
+// Load reads a rates file, CSV when its extension is .csv and JSON otherwise.
+func Load(path string) ([]domain.ExchangeRate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return ParseCSV(f)
+	}
+	return ParseJSON(f)
+}
+
+// ParseCSV reads rates of the form
+//
+//	date,currency,rate
+//	2021-11-26,EUR,1.1317
+//
+// where the header is optional.
+func ParseCSV(r io.Reader) ([]domain.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRates, err)
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "date") {
+		records = records[1:]
+	}
+	rates := make([]domain.ExchangeRate, 0, len(records))
+	for i, record := range records {
+		date, err := parseDate(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %w", ErrInvalidRates, i+1, err)
+		}
+		rate, err := decimal.NewFromString(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %w", ErrInvalidRates, i+1, err)
+		}
+		rates = append(rates, domain.ExchangeRate{Currency: record[1], Date: date, Rate: rate})
+	}
+	return rates, nil
+}
+
+// ParseJSON reads rates of the form
+//
+//	[{"date": "2021-11-26", "currency": "EUR", "rate": "1.1317"}]
+//
+// where a date can also be an RFC 3339 time and a rate a number.
+func ParseJSON(r io.Reader) ([]domain.ExchangeRate, error) {
+	var entries []struct {
+		Date     string          `json:"date"`
+		Currency string          `json:"currency"`
+		Rate     decimal.Decimal `json:"rate"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRates, err)
+	}
+	rates := make([]domain.ExchangeRate, 0, len(entries))
+	for i, entry := range entries {
+		date, err := parseDate(entry.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d: %w", ErrInvalidRates, i+1, err)
+		}
+		rates = append(rates, domain.ExchangeRate{Currency: entry.Currency, Date: date, Rate: entry.Rate})
+	}
+	return rates, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date.UTC(), nil
+}
@@ -0,0 +1,100 @@
+package rates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	want := []domain.ExchangeRate{
+		{Currency: "EUR", Date: time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("1.1317")},
+		{Currency: "RUB", Date: time.Date(2021, 11, 27, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("0.0133")},
+	}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "csv",
+			file:    "rates.csv",
+			content: "date,currency,rate\n2021-11-26,EUR,1.1317\n2021-11-27, RUB, 0.0133\n",
+		},
+		{
+			name:    "csv without header",
+			file:    "rates.CSV",
+			content: "2021-11-26,EUR,1.1317\n2021-11-27,RUB,0.0133\n",
+		},
+		{
+			name: "json",
+			file: "rates.json",
+			content: `[{"date": "2021-11-26", "currency": "EUR", "rate": "1.1317"},
+				{"date": "2021-11-27T00:00:00Z", "currency": "RUB", "rate": 0.0133}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			rates, err := Load(path)
+			require.NoError(t, err)
+			require.Len(t, rates, len(want))
+			for i := range want {
+				assert.Equal(t, want[i].Currency, rates[i].Currency)
+				assert.True(t, want[i].Date.Equal(rates[i].Date))
+				assert.True(t, want[i].Rate.Equal(rates[i].Rate), rates[i].Rate.String())
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		parse func(string) error
+		input string
+	}{
+		{name: "csv date", input: "26.11.2021,EUR,1.1", parse: parseCSV},
+		{name: "csv rate", input: "2021-11-26,EUR,abc", parse: parseCSV},
+		{name: "csv fields", input: "2021-11-26,EUR", parse: parseCSV},
+		{name: "json date", input: `[{"date": "", "currency": "EUR", "rate": 1}]`, parse: parseJSON},
+		{name: "json syntax", input: `{"date": "2021-11-26"`, parse: parseJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.ErrorIs(t, tt.parse(tt.input), ErrInvalidRates)
+		})
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(filepath.Join(t.TempDir(), "rates.csv"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func parseCSV(input string) error {
+	_, err := ParseCSV(strings.NewReader(input))
+	return err
+}
+
+func parseJSON(input string) error {
+	_, err := ParseJSON(strings.NewReader(input))
+	return err
+}
@@ -12,7 +12,7 @@ var ErrDefaultLogger = errors.New("default logger")
 
 func LoadFromConfig(path string) (*zap.SugaredLogger, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		defaultLogger, _ := zap.NewDevelopment()
+		defaultLogger, _ := zap.NewDevelopment(zap.WrapCore(NewMaskingCore))
 		sugar := defaultLogger.Sugar()
 		return sugar, ErrDefaultLogger
 	}
@@ -26,7 +26,7 @@ func LoadFromConfig(path string) (*zap.SugaredLogger, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	logger, err := cfg.Build()
+	logger, err := cfg.Build(zap.WrapCore(NewMaskingCore))
 	if err != nil {
 		return nil, fmt.Errorf("failed to build logger from config %q: %w", path, err)
 	}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"github.com/Killazius/L0/pkg/mask"
+	"go.uber.org/zap/zapcore"
+	"reflect"
+)
+
+// maskingCore redacts personal data in structured fields before they reach the
+// encoder, so logging a whole order never writes customer contacts in clear text.
+type maskingCore struct {
+	zapcore.Core
+}
+
+func NewMaskingCore(core zapcore.Core) zapcore.Core {
+	return maskingCore{Core: core}
+}
+
+func (c maskingCore) With(fields []zapcore.Field) zapcore.Core {
+	return maskingCore{Core: c.Core.With(maskFields(fields))}
+}
+
+func (c maskingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c maskingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, maskFields(fields))
+}
+
+func maskFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		if f.Type != zapcore.ReflectType || f.Interface == nil || !mask.Sensitive(reflect.TypeOf(f.Interface)) {
+			continue
+		}
+		masked := mask.Value(f.Interface)
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields)
+		}
+		out[i].Interface = masked
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
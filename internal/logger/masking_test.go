@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMaskingCore(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(NewMaskingCore(core)).Sugar()
+
+	order := &domain.Order{
+		OrderUID: "uid",
+		Delivery: domain.Delivery{Name: "Test Testov", Email: "test@gmail.com", City: "Haifa"},
+	}
+
+	log.With("first", order).Infow("success", "order", order, "count", 1)
+	log.Debugw("skipped", "order", order)
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	for _, key := range []string{"first", "order"} {
+		logged, ok := fields[key].(*domain.Order)
+		require.True(t, ok, key)
+		assert.Equal(t, "T*** T***", logged.Delivery.Name)
+		assert.Equal(t, "t***@gmail.com", logged.Delivery.Email)
+		assert.Equal(t, "Haifa", logged.Delivery.City)
+	}
+	assert.EqualValues(t, 1, fields["count"])
+	assert.Equal(t, "Test Testov", order.Delivery.Name)
+}
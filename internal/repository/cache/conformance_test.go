@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/repository/conformance"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+// TestCache_Conformance runs against the Redis in TEST_REDIS_ADDR, with the
+// password in TEST_REDIS_PASSWORD, and is skipped without one.
+func TestCache_Conformance(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+	conformance.TestCache(t, func(t *testing.T) service.OrderCache {
+		client := redis.NewClient(&redis.Options{Addr: addr, Password: os.Getenv("TEST_REDIS_PASSWORD")})
+		t.Cleanup(func() {
+			_ = client.Close()
+		})
+		require.NoError(t, client.Ping(context.Background()).Err())
+		return New(client, nil)
+	})
+}
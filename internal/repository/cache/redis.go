@@ -7,18 +7,35 @@ import (
 	"fmt"
 	"github.com/Killazius/L0/internal/config"
 	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/envelope"
 	"github.com/Killazius/L0/internal/repository"
 	"github.com/redis/go-redis/v9"
 	"time"
 )
 
 type Cache struct {
-	client *redis.Client
+	client  *redis.Client
+	keyring *envelope.Keyring
 }
 
-const defaultTTL = time.Hour * 24
+const (
+	defaultTTL = time.Hour * 24
+
+	orderField      = "order"
+	etagField       = "etag"
+	keyIDField      = "key_id"
+	wrappedKeyField = "wrapped_key"
+
+	// Secondary index keys map payment transactions and track numbers to order
+	// UIDs. Order UIDs are alphanumeric, so the prefixed keys do not collide.
+	transactionPrefix = "transaction:"
+	trackNumberPrefix = "track_number:"
+)
 
 func CreateClient(cfg config.RedisConfig) (*redis.Client, error) {
+	if cfg.Address == "" || cfg.Password == "" {
+		return nil, errors.New("redis address and password are required")
+	}
 	client := redis.NewClient(&redis.Options{
 		Addr:     cfg.Address,
 		DB:       cfg.DB,
@@ -31,37 +48,207 @@ func CreateClient(cfg config.RedisConfig) (*redis.Client, error) {
 	}
 	return client, nil
 }
-func New(client *redis.Client) *Cache {
-	return &Cache{client: client}
+
+// New creates a cache. When keyring is not nil, customer contact fields are
+// encrypted inside the cached JSON, the same way they are in the database.
+func New(client *redis.Client, keyring *envelope.Keyring) *Cache {
+	return &Cache{client: client, keyring: keyring}
 }
 
+// Set stores the order together with its ETag in a hash, so conditional
+// requests can be answered without decoding the whole order. The secondary
+// index keys of the order are refreshed in the same transaction and removed
+// on Delete; they can still outlive an expired order, so readers must check
+// the orders they point to.
 func (c *Cache) Set(ctx context.Context, order *domain.Order) error {
 	data, err := json.Marshal(order)
 	if err != nil {
 		return err
 	}
-	err = c.client.Set(ctx, order.OrderUID, data, defaultTTL).Err()
+	etag := order.ETag()
+	values := []any{orderField, data, etagField, etag}
+	if c.keyring.Enabled() {
+		sealedOrder := *order
+		sealed, err := repository.SealDelivery(c.keyring, order.OrderUID, &sealedOrder.Delivery)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt delivery: %w", err)
+		}
+		if data, err = json.Marshal(&sealedOrder); err != nil {
+			return err
+		}
+		values = []any{orderField, data, etagField, etag, keyIDField, sealed.KeyID, wrappedKeyField, sealed.WrappedKey}
+	}
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, order.OrderUID)
+		pipe.HSet(ctx, order.OrderUID, values...)
+		pipe.Expire(ctx, order.OrderUID, defaultTTL)
+		if order.Payment.Transaction != "" {
+			pipe.Set(ctx, transactionPrefix+order.Payment.Transaction, order.OrderUID, defaultTTL)
+		}
+		if order.TrackNumber != "" {
+			pipe.SAdd(ctx, trackNumberPrefix+order.TrackNumber, order.OrderUID)
+			pipe.Expire(ctx, trackNumberPrefix+order.TrackNumber, defaultTTL)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *Cache) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
+// GetUIDByTransaction returns the UID of the order cached last with the
+// payment transaction.
+func (c *Cache) GetUIDByTransaction(ctx context.Context, transaction string) (string, error) {
+	orderUID, err := c.client.Get(ctx, transactionPrefix+transaction).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", repository.ErrOrderNotFound
+		}
+		return "", fmt.Errorf("failed to get order uid from cache: %w", err)
+	}
+	return orderUID, nil
+}
+
+// GetUIDsByTrackNumber returns the UIDs of the cached orders with the track number.
+func (c *Cache) GetUIDsByTrackNumber(ctx context.Context, trackNumber string) ([]string, error) {
+	orderUIDs, err := c.client.SMembers(ctx, trackNumberPrefix+trackNumber).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order uids from cache: %w", err)
+	}
+	if len(orderUIDs) == 0 {
+		return nil, repository.ErrOrderNotFound
+	}
+	return orderUIDs, nil
+}
 
-	orderJSON, err := c.client.Get(ctx, orderUID).Result()
+func (c *Cache) GetETag(ctx context.Context, orderUID string) (string, error) {
+	etag, err := c.client.HGet(ctx, orderUID, etagField).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return nil, repository.ErrOrderNotFound
+			return "", repository.ErrOrderNotFound
 		}
+		return "", fmt.Errorf("failed to get order etag from cache: %w", err)
+	}
+	return etag, nil
+}
+
+func (c *Cache) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
+
+	values, err := c.client.HMGet(ctx, orderUID, orderField, keyIDField, wrappedKeyField).Result()
+	if err != nil {
 		return nil, fmt.Errorf("failed to get order from cache: %w", err)
 	}
+	return c.decode(orderUID, values)
+}
+
+// GetMany returns the cached orders keyed by order UID, orders that are not
+// cached are left out. All orders are read in one pipelined round trip.
+func (c *Cache) GetMany(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error) {
+	cmds := make([]*redis.SliceCmd, 0, len(orderUIDs))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, orderUID := range orderUIDs {
+			cmds = append(cmds, pipe.HMGet(ctx, orderUID, orderField, keyIDField, wrappedKeyField))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders from cache: %w", err)
+	}
+
+	orders := make(map[string]domain.Order, len(orderUIDs))
+	for i, cmd := range cmds {
+		order, err := c.decode(orderUIDs[i], cmd.Val())
+		if err != nil {
+			if errors.Is(err, repository.ErrOrderNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		orders[order.OrderUID] = *order
+	}
+	return orders, nil
+}
+
+// decode builds an order from the order, key_id and wrapped_key hash fields.
+func (c *Cache) decode(orderUID string, values []any) (*domain.Order, error) {
+	orderJSON, ok := values[0].(string)
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
 
 	var order domain.Order
-	err = json.Unmarshal([]byte(orderJSON), &order)
+	err := json.Unmarshal([]byte(orderJSON), &order)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal order: %w", err)
 	}
 
+	var sealed envelope.Sealed
+	if keyID, ok := values[1].(string); ok {
+		sealed.KeyID = keyID
+	}
+	if wrapped, ok := values[2].(string); ok {
+		sealed.WrappedKey = []byte(wrapped)
+	}
+	if err = repository.OpenDelivery(c.keyring, orderUID, &order.Delivery, sealed); err != nil {
+		return nil, fmt.Errorf("failed to decrypt cached delivery: %w", err)
+	}
+
 	return &order, nil
 }
+
+// Delete evicts the orders from the cache together with their secondary index
+// entries. A transaction key is only removed while it still points to the
+// evicted order.
+func (c *Cache) Delete(ctx context.Context, orderUIDs ...string) error {
+	if len(orderUIDs) == 0 {
+		return nil
+	}
+	cmds := make([]*redis.StringCmd, len(orderUIDs))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, orderUID := range orderUIDs {
+			cmds[i] = pipe.HGet(ctx, orderUID, orderField)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to get orders from cache: %w", err)
+	}
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, orderUIDs...)
+		for i, orderUID := range orderUIDs {
+			var keys indexKeys
+			if json.Unmarshal([]byte(cmds[i].Val()), &keys) != nil {
+				continue
+			}
+			if keys.TrackNumber != "" {
+				pipe.SRem(ctx, trackNumberPrefix+keys.TrackNumber, orderUID)
+			}
+			if keys.Payment.Transaction != "" {
+				deleteIfEqual.Eval(ctx, pipe, []string{transactionPrefix + keys.Payment.Transaction}, orderUID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete orders from cache: %w", err)
+	}
+	return nil
+}
+
+// indexKeys are the fields of a cached order the secondary indexes are keyed
+// by. They are never encrypted, so they are read without the keyring.
+type indexKeys struct {
+	TrackNumber string `json:"track_number"`
+	Payment     struct {
+		Transaction string `json:"transaction"`
+	} `json:"payment"`
+}
+
+// deleteIfEqual deletes KEYS[1] when it holds ARGV[1].
+var deleteIfEqual = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
@@ -0,0 +1,648 @@
+// Package conformance checks that implementations of service.OrderRepository
+// and service.OrderCache keep the guarantees the service relies on. Every
+// storage backend runs the same suite from its tests, so a backend that
+// diverges fails there instead of in production.
+//
+// The suites share the storage between subtests and with other tests: every
+// order they write has a fresh order UID, transaction and track number, and
+// they only look at their own orders.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/test"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// largeOrderItems is the number of items of the large order, more than any
+	// real order has.
+	largeOrderItems = 1000
+	// concurrentWriters is the number of goroutines writing at once.
+	concurrentWriters = 16
+)
+
+// TestRepository runs the repository suite against the repository returned by
+// newRepository.
+func TestRepository(t *testing.T, newRepository func(t *testing.T) service.OrderRepository) {
+	t.Helper()
+	repo := newRepository(t)
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		order := newOrder()
+		require.NoError(t, repo.Create(ctx, order))
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+		assert.EqualValues(t, 1, got.Version)
+
+		got, err = repo.GetByTransaction(ctx, order.Payment.Transaction)
+		require.NoError(t, err)
+		assert.Equal(t, order.OrderUID, got.OrderUID)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		order := newOrder()
+		require.NoError(t, repo.Create(ctx, order))
+
+		duplicate := newOrder()
+		duplicate.OrderUID = order.OrderUID
+		assert.ErrorIs(t, repo.Create(ctx, duplicate), repository.ErrDuplicateOrder)
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+	})
+
+	t.Run("duplicate in another month", func(t *testing.T) {
+		// Orders are partitioned by month of date_created, the UID must stay
+		// unique across partitions.
+		order := newOrder()
+		require.NoError(t, repo.Create(ctx, order))
+
+		duplicate := newOrder()
+		duplicate.OrderUID = order.OrderUID
+		duplicate.DateCreated = order.DateCreated.AddDate(0, -2, 0)
+		assert.ErrorIs(t, repo.Create(ctx, duplicate), repository.ErrDuplicateOrder)
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		order := newOrder()
+		_, err := repo.Get(ctx, order.OrderUID)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+		_, err = repo.GetParts(ctx, order.OrderUID, domain.PartItems)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+		_, err = repo.GetByTransaction(ctx, order.Payment.Transaction)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+
+		found, err := repo.GetMany(ctx, []string{order.OrderUID})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("partial data", func(t *testing.T) {
+		order := newOrder()
+		require.NoError(t, repo.Create(ctx, order))
+
+		tests := []struct {
+			name  string
+			parts domain.Part
+		}{
+			{name: "header", parts: 0},
+			{name: "delivery", parts: domain.PartDelivery},
+			{name: "payment", parts: domain.PartPayment},
+			{name: "items", parts: domain.PartItems},
+			{name: "delivery and items", parts: domain.PartDelivery | domain.PartItems},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := repo.GetParts(ctx, order.OrderUID, tt.parts)
+				require.NoError(t, err)
+
+				want := *order
+				if !tt.parts.Has(domain.PartDelivery) {
+					want.Delivery = domain.Delivery{}
+				}
+				if !tt.parts.Has(domain.PartPayment) {
+					want.Payment = domain.Payment{}
+				}
+				if !tt.parts.Has(domain.PartItems) {
+					want.Items = nil
+				}
+				assertOrder(t, &want, got)
+			})
+		}
+
+		deliveries, err := repo.GetDeliveries(ctx, []string{order.OrderUID})
+		require.NoError(t, err)
+		assert.Equal(t, order.Delivery, deliveries[order.OrderUID])
+		payments, err := repo.GetPayments(ctx, []string{order.OrderUID})
+		require.NoError(t, err)
+		assertJSON(t, order.Payment, payments[order.OrderUID])
+		items, err := repo.GetItems(ctx, []string{order.OrderUID})
+		require.NoError(t, err)
+		assertJSON(t, order.Items, items[order.OrderUID])
+	})
+
+	t.Run("item order", func(t *testing.T) {
+		order := newOrder()
+		order.Items = descendingItems(order, 10)
+		require.NoError(t, repo.Create(ctx, order))
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		require.Len(t, got.Items, len(order.Items))
+		for i, item := range got.Items {
+			assert.Equal(t, order.Items[i].ChrtID, item.ChrtID, "item %d", i)
+		}
+	})
+
+	t.Run("decimal precision", func(t *testing.T) {
+		order := preciseOrder()
+		require.NoError(t, repo.Create(ctx, order))
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertDecimals(t, order, got)
+	})
+
+	t.Run("customer", func(t *testing.T) {
+		first, second, third := newOrder(), newOrder(), newOrder()
+		customerID := "CUSTOMER" + first.OrderUID
+		for i, order := range []*domain.Order{first, second, third} {
+			order.CustomerID = customerID
+			order.DateCreated = first.DateCreated.Add(time.Duration(i) * time.Hour)
+			order.Payment.Currency = "USD"
+			order.Payment.Amount = order.Payment.Amount.Round(2)
+		}
+		second.Delivery = first.Delivery
+		third.Payment.Currency = "EUR"
+		for _, order := range []*domain.Order{first, second, third} {
+			require.NoError(t, repo.Create(ctx, order))
+		}
+
+		customer, err := repo.GetCustomer(ctx, customerID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, first.DateCreated, customer.FirstOrderAt, 0)
+		assert.WithinDuration(t, third.DateCreated, customer.LastOrderAt, 0)
+		assert.Equal(t, third.Delivery.Name, customer.Name)
+		assert.Equal(t, third.Delivery.Email, customer.Email)
+		require.Len(t, customer.Addresses, 2, "equal addresses are one entry")
+		assert.Equal(t, third.Delivery, customer.Addresses[0].Delivery)
+		assert.Equal(t, first.Delivery, customer.Addresses[1].Delivery)
+		assert.WithinDuration(t, first.DateCreated, customer.Addresses[1].FirstUsedAt, 0)
+		assert.WithinDuration(t, second.DateCreated, customer.Addresses[1].LastUsedAt, 0)
+
+		stats, err := repo.CustomerStats(ctx, customerID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats.OrderCount)
+		assertJSON(t, []domain.Amount{
+			{Currency: "EUR", Amount: third.Payment.Amount},
+			{Currency: "USD", Amount: first.Payment.Amount.Add(second.Payment.Amount)},
+		}, stats.LifetimeValue)
+
+		_, err = repo.Delete(ctx, third.OrderUID, time.Now().UTC())
+		require.NoError(t, err)
+		stats, err = repo.CustomerStats(ctx, customerID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.OrderCount, "deleted orders are not counted")
+		assert.Len(t, stats.LifetimeValue, 1)
+
+		_, err = repo.GetCustomer(ctx, "UNKNOWN"+customerID)
+		require.ErrorIs(t, err, repository.ErrCustomerNotFound)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		first, second, deleted := newOrder(), newOrder(), newOrder()
+		provider, brand := "PROVIDER"+first.OrderUID, "BRAND"+first.OrderUID
+		day := first.DateCreated.UTC().Truncate(24 * time.Hour)
+		for i, order := range []*domain.Order{first, second, deleted} {
+			order.DateCreated = day.Add(time.Duration(i+1) * time.Hour)
+			order.Payment.Provider = provider
+			order.Payment.Currency = "USD"
+			order.Payment.Amount = order.Payment.Amount.Round(2)
+			order.Payment.DeliveryCost = order.Payment.DeliveryCost.Round(2)
+			order.Items = order.Items[:1]
+			order.Items[0].Brand = brand
+			order.Items[0].TotalPrice = order.Items[0].TotalPrice.Round(2)
+		}
+		second.Items = append(second.Items, second.Items[0])
+		for _, order := range []*domain.Order{first, second, deleted} {
+			require.NoError(t, repo.Create(ctx, order))
+		}
+		_, err := repo.Delete(ctx, deleted.OrderUID, time.Now().UTC())
+		require.NoError(t, err)
+		_, err = repo.RefreshStats(ctx)
+		require.NoError(t, err)
+
+		stats, err := repo.DailyStats(ctx, domain.StatsQuery{From: day, To: day.AddDate(0, 0, 1), GroupBy: domain.DimensionProvider})
+		require.NoError(t, err)
+		var found []domain.DailyStats
+		for _, s := range stats {
+			if s.Group == provider {
+				found = append(found, s)
+			}
+		}
+		require.Len(t, found, 1)
+		assert.WithinDuration(t, day, found[0].Day, 0)
+		assert.Equal(t, "USD", found[0].Currency)
+		assert.Equal(t, 2, found[0].Orders, "deleted orders are not counted")
+		assert.Equal(t, 3, found[0].Items)
+		assertJSON(t, first.Payment.Amount.Add(second.Payment.Amount), found[0].Amount)
+		assertJSON(t, first.Payment.DeliveryCost.Add(second.Payment.DeliveryCost), found[0].DeliveryCost)
+		assert.Equal(t, "1.5", found[0].AvgBasketSize.String())
+		assert.Equal(t, 2, found[0].Unconverted, "orders without a rate are not converted")
+
+		days, err := repo.BrandStats(ctx, day, day.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		require.Len(t, days, 1)
+		var sales *domain.BrandStats
+		for i := range days[0].Brands {
+			if days[0].Brands[i].Brand == brand {
+				sales = &days[0].Brands[i]
+			}
+		}
+		require.NotNil(t, sales)
+		assert.Equal(t, 3, sales.Items)
+		assert.Equal(t, 2, sales.Orders)
+		revenue := first.Items[0].TotalPrice.Add(second.Items[0].TotalPrice).Add(second.Items[1].TotalPrice)
+		assertJSON(t, []domain.Amount{{Currency: "USD", Amount: revenue}}, sales.Revenue)
+	})
+
+	t.Run("exchange rates", func(t *testing.T) {
+		// XTS and XBA are the ISO 4217 codes reserved for tests, so the
+		// conversion leaves the orders of other tests alone.
+		const base, currency = "XTS", "XBA"
+		foreign, local := newOrder(), newOrder()
+		customerID := "CUSTOMER" + foreign.OrderUID
+		day := foreign.DateCreated.UTC().Truncate(24 * time.Hour)
+		for _, order := range []*domain.Order{foreign, local} {
+			order.CustomerID = customerID
+			order.DateCreated = day.Add(time.Hour)
+			order.Payment.Amount = order.Payment.Amount.Round(2)
+		}
+		foreign.Payment.Currency, local.Payment.Currency = currency, base
+		for _, order := range []*domain.Order{foreign, local} {
+			require.NoError(t, repo.Create(ctx, order))
+		}
+		stats, err := repo.CustomerStats(ctx, customerID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.LifetimeValueBase.Unconverted)
+
+		rate := decimal.RequireFromString("2.5")
+		converted, err := repo.SaveExchangeRates(ctx, base, []domain.ExchangeRate{
+			{Currency: currency, Date: day, Rate: rate},
+			{Currency: currency, Date: day.AddDate(0, 0, 1), Rate: decimal.RequireFromString("3")},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, converted, foreign.OrderUID)
+		assert.Contains(t, converted, local.OrderUID)
+
+		got, err := repo.Get(ctx, foreign.OrderUID)
+		require.NoError(t, err)
+		foreign.Payment.Convert(base, rate)
+		assertOrder(t, foreign, got)
+		got, err = repo.Get(ctx, local.OrderUID)
+		require.NoError(t, err)
+		local.Payment.Convert(base, decimal.NewFromInt(1))
+		assertOrder(t, local, got)
+
+		stats, err = repo.CustomerStats(ctx, customerID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.LifetimeValueBase.Unconverted)
+		assertJSON(t, foreign.Payment.BaseAmount.Add(*local.Payment.BaseAmount), stats.LifetimeValueBase.Amount)
+
+		onDay, err := repo.ExchangeRate(ctx, base, currency, day.Add(23*time.Hour))
+		require.NoError(t, err)
+		assertJSON(t, rate, onDay)
+		later, err := repo.ExchangeRate(ctx, base, currency, day.AddDate(0, 0, 2))
+		require.NoError(t, err)
+		assertJSON(t, decimal.RequireFromString("3"), later)
+		_, err = repo.ExchangeRate(ctx, "XXX", currency, day)
+		require.ErrorIs(t, err, repository.ErrRateNotFound)
+
+		rates, err := repo.ExchangeRates(ctx, base, day.Add(time.Hour))
+		require.NoError(t, err)
+		var effective []domain.ExchangeRate
+		for _, r := range rates {
+			if r.Currency == currency {
+				effective = append(effective, r)
+			}
+		}
+		require.Len(t, effective, 1)
+		assert.WithinDuration(t, day, effective[0].Date, 0)
+		assertJSON(t, rate, effective[0].Rate)
+
+		converted, err = repo.SaveExchangeRates(ctx, base, []domain.ExchangeRate{{Currency: currency, Date: day, Rate: decimal.NewFromInt(4)}})
+		require.NoError(t, err)
+		assert.NotContains(t, converted, foreign.OrderUID, "converted payments keep their rate")
+		got, err = repo.Get(ctx, foreign.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, foreign, got)
+	})
+
+	t.Run("versioned upsert without update time", func(t *testing.T) {
+		order := newOrder()
+		created, err := repo.Upsert(ctx, order)
+		require.NoError(t, err)
+		require.True(t, created)
+
+		next := *order
+		next.Version = order.Version + 1
+		next.UpdatedAt = time.Time{}
+		created, err = repo.Upsert(ctx, &next)
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assert.Equal(t, next.Version, got.Version)
+		assert.False(t, got.UpdatedAt.IsZero())
+		assert.False(t, got.UpdatedAt.Before(order.UpdatedAt))
+	})
+
+	t.Run("partition of a stored month", func(t *testing.T) {
+		// The month has no partition yet, so the order is stored in the
+		// default one and has to be moved when the month is created.
+		order := newOrder()
+		month := time.Date(2500+int(time.Now().UnixNano()%500), time.Month(1+time.Now().Nanosecond()%12), 1, 0, 0, 0, 0, time.UTC)
+		order.DateCreated = month.Add(time.Hour)
+		require.NoError(t, repo.Create(ctx, order))
+
+		_, err := repo.EnsurePartitions(ctx, month, month)
+		require.NoError(t, err)
+		_, err = repo.EnsurePartitions(ctx, month, month)
+		require.NoError(t, err)
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+	})
+
+	t.Run("large order", func(t *testing.T) {
+		order := newOrder()
+		order.Items = descendingItems(order, largeOrderItems)
+		require.NoError(t, repo.Create(ctx, order))
+
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+	})
+
+	t.Run("concurrent create of one order", func(t *testing.T) {
+		order := newOrder()
+		errs := make([]error, concurrentWriters)
+		var wg sync.WaitGroup
+		for i := range concurrentWriters {
+			wg.Go(func() {
+				copied := *order
+				errs[i] = repo.Create(ctx, &copied)
+			})
+		}
+		wg.Wait()
+
+		var created int
+		for _, err := range errs {
+			if err == nil {
+				created++
+				continue
+			}
+			assert.ErrorIs(t, err, repository.ErrDuplicateOrder)
+		}
+		assert.Equal(t, 1, created)
+		got, err := repo.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+	})
+
+	t.Run("concurrent create of many orders", func(t *testing.T) {
+		orders := make([]*domain.Order, concurrentWriters)
+		uids := make([]string, concurrentWriters)
+		for i := range orders {
+			orders[i] = newOrder()
+			uids[i] = orders[i].OrderUID
+		}
+		var wg sync.WaitGroup
+		for _, order := range orders {
+			wg.Go(func() {
+				assert.NoError(t, repo.Create(ctx, order))
+			})
+		}
+		wg.Wait()
+
+		found, err := repo.GetMany(ctx, uids)
+		require.NoError(t, err)
+		require.Len(t, found, len(orders))
+		for _, order := range orders {
+			got := found[order.OrderUID]
+			assertOrder(t, order, &got)
+		}
+	})
+}
+
+// TestCache runs the cache suite against the cache returned by newCache.
+func TestCache(t *testing.T, newCache func(t *testing.T) service.OrderCache) {
+	t.Helper()
+	cache := newCache(t)
+	ctx := context.Background()
+
+	t.Run("set and get", func(t *testing.T) {
+		order := newOrder()
+		require.NoError(t, cache.Set(ctx, order))
+
+		got, err := cache.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+
+		orderUID, err := cache.GetUIDByTransaction(ctx, order.Payment.Transaction)
+		require.NoError(t, err)
+		assert.Equal(t, order.OrderUID, orderUID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		order := newOrder()
+		_, err := cache.Get(ctx, order.OrderUID)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+		_, err = cache.GetETag(ctx, order.OrderUID)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+		_, err = cache.GetUIDByTransaction(ctx, order.Payment.Transaction)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+		_, err = cache.GetUIDsByTrackNumber(ctx, order.TrackNumber)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+	})
+
+	t.Run("etag", func(t *testing.T) {
+		order := newOrder()
+		require.NoError(t, cache.Set(ctx, order))
+		etag, err := cache.GetETag(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, etag)
+
+		require.NoError(t, cache.Set(ctx, order))
+		same, err := cache.GetETag(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assert.Equal(t, etag, same)
+
+		// The same order read back from the database is encoded differently,
+		// with its times in the zone of the connection.
+		reloaded := *order
+		reloaded.DateCreated = order.DateCreated.In(time.FixedZone("UTC+3", 3*60*60))
+		reloaded.Payment.Amount = order.Payment.Amount.Round(2)
+		require.NoError(t, cache.Set(ctx, &reloaded))
+		same, err = cache.GetETag(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assert.Equal(t, etag, same)
+
+		changed := *order
+		changed.Version = max(order.Version, 1) + 1
+		require.NoError(t, cache.Set(ctx, &changed))
+		other, err := cache.GetETag(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assert.NotEqual(t, etag, other)
+	})
+
+	t.Run("get many and delete", func(t *testing.T) {
+		first, second, missing := newOrder(), newOrder(), newOrder()
+		require.NoError(t, cache.Set(ctx, first))
+		require.NoError(t, cache.Set(ctx, second))
+
+		found, err := cache.GetMany(ctx, []string{first.OrderUID, second.OrderUID, missing.OrderUID})
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+		got := found[second.OrderUID]
+		assertOrder(t, second, &got)
+
+		require.NoError(t, cache.Delete(ctx, first.OrderUID, missing.OrderUID))
+		_, err = cache.Get(ctx, first.OrderUID)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+		_, err = cache.Get(ctx, second.OrderUID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("track number", func(t *testing.T) {
+		first, second := newOrder(), newOrder()
+		second.TrackNumber = first.TrackNumber
+		require.NoError(t, cache.Set(ctx, first))
+		require.NoError(t, cache.Set(ctx, second))
+
+		orderUIDs, err := cache.GetUIDsByTrackNumber(ctx, first.TrackNumber)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{first.OrderUID, second.OrderUID}, orderUIDs)
+
+		require.NoError(t, cache.Delete(ctx, first.OrderUID))
+		orderUIDs, err = cache.GetUIDsByTrackNumber(ctx, first.TrackNumber)
+		require.NoError(t, err)
+		assert.Equal(t, []string{second.OrderUID}, orderUIDs)
+		_, err = cache.GetUIDByTransaction(ctx, first.Payment.Transaction)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+
+		require.NoError(t, cache.Delete(ctx, second.OrderUID))
+		_, err = cache.GetUIDsByTrackNumber(ctx, first.TrackNumber)
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+	})
+
+	t.Run("decimal precision", func(t *testing.T) {
+		order := preciseOrder()
+		require.NoError(t, cache.Set(ctx, order))
+
+		got, err := cache.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertDecimals(t, order, got)
+	})
+
+	t.Run("large order", func(t *testing.T) {
+		order := newOrder()
+		order.Items = descendingItems(order, largeOrderItems)
+		require.NoError(t, cache.Set(ctx, order))
+
+		got, err := cache.Get(ctx, order.OrderUID)
+		require.NoError(t, err)
+		assertOrder(t, order, got)
+	})
+
+	t.Run("concurrent set and get", func(t *testing.T) {
+		order := newOrder()
+		require.NoError(t, cache.Set(ctx, order))
+		var wg sync.WaitGroup
+		for i := range concurrentWriters {
+			wg.Go(func() {
+				copied := *order
+				copied.SmID = i
+				assert.NoError(t, cache.Set(ctx, &copied))
+				got, err := cache.Get(ctx, order.OrderUID)
+				if assert.NoError(t, err) {
+					assert.Equal(t, order.OrderUID, got.OrderUID)
+				}
+			})
+		}
+		wg.Wait()
+	})
+}
+
+// newOrder returns a valid order with a unique order UID, transaction and
+// track number. Its amounts have the two decimal places the database keeps.
+func newOrder() *domain.Order {
+	order := test.GenerateOrder()
+	order.TrackNumber = "TRACK" + order.OrderUID
+	order.DateCreated = order.DateCreated.UTC().Truncate(time.Microsecond)
+	for i := range order.Items {
+		order.Items[i].Price = order.Items[i].Price.Round(2)
+	}
+	return order
+}
+
+// preciseOrder returns an order whose amounts are at the edges of NUMERIC(12, 2).
+func preciseOrder() *domain.Order {
+	order := newOrder()
+	order.Payment.Amount = decimal.RequireFromString("9999999999.99")
+	order.Payment.DeliveryCost = decimal.RequireFromString("0.01")
+	order.Items[0].Price = decimal.RequireFromString("1234567.89")
+	order.Items[0].TotalPrice = decimal.RequireFromString("0.10")
+	return order
+}
+
+// descendingItems returns n items of the order with descending chrt ids, so an
+// implementation that sorts them instead of keeping their order is caught.
+func descendingItems(order *domain.Order, n int) []domain.Item {
+	items := make([]domain.Item, n)
+	for i := range items {
+		items[i] = order.Items[0]
+		items[i].ChrtID = n - i
+		items[i].Rid = fmt.Sprintf("%s%d", order.Items[0].Rid, i)
+	}
+	return items
+}
+
+// assertOrder compares the stored order with the written one. The version and
+// update time are set by the storage and are not compared; decimals and times
+// are compared by value.
+func assertOrder(t *testing.T, want, got *domain.Order) {
+	t.Helper()
+	normalize := func(order domain.Order) domain.Order {
+		order.DateCreated = order.DateCreated.UTC()
+		order.UpdatedAt = time.Time{}
+		order.Version = 0
+		order.DeletedAt = nil
+		if len(order.Items) == 0 {
+			order.Items = nil
+		}
+		return order
+	}
+	assertJSON(t, normalize(*want), normalize(*got))
+}
+
+// assertJSON compares the values by their JSON, in which equal decimals look
+// the same whatever their exponent.
+func assertJSON(t *testing.T, want, got any) {
+	t.Helper()
+	wantJSON, err := json.Marshal(want)
+	require.NoError(t, err)
+	gotJSON, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+}
+
+func assertDecimals(t *testing.T, want, got *domain.Order) {
+	t.Helper()
+	assert.True(t, want.Payment.Amount.Equal(got.Payment.Amount), "amount %s, want %s", got.Payment.Amount, want.Payment.Amount)
+	assert.True(t, want.Payment.DeliveryCost.Equal(got.Payment.DeliveryCost), "delivery cost %s, want %s", got.Payment.DeliveryCost, want.Payment.DeliveryCost)
+	require.NotEmpty(t, got.Items)
+	assert.True(t, want.Items[0].Price.Equal(got.Items[0].Price), "price %s, want %s", got.Items[0].Price, want.Items[0].Price)
+	assert.True(t, want.Items[0].TotalPrice.Equal(got.Items[0].TotalPrice), "total price %s, want %s", got.Items[0].TotalPrice, want.Items[0].TotalPrice)
+}
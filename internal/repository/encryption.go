@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/envelope"
+)
+
+// deliveryFields lists the customer contact fields that are encrypted at rest.
+// The order is part of the ciphertext binding and must not change.
+func deliveryFields(d *domain.Delivery) []*string {
+	return []*string{&d.Name, &d.Phone, &d.Zip, &d.Address, &d.Email}
+}
+
+// SealDelivery encrypts the contact fields of d in place. With a nil keyring
+// the delivery is left as is and a zero Sealed is returned.
+func SealDelivery(k *envelope.Keyring, orderUID string, d *domain.Delivery) (envelope.Sealed, error) {
+	return k.Seal(orderUID, deliveryFields(d)...)
+}
+
+// OpenDelivery decrypts the contact fields of d in place.
+func OpenDelivery(k *envelope.Keyring, orderUID string, d *domain.Delivery, s envelope.Sealed) error {
+	return k.Open(s, orderUID, deliveryFields(d)...)
+}
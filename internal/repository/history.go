@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/pkg/mask"
+)
+
+// HistoryDocument returns the JSON object of the order as it is recorded in
+// the history. The history is not encrypted, so contact fields are masked like
+// for viewers.
+func HistoryDocument(order *domain.Order) (map[string]any, error) {
+	data, err := json.Marshal(mask.Masked(order))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode order: %w", err)
+	}
+	return DecodeDocument(data)
+}
+
+// DecodeDocument decodes a JSON object keeping numbers as json.Number, so that
+// amounts and ids survive a round trip unchanged.
+func DecodeDocument(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode order document: %w", err)
+	}
+	return doc, nil
+}
+
+// ReplayHistory rebuilds the order by applying the diffs of the events in
+// order. Without events the order is not found.
+func ReplayHistory(events []domain.HistoryEvent) (*domain.Order, error) {
+	if len(events) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	doc := make(map[string]any)
+	for _, event := range events {
+		domain.ApplyMergePatch(doc, event.Diff)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode order: %w", err)
+	}
+	var order domain.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, fmt.Errorf("failed to decode order: %w", err)
+	}
+	return &order, nil
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"slices"
+	"sync"
+)
+
+// Cache keeps orders as JSON with their ETags, like the Redis cache. Entries do
+// not expire. The secondary index entries of an order are removed on Delete.
+type Cache struct {
+	mu           sync.RWMutex
+	orders       map[string]cachedOrder
+	transactions map[string]string
+	trackNumbers map[string]map[string]struct{}
+}
+
+type cachedOrder struct {
+	data []byte
+	etag string
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		orders:       make(map[string]cachedOrder),
+		transactions: make(map[string]string),
+		trackNumbers: make(map[string]map[string]struct{}),
+	}
+}
+
+// Set stores the order with its ETag and indexes it by payment transaction and
+// track number.
+func (c *Cache) Set(_ context.Context, order *domain.Order) error {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.orders[order.OrderUID] = cachedOrder{data: data, etag: order.ETag()}
+	if order.Payment.Transaction != "" {
+		c.transactions[order.Payment.Transaction] = order.OrderUID
+	}
+	if order.TrackNumber != "" {
+		if c.trackNumbers[order.TrackNumber] == nil {
+			c.trackNumbers[order.TrackNumber] = make(map[string]struct{})
+		}
+		c.trackNumbers[order.TrackNumber][order.OrderUID] = struct{}{}
+	}
+	return nil
+}
+
+func (c *Cache) Get(_ context.Context, orderUID string) (*domain.Order, error) {
+	c.mu.RLock()
+	cached, ok := c.orders[orderUID]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+	return decode(cached)
+}
+
+// GetMany returns the cached orders keyed by order UID, orders that are not
+// cached are left out.
+func (c *Cache) GetMany(_ context.Context, orderUIDs []string) (map[string]domain.Order, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	orders := make(map[string]domain.Order, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		cached, ok := c.orders[orderUID]
+		if !ok {
+			continue
+		}
+		order, err := decode(cached)
+		if err != nil {
+			return nil, err
+		}
+		orders[orderUID] = *order
+	}
+	return orders, nil
+}
+
+// GetUIDByTransaction returns the UID of the order cached last with the
+// payment transaction.
+func (c *Cache) GetUIDByTransaction(_ context.Context, transaction string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	orderUID, ok := c.transactions[transaction]
+	if !ok {
+		return "", repository.ErrOrderNotFound
+	}
+	return orderUID, nil
+}
+
+// GetUIDsByTrackNumber returns the UIDs of the cached orders with the track number.
+func (c *Cache) GetUIDsByTrackNumber(_ context.Context, trackNumber string) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	orderUIDs := make([]string, 0, len(c.trackNumbers[trackNumber]))
+	for orderUID := range c.trackNumbers[trackNumber] {
+		orderUIDs = append(orderUIDs, orderUID)
+	}
+	if len(orderUIDs) == 0 {
+		return nil, repository.ErrOrderNotFound
+	}
+	slices.Sort(orderUIDs)
+	return orderUIDs, nil
+}
+
+func (c *Cache) GetETag(_ context.Context, orderUID string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cached, ok := c.orders[orderUID]
+	if !ok {
+		return "", repository.ErrOrderNotFound
+	}
+	return cached.etag, nil
+}
+
+// Delete evicts the orders from the cache together with their secondary index
+// entries.
+func (c *Cache) Delete(_ context.Context, orderUIDs ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, orderUID := range orderUIDs {
+		cached, ok := c.orders[orderUID]
+		if !ok {
+			continue
+		}
+		delete(c.orders, orderUID)
+		order, err := decode(cached)
+		if err != nil {
+			continue
+		}
+		if c.transactions[order.Payment.Transaction] == orderUID {
+			delete(c.transactions, order.Payment.Transaction)
+		}
+		if orderUIDs := c.trackNumbers[order.TrackNumber]; orderUIDs != nil {
+			delete(orderUIDs, orderUID)
+			if len(orderUIDs) == 0 {
+				delete(c.trackNumbers, order.TrackNumber)
+			}
+		}
+	}
+	return nil
+}
+
+func decode(cached cachedOrder) (*domain.Order, error) {
+	var order domain.Order
+	if err := json.Unmarshal(cached.data, &order); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+	return &order, nil
+}
@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"github.com/Killazius/L0/internal/repository/conformance"
+	"github.com/Killazius/L0/internal/service"
+	"testing"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+	t.Parallel()
+	conformance.TestRepository(t, func(*testing.T) service.OrderRepository {
+		return New()
+	})
+}
+
+func TestCache_Conformance(t *testing.T) {
+	t.Parallel()
+	conformance.TestCache(t, func(*testing.T) service.OrderCache {
+		return NewCache()
+	})
+}
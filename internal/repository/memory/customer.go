@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/shopspring/decimal"
+	"slices"
+	"strings"
+)
+
+// GetCustomer returns the customer with the address book.
+func (r *Repository) GetCustomer(_ context.Context, customerID string) (*domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	customer, ok := r.customers[customerID]
+	if !ok {
+		return nil, repository.ErrCustomerNotFound
+	}
+	c := *customer
+	c.Addresses = slices.Clone(customer.Addresses)
+	return &c, nil
+}
+
+// CustomerStats counts the orders of the customer that are not deleted and
+// sums their payments per currency and in the base currency.
+func (r *Repository) CustomerStats(_ context.Context, customerID string) (*domain.CustomerStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	totals := make(map[string]decimal.Decimal)
+	stats := domain.CustomerStats{LifetimeValue: []domain.Amount{}, LifetimeValueBase: &domain.BaseTotal{}}
+	for _, rec := range r.orders {
+		if rec.order.CustomerID != customerID || rec.order.DeletedAt != nil {
+			continue
+		}
+		stats.OrderCount++
+		payment := &rec.order.Payment
+		totals[payment.Currency] = totals[payment.Currency].Add(payment.Amount)
+		if payment.BaseAmount == nil {
+			stats.LifetimeValueBase.Unconverted++
+			continue
+		}
+		stats.LifetimeValueBase.Amount = stats.LifetimeValueBase.Amount.Add(*payment.BaseAmount)
+	}
+	for currency, total := range totals {
+		stats.LifetimeValue = append(stats.LifetimeValue, domain.Amount{Currency: currency, Amount: total})
+	}
+	slices.SortFunc(stats.LifetimeValue, func(a, b domain.Amount) int {
+		return strings.Compare(a.Currency, b.Currency)
+	})
+	return &stats, nil
+}
+
+// saveCustomer adds the customer of the order and its delivery address to the
+// address book.
+func (r *Repository) saveCustomer(order *domain.Order) {
+	customer, ok := r.customers[order.CustomerID]
+	if !ok {
+		customer = &domain.Customer{
+			CustomerID:   order.CustomerID,
+			FirstOrderAt: order.DateCreated,
+			LastOrderAt:  order.DateCreated,
+		}
+		r.customers[order.CustomerID] = customer
+	}
+	if order.DateCreated.Before(customer.FirstOrderAt) {
+		customer.FirstOrderAt = order.DateCreated
+	}
+	if order.DateCreated.After(customer.LastOrderAt) {
+		customer.LastOrderAt = order.DateCreated
+	}
+	customer.AddAddress(order.Delivery, order.DateCreated, order.DateCreated)
+}
+
+// eraseCustomer replaces the customer by the pseudonym that keeps the order
+// dates and has no address book.
+func (r *Repository) eraseCustomer(customerID, pseudonym string) {
+	customer, ok := r.customers[customerID]
+	if !ok {
+		return
+	}
+	delete(r.customers, customerID)
+	if _, ok := r.customers[pseudonym]; !ok {
+		r.customers[pseudonym] = &domain.Customer{
+			CustomerID:   pseudonym,
+			FirstOrderAt: customer.FirstOrderAt,
+			LastOrderAt:  customer.LastOrderAt,
+		}
+	}
+}
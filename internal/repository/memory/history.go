@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"maps"
+	"time"
+)
+
+// History returns the recorded changes of the order, oldest first. A non-zero
+// until skips the changes made after it.
+func (r *Repository) History(_ context.Context, orderUID string, until time.Time) ([]domain.HistoryEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.orders[orderUID]
+	if !ok {
+		return nil, nil
+	}
+	events := make([]domain.HistoryEvent, 0, len(rec.events))
+	for _, event := range rec.events {
+		if !until.IsZero() && event.OccurredAt.After(until) {
+			break
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// OrderAsOf rebuilds the order as it was at the given time from its history.
+// Contact fields of the delivery are masked, as they are recorded, and are
+// never recoverable.
+func (r *Repository) OrderAsOf(ctx context.Context, orderUID string, at time.Time) (*domain.Order, error) {
+	events, err := r.History(ctx, orderUID, at)
+	if err != nil {
+		return nil, err
+	}
+	return repository.ReplayHistory(events)
+}
+
+// appendEvent records the change of the order from before, nil for a new
+// order, to after. The last state of an order without events is recorded
+// first, as the PostgreSQL repository does for orders stored before the
+// history was kept.
+func (r *Repository) appendEvent(ctx context.Context, rec *record, eventType domain.EventType, before, after *domain.Order) error {
+	afterDoc, err := repository.HistoryDocument(after)
+	if err != nil {
+		return err
+	}
+	beforeDoc := make(map[string]any)
+	if before != nil {
+		if beforeDoc, err = repository.HistoryDocument(before); err != nil {
+			return err
+		}
+		if len(rec.events) == 0 {
+			r.addEvent(rec, before, domain.EventOrderSnapshot, domain.SystemActor, beforeDoc, before.UpdatedAt)
+		}
+	}
+	r.addEvent(rec, after, eventType, domain.ActorFromContext(ctx), domain.MergePatch(beforeDoc, afterDoc), time.Now().UTC())
+	return nil
+}
+
+func (r *Repository) addEvent(rec *record, order *domain.Order, eventType domain.EventType, actor string, diff map[string]any, occurredAt time.Time) {
+	r.eventID++
+	rec.events = append(rec.events, domain.HistoryEvent{
+		ID:         r.eventID,
+		Type:       eventType,
+		Version:    order.Version,
+		Actor:      actor,
+		Diff:       diff,
+		OccurredAt: occurredAt,
+	})
+}
+
+// scrubEvents replaces the erased customer id and delivery contact fields in
+// the recorded events of the order. The diffs are replaced, not changed, as
+// earlier readers may still hold them.
+func scrubEvents(rec *record, pseudonym string) {
+	for i, event := range rec.events {
+		diff := maps.Clone(event.Diff)
+		if _, ok := diff["customer_id"]; ok {
+			diff["customer_id"] = pseudonym
+		}
+		if delivery, ok := diff["delivery"].(map[string]any); ok {
+			delivery = maps.Clone(delivery)
+			for _, key := range []string{"name", "phone", "zip", "address", "email"} {
+				if _, ok := delivery[key]; ok {
+					delivery[key] = domain.ErasedValue
+				}
+			}
+			diff["delivery"] = delivery
+		}
+		rec.events[i].Diff = diff
+	}
+}
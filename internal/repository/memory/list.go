@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"slices"
+	"strings"
+)
+
+// List returns up to page.Limit orders matching the filter, newest first.
+func (r *Repository) List(_ context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error) {
+	return r.list(filter, page, true), nil
+}
+
+// ListHeaders is List without deliveries, payments and items.
+func (r *Repository) ListHeaders(_ context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error) {
+	return r.list(filter, page, false), nil
+}
+
+func (r *Repository) list(filter domain.OrderFilter, page domain.PageRequest, withParts bool) []domain.Order {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	orders := make([]domain.Order, 0)
+	for _, rec := range r.orders {
+		if rec.order.DeletedAt != nil || !matches(&rec.order, filter) {
+			continue
+		}
+		if page.After != nil && !before(&rec.order, *page.After) {
+			continue
+		}
+		order := rec.read()
+		if !withParts {
+			order.Delivery = domain.Delivery{}
+			order.Payment = domain.Payment{}
+			order.Items = nil
+		}
+		orders = append(orders, order)
+	}
+	slices.SortFunc(orders, func(a, b domain.Order) int {
+		if c := b.DateCreated.Compare(a.DateCreated); c != 0 {
+			return c
+		}
+		return strings.Compare(b.OrderUID, a.OrderUID)
+	})
+	if len(orders) > page.Limit {
+		orders = orders[:max(page.Limit, 0)]
+	}
+	return orders
+}
+
+// matches reports whether the order passes the filter.
+func matches(order *domain.Order, filter domain.OrderFilter) bool {
+	switch {
+	case filter.CustomerID != "" && order.CustomerID != filter.CustomerID,
+		filter.DeliveryService != "" && order.DeliveryService != filter.DeliveryService,
+		filter.TrackNumber != "" && order.TrackNumber != filter.TrackNumber,
+		!filter.CreatedFrom.IsZero() && order.DateCreated.Before(filter.CreatedFrom),
+		!filter.CreatedTo.IsZero() && !order.DateCreated.Before(filter.CreatedTo):
+		return false
+	}
+	if filter.ChrtID != 0 && !slices.ContainsFunc(order.Items, func(item domain.Item) bool { return item.ChrtID == filter.ChrtID }) {
+		return false
+	}
+	if filter.NmID != 0 && !slices.ContainsFunc(order.Items, func(item domain.Item) bool { return item.NmID == filter.NmID }) {
+		return false
+	}
+	return true
+}
+
+// before reports whether the order comes after the cursor in a list sorted by
+// date_created and order_uid, both descending.
+func before(order *domain.Order, cursor domain.Cursor) bool {
+	if c := order.DateCreated.Compare(cursor.DateCreated); c != 0 {
+		return c < 0
+	}
+	return order.OrderUID < cursor.OrderUID
+}
@@ -0,0 +1,221 @@
+// Package memory keeps orders in the memory of the process. Its Repository and
+// Cache follow the error semantics of the PostgreSQL and Redis ones, so the
+// service runs on them in tests and in the demo mode. Nothing survives a
+// restart.
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// record is a stored order with its history. seq orders the writes of the
+// payments, like the ids of the payments table.
+type record struct {
+	order     domain.Order
+	createdAt time.Time
+	seq       int64
+	events    []domain.HistoryEvent
+}
+
+type Repository struct {
+	mu        sync.RWMutex
+	orders    map[string]*record
+	customers map[string]*domain.Customer
+	// rates are the exchange rates by base currency and currency, by date.
+	rates     map[string]map[string][]domain.ExchangeRate
+	seq       int64
+	eventID   int64
+	erasureID int64
+}
+
+func New() *Repository {
+	return &Repository{
+		orders:    make(map[string]*record),
+		customers: make(map[string]*domain.Customer),
+		rates:     make(map[string]map[string][]domain.ExchangeRate),
+	}
+}
+
+// Create stores a new order. An order UID that is already stored, deleted or
+// not, is rejected with repository.ErrDuplicateOrder.
+func (r *Repository) Create(ctx context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.orders[order.OrderUID]; ok {
+		return repository.ErrDuplicateOrder
+	}
+	rec := r.insert(order)
+	return r.appendEvent(ctx, rec, domain.EventOrderCreated, nil, order)
+}
+
+// insert stores the order as a new record. An order without a version is
+// stored as version 1.
+func (r *Repository) insert(order *domain.Order) *record {
+	if order.Version == 0 {
+		order.Version = 1
+	}
+	r.seq++
+	rec := &record{order: clone(order), createdAt: time.Now().UTC(), seq: r.seq}
+	rec.order.DeletedAt = nil
+	r.orders[order.OrderUID] = rec
+	r.saveCustomer(order)
+	return rec
+}
+
+func (r *Repository) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
+	return r.GetParts(ctx, orderUID, domain.AllParts)
+}
+
+// GetParts returns the order with only the selected parts. Deleted orders are
+// not found.
+func (r *Repository) GetParts(_ context.Context, orderUID string, parts domain.Part) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.orders[orderUID]
+	if !ok || rec.order.DeletedAt != nil {
+		return nil, repository.ErrOrderNotFound
+	}
+	order := rec.read()
+	if !parts.Has(domain.PartDelivery) {
+		order.Delivery = domain.Delivery{}
+	}
+	if !parts.Has(domain.PartPayment) {
+		order.Payment = domain.Payment{}
+	}
+	if !parts.Has(domain.PartItems) {
+		order.Items = nil
+	}
+	return &order, nil
+}
+
+// GetMany returns the orders keyed by order UID, orders that do not exist are
+// left out.
+func (r *Repository) GetMany(_ context.Context, orderUIDs []string) (map[string]domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	found := make(map[string]domain.Order, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		if rec, ok := r.orders[orderUID]; ok && rec.order.DeletedAt == nil {
+			found[orderUID] = rec.read()
+		}
+	}
+	return found, nil
+}
+
+// GetByTransaction returns the order paid with the transaction. When several
+// orders share it, the one whose payment was written last is returned.
+func (r *Repository) GetByTransaction(_ context.Context, transaction string) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var latest *record
+	for _, rec := range r.orders {
+		if rec.order.DeletedAt != nil || rec.order.Payment.Transaction != transaction {
+			continue
+		}
+		if latest == nil || rec.seq > latest.seq {
+			latest = rec
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrOrderNotFound
+	}
+	order := latest.read()
+	return &order, nil
+}
+
+// GetAll returns every order that is not deleted, by order UID.
+func (r *Repository) GetAll(_ context.Context) ([]domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	orders := make([]domain.Order, 0, len(r.orders))
+	for _, rec := range r.orders {
+		if rec.order.DeletedAt == nil {
+			orders = append(orders, rec.read())
+		}
+	}
+	slices.SortFunc(orders, func(a, b domain.Order) int {
+		return strings.Compare(a.OrderUID, b.OrderUID)
+	})
+	return orders, nil
+}
+
+// GetDeliveries returns the deliveries of the orders keyed by order UID.
+func (r *Repository) GetDeliveries(_ context.Context, orderUIDs []string) (map[string]domain.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deliveries := make(map[string]domain.Delivery, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		if rec, ok := r.orders[orderUID]; ok {
+			deliveries[orderUID] = rec.order.Delivery
+		}
+	}
+	return deliveries, nil
+}
+
+// GetPayments returns the payments of the orders keyed by order UID.
+func (r *Repository) GetPayments(_ context.Context, orderUIDs []string) (map[string]domain.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	payments := make(map[string]domain.Payment, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		if rec, ok := r.orders[orderUID]; ok {
+			payments[orderUID] = rec.order.Payment
+		}
+	}
+	return payments, nil
+}
+
+// GetItems returns the items of the orders keyed by order UID, in insertion order.
+func (r *Repository) GetItems(_ context.Context, orderUIDs []string) (map[string][]domain.Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	items := make(map[string][]domain.Item, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		if rec, ok := r.orders[orderUID]; ok && len(rec.order.Items) > 0 {
+			items[orderUID] = slices.Clone(rec.order.Items)
+		}
+	}
+	return items, nil
+}
+
+// EnsurePartitions does nothing, memory has no partitions.
+func (r *Repository) EnsurePartitions(context.Context, time.Time, time.Time) (int, error) {
+	return 0, nil
+}
+
+// DetachPartitions does nothing, memory has no partitions.
+func (r *Repository) DetachPartitions(context.Context, time.Time) ([]string, error) {
+	return nil, nil
+}
+
+// read returns a copy of the stored order. An order stored without an update
+// time reports its creation time, as the orders table does.
+func (rec *record) read() domain.Order {
+	order := clone(&rec.order)
+	if order.UpdatedAt.IsZero() {
+		order.UpdatedAt = rec.createdAt
+	}
+	order.UpdatedAt = order.UpdatedAt.UTC()
+	return order
+}
+
+// clone returns a copy of the order that shares no memory with it.
+func clone(order *domain.Order) domain.Order {
+	c := *order
+	c.Items = slices.Clone(order.Items)
+	if order.DeletedAt != nil {
+		deletedAt := *order.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	if order.Payment.ExchangeRate != nil {
+		rate, amount := *order.Payment.ExchangeRate, *order.Payment.BaseAmount
+		c.Payment.ExchangeRate, c.Payment.BaseAmount = &rate, &amount
+	}
+	return c
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/test"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var (
+	_ service.OrderRepository = (*Repository)(nil)
+	_ service.OrderCache      = (*Cache)(nil)
+)
+
+func TestRepository_Upsert(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := New()
+
+	order := test.GenerateOrder()
+	order.Version = 2
+	created, err := repo.Upsert(ctx, order)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	stale := *order
+	stale.Version = 1
+	_, err = repo.Upsert(ctx, &stale)
+	assert.ErrorIs(t, err, repository.ErrStaleOrder)
+
+	newer := *order
+	newer.Version = 3
+	newer.TrackNumber = "NEWTRACK"
+	created, err = repo.Upsert(ctx, &newer)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	got, err := repo.Get(ctx, order.OrderUID)
+	require.NoError(t, err)
+	assert.Equal(t, "NEWTRACK", got.TrackNumber)
+	assert.EqualValues(t, 3, got.Version)
+}
+
+func TestRepository_Update(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := New()
+
+	order := test.GenerateOrder()
+	require.NoError(t, repo.Create(ctx, order))
+
+	update := *order
+	update.TrackNumber = "UPDATED"
+	require.NoError(t, repo.Update(ctx, &update, 1))
+	assert.EqualValues(t, 2, update.Version)
+
+	err := repo.Update(ctx, &update, 1)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+	missing := test.GenerateOrder()
+	err = repo.Update(ctx, missing, 1)
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
+
+func TestRepository_DeleteRestorePurge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := New()
+
+	order := test.GenerateOrder()
+	require.NoError(t, repo.Create(ctx, order))
+
+	deletedAt := time.Now().UTC().Add(-time.Hour)
+	deleted, err := repo.Delete(ctx, order.OrderUID, deletedAt)
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+	_, err = repo.Get(ctx, order.OrderUID)
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+	_, err = repo.Delete(ctx, order.OrderUID, deletedAt)
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+
+	restored, err := repo.Undelete(ctx, order.OrderUID, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	_, err = repo.Get(ctx, order.OrderUID)
+	require.NoError(t, err)
+
+	_, err = repo.Delete(ctx, order.OrderUID, deletedAt)
+	require.NoError(t, err)
+	purged, err := repo.Purge(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = repo.Undelete(ctx, order.OrderUID, time.Now())
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+	require.NoError(t, repo.Create(ctx, order))
+}
+
+func TestRepository_List(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := New()
+
+	base := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	var uids []string
+	for i := range 5 {
+		order := test.GenerateOrder()
+		order.DateCreated = base.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, repo.Create(ctx, order))
+		uids = append([]string{order.OrderUID}, uids...)
+	}
+
+	var listed []string
+	page := domain.PageRequest{Limit: 2}
+	for {
+		orders, err := repo.ListHeaders(ctx, domain.OrderFilter{}, page)
+		require.NoError(t, err)
+		if len(orders) == 0 {
+			break
+		}
+		for _, order := range orders {
+			assert.Empty(t, order.Items)
+			listed = append(listed, order.OrderUID)
+		}
+		last := orders[len(orders)-1]
+		page.After = &domain.Cursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}
+	}
+	assert.Equal(t, uids, listed)
+
+	orders, err := repo.List(ctx, domain.OrderFilter{CreatedFrom: base.Add(3 * time.Hour)}, domain.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, orders, 2)
+}
+
+func TestRepository_Search(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := New()
+
+	moscow := test.GenerateOrder()
+	moscow.Delivery.City = "Moscow"
+	moscow.Delivery.Name = "Ivan Petrov"
+	require.NoError(t, repo.Create(ctx, moscow))
+	kazan := test.GenerateOrder()
+	kazan.Delivery.City = "Kazan"
+	kazan.Delivery.Name = "Ivan Sidorov"
+	require.NoError(t, repo.Create(ctx, kazan))
+
+	hits, err := repo.Search(ctx, "ivan -kazan", domain.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, moscow.OrderUID, hits[0].Order.OrderUID)
+	assert.Contains(t, hits[0].Highlights[0], "<mark>Ivan</mark>")
+
+	hits, err = repo.Search(ctx, "ivan", domain.PageRequest{Limit: 10, Offset: 1})
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+}
+
+func TestRepository_OrderAsOf(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := New()
+
+	order := test.GenerateOrder()
+	require.NoError(t, repo.Create(ctx, order))
+	created := time.Now()
+	time.Sleep(time.Millisecond)
+
+	update := *order
+	update.TrackNumber = "UPDATED"
+	require.NoError(t, repo.Update(ctx, &update, 1))
+
+	events, err := repo.History(ctx, order.OrderUID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.EventOrderCreated, events[0].Type)
+
+	past, err := repo.OrderAsOf(ctx, order.OrderUID, created)
+	require.NoError(t, err)
+	assert.Equal(t, order.TrackNumber, past.TrackNumber)
+
+	_, err = repo.OrderAsOf(ctx, order.OrderUID, created.Add(-time.Hour))
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
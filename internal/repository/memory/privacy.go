@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"slices"
+	"strings"
+)
+
+// GetByCustomer returns the orders of the customer that are not deleted,
+// oldest first.
+func (r *Repository) GetByCustomer(_ context.Context, customerID string) ([]domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []domain.Order
+	for _, rec := range r.orders {
+		if rec.order.CustomerID == customerID && rec.order.DeletedAt == nil {
+			orders = append(orders, rec.read())
+		}
+	}
+	if len(orders) == 0 {
+		return nil, repository.ErrCustomerNotFound
+	}
+	slices.SortFunc(orders, func(a, b domain.Order) int {
+		return a.DateCreated.Compare(b.DateCreated)
+	})
+	return orders, nil
+}
+
+// EraseCustomer anonymises every order of the customer, deleted ones included:
+// customer_id is replaced by the erasure pseudonym and the contact fields of
+// deliveries by domain.ErasedValue, also in the recorded history. erasure.ID
+// and erasure.OrderUIDs are filled in. The customer is replaced by the
+// pseudonym without the address book.
+func (r *Repository) EraseCustomer(ctx context.Context, customerID string, erasure *domain.Erasure) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var erased []*record
+	for _, rec := range r.orders {
+		if rec.order.CustomerID == customerID {
+			erased = append(erased, rec)
+		}
+	}
+	if len(erased) == 0 {
+		return repository.ErrCustomerNotFound
+	}
+	slices.SortFunc(erased, func(a, b *record) int {
+		return strings.Compare(a.order.OrderUID, b.order.OrderUID)
+	})
+
+	ctx = domain.WithActor(ctx, erasure.RequestedBy)
+	orderUIDs := make([]string, 0, len(erased))
+	for _, rec := range erased {
+		before := rec.read()
+		after := clone(&before)
+		after.CustomerID = erasure.Pseudonym
+		after.UpdatedAt = erasure.ErasedAt
+		after.Version = before.Version + 1
+		for _, field := range []*string{&after.Delivery.Name, &after.Delivery.Phone, &after.Delivery.Zip, &after.Delivery.Address, &after.Delivery.Email} {
+			*field = domain.ErasedValue
+		}
+		rec.order = clone(&after)
+		if err := r.appendEvent(ctx, rec, domain.EventOrderErased, &before, &after); err != nil {
+			return err
+		}
+		scrubEvents(rec, erasure.Pseudonym)
+		orderUIDs = append(orderUIDs, rec.order.OrderUID)
+	}
+	r.eraseCustomer(customerID, erasure.Pseudonym)
+	r.erasureID++
+	erasure.ID = r.erasureID
+	erasure.OrderUIDs = orderUIDs
+	return nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/shopspring/decimal"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ExchangeRate returns the rate of the currency in baseCurrency effective on
+// the UTC day of at.
+func (r *Repository) ExchangeRate(_ context.Context, baseCurrency, currency string, at time.Time) (decimal.Decimal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rate, ok := effectiveRate(r.rates[baseCurrency][currency], at)
+	if !ok {
+		return decimal.Decimal{}, repository.ErrRateNotFound
+	}
+	return rate.Rate, nil
+}
+
+// ExchangeRates returns the rates in baseCurrency effective on the UTC day of
+// at, by currency.
+func (r *Repository) ExchangeRates(_ context.Context, baseCurrency string, at time.Time) ([]domain.ExchangeRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rates := []domain.ExchangeRate{}
+	for _, history := range r.rates[baseCurrency] {
+		if rate, ok := effectiveRate(history, at); ok {
+			rates = append(rates, rate)
+		}
+	}
+	slices.SortFunc(rates, func(a, b domain.ExchangeRate) int {
+		return strings.Compare(a.Currency, b.Currency)
+	})
+	return rates, nil
+}
+
+// SaveExchangeRates saves the rates in baseCurrency, replacing the rates of the
+// same currencies and days, then converts the stored payments that have no
+// amount in baseCurrency yet and now have a rate. The currency codes of the
+// rates are in upper case, those of payments are compared in upper case. It
+// returns the UIDs of the orders converted.
+func (r *Repository) SaveExchangeRates(_ context.Context, baseCurrency string, rates []domain.ExchangeRate) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byCurrency, ok := r.rates[baseCurrency]
+	if !ok {
+		byCurrency = make(map[string][]domain.ExchangeRate)
+		r.rates[baseCurrency] = byCurrency
+	}
+	for _, rate := range rates {
+		rate.Date = utcDay(rate.Date)
+		history := byCurrency[rate.Currency]
+		i, found := slices.BinarySearchFunc(history, rate.Date, compareRateDate)
+		if found {
+			history[i] = rate
+			continue
+		}
+		byCurrency[rate.Currency] = slices.Insert(history, i, rate)
+	}
+
+	var converted []string
+	for uid, rec := range r.orders {
+		payment := &rec.order.Payment
+		if payment.BaseCurrency == baseCurrency {
+			continue
+		}
+		rate := decimal.NewFromInt(1)
+		if currency := strings.ToUpper(payment.Currency); currency != baseCurrency {
+			effective, found := effectiveRate(byCurrency[currency], rec.order.DateCreated)
+			if !found {
+				continue
+			}
+			rate = effective.Rate
+		}
+		payment.Convert(baseCurrency, rate)
+		converted = append(converted, uid)
+	}
+	slices.Sort(converted)
+	return converted, nil
+}
+
+// effectiveRate returns the rate of the history, sorted by date, effective on
+// the UTC day of at.
+func effectiveRate(history []domain.ExchangeRate, at time.Time) (domain.ExchangeRate, bool) {
+	i, found := slices.BinarySearchFunc(history, utcDay(at), compareRateDate)
+	if found {
+		return history[i], true
+	}
+	if i == 0 {
+		return domain.ExchangeRate{}, false
+	}
+	return history[i-1], true
+}
+
+func compareRateDate(rate domain.ExchangeRate, day time.Time) int {
+	return rate.Date.Compare(day)
+}
+
+// utcDay returns the UTC midnight of the day of t.
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/domain"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Search returns orders whose delivery or items match the web search query,
+// best matches first, deleted orders left out. It understands a subset of the
+// PostgreSQL web search syntax: every word must match and a word prefixed with
+// "-" must not. Words are compared whole and case-insensitively.
+func (r *Repository) Search(_ context.Context, query string, page domain.PageRequest) ([]domain.SearchHit, error) {
+	include, exclude := parseQuery(query)
+	if len(include) == 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var hits []domain.SearchHit
+	for _, rec := range r.orders {
+		if rec.order.DeletedAt != nil {
+			continue
+		}
+		texts := []string{rec.order.Delivery.City + " " + rec.order.Delivery.Name + " " + rec.order.Delivery.Address}
+		for _, item := range rec.order.Items {
+			texts = append(texts, item.Brand+" "+item.Name)
+		}
+		hit := domain.SearchHit{Highlights: []string{}}
+		for _, text := range texts {
+			rank, ok := rankText(text, include, exclude)
+			if !ok {
+				continue
+			}
+			hit.Rank = max(hit.Rank, rank)
+			hit.Highlights = append(hit.Highlights, highlight(text, include))
+		}
+		if len(hit.Highlights) > 0 {
+			hit.Order = rec.read()
+			hits = append(hits, hit)
+		}
+	}
+	slices.SortFunc(hits, func(a, b domain.SearchHit) int {
+		switch {
+		case a.Rank > b.Rank:
+			return -1
+		case a.Rank < b.Rank:
+			return 1
+		}
+		return strings.Compare(a.Order.OrderUID, b.Order.OrderUID)
+	})
+
+	if page.Offset >= len(hits) {
+		return nil, nil
+	}
+	hits = hits[page.Offset:]
+	if len(hits) > page.Limit {
+		hits = hits[:max(page.Limit, 0)]
+	}
+	return hits, nil
+}
+
+// parseQuery returns the lowercase words the text must and must not contain.
+func parseQuery(query string) (include, exclude []string) {
+	for _, field := range strings.Fields(query) {
+		negated := strings.HasPrefix(field, "-")
+		for _, word := range words(field) {
+			if negated {
+				exclude = append(exclude, word)
+			} else {
+				include = append(include, word)
+			}
+		}
+	}
+	return include, exclude
+}
+
+// rankText reports whether the text matches and ranks it by the share of its
+// words that were searched for.
+func rankText(text string, include, exclude []string) (float32, bool) {
+	textWords := words(text)
+	for _, word := range exclude {
+		if slices.Contains(textWords, word) {
+			return 0, false
+		}
+	}
+	for _, word := range include {
+		if !slices.Contains(textWords, word) {
+			return 0, false
+		}
+	}
+	return float32(len(include)) / float32(len(textWords)), true
+}
+
+// highlight marks the searched words of the text like ts_headline does.
+func highlight(text string, include []string) string {
+	var b strings.Builder
+	start := -1
+	flush := func(end int) {
+		word := text[start:end]
+		if slices.Contains(include, strings.ToLower(word)) {
+			b.WriteString("<mark>" + word + "</mark>")
+		} else {
+			b.WriteString(word)
+		}
+		start = -1
+	}
+	for i, c := range text {
+		if isWordRune(c) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			flush(i)
+		}
+		b.WriteRune(c)
+	}
+	if start >= 0 {
+		flush(len(text))
+	}
+	return b.String()
+}
+
+// words splits the text into lowercase words.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !isWordRune(c)
+	})
+}
+
+func isWordRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+// statsKey is a row of the daily statistics.
+type statsKey struct {
+	day      time.Time
+	group    string
+	currency string
+}
+
+// RefreshStats does nothing, the statistics of memory are always up to date.
+func (r *Repository) RefreshStats(context.Context) (bool, error) {
+	return true, nil
+}
+
+// DailyStats returns the statistics of the days of the query, by day, group
+// and currency.
+func (r *Repository) DailyStats(_ context.Context, query domain.StatsQuery) ([]domain.DailyStats, error) {
+	if !query.GroupBy.Valid() {
+		return nil, fmt.Errorf("unknown stats dimension %q", query.GroupBy)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rows := make(map[statsKey]*domain.DailyStats)
+	for _, rec := range r.orders {
+		day, ok := statsDay(&rec.order, query.From, query.To)
+		if !ok {
+			continue
+		}
+		key := statsKey{day: day, group: statsGroup(&rec.order, query.GroupBy), currency: rec.order.Payment.Currency}
+		row, ok := rows[key]
+		if !ok {
+			row = &domain.DailyStats{Day: key.day, Group: key.group, Currency: key.currency}
+			rows[key] = row
+		}
+		row.Orders++
+		row.Amount = row.Amount.Add(rec.order.Payment.Amount)
+		row.DeliveryCost = row.DeliveryCost.Add(rec.order.Payment.DeliveryCost)
+		row.Items += len(rec.order.Items)
+		payment := &rec.order.Payment
+		if payment.ExchangeRate == nil {
+			row.Unconverted++
+			continue
+		}
+		row.BaseAmount = row.BaseAmount.Add(*payment.BaseAmount)
+		row.BaseDeliveryCost = row.BaseDeliveryCost.Add(payment.DeliveryCost.Mul(*payment.ExchangeRate).Round(2))
+	}
+
+	stats := make([]domain.DailyStats, 0, len(rows))
+	for _, row := range rows {
+		row.SetAvgBasketSize()
+		stats = append(stats, *row)
+	}
+	slices.SortFunc(stats, func(a, b domain.DailyStats) int {
+		return cmp.Or(a.Day.Compare(b.Day), strings.Compare(a.Group, b.Group), strings.Compare(a.Currency, b.Currency))
+	})
+	return stats, nil
+}
+
+// BrandStats returns the sales of every brand on the days from from to to, by
+// day.
+func (r *Repository) BrandStats(_ context.Context, from, to time.Time) ([]domain.DailyBrands, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	byDay := make(map[time.Time]*domain.DailyBrands)
+	for _, rec := range r.orders {
+		day, ok := statsDay(&rec.order, from, to)
+		if !ok {
+			continue
+		}
+		payment := &rec.order.Payment
+		brands := make(map[string]*domain.BrandSales)
+		for _, item := range rec.order.Items {
+			b, ok := brands[item.Brand]
+			if !ok {
+				b = &domain.BrandSales{Brand: item.Brand, Currency: payment.Currency, Orders: 1}
+				if payment.ExchangeRate == nil {
+					b.Unconverted = 1
+				}
+				brands[item.Brand] = b
+			}
+			b.Items++
+			b.Revenue = b.Revenue.Add(item.TotalPrice)
+			if payment.ExchangeRate != nil {
+				b.BaseRevenue = b.BaseRevenue.Add(item.TotalPrice.Mul(*payment.ExchangeRate).Round(2))
+			}
+		}
+		if len(brands) == 0 {
+			continue
+		}
+		d, ok := byDay[day]
+		if !ok {
+			d = &domain.DailyBrands{Day: day}
+			byDay[day] = d
+		}
+		for _, b := range brands {
+			d.AddBrand(*b)
+		}
+	}
+
+	days := make([]domain.DailyBrands, 0, len(byDay))
+	for _, d := range byDay {
+		slices.SortFunc(d.Brands, func(a, b domain.BrandStats) int {
+			return strings.Compare(a.Brand, b.Brand)
+		})
+		days = append(days, *d)
+	}
+	slices.SortFunc(days, func(a, b domain.DailyBrands) int {
+		return a.Day.Compare(b.Day)
+	})
+	return days, nil
+}
+
+// statsDay returns the UTC day the order was created on and whether the order
+// is counted in the statistics of the days from from to to.
+func statsDay(order *domain.Order, from, to time.Time) (time.Time, bool) {
+	if order.DeletedAt != nil {
+		return time.Time{}, false
+	}
+	day := utcDay(order.DateCreated)
+	return day, !day.Before(from) && day.Before(to)
+}
+
+func statsGroup(order *domain.Order, dimension domain.StatsDimension) string {
+	switch dimension {
+	case domain.DimensionProvider:
+		return order.Payment.Provider
+	case domain.DimensionBank:
+		return order.Payment.Bank
+	case domain.DimensionDeliveryService:
+		return order.DeliveryService
+	case domain.DimensionCurrency:
+		return order.Payment.Currency
+	case domain.DimensionLocale:
+		return order.Locale
+	}
+	return ""
+}
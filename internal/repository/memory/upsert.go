@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"slices"
+	"time"
+)
+
+// Upsert stores the order if it is new or newer than the stored one, see
+// domain.Order.IsNewerThan, and reports whether it was created. An order that
+// is not newer, or is deleted, is rejected with repository.ErrStaleOrder.
+func (r *Repository) Upsert(ctx context.Context, order *domain.Order) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.orders[order.OrderUID]
+	if !ok {
+		if order.UpdatedAt.IsZero() {
+			order.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		}
+		rec = r.insert(order)
+		return true, r.appendEvent(ctx, rec, domain.EventOrderCreated, nil, order)
+	}
+	stored := rec.read()
+	switch {
+	case stored.DeletedAt != nil:
+		return false, fmt.Errorf("%w: order is deleted", repository.ErrStaleOrder)
+	case !order.IsNewerThan(stored.Version, stored.UpdatedAt):
+		return false, fmt.Errorf("%w: version %d, stored %d", repository.ErrStaleOrder, order.Version, stored.Version)
+	}
+	if order.Version == 0 {
+		order.Version = stored.Version + 1
+	}
+	if order.UpdatedAt.IsZero() {
+		order.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	}
+	return false, r.replace(ctx, rec, order)
+}
+
+// Update replaces the order if its stored version is still expectedVersion and
+// bumps the version. It fails with repository.ErrVersionConflict if the order
+// was changed in between.
+func (r *Repository) Update(ctx context.Context, order *domain.Order, expectedVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.orders[order.OrderUID]
+	switch {
+	case !ok || rec.order.DeletedAt != nil:
+		return repository.ErrOrderNotFound
+	case rec.order.Version != expectedVersion:
+		return fmt.Errorf("%w: expected version %d, stored %d", repository.ErrVersionConflict, expectedVersion, rec.order.Version)
+	}
+	order.Version = rec.order.Version + 1
+	return r.replace(ctx, rec, order)
+}
+
+// replace overwrites the stored order, keeping whether it is deleted, and
+// records the change.
+func (r *Repository) replace(ctx context.Context, rec *record, order *domain.Order) error {
+	before := rec.read()
+	deletedAt := rec.order.DeletedAt
+	rec.order = clone(order)
+	rec.order.DeletedAt = deletedAt
+	r.seq++
+	rec.seq = r.seq
+	r.saveCustomer(order)
+	return r.appendEvent(ctx, rec, domain.EventOrderUpdated, &before, order)
+}
+
+// Delete marks the order deleted at the given time and returns it.
+func (r *Repository) Delete(ctx context.Context, orderUID string, deletedAt time.Time) (*domain.Order, error) {
+	return r.setDeleted(ctx, orderUID, true, deletedAt, domain.EventOrderDeleted)
+}
+
+// Undelete restores a deleted order and returns it.
+func (r *Repository) Undelete(ctx context.Context, orderUID string, restoredAt time.Time) (*domain.Order, error) {
+	return r.setDeleted(ctx, orderUID, false, restoredAt, domain.EventOrderRestored)
+}
+
+// setDeleted deletes or restores the order at the given time, bumps its
+// version and records the change. An order that is already in the requested
+// state is not found.
+func (r *Repository) setDeleted(ctx context.Context, orderUID string, deleted bool, at time.Time, eventType domain.EventType) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.orders[orderUID]
+	if !ok || (rec.order.DeletedAt != nil) == deleted {
+		return nil, repository.ErrOrderNotFound
+	}
+	before := rec.read()
+	after := clone(&before)
+	after.DeletedAt = nil
+	if deleted {
+		after.DeletedAt = &at
+	}
+	after.UpdatedAt = at
+	after.Version = before.Version + 1
+	rec.order = clone(&after)
+	if err := r.appendEvent(ctx, rec, eventType, &before, &after); err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// Purge removes up to limit orders deleted before the given time, oldest
+// deletion first, with their history and returns how many it removed.
+func (r *Repository) Purge(_ context.Context, deletedBefore time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged []*record
+	for _, rec := range r.orders {
+		if rec.order.DeletedAt != nil && rec.order.DeletedAt.Before(deletedBefore) {
+			purged = append(purged, rec)
+		}
+	}
+	slices.SortFunc(purged, func(a, b *record) int {
+		return a.order.DeletedAt.Compare(*b.order.DeletedAt)
+	})
+	if len(purged) > limit {
+		purged = purged[:limit]
+	}
+	for _, rec := range purged {
+		delete(r.orders, rec.order.OrderUID)
+	}
+	return len(purged), nil
+}
@@ -0,0 +1,30 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/repository/conformance"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"os"
+	"testing"
+)
+
+// TestRepository_Conformance runs against the database in TEST_POSTGRES_DSN
+// after migrating it, and is skipped without one. The suite only adds orders,
+// a database of a running service may be used.
+func TestRepository_Conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	conformance.TestRepository(t, func(t *testing.T) service.OrderRepository {
+		pool, err := pgxpool.New(context.Background(), dsn)
+		require.NoError(t, err)
+		t.Cleanup(pool.Close)
+
+		require.NoError(t, Migrate(context.Background(), zap.NewNop().Sugar(), pool))
+		return New(pool, nil)
+	})
+}
@@ -0,0 +1,233 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/envelope"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// querier is a pool or a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// storedAddress is an address book entry with its row id.
+type storedAddress struct {
+	id      int64
+	address domain.Address
+}
+
+// GetCustomer returns the customer with the address book. The contact details
+// are the ones of the address used last.
+func (r *Repository) GetCustomer(ctx context.Context, customerID string) (*domain.Customer, error) {
+	db := r.reader()
+	customer := domain.Customer{CustomerID: customerID}
+	err := db.QueryRow(ctx, "SELECT first_order_at, last_order_at FROM customers WHERE customer_id = $1", customerID).
+		Scan(&customer.FirstOrderAt, &customer.LastOrderAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	addresses, err := r.readAddresses(ctx, db, customerID)
+	if err != nil {
+		return nil, err
+	}
+	// Encrypted copies of one address have their own data keys and are only
+	// merged once decrypted.
+	for _, stored := range addresses {
+		customer.AddAddress(stored.address.Delivery, stored.address.FirstUsedAt, stored.address.LastUsedAt)
+	}
+	return &customer, nil
+}
+
+// CustomerStats counts the orders of the customer that are not deleted and
+// sums their payments per currency and in the base currency.
+func (r *Repository) CustomerStats(ctx context.Context, customerID string) (*domain.CustomerStats, error) {
+	rows, err := r.reader().Query(ctx, `
+		SELECT p.currency, count(*), sum(p.amount),
+			coalesce(sum(p.base_amount), 0), count(*) FILTER (WHERE p.base_amount IS NULL)
+		FROM orders o
+		JOIN payments p ON p.order_uid = o.order_uid
+		WHERE o.customer_id = $1 AND o.deleted_at IS NULL
+		GROUP BY p.currency
+		ORDER BY p.currency
+	`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := domain.CustomerStats{LifetimeValue: []domain.Amount{}, LifetimeValueBase: &domain.BaseTotal{}}
+	for rows.Next() {
+		var (
+			amount      domain.Amount
+			orders      int
+			base        decimal.Decimal
+			unconverted int
+		)
+		if err := rows.Scan(&amount.Currency, &orders, &amount.Amount, &base, &unconverted); err != nil {
+			return nil, fmt.Errorf("failed to scan customer stats: %w", err)
+		}
+		stats.OrderCount += orders
+		stats.LifetimeValueBase.Amount = stats.LifetimeValueBase.Amount.Add(base)
+		stats.LifetimeValueBase.Unconverted += unconverted
+		stats.LifetimeValue = append(stats.LifetimeValue, amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating customer stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// saveCustomer adds the customer of the order and its delivery address to the
+// address book. The upsert locks the customer, so the orders of one customer
+// update the address book one after another.
+func (r *Repository) saveCustomer(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO customers (customer_id, first_order_at, last_order_at)
+		VALUES ($1, $2, $2)
+		ON CONFLICT (customer_id) DO UPDATE SET
+			first_order_at = LEAST(customers.first_order_at, EXCLUDED.first_order_at),
+			last_order_at = GREATEST(customers.last_order_at, EXCLUDED.last_order_at)
+	`, order.CustomerID, order.DateCreated)
+	if err != nil {
+		return fmt.Errorf("failed to save customer: %w", err)
+	}
+
+	addresses, err := r.readAddresses(ctx, tx, order.CustomerID)
+	if err != nil {
+		return err
+	}
+	for _, stored := range addresses {
+		if stored.address.Delivery != order.Delivery {
+			continue
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE customer_addresses
+			SET first_used_at = LEAST(first_used_at, $2), last_used_at = GREATEST(last_used_at, $2)
+			WHERE id = $1
+		`, stored.id, order.DateCreated)
+		if err != nil {
+			return fmt.Errorf("failed to update customer address: %w", err)
+		}
+		return nil
+	}
+
+	delivery := order.Delivery
+	sealed, err := repository.SealDelivery(r.keyring, order.OrderUID, &delivery)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt customer address: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO customer_addresses (
+			customer_id, order_uid, name, phone, zip, city, address, region, email,
+			key_id, wrapped_key, first_used_at, last_used_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+	`,
+		order.CustomerID,
+		order.OrderUID,
+		delivery.Name,
+		delivery.Phone,
+		delivery.Zip,
+		delivery.City,
+		delivery.Address,
+		delivery.Region,
+		delivery.Email,
+		nullableKeyID(sealed),
+		sealed.WrappedKey,
+		order.DateCreated,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert customer address: %w", err)
+	}
+	return nil
+}
+
+// readAddresses returns the decrypted address book entries of the customer,
+// most recently used first.
+func (r *Repository) readAddresses(ctx context.Context, db querier, customerID string) ([]storedAddress, error) {
+	rows, err := db.Query(ctx, `
+		SELECT id, order_uid, name, phone, zip, city, address, region, email,
+			key_id, wrapped_key, first_used_at, last_used_at
+		FROM customer_addresses
+		WHERE customer_id = $1
+		ORDER BY last_used_at DESC, id DESC
+	`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var addresses []storedAddress
+	for rows.Next() {
+		var (
+			stored   storedAddress
+			orderUID string
+			keyID    *string
+			sealed   envelope.Sealed
+		)
+		delivery := &stored.address.Delivery
+		err := rows.Scan(
+			&stored.id,
+			&orderUID,
+			&delivery.Name,
+			&delivery.Phone,
+			&delivery.Zip,
+			&delivery.City,
+			&delivery.Address,
+			&delivery.Region,
+			&delivery.Email,
+			&keyID,
+			&sealed.WrappedKey,
+			&stored.address.FirstUsedAt,
+			&stored.address.LastUsedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan customer address: %w", err)
+		}
+		if keyID != nil {
+			sealed.KeyID = *keyID
+		}
+		if err = repository.OpenDelivery(r.keyring, orderUID, delivery, sealed); err != nil {
+			return nil, fmt.Errorf("failed to decrypt customer address: %w", err)
+		}
+		addresses = append(addresses, stored)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating customer addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// eraseCustomer replaces the customer by the pseudonym that keeps the order
+// dates and has no address book.
+func eraseCustomer(ctx context.Context, tx pgx.Tx, customerID, pseudonym string) error {
+	var firstOrderAt, lastOrderAt time.Time
+	err := tx.QueryRow(ctx, `
+		DELETE FROM customers WHERE customer_id = $1
+		RETURNING first_order_at, last_order_at
+	`, customerID).Scan(&firstOrderAt, &lastOrderAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete customer: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO customers (customer_id, first_order_at, last_order_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (customer_id) DO NOTHING
+	`, pseudonym, firstOrderAt, lastOrderAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert erased customer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// Delete marks the order deleted at the given time and returns it. Deleted
+// orders are left out of every read until they are restored or purged.
+func (r *Repository) Delete(ctx context.Context, orderUID string, deletedAt time.Time) (*domain.Order, error) {
+	return r.setDeleted(ctx, orderUID, true, deletedAt, domain.EventOrderDeleted)
+}
+
+// Undelete restores a deleted order and returns it.
+func (r *Repository) Undelete(ctx context.Context, orderUID string, restoredAt time.Time) (*domain.Order, error) {
+	return r.setDeleted(ctx, orderUID, false, restoredAt, domain.EventOrderRestored)
+}
+
+// setDeleted deletes or restores the order at the given time, bumps its
+// version and records the change. An order that is already in the requested
+// state is not found.
+func (r *Repository) setDeleted(ctx context.Context, orderUID string, deleted bool, at time.Time, eventType domain.EventType) (*domain.Order, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	var isDeleted bool
+	err = tx.QueryRow(ctx, "SELECT deleted_at IS NOT NULL FROM orders WHERE order_uid = $1 FOR UPDATE", orderUID).Scan(&isDeleted)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, repository.ErrOrderNotFound
+	case err != nil:
+		return nil, fmt.Errorf("failed to lock order: %w", err)
+	case isDeleted == deleted:
+		return nil, repository.ErrOrderNotFound
+	}
+
+	before, err := r.readOrder(ctx, tx, orderUID, domain.AllParts)
+	if err != nil {
+		return nil, err
+	}
+	after := *before
+	after.DeletedAt = nil
+	if deleted {
+		after.DeletedAt = &at
+	}
+	after.UpdatedAt = at
+	after.Version = before.Version + 1
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET deleted_at = $2, updated_at = $3, version = $4
+		WHERE order_uid = $1
+	`, orderUID, after.DeletedAt, after.UpdatedAt, after.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+	if err = r.appendEvent(ctx, tx, eventType, before, &after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.wrote(orderUID)
+	return &after, nil
+}
+
+// Purge hard-deletes up to limit orders deleted before the given time, with
+// their delivery, payment, items and history, and returns how many it removed.
+// Their order UIDs are released and can be stored again.
+func (r *Repository) Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	var purged int
+	err := r.DB.QueryRow(ctx, `
+		WITH purged AS (
+			DELETE FROM orders
+			WHERE order_uid IN (
+				SELECT order_uid
+				FROM orders
+				WHERE deleted_at < $1
+				ORDER BY deleted_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING order_uid
+		),
+		purged_deliveries AS (
+			DELETE FROM deliveries
+			WHERE order_uid IN (SELECT order_uid FROM purged)
+		),
+		purged_payments AS (
+			DELETE FROM payments
+			WHERE order_uid IN (SELECT order_uid FROM purged)
+		),
+		purged_items AS (
+			DELETE FROM items
+			WHERE order_uid IN (SELECT order_uid FROM purged)
+		),
+		purged_history AS (
+			DELETE FROM order_events
+			WHERE order_uid IN (SELECT order_uid FROM purged)
+		),
+		purged_uids AS (
+			DELETE FROM order_uids
+			WHERE order_uid IN (SELECT order_uid FROM purged)
+		)
+		SELECT count(*) FROM purged
+	`, deletedBefore, limit).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge orders: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,128 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/envelope"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+func nullableKeyID(s envelope.Sealed) *string {
+	if s.IsZero() {
+		return nil
+	}
+	return &s.KeyID
+}
+
+// ReencryptDeliveries brings up to batchSize deliveries, then the address
+// book entries of the customers, to the active master key: plain text rows are
+// encrypted and data keys wrapped by a retired master key are rewrapped. It
+// returns the number of updated rows; zero means nothing is left. Rows are
+// locked with SKIP LOCKED, so several runs can work in parallel.
+func (r *Repository) ReencryptDeliveries(ctx context.Context, batchSize int) (int, error) {
+	if !r.keyring.Enabled() {
+		return 0, errors.New("encryption is not configured")
+	}
+	n, err := r.reencrypt(ctx, "deliveries", batchSize)
+	if err != nil || n > 0 {
+		return n, err
+	}
+	return r.reencrypt(ctx, "customer_addresses", batchSize)
+}
+
+// reencrypt brings up to batchSize rows of the table to the active master key.
+// The table holds the contact fields of a delivery bound to its order_uid.
+func (r *Repository) reencrypt(ctx context.Context, table string, batchSize int) (int, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, order_uid, name, phone, zip, address, email, key_id, wrapped_key
+		FROM `+table+`
+		WHERE key_id IS DISTINCT FROM $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, r.keyring.ActiveKeyID(), batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+
+	type pending struct {
+		id       int64
+		orderUID string
+		delivery domain.Delivery
+		sealed   envelope.Sealed
+	}
+	var batch []pending
+	for rows.Next() {
+		var p pending
+		var keyID *string
+		if err := rows.Scan(
+			&p.id,
+			&p.orderUID,
+			&p.delivery.Name,
+			&p.delivery.Phone,
+			&p.delivery.Zip,
+			&p.delivery.Address,
+			&p.delivery.Email,
+			&keyID,
+			&p.sealed.WrappedKey,
+		); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		if keyID != nil {
+			p.sealed.KeyID = *keyID
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating %s: %w", table, err)
+	}
+
+	for _, p := range batch {
+		if p.sealed.IsZero() {
+			sealed, err := repository.SealDelivery(r.keyring, p.orderUID, &p.delivery)
+			if err != nil {
+				return 0, fmt.Errorf("failed to encrypt delivery %s: %w", p.orderUID, err)
+			}
+			_, err = tx.Exec(ctx, `
+				UPDATE `+table+`
+				SET name = $2, phone = $3, zip = $4, address = $5, email = $6, key_id = $7, wrapped_key = $8
+				WHERE id = $1
+			`, p.id, p.delivery.Name, p.delivery.Phone, p.delivery.Zip, p.delivery.Address, p.delivery.Email,
+				sealed.KeyID, sealed.WrappedKey)
+			if err != nil {
+				return 0, fmt.Errorf("failed to update delivery %s: %w", p.orderUID, err)
+			}
+			continue
+		}
+		rewrapped, err := r.keyring.Rewrap(p.sealed)
+		if err != nil {
+			return 0, fmt.Errorf("failed to rewrap data key of %s: %w", p.orderUID, err)
+		}
+		_, err = tx.Exec(ctx, "UPDATE "+table+" SET key_id = $2, wrapped_key = $3 WHERE id = $1",
+			p.id, rewrapped.KeyID, rewrapped.WrappedKey)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update delivery %s: %w", p.orderUID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,143 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// History returns the recorded changes of the order, oldest first. A non-zero
+// until skips the changes made after it.
+func (r *Repository) History(ctx context.Context, orderUID string, until time.Time) ([]domain.HistoryEvent, error) {
+	var bound *time.Time
+	if !until.IsZero() {
+		bound = &until
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, type, version, actor, diff, occurred_at
+		FROM order_events
+		WHERE order_uid = $1 AND ($2::timestamptz IS NULL OR occurred_at <= $2)
+		ORDER BY id
+	`, orderUID, bound)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order history: %w", err)
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.HistoryEvent, error) {
+		var (
+			event domain.HistoryEvent
+			diff  []byte
+		)
+		if err := row.Scan(&event.ID, &event.Type, &event.Version, &event.Actor, &diff, &event.OccurredAt); err != nil {
+			return event, err
+		}
+		event.Diff, err = repository.DecodeDocument(diff)
+		return event, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order history: %w", err)
+	}
+	return events, nil
+}
+
+// OrderAsOf rebuilds the order as it was at the given time from its history.
+// History records the delivery contact fields masked, so they are never
+// recoverable: the rebuilt order carries them masked whatever the caller's role.
+func (r *Repository) OrderAsOf(ctx context.Context, orderUID string, at time.Time) (*domain.Order, error) {
+	events, err := r.History(ctx, orderUID, at)
+	if err != nil {
+		return nil, err
+	}
+	return repository.ReplayHistory(events)
+}
+
+// appendEvent records the change of the order from before, nil for a new
+// order, to after. It runs in the transaction of the change, so a change is
+// never stored without its event. The actor is read from the context.
+func (r *Repository) appendEvent(ctx context.Context, tx pgx.Tx, eventType domain.EventType, before, after *domain.Order) error {
+	afterDoc, err := repository.HistoryDocument(after)
+	if err != nil {
+		return err
+	}
+	beforeDoc := make(map[string]any)
+	if before != nil {
+		if beforeDoc, err = repository.HistoryDocument(before); err != nil {
+			return err
+		}
+		// An order stored before the history was kept has no events, its
+		// last state is recorded first so that the diffs can be replayed.
+		var recorded bool
+		err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM order_events WHERE order_uid = $1)", before.OrderUID).Scan(&recorded)
+		if err != nil {
+			return fmt.Errorf("failed to check order history: %w", err)
+		}
+		if !recorded {
+			err = insertEvent(ctx, tx, before, domain.EventOrderSnapshot, domain.SystemActor, beforeDoc, before.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return insertEvent(ctx, tx, after, eventType, domain.ActorFromContext(ctx), domain.MergePatch(beforeDoc, afterDoc), time.Time{})
+}
+
+// recordErasure appends an erasure event to the history of every erased order
+// and removes the erased data from the earlier events: the history is the one
+// place that would otherwise keep it.
+func (r *Repository) recordErasure(ctx context.Context, tx pgx.Tx, before []*domain.Order, erasure *domain.Erasure) error {
+	ctx = domain.WithActor(ctx, erasure.RequestedBy)
+	orderUIDs := make([]string, 0, len(before))
+	for _, order := range before {
+		after, err := r.readOrder(ctx, tx, order.OrderUID, domain.AllParts)
+		if err != nil {
+			return fmt.Errorf("failed to get order %s: %w", order.OrderUID, err)
+		}
+		if err := r.appendEvent(ctx, tx, domain.EventOrderErased, order, after); err != nil {
+			return err
+		}
+		orderUIDs = append(orderUIDs, order.OrderUID)
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE order_events
+		SET diff = diff
+			|| CASE WHEN diff ? 'customer_id'
+				THEN jsonb_build_object('customer_id', $2::text)
+				ELSE '{}'::jsonb END
+			|| CASE WHEN jsonb_typeof(diff->'delivery') = 'object'
+				THEN jsonb_build_object('delivery', diff->'delivery' || (
+					SELECT COALESCE(jsonb_object_agg(key, $3::text), '{}'::jsonb)
+					FROM jsonb_object_keys(diff->'delivery') AS key
+					WHERE key IN ('name', 'phone', 'zip', 'address', 'email')
+				))
+				ELSE '{}'::jsonb END
+		WHERE order_uid = ANY($1)
+	`, orderUIDs, erasure.Pseudonym, domain.ErasedValue)
+	if err != nil {
+		return fmt.Errorf("failed to erase order history: %w", err)
+	}
+	return nil
+}
+
+// insertEvent stores an event, a zero occurredAt is the transaction time.
+func insertEvent(ctx context.Context, tx pgx.Tx, order *domain.Order, eventType domain.EventType, actor string, diff map[string]any, occurredAt time.Time) error {
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode order diff: %w", err)
+	}
+	var at *time.Time
+	if !occurredAt.IsZero() {
+		at = &occurredAt
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_events (order_uid, type, version, actor, diff, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6::timestamptz, CURRENT_TIMESTAMP))
+	`, order.OrderUID, eventType, order.Version, actor, string(data), at)
+	if err != nil {
+		return fmt.Errorf("failed to insert order event: %w", err)
+	}
+	return nil
+}
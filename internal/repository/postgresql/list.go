@@ -0,0 +1,335 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/envelope"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strconv"
+	"strings"
+)
+
+// List returns up to page.Limit orders matching the filter, newest first.
+// Deliveries, payments and items of the whole page are read with one query each.
+func (r *Repository) List(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error) {
+	db := r.reader()
+	orders, err := r.listHeaders(ctx, db, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.attachParts(ctx, db, orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetMany returns the orders keyed by order UID, orders that do not exist are
+// left out. Every table is read with one query for all orders.
+func (r *Repository) GetMany(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error) {
+	db := r.reader(orderUIDs...)
+	rows, err := db.Query(ctx, `
+		SELECT
+			order_uid, track_number, entry, locale, internal_signature,
+			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard,
+			COALESCE(updated_at, created_at, date_created), version
+		FROM orders
+		WHERE order_uid = ANY($1) AND deleted_at IS NULL
+	`, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	orders, err := pgx.CollectRows(rows, scanOrderHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order: %w", err)
+	}
+	if err = r.attachParts(ctx, db, orders); err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]domain.Order, len(orders))
+	for _, order := range orders {
+		found[order.OrderUID] = order
+	}
+	return found, nil
+}
+
+// attachParts loads the deliveries, payments and items of the orders from db.
+func (r *Repository) attachParts(ctx context.Context, db *pgxpool.Pool, orders []domain.Order) error {
+	orderUIDs := make([]string, 0, len(orders))
+	for _, order := range orders {
+		orderUIDs = append(orderUIDs, order.OrderUID)
+	}
+
+	deliveries, err := r.loadDeliveries(ctx, db, orderUIDs)
+	if err != nil {
+		return err
+	}
+	payments, err := r.loadPayments(ctx, db, orderUIDs)
+	if err != nil {
+		return err
+	}
+	items, err := r.loadItems(ctx, db, orderUIDs)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		uid := orders[i].OrderUID
+		delivery, ok := deliveries[uid]
+		if !ok {
+			return fmt.Errorf("order %s: %w", uid, repository.ErrDeliveryNotFound)
+		}
+		payment, ok := payments[uid]
+		if !ok {
+			return fmt.Errorf("order %s: %w", uid, repository.ErrPaymentNotFound)
+		}
+		if len(items[uid]) == 0 {
+			return fmt.Errorf("order %s: %w", uid, repository.ErrItemsNotFound)
+		}
+		orders[i].Delivery = delivery
+		orders[i].Payment = payment
+		orders[i].Items = items[uid]
+	}
+	return nil
+}
+
+// ListHeaders is List without deliveries, payments and items.
+func (r *Repository) ListHeaders(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error) {
+	return r.listHeaders(ctx, r.reader(), filter, page)
+}
+
+func (r *Repository) listHeaders(ctx context.Context, db *pgxpool.Pool, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error) {
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		args       []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if filter.CustomerID != "" {
+		conditions = append(conditions, "customer_id = "+arg(filter.CustomerID))
+	}
+	if filter.DeliveryService != "" {
+		conditions = append(conditions, "delivery_service = "+arg(filter.DeliveryService))
+	}
+	if filter.TrackNumber != "" {
+		conditions = append(conditions, "track_number = "+arg(filter.TrackNumber))
+	}
+	if filter.ChrtID != 0 {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM items WHERE items.order_uid = orders.order_uid AND items.chrt_id = "+arg(filter.ChrtID)+")")
+	}
+	if filter.NmID != 0 {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM items WHERE items.order_uid = orders.order_uid AND items.nm_id = "+arg(filter.NmID)+")")
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "date_created >= "+arg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "date_created < "+arg(filter.CreatedTo))
+	}
+	if page.After != nil {
+		conditions = append(conditions,
+			fmt.Sprintf("(date_created, order_uid) < (%s, %s)", arg(page.After.DateCreated), arg(page.After.OrderUID)))
+	}
+
+	query := `
+		SELECT
+			order_uid, track_number, entry, locale, internal_signature,
+			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard,
+			COALESCE(updated_at, created_at, date_created), version
+		FROM orders
+		WHERE ` + strings.Join(conditions, " AND ")
+	query += " ORDER BY date_created DESC, order_uid DESC LIMIT " + arg(page.Limit)
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	orders, err := pgx.CollectRows(rows, scanOrderHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order: %w", err)
+	}
+	return orders, nil
+}
+
+func scanOrderHeader(row pgx.CollectableRow) (domain.Order, error) {
+	var order domain.Order
+	err := row.Scan(
+		&order.OrderUID,
+		&order.TrackNumber,
+		&order.Entry,
+		&order.Locale,
+		&order.InternalSignature,
+		&order.CustomerID,
+		&order.DeliveryService,
+		&order.ShardKey,
+		&order.SmID,
+		&order.DateCreated,
+		&order.OofShard,
+		&order.UpdatedAt,
+		&order.Version,
+	)
+	order.UpdatedAt = order.UpdatedAt.UTC()
+	return order, err
+}
+
+// GetDeliveries returns the deliveries of the orders keyed by order UID.
+func (r *Repository) GetDeliveries(ctx context.Context, orderUIDs []string) (map[string]domain.Delivery, error) {
+	return r.loadDeliveries(ctx, r.reader(orderUIDs...), orderUIDs)
+}
+
+func (r *Repository) loadDeliveries(ctx context.Context, db *pgxpool.Pool, orderUIDs []string) (map[string]domain.Delivery, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			order_uid, name, phone, zip, city, address, region, email, key_id, wrapped_key
+		FROM deliveries
+		WHERE order_uid = ANY($1)
+	`, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make(map[string]domain.Delivery, len(orderUIDs))
+	for rows.Next() {
+		var (
+			orderUID string
+			delivery domain.Delivery
+			keyID    *string
+			sealed   envelope.Sealed
+		)
+		err := rows.Scan(
+			&orderUID,
+			&delivery.Name,
+			&delivery.Phone,
+			&delivery.Zip,
+			&delivery.City,
+			&delivery.Address,
+			&delivery.Region,
+			&delivery.Email,
+			&keyID,
+			&sealed.WrappedKey,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		if keyID != nil {
+			sealed.KeyID = *keyID
+		}
+		if err = repository.OpenDelivery(r.keyring, orderUID, &delivery, sealed); err != nil {
+			return nil, fmt.Errorf("failed to decrypt delivery: %w", err)
+		}
+		deliveries[orderUID] = delivery
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetPayments returns the payments of the orders keyed by order UID.
+func (r *Repository) GetPayments(ctx context.Context, orderUIDs []string) (map[string]domain.Payment, error) {
+	return r.loadPayments(ctx, r.reader(orderUIDs...), orderUIDs)
+}
+
+func (r *Repository) loadPayments(ctx context.Context, db *pgxpool.Pool, orderUIDs []string) (map[string]domain.Payment, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			order_uid, transaction, request_id, currency, provider, amount,
+			payment_dt, bank, delivery_cost, goods_total, custom_fee,
+			COALESCE(base_currency, ''), exchange_rate, base_amount
+		FROM payments
+		WHERE order_uid = ANY($1)
+	`, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := make(map[string]domain.Payment, len(orderUIDs))
+	for rows.Next() {
+		var (
+			orderUID string
+			payment  domain.Payment
+		)
+		err := rows.Scan(
+			&orderUID,
+			&payment.Transaction,
+			&payment.RequestID,
+			&payment.Currency,
+			&payment.Provider,
+			&payment.Amount,
+			&payment.PaymentDt,
+			&payment.Bank,
+			&payment.DeliveryCost,
+			&payment.GoodsTotal,
+			&payment.CustomFee,
+			&payment.BaseCurrency,
+			&payment.ExchangeRate,
+			&payment.BaseAmount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments[orderUID] = payment
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payments: %w", err)
+	}
+	return payments, nil
+}
+
+// GetItems returns the items of the orders keyed by order UID, in insertion order.
+func (r *Repository) GetItems(ctx context.Context, orderUIDs []string) (map[string][]domain.Item, error) {
+	return r.loadItems(ctx, r.reader(orderUIDs...), orderUIDs)
+}
+
+func (r *Repository) loadItems(ctx context.Context, db *pgxpool.Pool, orderUIDs []string) (map[string][]domain.Item, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			order_uid, chrt_id, track_number, price, rid, name, sale,
+			size, total_price, nm_id, brand, status
+		FROM items
+		WHERE order_uid = ANY($1)
+		ORDER BY id
+	`, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make(map[string][]domain.Item, len(orderUIDs))
+	for rows.Next() {
+		var (
+			orderUID string
+			item     domain.Item
+		)
+		err := rows.Scan(
+			&orderUID,
+			&item.ChrtID,
+			&item.TrackNumber,
+			&item.Price,
+			&item.Rid,
+			&item.Name,
+			&item.Sale,
+			&item.Size,
+			&item.TotalPrice,
+			&item.NmID,
+			&item.Brand,
+			&item.Status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items[orderUID] = append(items[orderUID], item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating items: %w", err)
+	}
+	return items, nil
+}
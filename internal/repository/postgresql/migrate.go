@@ -0,0 +1,155 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"go.uber.org/zap"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+const gooseAnnotation = "-- +goose "
+
+// Migration is the SQL of an embedded migration, split by direction and
+// without the goose annotations.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// NewMigrator returns the goose provider of the embedded migrations. Every
+// command of the provider holds a session advisory lock, so migrators and
+// instances of the service migrating on start never run at once; a second one
+// waits for the lock. Closing the provider does not close the pool.
+func NewMigrator(pool *pgxpool.Pool) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, stdlib.OpenDBFromPool(pool), migrations.FS,
+		goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	return provider, nil
+}
+
+// Migrate applies the pending embedded migrations.
+func Migrate(ctx context.Context, log *zap.SugaredLogger, pool *pgxpool.Pool) error {
+	provider, err := NewMigrator(pool)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := provider.Close(); err != nil {
+			log.Warnw("failed to close migrator", "error", err)
+		}
+	}()
+	results, err := provider.Up(ctx)
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		results = partial.Applied
+	}
+	for _, result := range results {
+		log.Infow("migration applied", "migration", result.Source.Path, "duration", result.Duration)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate: %w", err)
+	}
+	return nil
+}
+
+// ReadMigrations parses the embedded migrations, by version. It fails on a
+// migration that goose would not run or that could not be rolled back: one
+// without a version, with a version used twice, without an Up or a Down
+// section, or with an unbalanced StatementBegin.
+func ReadMigrations() ([]Migration, error) {
+	names, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	result := make([]Migration, 0, len(names))
+	versions := make(map[int64]string, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(migrations.FS, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		migration, err := parseMigration(name, string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration %s: %w", name, err)
+		}
+		if other, ok := versions[migration.Version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s have the same version %d", other, name, migration.Version)
+		}
+		versions[migration.Version] = name
+		result = append(result, migration)
+	}
+	slices.SortFunc(result, func(a, b Migration) int {
+		return int(a.Version - b.Version)
+	})
+	return result, nil
+}
+
+func parseMigration(name, data string) (Migration, error) {
+	version, err := goose.NumericComponent(name)
+	if err != nil {
+		return Migration{}, err
+	}
+	var up, down strings.Builder
+	var section *strings.Builder
+	var inStatement bool
+	for line := range strings.Lines(data) {
+		annotation, ok := strings.CutPrefix(strings.TrimSpace(line), gooseAnnotation)
+		if !ok {
+			if section != nil {
+				section.WriteString(line)
+			}
+			continue
+		}
+		switch annotation = strings.TrimSpace(annotation); annotation {
+		case "Up", "Down":
+			next := &up
+			if annotation == "Down" {
+				next = &down
+			}
+			if inStatement || next.Len() > 0 || next == section {
+				return Migration{}, fmt.Errorf("unexpected %s section", annotation)
+			}
+			section = next
+		case "StatementBegin":
+			if section == nil || inStatement {
+				return Migration{}, errors.New("unexpected StatementBegin")
+			}
+			inStatement = true
+		case "StatementEnd":
+			if !inStatement {
+				return Migration{}, errors.New("StatementEnd without StatementBegin")
+			}
+			inStatement = false
+		}
+	}
+	switch {
+	case inStatement:
+		return Migration{}, errors.New("StatementBegin without StatementEnd")
+	case strings.TrimSpace(up.String()) == "":
+		return Migration{}, errors.New("no Up section")
+	case strings.TrimSpace(down.String()) == "":
+		return Migration{}, errors.New("no Down section")
+	}
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      strings.TrimSpace(up.String()) + "\n",
+		Down:    strings.TrimSpace(down.String()) + "\n",
+	}, nil
+}
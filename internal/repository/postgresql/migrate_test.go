@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReadMigrations(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := ReadMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.EqualValues(t, i+1, migration.Version, migration.Name)
+		assert.NotContains(t, migration.Up, gooseAnnotation, migration.Name)
+		assert.NotContains(t, migration.Down, gooseAnnotation, migration.Name)
+	}
+}
+
+func TestParseMigration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		up      string
+		down    string
+		wantErr string
+	}{
+		{
+			name: "statements",
+			file: "00001_init.sql",
+			data: "-- +goose Up\n-- +goose StatementBegin\nCREATE TABLE t (id INT);\n-- +goose StatementEnd\n\n-- +goose Down\nDROP TABLE t;\n",
+			up:   "CREATE TABLE t (id INT);\n",
+			down: "DROP TABLE t;\n",
+		},
+		{
+			name:    "no version",
+			file:    "init.sql",
+			data:    "-- +goose Up\nSELECT 1;\n-- +goose Down\nSELECT 1;\n",
+			wantErr: "separator",
+		},
+		{
+			name:    "no down",
+			file:    "00002_up.sql",
+			data:    "-- +goose Up\nSELECT 1;\n",
+			wantErr: "no Down section",
+		},
+		{
+			name:    "two up sections",
+			file:    "00003_twice.sql",
+			data:    "-- +goose Up\nSELECT 1;\n-- +goose Up\nSELECT 2;\n-- +goose Down\nSELECT 1;\n",
+			wantErr: "unexpected Up section",
+		},
+		{
+			name:    "unterminated statement",
+			file:    "00004_open.sql",
+			data:    "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n-- +goose Down\nSELECT 1;\n",
+			wantErr: "unexpected Down section",
+		},
+		{
+			name:    "statement end without begin",
+			file:    "00005_end.sql",
+			data:    "-- +goose Up\nSELECT 1;\n-- +goose StatementEnd\n-- +goose Down\nSELECT 1;\n",
+			wantErr: "StatementEnd without StatementBegin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			migration, err := parseMigration(tt.file, tt.data)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.up, migration.Up)
+			assert.Equal(t, tt.down, migration.Down)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// partitionName matches the monthly orders partitions created by the
+// ensure_order_partitions function, orders_y2021m11 holds November 2021.
+var partitionName = regexp.MustCompile(`^orders_y(\d{4})m(\d{2})$`)
+
+// EnsurePartitions creates the missing monthly orders and items partitions from
+// the month of from to the month of to, both included, and returns how many
+// months it created.
+func (r *Repository) EnsurePartitions(ctx context.Context, from, to time.Time) (int, error) {
+	var created int
+	err := r.DB.QueryRow(ctx, "SELECT ensure_order_partitions($1::date, $2::date)", from.UTC(), to.UTC()).Scan(&created)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create partitions: %w", err)
+	}
+	return created, nil
+}
+
+// DetachPartitions detaches the monthly orders and items partitions of the
+// months that ended before the given time and returns the names of the
+// detached orders partitions. Their orders are no longer read and their order
+// UIDs can be stored again; their deliveries, payments and history stay until
+// the partition is archived.
+func (r *Repository) DetachPartitions(ctx context.Context, before time.Time) ([]string, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	orders, err := attachedPartitions(ctx, tx, "orders")
+	if err != nil {
+		return nil, err
+	}
+	items, err := attachedPartitions(ctx, tx, "items")
+	if err != nil {
+		return nil, err
+	}
+	attachedItems := make(map[string]bool, len(items))
+	for _, name := range items {
+		attachedItems[name] = true
+	}
+
+	var detached []string
+	for _, name := range orders {
+		month, ok := partitionMonth(name)
+		if !ok || month.AddDate(0, 1, 0).After(before) {
+			continue
+		}
+		if _, err := tx.Exec(ctx, "ALTER TABLE orders DETACH PARTITION "+pgx.Identifier{name}.Sanitize()); err != nil {
+			return nil, fmt.Errorf("failed to detach partition %s: %w", name, err)
+		}
+		_, err := tx.Exec(ctx, fmt.Sprintf(`
+			DELETE FROM order_uids
+			WHERE order_uid IN (SELECT order_uid FROM %s)
+				AND order_uid NOT IN (SELECT order_uid FROM orders)
+		`, pgx.Identifier{name}.Sanitize()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to release order uids of partition %s: %w", name, err)
+		}
+		itemsName := itemsPartition(name)
+		if attachedItems[itemsName] {
+			if _, err := tx.Exec(ctx, "ALTER TABLE items DETACH PARTITION "+pgx.Identifier{itemsName}.Sanitize()); err != nil {
+				return nil, fmt.Errorf("failed to detach partition %s: %w", itemsName, err)
+			}
+		}
+		detached = append(detached, name)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return detached, nil
+}
+
+// DetachedPartitions returns the names of the detached orders partitions that
+// were not archived yet, oldest first.
+func (r *Repository) DetachedPartitions(ctx context.Context) ([]string, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT c.relname
+		FROM pg_class c
+		WHERE c.relkind = 'r'
+			AND c.relnamespace = current_schema()::regnamespace
+			AND c.relname ~ '^orders_y[0-9]{4}m[0-9]{2}$'
+			AND NOT EXISTS (SELECT 1 FROM pg_inherits i WHERE i.inhrelid = c.oid)
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get detached partitions: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan detached partitions: %w", err)
+	}
+	return names, nil
+}
+
+// ExportPartition passes every order of the detached partition to write as a
+// JSON object with its delivery, payment, items and history, and returns the
+// number of orders. The rows are exported as they are stored: an encrypted
+// delivery stays encrypted.
+func (r *Repository) ExportPartition(ctx context.Context, partition string, write func(line []byte) error) (int, error) {
+	if _, ok := partitionMonth(partition); !ok {
+		return 0, fmt.Errorf("invalid partition name %q", partition)
+	}
+	rows, err := r.DB.Query(ctx, fmt.Sprintf(`
+		SELECT jsonb_build_object(
+			'order', to_jsonb(o),
+			'delivery', (SELECT to_jsonb(d) - 'search_vector' FROM deliveries d WHERE d.order_uid = o.order_uid),
+			'payment', (SELECT to_jsonb(p) FROM payments p WHERE p.order_uid = o.order_uid),
+			'items', COALESCE((
+				SELECT jsonb_agg(to_jsonb(i) - 'search_vector' ORDER BY i.id)
+				FROM %s i
+				WHERE i.order_uid = o.order_uid
+			), '[]'::jsonb),
+			'events', COALESCE((
+				SELECT jsonb_agg(to_jsonb(e) ORDER BY e.id)
+				FROM order_events e
+				WHERE e.order_uid = o.order_uid
+			), '[]'::jsonb)
+		)::text
+		FROM %s o
+		ORDER BY o.date_created, o.order_uid
+	`, pgx.Identifier{itemsPartition(partition)}.Sanitize(), pgx.Identifier{partition}.Sanitize()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to export partition %s: %w", partition, err)
+	}
+	defer rows.Close()
+
+	var exported int
+	for rows.Next() {
+		var line []byte
+		if err := rows.Scan(&line); err != nil {
+			return exported, fmt.Errorf("failed to scan partition %s: %w", partition, err)
+		}
+		if err := write(line); err != nil {
+			return exported, err
+		}
+		exported++
+	}
+	if err := rows.Err(); err != nil {
+		return exported, fmt.Errorf("failed to export partition %s: %w", partition, err)
+	}
+	return exported, nil
+}
+
+// DropPartition drops the detached orders partition and its items partition
+// together with the deliveries, payments and history of its orders. An order
+// UID that was stored again after the partition was detached keeps them.
+func (r *Repository) DropPartition(ctx context.Context, partition string) error {
+	if _, ok := partitionMonth(partition); !ok {
+		return fmt.Errorf("invalid partition name %q", partition)
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	table := pgx.Identifier{partition}.Sanitize()
+	for _, child := range []string{"deliveries", "payments", "order_events"} {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE order_uid IN (SELECT order_uid FROM %s)
+				AND order_uid NOT IN (SELECT order_uid FROM orders)
+		`, child, table))
+		if err != nil {
+			return fmt.Errorf("failed to delete %s of partition %s: %w", child, partition, err)
+		}
+	}
+	if _, err := tx.Exec(ctx, "DROP TABLE IF EXISTS "+pgx.Identifier{itemsPartition(partition)}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to drop items of partition %s: %w", partition, err)
+	}
+	if _, err := tx.Exec(ctx, "DROP TABLE "+table); err != nil {
+		return fmt.Errorf("failed to drop partition %s: %w", partition, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// attachedPartitions returns the names of the partitions of the parent table.
+func attachedPartitions(ctx context.Context, tx pgx.Tx, parent string) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass($1)
+		ORDER BY c.relname
+	`, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s partitions: %w", parent, err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s partitions: %w", parent, err)
+	}
+	return names, nil
+}
+
+// partitionMonth returns the first instant of the month held by the monthly
+// orders partition. The default partition has no month.
+func partitionMonth(name string) (time.Time, bool) {
+	match := partitionName.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, false
+	}
+	month, err := time.Parse("2006-01", match[1]+"-"+match[2])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// itemsPartition returns the items partition of the same month as the orders
+// partition.
+func itemsPartition(ordersPartition string) string {
+	return "items_" + strings.TrimPrefix(ordersPartition, "orders_")
+}
@@ -6,24 +6,50 @@ import (
 	"fmt"
 	"github.com/Killazius/L0/internal/config"
 	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/envelope"
 	"github.com/Killazius/L0/internal/repository"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// uniqueViolation is the SQLSTATE of a unique constraint violation.
+const uniqueViolation = "23505"
+
 type Repository struct {
-	DB *pgxpool.Pool
+	DB       *pgxpool.Pool
+	keyring  *envelope.Keyring
+	replicas *Replicas
 }
 
-func New(db *pgxpool.Pool) *Repository {
-	return &Repository{DB: db}
+// New creates a repository. When keyring is not nil, customer contact fields of
+// deliveries are encrypted before they are written.
+func New(db *pgxpool.Pool, keyring *envelope.Keyring) *Repository {
+	return &Repository{DB: db, keyring: keyring}
 }
 
 func (r *Repository) Close() {
 	r.DB.Close()
 }
 func CreatePool(cfg config.PostgresConfig) (*pgxpool.Pool, error) {
-	poolConfig, err := pgxpool.ParseConfig(cfg.GetURL())
+	pool, err := newPool(cfg.GetURL(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	if err := pool.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return pool, nil
+}
+
+// newPool creates a pool connecting to the DSN with the pool settings of cfg.
+func newPool(dsn string, cfg config.PostgresConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse connection string: %w", err)
 	}
@@ -38,14 +64,6 @@ func CreatePool(cfg config.PostgresConfig) (*pgxpool.Pool, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
-	defer cancel()
-
-	if err := pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
 	return pool, nil
 }
 
@@ -61,6 +79,9 @@ func (r *Repository) Create(ctx context.Context, order *domain.Order) error {
 			return
 		}
 	}(tx, ctx)
+	if err = lockOrderUID(ctx, tx, order.OrderUID); err != nil {
+		return err
+	}
 	var exists bool
 	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM orders WHERE order_uid = $1)", order.OrderUID).Scan(&exists)
 	if err != nil {
@@ -69,11 +90,52 @@ func (r *Repository) Create(ctx context.Context, order *domain.Order) error {
 	if exists {
 		return repository.ErrDuplicateOrder
 	}
+	if err = r.insertOrder(ctx, tx, order); err != nil {
+		return err
+	}
+	if err = r.appendEvent(ctx, tx, domain.EventOrderCreated, nil, order); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.wrote(order.OrderUID)
+
+	return nil
+}
+
+// lockOrderUID serializes the transactions inserting the order UID until the
+// end of the transaction, so they see each other's orders. The orders table is
+// partitioned by date_created and cannot reject the second insert itself;
+// insertOrder claims the UID in order_uids, which does.
+func lockOrderUID(ctx context.Context, tx pgx.Tx, orderUID string) error {
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", orderUID); err != nil {
+		return fmt.Errorf("failed to lock order uid: %w", err)
+	}
+	return nil
+}
+
+// insertOrder inserts the order with its delivery, payment and items. An order
+// without a version is stored as version 1. An order UID that is already
+// stored fails with repository.ErrDuplicateOrder.
+func (r *Repository) insertOrder(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	if order.Version == 0 {
+		order.Version = 1
+	}
+	_, err := tx.Exec(ctx, "INSERT INTO order_uids (order_uid) VALUES ($1)", order.OrderUID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return repository.ErrDuplicateOrder
+		}
+		return fmt.Errorf("failed to claim order uid: %w", err)
+	}
 	_, err = tx.Exec(ctx, `
         INSERT INTO "orders" (
             order_uid, track_number, entry, locale, internal_signature,
-            customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+            customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, updated_at, version
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
     `,
 		order.OrderUID,
 		order.TrackNumber,
@@ -86,34 +148,50 @@ func (r *Repository) Create(ctx context.Context, order *domain.Order) error {
 		order.SmID,
 		order.DateCreated,
 		order.OofShard,
+		order.UpdatedAt,
+		order.Version,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert order: %w", err)
 	}
+	return r.insertParts(ctx, tx, order)
+}
 
+func (r *Repository) insertParts(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	delivery := order.Delivery
+	sealed, err := repository.SealDelivery(r.keyring, order.OrderUID, &delivery)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt delivery: %w", err)
+	}
 	_, err = tx.Exec(ctx, `
         INSERT INTO deliveries (
-            order_uid, name, phone, zip, city, address, region, email
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            order_uid, name, phone, zip, city, address, region, email, key_id, wrapped_key
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
     `,
 		order.OrderUID,
-		order.Delivery.Name,
-		order.Delivery.Phone,
-		order.Delivery.Zip,
-		order.Delivery.City,
-		order.Delivery.Address,
-		order.Delivery.Region,
-		order.Delivery.Email,
+		delivery.Name,
+		delivery.Phone,
+		delivery.Zip,
+		delivery.City,
+		delivery.Address,
+		delivery.Region,
+		delivery.Email,
+		nullableKeyID(sealed),
+		sealed.WrappedKey,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert delivery: %w", err)
 	}
+	if err = r.saveCustomer(ctx, tx, order); err != nil {
+		return err
+	}
 
 	_, err = tx.Exec(ctx, `
         INSERT INTO payments (
             order_uid, transaction, request_id, currency, provider,
-            amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+            amount, payment_dt, bank, delivery_cost, goods_total, custom_fee,
+            base_currency, exchange_rate, base_amount
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14)
     `,
 		order.OrderUID,
 		order.Payment.Transaction,
@@ -126,6 +204,9 @@ func (r *Repository) Create(ctx context.Context, order *domain.Order) error {
 		order.Payment.DeliveryCost,
 		order.Payment.GoodsTotal,
 		order.Payment.CustomFee,
+		order.Payment.BaseCurrency,
+		order.Payment.ExchangeRate,
+		order.Payment.BaseAmount,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert payment: %w", err)
@@ -135,8 +216,8 @@ func (r *Repository) Create(ctx context.Context, order *domain.Order) error {
 		_, err = tx.Exec(ctx, `
             INSERT INTO items (
                 order_uid, chrt_id, track_number, price, rid, name,
-                sale, size, total_price, nm_id, brand, status
-            ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+                sale, size, total_price, nm_id, brand, status, date_created
+            ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
         `,
 			order.OrderUID,
 			item.ChrtID,
@@ -150,21 +231,24 @@ func (r *Repository) Create(ctx context.Context, order *domain.Order) error {
 			item.NmID,
 			item.Brand,
 			item.Status,
+			order.DateCreated,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert item: %w", err)
 		}
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 func (r *Repository) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
-	tx, err := r.DB.Begin(ctx)
+	return r.GetParts(ctx, orderUID, domain.AllParts)
+}
+
+// GetParts returns the order with only the selected parts, the tables of the
+// other parts are not queried. Deleted orders are not found. The order is read
+// from a replica unless it was just written.
+func (r *Repository) GetParts(ctx context.Context, orderUID string, parts domain.Part) (*domain.Order, error) {
+	tx, err := r.reader(orderUID).Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -175,28 +259,91 @@ func (r *Repository) Get(ctx context.Context, orderUID string) (*domain.Order, e
 		}
 	}(tx, ctx)
 
-	order, err := r.getOrder(ctx, tx, orderUID)
+	order, err := r.readOrder(ctx, tx, orderUID, parts)
 	if err != nil {
 		return nil, err
 	}
+	if order.DeletedAt != nil {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	delivery, err := r.getDelivery(ctx, tx, orderUID)
+	return order, nil
+}
+
+// readOrder reads the order in the transaction, deleted orders included.
+func (r *Repository) readOrder(ctx context.Context, tx pgx.Tx, orderUID string, parts domain.Part) (*domain.Order, error) {
+	order, err := r.getOrder(ctx, tx, orderUID)
 	if err != nil {
 		return nil, err
 	}
-	order.Delivery = *delivery
 
-	payment, err := r.getPayment(ctx, tx, orderUID)
+	if parts.Has(domain.PartDelivery) {
+		delivery, err := r.getDelivery(ctx, tx, orderUID)
+		if err != nil {
+			return nil, err
+		}
+		order.Delivery = *delivery
+	}
+
+	if parts.Has(domain.PartPayment) {
+		payment, err := r.getPayment(ctx, tx, orderUID)
+		if err != nil {
+			return nil, err
+		}
+		order.Payment = *payment
+	}
+
+	if parts.Has(domain.PartItems) {
+		items, err := r.getItems(ctx, tx, orderUID)
+		if err != nil {
+			return nil, err
+		}
+		order.Items = items
+	}
+	return order, nil
+}
+
+// GetByTransaction returns the order paid with the transaction. When several
+// orders share it, the latest one is returned. The order is read from the
+// primary in the transaction that found it, a lagging replica could miss it.
+func (r *Repository) GetByTransaction(ctx context.Context, transaction string) (*domain.Order, error) {
+	tx, err := r.DB.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	order.Payment = *payment
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
 
-	items, err := r.getItems(ctx, tx, orderUID)
+	var orderUID string
+	err = tx.QueryRow(ctx, `
+		SELECT p.order_uid
+		FROM payments p
+		JOIN orders o ON o.order_uid = p.order_uid
+		WHERE p.transaction = $1 AND o.deleted_at IS NULL
+		ORDER BY p.id DESC
+		LIMIT 1
+	`, transaction).Scan(&orderUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order by transaction: %w", err)
+	}
+	order, err := r.readOrder(ctx, tx, orderUID, domain.AllParts)
 	if err != nil {
 		return nil, err
 	}
-	order.Items = items
+	if order.DeletedAt != nil {
+		return nil, repository.ErrOrderNotFound
+	}
 
 	if err = tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -211,7 +358,8 @@ func (r *Repository) getOrder(ctx context.Context, tx pgx.Tx, orderUID string) (
 	query := `
 		SELECT 
 			order_uid, track_number, entry, locale, internal_signature,
-			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
+			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard,
+			COALESCE(updated_at, created_at, date_created), version, deleted_at
 		FROM orders 
 		WHERE order_uid = $1
 	`
@@ -228,6 +376,9 @@ func (r *Repository) getOrder(ctx context.Context, tx pgx.Tx, orderUID string) (
 		&order.SmID,
 		&order.DateCreated,
 		&order.OofShard,
+		&order.UpdatedAt,
+		&order.Version,
+		&order.DeletedAt,
 	)
 
 	if err != nil {
@@ -236,6 +387,7 @@ func (r *Repository) getOrder(ctx context.Context, tx pgx.Tx, orderUID string) (
 		}
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
+	order.UpdatedAt = order.UpdatedAt.UTC()
 
 	return &order, nil
 }
@@ -245,11 +397,13 @@ func (r *Repository) getDelivery(ctx context.Context, tx pgx.Tx, orderUID string
 
 	query := `
 		SELECT 
-			name, phone, zip, city, address, region, email
+			name, phone, zip, city, address, region, email, key_id, wrapped_key
 		FROM deliveries 
 		WHERE order_uid = $1
 	`
 
+	var keyID *string
+	var sealed envelope.Sealed
 	err := tx.QueryRow(ctx, query, orderUID).Scan(
 		&delivery.Name,
 		&delivery.Phone,
@@ -258,6 +412,8 @@ func (r *Repository) getDelivery(ctx context.Context, tx pgx.Tx, orderUID string
 		&delivery.Address,
 		&delivery.Region,
 		&delivery.Email,
+		&keyID,
+		&sealed.WrappedKey,
 	)
 
 	if err != nil {
@@ -266,6 +422,12 @@ func (r *Repository) getDelivery(ctx context.Context, tx pgx.Tx, orderUID string
 		}
 		return nil, fmt.Errorf("failed to get delivery: %w", err)
 	}
+	if keyID != nil {
+		sealed.KeyID = *keyID
+	}
+	if err = repository.OpenDelivery(r.keyring, orderUID, &delivery, sealed); err != nil {
+		return nil, fmt.Errorf("failed to decrypt delivery: %w", err)
+	}
 
 	return &delivery, nil
 }
@@ -276,7 +438,8 @@ func (r *Repository) getPayment(ctx context.Context, tx pgx.Tx, orderUID string)
 	query := `
 		SELECT 
 			transaction, request_id, currency, provider, amount,
-			payment_dt, bank, delivery_cost, goods_total, custom_fee
+			payment_dt, bank, delivery_cost, goods_total, custom_fee,
+			COALESCE(base_currency, ''), exchange_rate, base_amount
 		FROM payments 
 		WHERE order_uid = $1
 	`
@@ -292,6 +455,9 @@ func (r *Repository) getPayment(ctx context.Context, tx pgx.Tx, orderUID string)
 		&payment.DeliveryCost,
 		&payment.GoodsTotal,
 		&payment.CustomFee,
+		&payment.BaseCurrency,
+		&payment.ExchangeRate,
+		&payment.BaseAmount,
 	)
 
 	if err != nil {
@@ -355,7 +521,7 @@ func (r *Repository) getItems(ctx context.Context, tx pgx.Tx, orderUID string) (
 func (r *Repository) GetAll(ctx context.Context) ([]domain.Order, error) {
 	var orders []domain.Order
 
-	rows, err := r.DB.Query(ctx, "SELECT order_uid FROM orders")
+	rows, err := r.reader().Query(ctx, "SELECT order_uid FROM orders WHERE deleted_at IS NULL")
 	if err != nil {
 		return nil, fmt.Errorf("failed to query orders: %w", err)
 	}
@@ -0,0 +1,129 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) GetByCustomer(ctx context.Context, customerID string) ([]domain.Order, error) {
+	rows, err := r.DB.Query(ctx, "SELECT order_uid FROM orders WHERE customer_id = $1 AND deleted_at IS NULL ORDER BY date_created", customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	orderUIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order_uid: %w", err)
+	}
+	if len(orderUIDs) == 0 {
+		return nil, repository.ErrCustomerNotFound
+	}
+
+	orders := make([]domain.Order, 0, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		order, err := r.Get(ctx, orderUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order %s: %w", orderUID, err)
+		}
+		orders = append(orders, *order)
+	}
+	return orders, nil
+}
+
+// EraseCustomer anonymises every order of the customer in one transaction:
+// customer_id is replaced by the erasure pseudonym and the contact fields of
+// deliveries by domain.ErasedValue. City and region are kept for analytics.
+// The customer is replaced by the pseudonym without the address book.
+// The erasure record and the order events are stored in the same
+// transaction, erasure.ID and erasure.OrderUIDs are filled in.
+func (r *Repository) EraseCustomer(ctx context.Context, customerID string, erasure *domain.Erasure) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	before, err := r.lockCustomerOrders(ctx, tx, customerID)
+	if err != nil {
+		return err
+	}
+	if len(before) == 0 {
+		return repository.ErrCustomerNotFound
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE orders SET customer_id = $2, updated_at = $3, version = version + 1
+		WHERE customer_id = $1
+		RETURNING order_uid
+	`, customerID, erasure.Pseudonym, erasure.ErasedAt)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise orders: %w", err)
+	}
+	orderUIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to anonymise orders: %w", err)
+	}
+	if len(orderUIDs) == 0 {
+		return repository.ErrCustomerNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE deliveries
+		SET name = $2, phone = $2, zip = $2, address = $2, email = $2, key_id = NULL, wrapped_key = NULL
+		WHERE order_uid = ANY($1)
+	`, orderUIDs, domain.ErasedValue)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise deliveries: %w", err)
+	}
+	if err = eraseCustomer(ctx, tx, customerID, erasure.Pseudonym); err != nil {
+		return err
+	}
+	if err = r.recordErasure(ctx, tx, before, erasure); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO customer_erasures (customer_hash, pseudonym, order_uids, requested_by, reason, erased_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, erasure.CustomerHash, erasure.Pseudonym, orderUIDs, erasure.RequestedBy, erasure.Reason, erasure.ErasedAt).Scan(&erasure.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert erasure record: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	erasure.OrderUIDs = orderUIDs
+	r.wrote(orderUIDs...)
+	return nil
+}
+
+// lockCustomerOrders locks the orders of the customer and returns them as they
+// are before the erasure.
+func (r *Repository) lockCustomerOrders(ctx context.Context, tx pgx.Tx, customerID string) ([]*domain.Order, error) {
+	rows, err := tx.Query(ctx, "SELECT order_uid FROM orders WHERE customer_id = $1 ORDER BY order_uid FOR UPDATE", customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock orders: %w", err)
+	}
+	orderUIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order_uid: %w", err)
+	}
+	orders := make([]*domain.Order, 0, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		order, err := r.readOrder(ctx, tx, orderUID, domain.AllParts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order %s: %w", orderUID, err)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
@@ -0,0 +1,144 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// ratesLock is the key of the advisory lock held while exchange rates are
+// saved, so instances loading the same rates on start do not convert the same
+// payments at the same time.
+const ratesLock = 5_000_050
+
+// ExchangeRate returns the rate of the currency in baseCurrency effective on
+// the UTC day of at.
+func (r *Repository) ExchangeRate(ctx context.Context, baseCurrency, currency string, at time.Time) (decimal.Decimal, error) {
+	var rate decimal.Decimal
+	err := r.DB.QueryRow(ctx, `
+		SELECT rate
+		FROM exchange_rates
+		WHERE base_currency = $1 AND currency = $2 AND rate_date <= $3::date
+		ORDER BY rate_date DESC
+		LIMIT 1
+	`, baseCurrency, currency, at.UTC().Format(time.DateOnly)).Scan(&rate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return decimal.Decimal{}, repository.ErrRateNotFound
+	}
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// ExchangeRates returns the rates in baseCurrency effective on the UTC day of
+// at, by currency.
+func (r *Repository) ExchangeRates(ctx context.Context, baseCurrency string, at time.Time) ([]domain.ExchangeRate, error) {
+	rows, err := r.reader().Query(ctx, `
+		SELECT DISTINCT ON (currency) currency, rate_date, rate
+		FROM exchange_rates
+		WHERE base_currency = $1 AND rate_date <= $2::date
+		ORDER BY currency, rate_date DESC
+	`, baseCurrency, at.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []domain.ExchangeRate{}
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		if err := rows.Scan(&rate.Currency, &rate.Date, &rate.Rate); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rate.Date = rate.Date.UTC()
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// SaveExchangeRates saves the rates in baseCurrency, replacing the rates of the
+// same currencies and days, then converts the stored payments that have no
+// amount in baseCurrency yet and now have a rate. The currency codes of the
+// rates are in upper case, those of payments are compared in upper case.
+// Converted payments keep
+// their rate when rates are replaced later. It returns the UIDs of the orders
+// converted.
+func (r *Repository) SaveExchangeRates(ctx context.Context, baseCurrency string, rates []domain.ExchangeRate) ([]string, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	if _, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", ratesLock); err != nil {
+		return nil, fmt.Errorf("failed to lock exchange rates: %w", err)
+	}
+	currencies := make([]string, len(rates))
+	dates := make([]string, len(rates))
+	values := make([]string, len(rates))
+	for i, rate := range rates {
+		currencies[i] = rate.Currency
+		dates[i] = rate.Date.UTC().Format(time.DateOnly)
+		values[i] = rate.Rate.String()
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO exchange_rates (base_currency, currency, rate_date, rate)
+		SELECT $1::text, currency, rate_date::date, rate::numeric
+		FROM unnest($2::text[], $3::text[], $4::text[]) AS r (currency, rate_date, rate)
+		ON CONFLICT (base_currency, currency, rate_date) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			updated_at = CURRENT_TIMESTAMP
+	`, baseCurrency, currencies, dates, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save exchange rates: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH pending AS (
+			SELECT p.id,
+				CASE WHEN upper(p.currency) = $1 THEN 1 ELSE (
+					SELECT er.rate
+					FROM exchange_rates er
+					WHERE er.base_currency = $1 AND er.currency = upper(p.currency)
+						AND er.rate_date <= (o.date_created AT TIME ZONE 'UTC')::date
+					ORDER BY er.rate_date DESC
+					LIMIT 1
+				) END AS rate
+			FROM payments p
+			JOIN orders o ON o.order_uid = p.order_uid
+			WHERE p.base_currency IS DISTINCT FROM $1
+		)
+		UPDATE payments p
+		SET base_currency = $1, exchange_rate = pending.rate, base_amount = round(p.amount * pending.rate, 2)
+		FROM pending
+		WHERE p.id = pending.id AND pending.rate IS NOT NULL
+		RETURNING p.order_uid
+	`, baseCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert payments: %w", err)
+	}
+	converted, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan converted payments: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.wrote(converted...)
+	return converted, nil
+}
@@ -0,0 +1,216 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Replicas routes the read-only queries of a Repository to read replicas. A
+// replica is used while its last health check succeeded and it lagged less
+// than the configured maximum behind the primary. Orders written in the
+// read-your-writes window are read from the primary, a replica may not have
+// them yet.
+type Replicas struct {
+	log      *zap.SugaredLogger
+	cfg      config.ReplicaConfig
+	replicas []*replica
+	next     atomic.Uint64
+
+	mu     sync.Mutex
+	writes map[string]time.Time
+}
+
+// errReplicaNotStreaming is returned for a replica whose WAL receiver is not
+// streaming from the primary: it replayed everything it received, but it no
+// longer receives anything.
+var errReplicaNotStreaming = errors.New("replica is not streaming from the primary")
+
+type replica struct {
+	pool    *pgxpool.Pool
+	host    string
+	healthy atomic.Bool
+}
+
+// NewReplicas creates the replica set. The replicas are not used until the
+// first Check.
+func NewReplicas(log *zap.SugaredLogger, pools []*pgxpool.Pool, cfg config.ReplicaConfig) *Replicas {
+	replicas := make([]*replica, 0, len(pools))
+	for _, pool := range pools {
+		replicas = append(replicas, &replica{pool: pool, host: pool.Config().ConnConfig.Host})
+	}
+	return &Replicas{log: log, cfg: cfg, replicas: replicas, writes: make(map[string]time.Time)}
+}
+
+// CreateReplicaPools creates a pool per replica DSN with the pool settings of
+// the primary. The replicas are not pinged: one that is down is left out by
+// the health check instead of failing the start.
+func CreateReplicaPools(cfg config.PostgresConfig) ([]*pgxpool.Pool, error) {
+	pools := make([]*pgxpool.Pool, 0, len(cfg.Replicas.DSNs))
+	for _, dsn := range cfg.Replicas.DSNs {
+		pool, err := newPool(dsn, cfg)
+		if err != nil {
+			for _, created := range pools {
+				created.Close()
+			}
+			return nil, fmt.Errorf("failed to create replica pool: %w", err)
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// Run checks the replicas every check interval until ctx is done.
+func (r *Replicas) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Check(ctx)
+		}
+	}
+}
+
+// Check updates the health of every replica and forgets the writes that left
+// the read-your-writes window.
+func (r *Replicas) Check(ctx context.Context) {
+	for _, replica := range r.replicas {
+		lag, err := replicaLag(ctx, replica.pool, r.cfg.CheckInterval)
+		healthy := err == nil && lag <= r.cfg.MaxLag
+		if healthy != replica.healthy.Load() {
+			r.log.Infow("replica health changed", "host", replica.host, "healthy", healthy, "lag", lag, "error", err)
+		}
+		replica.healthy.Store(healthy)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for orderUID, at := range r.writes {
+		if time.Since(at) > r.cfg.ReadYourWrites {
+			delete(r.writes, orderUID)
+		}
+	}
+}
+
+// Close closes the replica pools.
+func (r *Replicas) Close() {
+	for _, replica := range r.replicas {
+		replica.pool.Close()
+	}
+}
+
+// wrote records that the orders were just written.
+func (r *Replicas) wrote(orderUIDs ...string) {
+	if r.cfg.ReadYourWrites <= 0 {
+		return
+	}
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, orderUID := range orderUIDs {
+		r.writes[orderUID] = now
+	}
+}
+
+// pool returns the next healthy replica in turn, or nil if there is none or
+// one of the orders was written in the read-your-writes window.
+func (r *Replicas) pool(orderUIDs ...string) *pgxpool.Pool {
+	if len(orderUIDs) > 0 {
+		r.mu.Lock()
+		for _, orderUID := range orderUIDs {
+			if at, ok := r.writes[orderUID]; ok && time.Since(at) <= r.cfg.ReadYourWrites {
+				r.mu.Unlock()
+				return nil
+			}
+		}
+		r.mu.Unlock()
+	}
+	for range r.replicas {
+		replica := r.replicas[r.next.Add(1)%uint64(len(r.replicas))]
+		if replica.healthy.Load() {
+			return replica.pool
+		}
+	}
+	return nil
+}
+
+// replicaStatus is what a replica reports about its replication.
+type replicaStatus struct {
+	inRecovery bool
+	// streaming is whether the WAL receiver is connected to the primary.
+	streaming bool
+	// caughtUp is whether everything received was replayed.
+	caughtUp bool
+	// replayAge is the time since the last replayed transaction.
+	replayAge time.Duration
+}
+
+// replicaLag returns how far the replica is behind the primary. A streaming
+// replica that replayed everything it received is not behind, however old its
+// last replayed transaction is. A replica without a streaming WAL receiver
+// cannot tell how far behind it is and is reported as not streaming.
+func replicaLag(ctx context.Context, pool *pgxpool.Pool, timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var status replicaStatus
+	var seconds float64
+	err := pool.QueryRow(ctx, `
+		SELECT pg_is_in_recovery(),
+			COALESCE((SELECT status = 'streaming' FROM pg_stat_wal_receiver), false),
+			COALESCE(pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn(), false),
+			COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)::float8
+	`).Scan(&status.inRecovery, &status.streaming, &status.caughtUp, &seconds)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check replica lag: %w", err)
+	}
+	status.replayAge = time.Duration(seconds * float64(time.Second))
+	return status.lag()
+}
+
+func (s replicaStatus) lag() (time.Duration, error) {
+	switch {
+	case !s.inRecovery:
+		return 0, nil
+	case !s.streaming:
+		return 0, errReplicaNotStreaming
+	case s.caughtUp:
+		return 0, nil
+	default:
+		return s.replayAge, nil
+	}
+}
+
+// WithReplicas routes the read-only queries to the replicas and returns the
+// repository.
+func (r *Repository) WithReplicas(replicas *Replicas) *Repository {
+	r.replicas = replicas
+	return r
+}
+
+// reader returns the pool for a read-only query of the orders: a healthy
+// replica, or the primary without one or for an order written just before.
+func (r *Repository) reader(orderUIDs ...string) *pgxpool.Pool {
+	if r.replicas == nil {
+		return r.DB
+	}
+	if pool := r.replicas.pool(orderUIDs...); pool != nil {
+		return pool
+	}
+	return r.DB
+}
+
+// wrote records the written orders for read-your-writes.
+func (r *Repository) wrote(orderUIDs ...string) {
+	if r.replicas != nil {
+		r.replicas.wrote(orderUIDs...)
+	}
+}
@@ -0,0 +1,154 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// newTestReplicas creates a replica set over pools that never connect: pools
+// connect lazily and the tests only compare them.
+func newTestReplicas(t *testing.T, n int, cfg config.ReplicaConfig) (*Repository, []*pgxpool.Pool) {
+	t.Helper()
+	newPool := func() *pgxpool.Pool {
+		pool, err := pgxpool.New(context.Background(), "postgres://postgres@127.0.0.1:1/postgres")
+		require.NoError(t, err)
+		t.Cleanup(pool.Close)
+		return pool
+	}
+	pools := make([]*pgxpool.Pool, 0, n)
+	for range n {
+		pools = append(pools, newPool())
+	}
+	repo := New(newPool(), nil).WithReplicas(NewReplicas(zap.NewNop().Sugar(), pools, cfg))
+	return repo, pools
+}
+
+func TestRepository_Reader(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.ReplicaConfig{MaxLag: time.Second, ReadYourWrites: time.Hour}
+
+	t.Run("without replicas", func(t *testing.T) {
+		t.Parallel()
+
+		repo := New(nil, nil)
+		assert.Nil(t, repo.reader("order-1"))
+	})
+
+	t.Run("unchecked replicas are not used", func(t *testing.T) {
+		t.Parallel()
+
+		repo, _ := newTestReplicas(t, 2, cfg)
+		assert.Same(t, repo.DB, repo.reader())
+	})
+
+	t.Run("healthy replicas in turn", func(t *testing.T) {
+		t.Parallel()
+
+		repo, pools := newTestReplicas(t, 3, cfg)
+		repo.replicas.replicas[0].healthy.Store(true)
+		repo.replicas.replicas[2].healthy.Store(true)
+
+		used := make(map[*pgxpool.Pool]int)
+		for range 10 {
+			used[repo.reader()]++
+		}
+		assert.Equal(t, 5, used[pools[0]])
+		assert.Equal(t, 5, used[pools[2]])
+		assert.Zero(t, used[pools[1]])
+		assert.Zero(t, used[repo.DB])
+	})
+
+	t.Run("written orders are read from the primary", func(t *testing.T) {
+		t.Parallel()
+
+		repo, pools := newTestReplicas(t, 1, cfg)
+		repo.replicas.replicas[0].healthy.Store(true)
+		repo.wrote("order-1")
+
+		assert.Same(t, repo.DB, repo.reader("order-1"))
+		assert.Same(t, repo.DB, repo.reader("order-2", "order-1"))
+		assert.Same(t, pools[0], repo.reader("order-2"))
+		assert.Same(t, pools[0], repo.reader())
+	})
+
+	t.Run("writes leave the window", func(t *testing.T) {
+		t.Parallel()
+
+		repo, pools := newTestReplicas(t, 1, config.ReplicaConfig{MaxLag: time.Second, ReadYourWrites: time.Millisecond})
+		repo.replicas.replicas[0].healthy.Store(true)
+		repo.wrote("order-1")
+		time.Sleep(5 * time.Millisecond)
+
+		assert.Same(t, pools[0], repo.reader("order-1"))
+	})
+
+	t.Run("disabled read your writes", func(t *testing.T) {
+		t.Parallel()
+
+		repo, pools := newTestReplicas(t, 1, config.ReplicaConfig{MaxLag: time.Second})
+		repo.replicas.replicas[0].healthy.Store(true)
+		repo.wrote("order-1")
+
+		assert.Same(t, pools[0], repo.reader("order-1"))
+	})
+}
+
+func TestReplicas_CheckExcludesUnreachable(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := newTestReplicas(t, 1, config.ReplicaConfig{CheckInterval: 100 * time.Millisecond, MaxLag: time.Second})
+	repo.replicas.replicas[0].healthy.Store(true)
+
+	repo.replicas.Check(context.Background())
+
+	assert.False(t, repo.replicas.replicas[0].healthy.Load())
+	assert.Same(t, repo.DB, repo.reader())
+}
+
+func TestReplicaStatus_Lag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  replicaStatus
+		lag     time.Duration
+		wantErr error
+	}{
+		{
+			name:   "primary",
+			status: replicaStatus{replayAge: time.Hour},
+		},
+		{
+			name:   "caught up",
+			status: replicaStatus{inRecovery: true, streaming: true, caughtUp: true, replayAge: time.Hour},
+		},
+		{
+			name:   "behind",
+			status: replicaStatus{inRecovery: true, streaming: true, replayAge: time.Minute},
+			lag:    time.Minute,
+		},
+		{
+			name:    "dead wal receiver",
+			status:  replicaStatus{inRecovery: true, caughtUp: true, replayAge: time.Hour},
+			wantErr: errReplicaNotStreaming,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lag, err := tt.status.lag()
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.lag, lag)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// deliveryText and itemText are the texts indexed by the search_vector columns,
+// see migration 00006.
+const (
+	deliveryText = `d.city || ' ' || CASE WHEN d.key_id IS NULL THEN d.name || ' ' || d.address ELSE '' END`
+	itemText     = `i.brand || ' ' || i.name`
+	headline     = `'StartSel=<mark>, StopSel=</mark>, MaxFragments=2'`
+)
+
+// Search returns orders whose delivery or items match the web search query,
+// best matches first, deleted orders left out. An order ranks by its best
+// matching row. Names and addresses of encrypted deliveries are not indexed
+// and never match.
+func (r *Repository) Search(ctx context.Context, query string, page domain.PageRequest) ([]domain.SearchHit, error) {
+	rows, err := r.reader().Query(ctx, `
+		WITH query AS (
+			SELECT websearch_to_tsquery('simple', $1) AS q
+		),
+		ranked AS (
+			SELECT order_uid, max(rank) AS rank
+			FROM (
+				SELECT d.order_uid, ts_rank(d.search_vector, query.q) AS rank
+				FROM deliveries d, query
+				WHERE d.search_vector @@ query.q
+				UNION ALL
+				SELECT i.order_uid, ts_rank(i.search_vector, query.q)
+				FROM items i, query
+				WHERE i.search_vector @@ query.q
+			) matches
+			WHERE EXISTS (SELECT 1 FROM orders o WHERE o.order_uid = matches.order_uid AND o.deleted_at IS NULL)
+			GROUP BY order_uid
+			ORDER BY rank DESC, order_uid
+			LIMIT $2 OFFSET $3
+		)
+		SELECT r.order_uid, r.rank, ARRAY(
+			SELECT ts_headline('simple', `+deliveryText+`, query.q, `+headline+`)
+			FROM deliveries d
+			WHERE d.order_uid = r.order_uid AND d.search_vector @@ query.q
+			UNION ALL
+			SELECT ts_headline('simple', `+itemText+`, query.q, `+headline+`)
+			FROM items i
+			WHERE i.order_uid = r.order_uid AND i.search_vector @@ query.q
+		)
+		FROM ranked r, query
+		ORDER BY r.rank DESC, r.order_uid
+	`, query, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	type match struct {
+		orderUID   string
+		rank       float32
+		highlights []string
+	}
+	matches, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (match, error) {
+		var m match
+		err := row.Scan(&m.orderUID, &m.rank, &m.highlights)
+		return m, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan search result: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	orderUIDs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		orderUIDs = append(orderUIDs, m.orderUID)
+	}
+	orders, err := r.GetMany(ctx, orderUIDs)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]domain.SearchHit, 0, len(matches))
+	for _, m := range matches {
+		// An order deleted after it was ranked is skipped.
+		if order, ok := orders[m.orderUID]; ok {
+			hits = append(hits, domain.SearchHit{Order: order, Rank: m.rank, Highlights: m.highlights})
+		}
+	}
+	return hits, nil
+}
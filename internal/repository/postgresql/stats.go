@@ -0,0 +1,156 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// statsLock is the key of the advisory lock held while the statistics are
+// refreshed, so instances do not refresh them at the same time.
+const statsLock = 4_900_049
+
+// statsViews are the materialized views of the statistics.
+var statsViews = []string{"stats_daily", "stats_daily_brands"}
+
+// statsColumns maps the dimensions to the columns of stats_daily.
+var statsColumns = map[domain.StatsDimension]string{
+	domain.DimensionProvider:        "provider",
+	domain.DimensionBank:            "bank",
+	domain.DimensionDeliveryService: "delivery_service",
+	domain.DimensionCurrency:        "currency",
+	domain.DimensionLocale:          "locale",
+}
+
+// RefreshStats refreshes the materialized views of the statistics without
+// blocking their readers. It reports false when another instance is refreshing
+// them.
+func (r *Repository) RefreshStats(ctx context.Context) (bool, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", statsLock).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to lock stats: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	for _, view := range statsViews {
+		if _, err := tx.Exec(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+pgx.Identifier{view}.Sanitize()); err != nil {
+			return false, fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// DailyStats returns the statistics of the days of the query as of the last
+// refresh, by day, group and currency.
+func (r *Repository) DailyStats(ctx context.Context, query domain.StatsQuery) ([]domain.DailyStats, error) {
+	group := "''::text"
+	if query.GroupBy != "" {
+		column, ok := statsColumns[query.GroupBy]
+		if !ok {
+			return nil, fmt.Errorf("unknown stats dimension %q", query.GroupBy)
+		}
+		group = column
+	}
+	rows, err := r.reader().Query(ctx, `
+		SELECT day, `+group+`, currency, sum(orders)::bigint, sum(amount), sum(delivery_cost), sum(items)::bigint,
+			sum(base_amount), sum(base_delivery_cost), sum(unconverted)::bigint
+		FROM stats_daily
+		WHERE day >= $1::date AND day < $2::date
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`, query.From.UTC().Format(time.DateOnly), query.To.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []domain.DailyStats{}
+	for rows.Next() {
+		var s domain.DailyStats
+		err := rows.Scan(
+			&s.Day,
+			&s.Group,
+			&s.Currency,
+			&s.Orders,
+			&s.Amount,
+			&s.DeliveryCost,
+			&s.Items,
+			&s.BaseAmount,
+			&s.BaseDeliveryCost,
+			&s.Unconverted,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily stats: %w", err)
+		}
+		s.Day = s.Day.UTC()
+		s.SetAvgBasketSize()
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily stats: %w", err)
+	}
+	return stats, nil
+}
+
+// BrandStats returns the sales of every brand on the days from from to to as
+// of the last refresh, by day.
+func (r *Repository) BrandStats(ctx context.Context, from, to time.Time) ([]domain.DailyBrands, error) {
+	rows, err := r.reader().Query(ctx, `
+		SELECT day, brand, currency, items, orders, revenue, base_revenue, unconverted
+		FROM stats_daily_brands
+		WHERE day >= $1::date AND day < $2::date
+		ORDER BY day, brand, currency
+	`, from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query brand stats: %w", err)
+	}
+	defer rows.Close()
+
+	days := []domain.DailyBrands{}
+	for rows.Next() {
+		var (
+			day   time.Time
+			sales domain.BrandSales
+		)
+		err := rows.Scan(
+			&day,
+			&sales.Brand,
+			&sales.Currency,
+			&sales.Items,
+			&sales.Orders,
+			&sales.Revenue,
+			&sales.BaseRevenue,
+			&sales.Unconverted,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan brand stats: %w", err)
+		}
+		day = day.UTC()
+		if len(days) == 0 || !days[len(days)-1].Day.Equal(day) {
+			days = append(days, domain.DailyBrands{Day: day})
+		}
+		days[len(days)-1].AddBrand(sales)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating brand stats: %w", err)
+	}
+	return days, nil
+}
@@ -0,0 +1,159 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// Upsert stores the order if it is new or newer than the stored one, see
+// domain.Order.IsNewerThan, and reports whether it was created. The delivery,
+// payment and items of an updated order are replaced in the same transaction.
+// An order that is not newer, or is deleted, is rejected with
+// repository.ErrStaleOrder.
+func (r *Repository) Upsert(ctx context.Context, order *domain.Order) (bool, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+	if err = lockOrderUID(ctx, tx, order.OrderUID); err != nil {
+		return false, err
+	}
+
+	var (
+		version   int64
+		updatedAt time.Time
+		deleted   bool
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT version, COALESCE(updated_at, created_at, date_created), deleted_at IS NOT NULL
+		FROM orders
+		WHERE order_uid = $1
+		FOR UPDATE
+	`, order.OrderUID).Scan(&version, &updatedAt, &deleted)
+	created := errors.Is(err, pgx.ErrNoRows)
+	switch {
+	case created:
+		if order.UpdatedAt.IsZero() {
+			order.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		}
+		err = r.insertOrder(ctx, tx, order)
+		if err == nil {
+			err = r.appendEvent(ctx, tx, domain.EventOrderCreated, nil, order)
+		}
+	case err != nil:
+		return false, fmt.Errorf("failed to lock order: %w", err)
+	case deleted:
+		return false, fmt.Errorf("%w: order is deleted", repository.ErrStaleOrder)
+	case !order.IsNewerThan(version, updatedAt):
+		return false, fmt.Errorf("%w: version %d, stored %d", repository.ErrStaleOrder, order.Version, version)
+	default:
+		if order.Version == 0 {
+			order.Version = version + 1
+		}
+		if order.UpdatedAt.IsZero() {
+			order.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		}
+		err = r.replaceOrder(ctx, tx, order)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.wrote(order.OrderUID)
+	return created, nil
+}
+
+// Update replaces the order if its stored version is still expectedVersion and
+// bumps the version. It fails with repository.ErrVersionConflict if the order
+// was changed in between.
+func (r *Repository) Update(ctx context.Context, order *domain.Order, expectedVersion int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			return
+		}
+	}(tx, ctx)
+
+	var version int64
+	err = tx.QueryRow(ctx, "SELECT version FROM orders WHERE order_uid = $1 AND deleted_at IS NULL FOR UPDATE", order.OrderUID).Scan(&version)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return repository.ErrOrderNotFound
+	case err != nil:
+		return fmt.Errorf("failed to lock order: %w", err)
+	case version != expectedVersion:
+		return fmt.Errorf("%w: expected version %d, stored %d", repository.ErrVersionConflict, expectedVersion, version)
+	}
+
+	order.Version = version + 1
+	if err := r.replaceOrder(ctx, tx, order); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.wrote(order.OrderUID)
+	return nil
+}
+
+// replaceOrder overwrites the locked order row, replaces its delivery,
+// payment and items and records the change.
+func (r *Repository) replaceOrder(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	before, err := r.readOrder(ctx, tx, order.OrderUID, domain.AllParts)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE orders SET
+			track_number = $2, entry = $3, locale = $4, internal_signature = $5,
+			customer_id = $6, delivery_service = $7, shardkey = $8, sm_id = $9,
+			date_created = $10, oof_shard = $11, updated_at = $12, version = $13
+		WHERE order_uid = $1
+	`,
+		order.OrderUID,
+		order.TrackNumber,
+		order.Entry,
+		order.Locale,
+		order.InternalSignature,
+		order.CustomerID,
+		order.DeliveryService,
+		order.ShardKey,
+		order.SmID,
+		order.DateCreated,
+		order.OofShard,
+		order.UpdatedAt,
+		order.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	for _, table := range []string{"items", "payments", "deliveries"} {
+		if _, err := tx.Exec(ctx, "DELETE FROM "+table+" WHERE order_uid = $1", order.OrderUID); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+	if err := r.insertParts(ctx, tx, order); err != nil {
+		return err
+	}
+	return r.appendEvent(ctx, tx, domain.EventOrderUpdated, before, order)
+}
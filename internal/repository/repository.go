@@ -14,6 +14,10 @@ var (
 	ErrPaymentNotFound  = errors.New("payment not found")
 	ErrItemsNotFound    = errors.New("items not found")
 	ErrDuplicateOrder   = errors.New("duplicate order")
+	ErrCustomerNotFound = errors.New("customer not found")
+	ErrStaleOrder       = errors.New("stale order version")
+	ErrVersionConflict  = errors.New("order version conflict")
+	ErrRateNotFound     = errors.New("exchange rate not found")
 )
 
 type OrderProvider interface {
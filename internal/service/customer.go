@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+)
+
+// GetCustomer returns the customer with the address book.
+func (s *Service) GetCustomer(ctx context.Context, customerID string) (*domain.Customer, error) {
+	customer, err := s.repo.GetCustomer(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCustomerNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, customerID)
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	return customer, nil
+}
+
+// ListCustomerOrders returns a page of the orders of the customer, newest
+// first, with the order count and the lifetime value of all of them, also in
+// the base currency when amounts are converted. A customer whose orders are
+// all deleted has an empty page.
+func (s *Service) ListCustomerOrders(ctx context.Context, customerID string, pageSize int, pageToken string) (*domain.CustomerOrders, error) {
+	stats, err := s.repo.CustomerStats(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer stats: %w", err)
+	}
+	stats.LifetimeValueBase = s.baseTotal(stats.LifetimeValueBase)
+	if stats.OrderCount == 0 {
+		if _, err := s.GetCustomer(ctx, customerID); err != nil {
+			return nil, err
+		}
+	}
+	page, err := s.listPage(ctx, domain.OrderFilter{CustomerID: customerID}, pageSize, pageToken, s.repo.List)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CustomerOrders{
+		CustomerID:    customerID,
+		CustomerStats: *stats,
+		Orders:        page.Orders,
+		NextCursor:    page.NextCursor,
+	}, nil
+}
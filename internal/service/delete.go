@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"time"
+)
+
+// DeleteOrder soft-deletes the order and evicts it from the cache, so that it
+// is no longer found. It can be restored until it is purged.
+func (s *Service) DeleteOrder(ctx context.Context, uid string) error {
+	order, err := s.repo.Delete(ctx, uid, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			return fmt.Errorf("%w: %s", ErrOrderNotFound, uid)
+		}
+		return fmt.Errorf("failed to delete order: %w", err)
+	}
+	s.evict(ctx, uid)
+	s.publisher.Publish(domain.EventOrderDeleted, order)
+	return nil
+}
+
+// RestoreOrder restores a soft-deleted order and returns it.
+func (s *Service) RestoreOrder(ctx context.Context, uid string) (*domain.Order, error) {
+	order, err := s.repo.Undelete(ctx, uid, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			return nil, fmt.Errorf("%w: no deleted order %s", ErrOrderNotFound, uid)
+		}
+		return nil, fmt.Errorf("failed to restore order: %w", err)
+	}
+	s.publisher.Publish(domain.EventOrderRestored, order)
+	s.cacheOrders(ctx, []domain.Order{*order})
+	return order, nil
+}
+
+// PurgeDeletedOrders hard-deletes the orders deleted more than retention ago,
+// batchSize orders per transaction, and returns how many it removed.
+func (s *Service) PurgeDeletedOrders(ctx context.Context, retention time.Duration, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("invalid purge batch size %d", batchSize)
+	}
+	deletedBefore := time.Now().Add(-retention)
+	var purged int
+	for {
+		n, err := s.repo.Purge(ctx, deletedBefore, batchSize)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+		if n < batchSize {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"time"
+)
+
+// GetOrderHistory returns the recorded changes of the order. With a non-zero
+// asOf only the changes up to it are returned, along with the order as it was
+// then. Orders stored before the history was kept have none until they change.
+// The history holds the delivery contact fields masked, so the rebuilt order
+// never recovers them, even for roles that see them on the live order.
+func (s *Service) GetOrderHistory(ctx context.Context, uid string, asOf time.Time) (*domain.OrderHistory, error) {
+	events, err := s.repo.History(ctx, uid, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order history: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: no history for %s", ErrOrderNotFound, uid)
+	}
+	history := &domain.OrderHistory{OrderUID: uid, Events: events}
+	if !asOf.IsZero() {
+		history.Order, err = s.repo.OrderAsOf(ctx, uid, asOf)
+		if err != nil {
+			return nil, repositoryError(err, uid)
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListOrders returns a page of orders matching the filter, newest first.
+// pageToken is the NextCursor of the previous page, empty for the first one.
+// Pages are always read from the database: the cache indexes only hold the
+// orders cached recently, so a page built from them can miss older orders.
+func (s *Service) ListOrders(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string) (*domain.OrderPage, error) {
+	return s.listPage(ctx, filter, pageSize, pageToken, s.repo.List)
+}
+
+// ListOrderHeaders is ListOrders without deliveries, payments and items, for
+// callers that load them separately only when they need them.
+func (s *Service) ListOrderHeaders(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string) (*domain.OrderPage, error) {
+	return s.listPage(ctx, filter, pageSize, pageToken, s.repo.ListHeaders)
+}
+
+func (s *Service) GetDeliveries(ctx context.Context, orderUIDs []string) (map[string]domain.Delivery, error) {
+	deliveries, err := s.repo.GetDeliveries(ctx, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *Service) GetPayments(ctx context.Context, orderUIDs []string) (map[string]domain.Payment, error) {
+	payments, err := s.repo.GetPayments(ctx, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	return payments, nil
+}
+
+func (s *Service) GetItems(ctx context.Context, orderUIDs []string) (map[string][]domain.Item, error) {
+	items, err := s.repo.GetItems(ctx, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+	return items, nil
+}
+
+type listFunc func(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error)
+
+func (s *Service) listPage(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string, list listFunc) (*domain.OrderPage, error) {
+	switch {
+	case pageSize < 0:
+		return nil, fmt.Errorf("%w: negative page size", ErrInvalidQuery)
+	case pageSize == 0:
+		pageSize = DefaultPageSize
+	case pageSize > MaxPageSize:
+		pageSize = MaxPageSize
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", ErrInvalidQuery)
+	}
+	page := domain.PageRequest{Limit: pageSize + 1}
+	if pageToken != "" {
+		cursor, err := domain.DecodeCursor(pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+		page.After = &cursor
+	}
+
+	orders, err := list(ctx, filter, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	result := &domain.OrderPage{Orders: orders}
+	// One extra order is requested to know whether there is a next page.
+	if len(orders) > pageSize {
+		result.Orders = orders[:pageSize]
+		last := result.Orders[pageSize-1]
+		result.NextCursor = domain.Cursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}.Encode()
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"go.uber.org/zap"
+)
+
+const MaxLookupSize = 1000
+
+// LookupOrders returns the orders with the given UIDs. Cached orders are read
+// in one round trip, the rest with one query per table, and are cached after.
+// Duplicate UIDs are returned once.
+func (s *Service) LookupOrders(ctx context.Context, orderUIDs []string) (*domain.OrderLookup, error) {
+	orderUIDs = uniqueUIDs(orderUIDs)
+	switch {
+	case len(orderUIDs) == 0:
+		return nil, fmt.Errorf("%w: no order UIDs", ErrInvalidQuery)
+	case len(orderUIDs) > MaxLookupSize:
+		return nil, fmt.Errorf("%w: more than %d order UIDs", ErrInvalidQuery, MaxLookupSize)
+	}
+
+	cacheCtx, cancel := context.WithTimeout(ctx, cacheTimeout)
+	defer cancel()
+	found, err := s.cache.GetMany(cacheCtx, orderUIDs)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, fmt.Errorf("cache operation canceled: %w", err)
+		}
+		zap.L().Warn("cache error, falling back to database", zap.Int("orders", len(orderUIDs)), zap.Error(err))
+		found = make(map[string]domain.Order, len(orderUIDs))
+	}
+
+	misses := make([]string, 0, len(orderUIDs)-len(found))
+	for _, uid := range orderUIDs {
+		if _, ok := found[uid]; !ok {
+			misses = append(misses, uid)
+		}
+	}
+	if len(misses) > 0 {
+		loaded, err := s.repo.GetMany(ctx, misses)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrDeliveryNotFound),
+				errors.Is(err, repository.ErrPaymentNotFound),
+				errors.Is(err, repository.ErrItemsNotFound):
+				return nil, fmt.Errorf("%w: %w", ErrInvalidOrderData, err)
+			default:
+				return nil, fmt.Errorf("failed to get orders from database: %w", err)
+			}
+		}
+		backfill := make([]domain.Order, 0, len(loaded))
+		for _, order := range loaded {
+			found[order.OrderUID] = order
+			backfill = append(backfill, order)
+		}
+		s.cacheOrders(ctx, backfill)
+	}
+
+	lookup := &domain.OrderLookup{
+		Orders:  make([]domain.Order, 0, len(found)),
+		Missing: make([]string, 0, len(orderUIDs)-len(found)),
+	}
+	for _, uid := range orderUIDs {
+		if order, ok := found[uid]; ok {
+			lookup.Orders = append(lookup.Orders, order)
+		} else {
+			lookup.Missing = append(lookup.Missing, uid)
+		}
+	}
+	return lookup, nil
+}
+
+// GetOrderByTransaction returns the order paid with the payment transaction.
+// The cache index is only a hint, the order it points to is checked.
+func (s *Service) GetOrderByTransaction(ctx context.Context, transaction string) (*domain.Order, error) {
+	cacheCtx, cancel := context.WithTimeout(ctx, cacheTimeout)
+	defer cancel()
+	uid, err := s.cache.GetUIDByTransaction(cacheCtx, transaction)
+	if err == nil {
+		order, err := s.GetOrder(ctx, uid)
+		if err == nil && order.Payment.Transaction == transaction {
+			return order, nil
+		}
+		if errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	} else if !errors.Is(err, repository.ErrOrderNotFound) {
+		zap.L().Warn("cache error, falling back to database", zap.String("transaction", transaction), zap.Error(err))
+	}
+
+	order, err := s.repo.GetByTransaction(ctx, transaction)
+	if err != nil {
+		return nil, repositoryError(err, "with transaction "+transaction)
+	}
+	s.cacheOrders(ctx, []domain.Order{*order})
+	return order, nil
+}
+
+func (s *Service) cacheOrders(ctx context.Context, orders []domain.Order) {
+	if len(orders) == 0 {
+		return
+	}
+	s.wg.Go(func() {
+		cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheTimeout)
+		defer cancel()
+		for i := range orders {
+			if err := s.cache.Set(cacheCtx, &orders[i]); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					zap.L().Warn("failed to cache order", zap.String("order_uid", orders[i].OrderUID), zap.Error(err))
+				}
+				return
+			}
+		}
+	})
+}
+
+func uniqueUIDs(orderUIDs []string) []string {
+	seen := make(map[string]struct{}, len(orderUIDs))
+	unique := make([]string, 0, len(orderUIDs))
+	for _, uid := range orderUIDs {
+		if _, ok := seen[uid]; ok || uid == "" {
+			continue
+		}
+		seen[uid] = struct{}{}
+		unique = append(unique, uid)
+	}
+	return unique
+}
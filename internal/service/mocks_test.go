@@ -6,8 +6,10 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/Killazius/L0/internal/domain"
+	"github.com/shopspring/decimal"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -38,65 +40,2256 @@ func (_m *MockOrderRepository) EXPECT() *MockOrderRepository_Expecter {
 	return &MockOrderRepository_Expecter{mock: &_m.Mock}
 }
 
+// BrandStats provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) BrandStats(ctx context.Context, from time.Time, to time.Time) ([]domain.DailyBrands, error) {
+	ret := _mock.Called(ctx, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BrandStats")
+	}
+
+	var r0 []domain.DailyBrands
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) ([]domain.DailyBrands, error)); ok {
+		return returnFunc(ctx, from, to)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []domain.DailyBrands); ok {
+		r0 = returnFunc(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.DailyBrands)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = returnFunc(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_BrandStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BrandStats'
+type MockOrderRepository_BrandStats_Call struct {
+	*mock.Call
+}
+
+// BrandStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - from time.Time
+//   - to time.Time
+func (_e *MockOrderRepository_Expecter) BrandStats(ctx interface{}, from interface{}, to interface{}) *MockOrderRepository_BrandStats_Call {
+	return &MockOrderRepository_BrandStats_Call{Call: _e.mock.On("BrandStats", ctx, from, to)}
+}
+
+func (_c *MockOrderRepository_BrandStats_Call) Run(run func(ctx context.Context, from time.Time, to time.Time)) *MockOrderRepository_BrandStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_BrandStats_Call) Return(dailyBrandss []domain.DailyBrands, err error) *MockOrderRepository_BrandStats_Call {
+	_c.Call.Return(dailyBrandss, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_BrandStats_Call) RunAndReturn(run func(ctx context.Context, from time.Time, to time.Time) ([]domain.DailyBrands, error)) *MockOrderRepository_BrandStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function for the type MockOrderRepository
 func (_mock *MockOrderRepository) Create(ctx context.Context, order *domain.Order) error {
 	ret := _mock.Called(ctx, order)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Create")
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *domain.Order) error); ok {
+		r0 = returnFunc(ctx, order)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockOrderRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - order *domain.Order
+func (_e *MockOrderRepository_Expecter) Create(ctx interface{}, order interface{}) *MockOrderRepository_Create_Call {
+	return &MockOrderRepository_Create_Call{Call: _e.mock.On("Create", ctx, order)}
+}
+
+func (_c *MockOrderRepository_Create_Call) Run(run func(ctx context.Context, order *domain.Order)) *MockOrderRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *domain.Order
+		if args[1] != nil {
+			arg1 = args[1].(*domain.Order)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Create_Call) Return(err error) *MockOrderRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Create_Call) RunAndReturn(run func(ctx context.Context, order *domain.Order) error) *MockOrderRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CustomerStats provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) CustomerStats(ctx context.Context, customerID string) (*domain.CustomerStats, error) {
+	ret := _mock.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CustomerStats")
+	}
+
+	var r0 *domain.CustomerStats
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.CustomerStats, error)); ok {
+		return returnFunc(ctx, customerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.CustomerStats); ok {
+		r0 = returnFunc(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CustomerStats)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_CustomerStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerStats'
+type MockOrderRepository_CustomerStats_Call struct {
+	*mock.Call
+}
+
+// CustomerStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+func (_e *MockOrderRepository_Expecter) CustomerStats(ctx interface{}, customerID interface{}) *MockOrderRepository_CustomerStats_Call {
+	return &MockOrderRepository_CustomerStats_Call{Call: _e.mock.On("CustomerStats", ctx, customerID)}
+}
+
+func (_c *MockOrderRepository_CustomerStats_Call) Run(run func(ctx context.Context, customerID string)) *MockOrderRepository_CustomerStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_CustomerStats_Call) Return(customerStats *domain.CustomerStats, err error) *MockOrderRepository_CustomerStats_Call {
+	_c.Call.Return(customerStats, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_CustomerStats_Call) RunAndReturn(run func(ctx context.Context, customerID string) (*domain.CustomerStats, error)) *MockOrderRepository_CustomerStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DailyStats provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) DailyStats(ctx context.Context, query domain.StatsQuery) ([]domain.DailyStats, error) {
+	ret := _mock.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DailyStats")
+	}
+
+	var r0 []domain.DailyStats
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.StatsQuery) ([]domain.DailyStats, error)); ok {
+		return returnFunc(ctx, query)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.StatsQuery) []domain.DailyStats); ok {
+		r0 = returnFunc(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.DailyStats)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.StatsQuery) error); ok {
+		r1 = returnFunc(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_DailyStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DailyStats'
+type MockOrderRepository_DailyStats_Call struct {
+	*mock.Call
+}
+
+// DailyStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query domain.StatsQuery
+func (_e *MockOrderRepository_Expecter) DailyStats(ctx interface{}, query interface{}) *MockOrderRepository_DailyStats_Call {
+	return &MockOrderRepository_DailyStats_Call{Call: _e.mock.On("DailyStats", ctx, query)}
+}
+
+func (_c *MockOrderRepository_DailyStats_Call) Run(run func(ctx context.Context, query domain.StatsQuery)) *MockOrderRepository_DailyStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.StatsQuery
+		if args[1] != nil {
+			arg1 = args[1].(domain.StatsQuery)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_DailyStats_Call) Return(dailyStatss []domain.DailyStats, err error) *MockOrderRepository_DailyStats_Call {
+	_c.Call.Return(dailyStatss, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_DailyStats_Call) RunAndReturn(run func(ctx context.Context, query domain.StatsQuery) ([]domain.DailyStats, error)) *MockOrderRepository_DailyStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) Delete(ctx context.Context, orderUID string, deletedAt time.Time) (*domain.Order, error) {
+	ret := _mock.Called(ctx, orderUID, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) (*domain.Order, error)); ok {
+		return returnFunc(ctx, orderUID, deletedAt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) *domain.Order); ok {
+		r0 = returnFunc(ctx, orderUID, deletedAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, orderUID, deletedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockOrderRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+//   - deletedAt time.Time
+func (_e *MockOrderRepository_Expecter) Delete(ctx interface{}, orderUID interface{}, deletedAt interface{}) *MockOrderRepository_Delete_Call {
+	return &MockOrderRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, orderUID, deletedAt)}
+}
+
+func (_c *MockOrderRepository_Delete_Call) Run(run func(ctx context.Context, orderUID string, deletedAt time.Time)) *MockOrderRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Delete_Call) Return(order *domain.Order, err error) *MockOrderRepository_Delete_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, orderUID string, deletedAt time.Time) (*domain.Order, error)) *MockOrderRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DetachPartitions provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) DetachPartitions(ctx context.Context, before time.Time) ([]string, error) {
+	ret := _mock.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DetachPartitions")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) ([]string, error)); ok {
+		return returnFunc(ctx, before)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) []string); ok {
+		r0 = returnFunc(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = returnFunc(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_DetachPartitions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DetachPartitions'
+type MockOrderRepository_DetachPartitions_Call struct {
+	*mock.Call
+}
+
+// DetachPartitions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+func (_e *MockOrderRepository_Expecter) DetachPartitions(ctx interface{}, before interface{}) *MockOrderRepository_DetachPartitions_Call {
+	return &MockOrderRepository_DetachPartitions_Call{Call: _e.mock.On("DetachPartitions", ctx, before)}
+}
+
+func (_c *MockOrderRepository_DetachPartitions_Call) Run(run func(ctx context.Context, before time.Time)) *MockOrderRepository_DetachPartitions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_DetachPartitions_Call) Return(strings []string, err error) *MockOrderRepository_DetachPartitions_Call {
+	_c.Call.Return(strings, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_DetachPartitions_Call) RunAndReturn(run func(ctx context.Context, before time.Time) ([]string, error)) *MockOrderRepository_DetachPartitions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnsurePartitions provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) EnsurePartitions(ctx context.Context, from time.Time, to time.Time) (int, error) {
+	ret := _mock.Called(ctx, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnsurePartitions")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) (int, error)); ok {
+		return returnFunc(ctx, from, to)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) int); ok {
+		r0 = returnFunc(ctx, from, to)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = returnFunc(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_EnsurePartitions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnsurePartitions'
+type MockOrderRepository_EnsurePartitions_Call struct {
+	*mock.Call
+}
+
+// EnsurePartitions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - from time.Time
+//   - to time.Time
+func (_e *MockOrderRepository_Expecter) EnsurePartitions(ctx interface{}, from interface{}, to interface{}) *MockOrderRepository_EnsurePartitions_Call {
+	return &MockOrderRepository_EnsurePartitions_Call{Call: _e.mock.On("EnsurePartitions", ctx, from, to)}
+}
+
+func (_c *MockOrderRepository_EnsurePartitions_Call) Run(run func(ctx context.Context, from time.Time, to time.Time)) *MockOrderRepository_EnsurePartitions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_EnsurePartitions_Call) Return(n int, err error) *MockOrderRepository_EnsurePartitions_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_EnsurePartitions_Call) RunAndReturn(run func(ctx context.Context, from time.Time, to time.Time) (int, error)) *MockOrderRepository_EnsurePartitions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EraseCustomer provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) EraseCustomer(ctx context.Context, customerID string, erasure *domain.Erasure) error {
+	ret := _mock.Called(ctx, customerID, erasure)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseCustomer")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *domain.Erasure) error); ok {
+		r0 = returnFunc(ctx, customerID, erasure)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderRepository_EraseCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EraseCustomer'
+type MockOrderRepository_EraseCustomer_Call struct {
+	*mock.Call
+}
+
+// EraseCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+//   - erasure *domain.Erasure
+func (_e *MockOrderRepository_Expecter) EraseCustomer(ctx interface{}, customerID interface{}, erasure interface{}) *MockOrderRepository_EraseCustomer_Call {
+	return &MockOrderRepository_EraseCustomer_Call{Call: _e.mock.On("EraseCustomer", ctx, customerID, erasure)}
+}
+
+func (_c *MockOrderRepository_EraseCustomer_Call) Run(run func(ctx context.Context, customerID string, erasure *domain.Erasure)) *MockOrderRepository_EraseCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *domain.Erasure
+		if args[2] != nil {
+			arg2 = args[2].(*domain.Erasure)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_EraseCustomer_Call) Return(err error) *MockOrderRepository_EraseCustomer_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderRepository_EraseCustomer_Call) RunAndReturn(run func(ctx context.Context, customerID string, erasure *domain.Erasure) error) *MockOrderRepository_EraseCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExchangeRate provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) ExchangeRate(ctx context.Context, baseCurrency string, currency string, at time.Time) (decimal.Decimal, error) {
+	ret := _mock.Called(ctx, baseCurrency, currency, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExchangeRate")
+	}
+
+	var r0 decimal.Decimal
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, time.Time) (decimal.Decimal, error)); ok {
+		return returnFunc(ctx, baseCurrency, currency, at)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, time.Time) decimal.Decimal); ok {
+		r0 = returnFunc(ctx, baseCurrency, currency, at)
+	} else {
+		r0 = ret.Get(0).(decimal.Decimal)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, baseCurrency, currency, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_ExchangeRate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExchangeRate'
+type MockOrderRepository_ExchangeRate_Call struct {
+	*mock.Call
+}
+
+// ExchangeRate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - baseCurrency string
+//   - currency string
+//   - at time.Time
+func (_e *MockOrderRepository_Expecter) ExchangeRate(ctx interface{}, baseCurrency interface{}, currency interface{}, at interface{}) *MockOrderRepository_ExchangeRate_Call {
+	return &MockOrderRepository_ExchangeRate_Call{Call: _e.mock.On("ExchangeRate", ctx, baseCurrency, currency, at)}
+}
+
+func (_c *MockOrderRepository_ExchangeRate_Call) Run(run func(ctx context.Context, baseCurrency string, currency string, at time.Time)) *MockOrderRepository_ExchangeRate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_ExchangeRate_Call) Return(decimal1 decimal.Decimal, err error) *MockOrderRepository_ExchangeRate_Call {
+	_c.Call.Return(decimal1, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_ExchangeRate_Call) RunAndReturn(run func(ctx context.Context, baseCurrency string, currency string, at time.Time) (decimal.Decimal, error)) *MockOrderRepository_ExchangeRate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExchangeRates provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) ExchangeRates(ctx context.Context, baseCurrency string, at time.Time) ([]domain.ExchangeRate, error) {
+	ret := _mock.Called(ctx, baseCurrency, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExchangeRates")
+	}
+
+	var r0 []domain.ExchangeRate
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]domain.ExchangeRate, error)); ok {
+		return returnFunc(ctx, baseCurrency, at)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) []domain.ExchangeRate); ok {
+		r0 = returnFunc(ctx, baseCurrency, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ExchangeRate)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, baseCurrency, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_ExchangeRates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExchangeRates'
+type MockOrderRepository_ExchangeRates_Call struct {
+	*mock.Call
+}
+
+// ExchangeRates is a helper method to define mock.On call
+//   - ctx context.Context
+//   - baseCurrency string
+//   - at time.Time
+func (_e *MockOrderRepository_Expecter) ExchangeRates(ctx interface{}, baseCurrency interface{}, at interface{}) *MockOrderRepository_ExchangeRates_Call {
+	return &MockOrderRepository_ExchangeRates_Call{Call: _e.mock.On("ExchangeRates", ctx, baseCurrency, at)}
+}
+
+func (_c *MockOrderRepository_ExchangeRates_Call) Run(run func(ctx context.Context, baseCurrency string, at time.Time)) *MockOrderRepository_ExchangeRates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_ExchangeRates_Call) Return(exchangeRates []domain.ExchangeRate, err error) *MockOrderRepository_ExchangeRates_Call {
+	_c.Call.Return(exchangeRates, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_ExchangeRates_Call) RunAndReturn(run func(ctx context.Context, baseCurrency string, at time.Time) ([]domain.ExchangeRate, error)) *MockOrderRepository_ExchangeRates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
+	ret := _mock.Called(ctx, orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Order, error)); ok {
+		return returnFunc(ctx, orderUID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Order); ok {
+		r0 = returnFunc(ctx, orderUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, orderUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockOrderRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+func (_e *MockOrderRepository_Expecter) Get(ctx interface{}, orderUID interface{}) *MockOrderRepository_Get_Call {
+	return &MockOrderRepository_Get_Call{Call: _e.mock.On("Get", ctx, orderUID)}
+}
+
+func (_c *MockOrderRepository_Get_Call) Run(run func(ctx context.Context, orderUID string)) *MockOrderRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Get_Call) Return(order *domain.Order, err error) *MockOrderRepository_Get_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Get_Call) RunAndReturn(run func(ctx context.Context, orderUID string) (*domain.Order, error)) *MockOrderRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAll provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetAll(ctx context.Context) ([]domain.Order, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]domain.Order, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []domain.Order); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAll'
+type MockOrderRepository_GetAll_Call struct {
+	*mock.Call
+}
+
+// GetAll is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockOrderRepository_Expecter) GetAll(ctx interface{}) *MockOrderRepository_GetAll_Call {
+	return &MockOrderRepository_GetAll_Call{Call: _e.mock.On("GetAll", ctx)}
+}
+
+func (_c *MockOrderRepository_GetAll_Call) Run(run func(ctx context.Context)) *MockOrderRepository_GetAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetAll_Call) Return(orders []domain.Order, err error) *MockOrderRepository_GetAll_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetAll_Call) RunAndReturn(run func(ctx context.Context) ([]domain.Order, error)) *MockOrderRepository_GetAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByCustomer provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetByCustomer(ctx context.Context, customerID string) ([]domain.Order, error) {
+	ret := _mock.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCustomer")
+	}
+
+	var r0 []domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]domain.Order, error)); ok {
+		return returnFunc(ctx, customerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []domain.Order); ok {
+		r0 = returnFunc(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetByCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByCustomer'
+type MockOrderRepository_GetByCustomer_Call struct {
+	*mock.Call
+}
+
+// GetByCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+func (_e *MockOrderRepository_Expecter) GetByCustomer(ctx interface{}, customerID interface{}) *MockOrderRepository_GetByCustomer_Call {
+	return &MockOrderRepository_GetByCustomer_Call{Call: _e.mock.On("GetByCustomer", ctx, customerID)}
+}
+
+func (_c *MockOrderRepository_GetByCustomer_Call) Run(run func(ctx context.Context, customerID string)) *MockOrderRepository_GetByCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByCustomer_Call) Return(orders []domain.Order, err error) *MockOrderRepository_GetByCustomer_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByCustomer_Call) RunAndReturn(run func(ctx context.Context, customerID string) ([]domain.Order, error)) *MockOrderRepository_GetByCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByTransaction provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetByTransaction(ctx context.Context, transaction string) (*domain.Order, error) {
+	ret := _mock.Called(ctx, transaction)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByTransaction")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Order, error)); ok {
+		return returnFunc(ctx, transaction)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Order); ok {
+		r0 = returnFunc(ctx, transaction)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, transaction)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetByTransaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByTransaction'
+type MockOrderRepository_GetByTransaction_Call struct {
+	*mock.Call
+}
+
+// GetByTransaction is a helper method to define mock.On call
+//   - ctx context.Context
+//   - transaction string
+func (_e *MockOrderRepository_Expecter) GetByTransaction(ctx interface{}, transaction interface{}) *MockOrderRepository_GetByTransaction_Call {
+	return &MockOrderRepository_GetByTransaction_Call{Call: _e.mock.On("GetByTransaction", ctx, transaction)}
+}
+
+func (_c *MockOrderRepository_GetByTransaction_Call) Run(run func(ctx context.Context, transaction string)) *MockOrderRepository_GetByTransaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByTransaction_Call) Return(order *domain.Order, err error) *MockOrderRepository_GetByTransaction_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetByTransaction_Call) RunAndReturn(run func(ctx context.Context, transaction string) (*domain.Order, error)) *MockOrderRepository_GetByTransaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCustomer provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetCustomer(ctx context.Context, customerID string) (*domain.Customer, error) {
+	ret := _mock.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomer")
+	}
+
+	var r0 *domain.Customer
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Customer, error)); ok {
+		return returnFunc(ctx, customerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Customer); ok {
+		r0 = returnFunc(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Customer)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCustomer'
+type MockOrderRepository_GetCustomer_Call struct {
+	*mock.Call
+}
+
+// GetCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+func (_e *MockOrderRepository_Expecter) GetCustomer(ctx interface{}, customerID interface{}) *MockOrderRepository_GetCustomer_Call {
+	return &MockOrderRepository_GetCustomer_Call{Call: _e.mock.On("GetCustomer", ctx, customerID)}
+}
+
+func (_c *MockOrderRepository_GetCustomer_Call) Run(run func(ctx context.Context, customerID string)) *MockOrderRepository_GetCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetCustomer_Call) Return(customer *domain.Customer, err error) *MockOrderRepository_GetCustomer_Call {
+	_c.Call.Return(customer, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetCustomer_Call) RunAndReturn(run func(ctx context.Context, customerID string) (*domain.Customer, error)) *MockOrderRepository_GetCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDeliveries provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetDeliveries(ctx context.Context, orderUIDs []string) (map[string]domain.Delivery, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeliveries")
+	}
+
+	var r0 map[string]domain.Delivery
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (map[string]domain.Delivery, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) map[string]domain.Delivery); ok {
+		r0 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]domain.Delivery)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderUIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeliveries'
+type MockOrderRepository_GetDeliveries_Call struct {
+	*mock.Call
+}
+
+// GetDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUIDs []string
+func (_e *MockOrderRepository_Expecter) GetDeliveries(ctx interface{}, orderUIDs interface{}) *MockOrderRepository_GetDeliveries_Call {
+	return &MockOrderRepository_GetDeliveries_Call{Call: _e.mock.On("GetDeliveries", ctx, orderUIDs)}
+}
+
+func (_c *MockOrderRepository_GetDeliveries_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderRepository_GetDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetDeliveries_Call) Return(stringToDelivery map[string]domain.Delivery, err error) *MockOrderRepository_GetDeliveries_Call {
+	_c.Call.Return(stringToDelivery, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetDeliveries_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) (map[string]domain.Delivery, error)) *MockOrderRepository_GetDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetItems provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetItems(ctx context.Context, orderUIDs []string) (map[string][]domain.Item, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItems")
+	}
+
+	var r0 map[string][]domain.Item
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (map[string][]domain.Item, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) map[string][]domain.Item); ok {
+		r0 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]domain.Item)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderUIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItems'
+type MockOrderRepository_GetItems_Call struct {
+	*mock.Call
+}
+
+// GetItems is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUIDs []string
+func (_e *MockOrderRepository_Expecter) GetItems(ctx interface{}, orderUIDs interface{}) *MockOrderRepository_GetItems_Call {
+	return &MockOrderRepository_GetItems_Call{Call: _e.mock.On("GetItems", ctx, orderUIDs)}
+}
+
+func (_c *MockOrderRepository_GetItems_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderRepository_GetItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetItems_Call) Return(stringToItems map[string][]domain.Item, err error) *MockOrderRepository_GetItems_Call {
+	_c.Call.Return(stringToItems, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetItems_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) (map[string][]domain.Item, error)) *MockOrderRepository_GetItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMany provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetMany(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMany")
+	}
+
+	var r0 map[string]domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (map[string]domain.Order, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) map[string]domain.Order); ok {
+		r0 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderUIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMany'
+type MockOrderRepository_GetMany_Call struct {
+	*mock.Call
+}
+
+// GetMany is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUIDs []string
+func (_e *MockOrderRepository_Expecter) GetMany(ctx interface{}, orderUIDs interface{}) *MockOrderRepository_GetMany_Call {
+	return &MockOrderRepository_GetMany_Call{Call: _e.mock.On("GetMany", ctx, orderUIDs)}
+}
+
+func (_c *MockOrderRepository_GetMany_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderRepository_GetMany_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetMany_Call) Return(stringToOrder map[string]domain.Order, err error) *MockOrderRepository_GetMany_Call {
+	_c.Call.Return(stringToOrder, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetMany_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error)) *MockOrderRepository_GetMany_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetParts provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetParts(ctx context.Context, orderUID string, parts domain.Part) (*domain.Order, error) {
+	ret := _mock.Called(ctx, orderUID, parts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetParts")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, domain.Part) (*domain.Order, error)); ok {
+		return returnFunc(ctx, orderUID, parts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, domain.Part) *domain.Order); ok {
+		r0 = returnFunc(ctx, orderUID, parts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, domain.Part) error); ok {
+		r1 = returnFunc(ctx, orderUID, parts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetParts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetParts'
+type MockOrderRepository_GetParts_Call struct {
+	*mock.Call
+}
+
+// GetParts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+//   - parts domain.Part
+func (_e *MockOrderRepository_Expecter) GetParts(ctx interface{}, orderUID interface{}, parts interface{}) *MockOrderRepository_GetParts_Call {
+	return &MockOrderRepository_GetParts_Call{Call: _e.mock.On("GetParts", ctx, orderUID, parts)}
+}
+
+func (_c *MockOrderRepository_GetParts_Call) Run(run func(ctx context.Context, orderUID string, parts domain.Part)) *MockOrderRepository_GetParts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 domain.Part
+		if args[2] != nil {
+			arg2 = args[2].(domain.Part)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetParts_Call) Return(order *domain.Order, err error) *MockOrderRepository_GetParts_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetParts_Call) RunAndReturn(run func(ctx context.Context, orderUID string, parts domain.Part) (*domain.Order, error)) *MockOrderRepository_GetParts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPayments provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) GetPayments(ctx context.Context, orderUIDs []string) (map[string]domain.Payment, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPayments")
+	}
+
+	var r0 map[string]domain.Payment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (map[string]domain.Payment, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) map[string]domain.Payment); ok {
+		r0 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]domain.Payment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderUIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_GetPayments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPayments'
+type MockOrderRepository_GetPayments_Call struct {
+	*mock.Call
+}
+
+// GetPayments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUIDs []string
+func (_e *MockOrderRepository_Expecter) GetPayments(ctx interface{}, orderUIDs interface{}) *MockOrderRepository_GetPayments_Call {
+	return &MockOrderRepository_GetPayments_Call{Call: _e.mock.On("GetPayments", ctx, orderUIDs)}
+}
+
+func (_c *MockOrderRepository_GetPayments_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderRepository_GetPayments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_GetPayments_Call) Return(stringToPayment map[string]domain.Payment, err error) *MockOrderRepository_GetPayments_Call {
+	_c.Call.Return(stringToPayment, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_GetPayments_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) (map[string]domain.Payment, error)) *MockOrderRepository_GetPayments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// History provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) History(ctx context.Context, orderUID string, until time.Time) ([]domain.HistoryEvent, error) {
+	ret := _mock.Called(ctx, orderUID, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for History")
+	}
+
+	var r0 []domain.HistoryEvent
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]domain.HistoryEvent, error)); ok {
+		return returnFunc(ctx, orderUID, until)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) []domain.HistoryEvent); ok {
+		r0 = returnFunc(ctx, orderUID, until)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.HistoryEvent)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, orderUID, until)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_History_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'History'
+type MockOrderRepository_History_Call struct {
+	*mock.Call
+}
+
+// History is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+//   - until time.Time
+func (_e *MockOrderRepository_Expecter) History(ctx interface{}, orderUID interface{}, until interface{}) *MockOrderRepository_History_Call {
+	return &MockOrderRepository_History_Call{Call: _e.mock.On("History", ctx, orderUID, until)}
+}
+
+func (_c *MockOrderRepository_History_Call) Run(run func(ctx context.Context, orderUID string, until time.Time)) *MockOrderRepository_History_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_History_Call) Return(historyEvents []domain.HistoryEvent, err error) *MockOrderRepository_History_Call {
+	_c.Call.Return(historyEvents, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_History_Call) RunAndReturn(run func(ctx context.Context, orderUID string, until time.Time) ([]domain.HistoryEvent, error)) *MockOrderRepository_History_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) List(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error) {
+	ret := _mock.Called(ctx, filter, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.OrderFilter, domain.PageRequest) ([]domain.Order, error)); ok {
+		return returnFunc(ctx, filter, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.OrderFilter, domain.PageRequest) []domain.Order); ok {
+		r0 = returnFunc(ctx, filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.OrderFilter, domain.PageRequest) error); ok {
+		r1 = returnFunc(ctx, filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockOrderRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.OrderFilter
+//   - page domain.PageRequest
+func (_e *MockOrderRepository_Expecter) List(ctx interface{}, filter interface{}, page interface{}) *MockOrderRepository_List_Call {
+	return &MockOrderRepository_List_Call{Call: _e.mock.On("List", ctx, filter, page)}
+}
+
+func (_c *MockOrderRepository_List_Call) Run(run func(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest)) *MockOrderRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(domain.OrderFilter)
+		}
+		var arg2 domain.PageRequest
+		if args[2] != nil {
+			arg2 = args[2].(domain.PageRequest)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_List_Call) Return(orders []domain.Order, err error) *MockOrderRepository_List_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_List_Call) RunAndReturn(run func(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error)) *MockOrderRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListHeaders provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) ListHeaders(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error) {
+	ret := _mock.Called(ctx, filter, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListHeaders")
+	}
+
+	var r0 []domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.OrderFilter, domain.PageRequest) ([]domain.Order, error)); ok {
+		return returnFunc(ctx, filter, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.OrderFilter, domain.PageRequest) []domain.Order); ok {
+		r0 = returnFunc(ctx, filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.OrderFilter, domain.PageRequest) error); ok {
+		r1 = returnFunc(ctx, filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_ListHeaders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListHeaders'
+type MockOrderRepository_ListHeaders_Call struct {
+	*mock.Call
+}
+
+// ListHeaders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.OrderFilter
+//   - page domain.PageRequest
+func (_e *MockOrderRepository_Expecter) ListHeaders(ctx interface{}, filter interface{}, page interface{}) *MockOrderRepository_ListHeaders_Call {
+	return &MockOrderRepository_ListHeaders_Call{Call: _e.mock.On("ListHeaders", ctx, filter, page)}
+}
+
+func (_c *MockOrderRepository_ListHeaders_Call) Run(run func(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest)) *MockOrderRepository_ListHeaders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(domain.OrderFilter)
+		}
+		var arg2 domain.PageRequest
+		if args[2] != nil {
+			arg2 = args[2].(domain.PageRequest)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_ListHeaders_Call) Return(orders []domain.Order, err error) *MockOrderRepository_ListHeaders_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_ListHeaders_Call) RunAndReturn(run func(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error)) *MockOrderRepository_ListHeaders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrderAsOf provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) OrderAsOf(ctx context.Context, orderUID string, at time.Time) (*domain.Order, error) {
+	ret := _mock.Called(ctx, orderUID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrderAsOf")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) (*domain.Order, error)); ok {
+		return returnFunc(ctx, orderUID, at)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) *domain.Order); ok {
+		r0 = returnFunc(ctx, orderUID, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, orderUID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_OrderAsOf_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrderAsOf'
+type MockOrderRepository_OrderAsOf_Call struct {
+	*mock.Call
+}
+
+// OrderAsOf is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+//   - at time.Time
+func (_e *MockOrderRepository_Expecter) OrderAsOf(ctx interface{}, orderUID interface{}, at interface{}) *MockOrderRepository_OrderAsOf_Call {
+	return &MockOrderRepository_OrderAsOf_Call{Call: _e.mock.On("OrderAsOf", ctx, orderUID, at)}
+}
+
+func (_c *MockOrderRepository_OrderAsOf_Call) Run(run func(ctx context.Context, orderUID string, at time.Time)) *MockOrderRepository_OrderAsOf_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_OrderAsOf_Call) Return(order *domain.Order, err error) *MockOrderRepository_OrderAsOf_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_OrderAsOf_Call) RunAndReturn(run func(ctx context.Context, orderUID string, at time.Time) (*domain.Order, error)) *MockOrderRepository_OrderAsOf_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Purge provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	ret := _mock.Called(ctx, deletedBefore, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int) (int, error)); ok {
+		return returnFunc(ctx, deletedBefore, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int) int); ok {
+		r0 = returnFunc(ctx, deletedBefore, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = returnFunc(ctx, deletedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type MockOrderRepository_Purge_Call struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - deletedBefore time.Time
+//   - limit int
+func (_e *MockOrderRepository_Expecter) Purge(ctx interface{}, deletedBefore interface{}, limit interface{}) *MockOrderRepository_Purge_Call {
+	return &MockOrderRepository_Purge_Call{Call: _e.mock.On("Purge", ctx, deletedBefore, limit)}
+}
+
+func (_c *MockOrderRepository_Purge_Call) Run(run func(ctx context.Context, deletedBefore time.Time, limit int)) *MockOrderRepository_Purge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Purge_Call) Return(n int, err error) *MockOrderRepository_Purge_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Purge_Call) RunAndReturn(run func(ctx context.Context, deletedBefore time.Time, limit int) (int, error)) *MockOrderRepository_Purge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RefreshStats provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) RefreshStats(ctx context.Context) (bool, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshStats")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (bool, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_RefreshStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RefreshStats'
+type MockOrderRepository_RefreshStats_Call struct {
+	*mock.Call
+}
+
+// RefreshStats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockOrderRepository_Expecter) RefreshStats(ctx interface{}) *MockOrderRepository_RefreshStats_Call {
+	return &MockOrderRepository_RefreshStats_Call{Call: _e.mock.On("RefreshStats", ctx)}
+}
+
+func (_c *MockOrderRepository_RefreshStats_Call) Run(run func(ctx context.Context)) *MockOrderRepository_RefreshStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_RefreshStats_Call) Return(b bool, err error) *MockOrderRepository_RefreshStats_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_RefreshStats_Call) RunAndReturn(run func(ctx context.Context) (bool, error)) *MockOrderRepository_RefreshStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveExchangeRates provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) SaveExchangeRates(ctx context.Context, baseCurrency string, rates []domain.ExchangeRate) ([]string, error) {
+	ret := _mock.Called(ctx, baseCurrency, rates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveExchangeRates")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []domain.ExchangeRate) ([]string, error)); ok {
+		return returnFunc(ctx, baseCurrency, rates)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []domain.ExchangeRate) []string); ok {
+		r0 = returnFunc(ctx, baseCurrency, rates)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []domain.ExchangeRate) error); ok {
+		r1 = returnFunc(ctx, baseCurrency, rates)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_SaveExchangeRates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveExchangeRates'
+type MockOrderRepository_SaveExchangeRates_Call struct {
+	*mock.Call
+}
+
+// SaveExchangeRates is a helper method to define mock.On call
+//   - ctx context.Context
+//   - baseCurrency string
+//   - rates []domain.ExchangeRate
+func (_e *MockOrderRepository_Expecter) SaveExchangeRates(ctx interface{}, baseCurrency interface{}, rates interface{}) *MockOrderRepository_SaveExchangeRates_Call {
+	return &MockOrderRepository_SaveExchangeRates_Call{Call: _e.mock.On("SaveExchangeRates", ctx, baseCurrency, rates)}
+}
+
+func (_c *MockOrderRepository_SaveExchangeRates_Call) Run(run func(ctx context.Context, baseCurrency string, rates []domain.ExchangeRate)) *MockOrderRepository_SaveExchangeRates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 []domain.ExchangeRate
+		if args[2] != nil {
+			arg2 = args[2].([]domain.ExchangeRate)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_SaveExchangeRates_Call) Return(strings []string, err error) *MockOrderRepository_SaveExchangeRates_Call {
+	_c.Call.Return(strings, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_SaveExchangeRates_Call) RunAndReturn(run func(ctx context.Context, baseCurrency string, rates []domain.ExchangeRate) ([]string, error)) *MockOrderRepository_SaveExchangeRates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) Search(ctx context.Context, query string, page domain.PageRequest) ([]domain.SearchHit, error) {
+	ret := _mock.Called(ctx, query, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []domain.SearchHit
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, domain.PageRequest) ([]domain.SearchHit, error)); ok {
+		return returnFunc(ctx, query, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, domain.PageRequest) []domain.SearchHit); ok {
+		r0 = returnFunc(ctx, query, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SearchHit)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, domain.PageRequest) error); ok {
+		r1 = returnFunc(ctx, query, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type MockOrderRepository_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - page domain.PageRequest
+func (_e *MockOrderRepository_Expecter) Search(ctx interface{}, query interface{}, page interface{}) *MockOrderRepository_Search_Call {
+	return &MockOrderRepository_Search_Call{Call: _e.mock.On("Search", ctx, query, page)}
+}
+
+func (_c *MockOrderRepository_Search_Call) Run(run func(ctx context.Context, query string, page domain.PageRequest)) *MockOrderRepository_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 domain.PageRequest
+		if args[2] != nil {
+			arg2 = args[2].(domain.PageRequest)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Search_Call) Return(searchHits []domain.SearchHit, err error) *MockOrderRepository_Search_Call {
+	_c.Call.Return(searchHits, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Search_Call) RunAndReturn(run func(ctx context.Context, query string, page domain.PageRequest) ([]domain.SearchHit, error)) *MockOrderRepository_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Undelete provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) Undelete(ctx context.Context, orderUID string, restoredAt time.Time) (*domain.Order, error) {
+	ret := _mock.Called(ctx, orderUID, restoredAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Undelete")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) (*domain.Order, error)); ok {
+		return returnFunc(ctx, orderUID, restoredAt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) *domain.Order); ok {
+		r0 = returnFunc(ctx, orderUID, restoredAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, orderUID, restoredAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_Undelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Undelete'
+type MockOrderRepository_Undelete_Call struct {
+	*mock.Call
+}
+
+// Undelete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+//   - restoredAt time.Time
+func (_e *MockOrderRepository_Expecter) Undelete(ctx interface{}, orderUID interface{}, restoredAt interface{}) *MockOrderRepository_Undelete_Call {
+	return &MockOrderRepository_Undelete_Call{Call: _e.mock.On("Undelete", ctx, orderUID, restoredAt)}
+}
+
+func (_c *MockOrderRepository_Undelete_Call) Run(run func(ctx context.Context, orderUID string, restoredAt time.Time)) *MockOrderRepository_Undelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Undelete_Call) Return(order *domain.Order, err error) *MockOrderRepository_Undelete_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Undelete_Call) RunAndReturn(run func(ctx context.Context, orderUID string, restoredAt time.Time) (*domain.Order, error)) *MockOrderRepository_Undelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) Update(ctx context.Context, order *domain.Order, expectedVersion int64) error {
+	ret := _mock.Called(ctx, order, expectedVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *domain.Order, int64) error); ok {
+		r0 = returnFunc(ctx, order, expectedVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockOrderRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - order *domain.Order
+//   - expectedVersion int64
+func (_e *MockOrderRepository_Expecter) Update(ctx interface{}, order interface{}, expectedVersion interface{}) *MockOrderRepository_Update_Call {
+	return &MockOrderRepository_Update_Call{Call: _e.mock.On("Update", ctx, order, expectedVersion)}
+}
+
+func (_c *MockOrderRepository_Update_Call) Run(run func(ctx context.Context, order *domain.Order, expectedVersion int64)) *MockOrderRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *domain.Order
+		if args[1] != nil {
+			arg1 = args[1].(*domain.Order)
+		}
+		var arg2 int64
+		if args[2] != nil {
+			arg2 = args[2].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Update_Call) Return(err error) *MockOrderRepository_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Update_Call) RunAndReturn(run func(ctx context.Context, order *domain.Order, expectedVersion int64) error) *MockOrderRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) Upsert(ctx context.Context, order *domain.Order) (bool, error) {
+	ret := _mock.Called(ctx, order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *domain.Order) (bool, error)); ok {
+		return returnFunc(ctx, order)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *domain.Order) bool); ok {
+		r0 = returnFunc(ctx, order)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *domain.Order) error); ok {
+		r1 = returnFunc(ctx, order)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockOrderRepository_Upsert_Call struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - order *domain.Order
+func (_e *MockOrderRepository_Expecter) Upsert(ctx interface{}, order interface{}) *MockOrderRepository_Upsert_Call {
+	return &MockOrderRepository_Upsert_Call{Call: _e.mock.On("Upsert", ctx, order)}
+}
+
+func (_c *MockOrderRepository_Upsert_Call) Run(run func(ctx context.Context, order *domain.Order)) *MockOrderRepository_Upsert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *domain.Order
+		if args[1] != nil {
+			arg1 = args[1].(*domain.Order)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_Upsert_Call) Return(b bool, err error) *MockOrderRepository_Upsert_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_Upsert_Call) RunAndReturn(run func(ctx context.Context, order *domain.Order) (bool, error)) *MockOrderRepository_Upsert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOrderCache creates a new instance of MockOrderCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderCache {
+	mock := &MockOrderCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderCache is an autogenerated mock type for the OrderCache type
+type MockOrderCache struct {
+	mock.Mock
+}
+
+type MockOrderCache_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderCache) EXPECT() *MockOrderCache_Expecter {
+	return &MockOrderCache_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function for the type MockOrderCache
+func (_mock *MockOrderCache) Delete(ctx context.Context, orderUIDs ...string) error {
+	var tmpRet mock.Arguments
+	if len(orderUIDs) > 0 {
+		tmpRet = _mock.Called(ctx, orderUIDs)
+	} else {
+		tmpRet = _mock.Called(ctx)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *domain.Order) error); ok {
-		r0 = returnFunc(ctx, order)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, ...string) error); ok {
+		r0 = returnFunc(ctx, orderUIDs...)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockOrderRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
-type MockOrderRepository_Create_Call struct {
+// MockOrderCache_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockOrderCache_Delete_Call struct {
 	*mock.Call
 }
 
-// Create is a helper method to define mock.On call
+// Delete is a helper method to define mock.On call
 //   - ctx context.Context
-//   - order *domain.Order
-func (_e *MockOrderRepository_Expecter) Create(ctx interface{}, order interface{}) *MockOrderRepository_Create_Call {
-	return &MockOrderRepository_Create_Call{Call: _e.mock.On("Create", ctx, order)}
+//   - orderUIDs ...string
+func (_e *MockOrderCache_Expecter) Delete(ctx interface{}, orderUIDs ...interface{}) *MockOrderCache_Delete_Call {
+	return &MockOrderCache_Delete_Call{Call: _e.mock.On("Delete",
+		append([]interface{}{ctx}, orderUIDs...)...)}
 }
 
-func (_c *MockOrderRepository_Create_Call) Run(run func(ctx context.Context, order *domain.Order)) *MockOrderRepository_Create_Call {
+func (_c *MockOrderCache_Delete_Call) Run(run func(ctx context.Context, orderUIDs ...string)) *MockOrderCache_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *domain.Order
-		if args[1] != nil {
-			arg1 = args[1].(*domain.Order)
+		var arg1 []string
+		var variadicArgs []string
+		if len(args) > 1 {
+			variadicArgs = args[1].([]string)
 		}
+		arg1 = variadicArgs
 		run(
 			arg0,
-			arg1,
+			arg1...,
 		)
 	})
 	return _c
 }
 
-func (_c *MockOrderRepository_Create_Call) Return(err error) *MockOrderRepository_Create_Call {
+func (_c *MockOrderCache_Delete_Call) Return(err error) *MockOrderCache_Delete_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockOrderRepository_Create_Call) RunAndReturn(run func(ctx context.Context, order *domain.Order) error) *MockOrderRepository_Create_Call {
+func (_c *MockOrderCache_Delete_Call) RunAndReturn(run func(ctx context.Context, orderUIDs ...string) error) *MockOrderCache_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Get provides a mock function for the type MockOrderRepository
-func (_mock *MockOrderRepository) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
+// Get provides a mock function for the type MockOrderCache
+func (_mock *MockOrderCache) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
 	ret := _mock.Called(ctx, orderUID)
 
 	if len(ret) == 0 {
@@ -123,19 +2316,19 @@ func (_mock *MockOrderRepository) Get(ctx context.Context, orderUID string) (*do
 	return r0, r1
 }
 
-// MockOrderRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
-type MockOrderRepository_Get_Call struct {
+// MockOrderCache_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockOrderCache_Get_Call struct {
 	*mock.Call
 }
 
 // Get is a helper method to define mock.On call
 //   - ctx context.Context
 //   - orderUID string
-func (_e *MockOrderRepository_Expecter) Get(ctx interface{}, orderUID interface{}) *MockOrderRepository_Get_Call {
-	return &MockOrderRepository_Get_Call{Call: _e.mock.On("Get", ctx, orderUID)}
+func (_e *MockOrderCache_Expecter) Get(ctx interface{}, orderUID interface{}) *MockOrderCache_Get_Call {
+	return &MockOrderCache_Get_Call{Call: _e.mock.On("Get", ctx, orderUID)}
 }
 
-func (_c *MockOrderRepository_Get_Call) Run(run func(ctx context.Context, orderUID string)) *MockOrderRepository_Get_Call {
+func (_c *MockOrderCache_Get_Call) Run(run func(ctx context.Context, orderUID string)) *MockOrderCache_Get_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -153,146 +2346,257 @@ func (_c *MockOrderRepository_Get_Call) Run(run func(ctx context.Context, orderU
 	return _c
 }
 
-func (_c *MockOrderRepository_Get_Call) Return(order *domain.Order, err error) *MockOrderRepository_Get_Call {
+func (_c *MockOrderCache_Get_Call) Return(order *domain.Order, err error) *MockOrderCache_Get_Call {
 	_c.Call.Return(order, err)
 	return _c
 }
 
-func (_c *MockOrderRepository_Get_Call) RunAndReturn(run func(ctx context.Context, orderUID string) (*domain.Order, error)) *MockOrderRepository_Get_Call {
+func (_c *MockOrderCache_Get_Call) RunAndReturn(run func(ctx context.Context, orderUID string) (*domain.Order, error)) *MockOrderCache_Get_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetAll provides a mock function for the type MockOrderRepository
-func (_mock *MockOrderRepository) GetAll(ctx context.Context) ([]domain.Order, error) {
-	ret := _mock.Called(ctx)
+// GetETag provides a mock function for the type MockOrderCache
+func (_mock *MockOrderCache) GetETag(ctx context.Context, orderUID string) (string, error) {
+	ret := _mock.Called(ctx, orderUID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAll")
+		panic("no return value specified for GetETag")
 	}
 
-	var r0 []domain.Order
+	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]domain.Order, error)); ok {
-		return returnFunc(ctx)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return returnFunc(ctx, orderUID)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context) []domain.Order); ok {
-		r0 = returnFunc(ctx)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, orderUID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, orderUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderCache_GetETag_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetETag'
+type MockOrderCache_GetETag_Call struct {
+	*mock.Call
+}
+
+// GetETag is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+func (_e *MockOrderCache_Expecter) GetETag(ctx interface{}, orderUID interface{}) *MockOrderCache_GetETag_Call {
+	return &MockOrderCache_GetETag_Call{Call: _e.mock.On("GetETag", ctx, orderUID)}
+}
+
+func (_c *MockOrderCache_GetETag_Call) Run(run func(ctx context.Context, orderUID string)) *MockOrderCache_GetETag_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderCache_GetETag_Call) Return(s string, err error) *MockOrderCache_GetETag_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockOrderCache_GetETag_Call) RunAndReturn(run func(ctx context.Context, orderUID string) (string, error)) *MockOrderCache_GetETag_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMany provides a mock function for the type MockOrderCache
+func (_mock *MockOrderCache) GetMany(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMany")
+	}
+
+	var r0 map[string]domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (map[string]domain.Order, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) map[string]domain.Order); ok {
+		r0 = returnFunc(ctx, orderUIDs)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.Order)
+			r0 = ret.Get(0).(map[string]domain.Order)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = returnFunc(ctx)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderUIDs)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockOrderRepository_GetAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAll'
-type MockOrderRepository_GetAll_Call struct {
+// MockOrderCache_GetMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMany'
+type MockOrderCache_GetMany_Call struct {
 	*mock.Call
 }
 
-// GetAll is a helper method to define mock.On call
+// GetMany is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *MockOrderRepository_Expecter) GetAll(ctx interface{}) *MockOrderRepository_GetAll_Call {
-	return &MockOrderRepository_GetAll_Call{Call: _e.mock.On("GetAll", ctx)}
+//   - orderUIDs []string
+func (_e *MockOrderCache_Expecter) GetMany(ctx interface{}, orderUIDs interface{}) *MockOrderCache_GetMany_Call {
+	return &MockOrderCache_GetMany_Call{Call: _e.mock.On("GetMany", ctx, orderUIDs)}
 }
 
-func (_c *MockOrderRepository_GetAll_Call) Run(run func(ctx context.Context)) *MockOrderRepository_GetAll_Call {
+func (_c *MockOrderCache_GetMany_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderCache_GetMany_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockOrderRepository_GetAll_Call) Return(orders []domain.Order, err error) *MockOrderRepository_GetAll_Call {
-	_c.Call.Return(orders, err)
+func (_c *MockOrderCache_GetMany_Call) Return(stringToOrder map[string]domain.Order, err error) *MockOrderCache_GetMany_Call {
+	_c.Call.Return(stringToOrder, err)
 	return _c
 }
 
-func (_c *MockOrderRepository_GetAll_Call) RunAndReturn(run func(ctx context.Context) ([]domain.Order, error)) *MockOrderRepository_GetAll_Call {
+func (_c *MockOrderCache_GetMany_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error)) *MockOrderCache_GetMany_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewMockOrderCache creates a new instance of MockOrderCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewMockOrderCache(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *MockOrderCache {
-	mock := &MockOrderCache{}
-	mock.Mock.Test(t)
+// GetUIDByTransaction provides a mock function for the type MockOrderCache
+func (_mock *MockOrderCache) GetUIDByTransaction(ctx context.Context, transaction string) (string, error) {
+	ret := _mock.Called(ctx, transaction)
 
-	t.Cleanup(func() { mock.AssertExpectations(t) })
+	if len(ret) == 0 {
+		panic("no return value specified for GetUIDByTransaction")
+	}
 
-	return mock
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return returnFunc(ctx, transaction)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, transaction)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, transaction)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockOrderCache is an autogenerated mock type for the OrderCache type
-type MockOrderCache struct {
-	mock.Mock
+// MockOrderCache_GetUIDByTransaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUIDByTransaction'
+type MockOrderCache_GetUIDByTransaction_Call struct {
+	*mock.Call
 }
 
-type MockOrderCache_Expecter struct {
-	mock *mock.Mock
+// GetUIDByTransaction is a helper method to define mock.On call
+//   - ctx context.Context
+//   - transaction string
+func (_e *MockOrderCache_Expecter) GetUIDByTransaction(ctx interface{}, transaction interface{}) *MockOrderCache_GetUIDByTransaction_Call {
+	return &MockOrderCache_GetUIDByTransaction_Call{Call: _e.mock.On("GetUIDByTransaction", ctx, transaction)}
 }
 
-func (_m *MockOrderCache) EXPECT() *MockOrderCache_Expecter {
-	return &MockOrderCache_Expecter{mock: &_m.Mock}
+func (_c *MockOrderCache_GetUIDByTransaction_Call) Run(run func(ctx context.Context, transaction string)) *MockOrderCache_GetUIDByTransaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
 }
 
-// Get provides a mock function for the type MockOrderCache
-func (_mock *MockOrderCache) Get(ctx context.Context, orderUID string) (*domain.Order, error) {
-	ret := _mock.Called(ctx, orderUID)
+func (_c *MockOrderCache_GetUIDByTransaction_Call) Return(s string, err error) *MockOrderCache_GetUIDByTransaction_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockOrderCache_GetUIDByTransaction_Call) RunAndReturn(run func(ctx context.Context, transaction string) (string, error)) *MockOrderCache_GetUIDByTransaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUIDsByTrackNumber provides a mock function for the type MockOrderCache
+func (_mock *MockOrderCache) GetUIDsByTrackNumber(ctx context.Context, trackNumber string) ([]string, error) {
+	ret := _mock.Called(ctx, trackNumber)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Get")
+		panic("no return value specified for GetUIDsByTrackNumber")
 	}
 
-	var r0 *domain.Order
+	var r0 []string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Order, error)); ok {
-		return returnFunc(ctx, orderUID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return returnFunc(ctx, trackNumber)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Order); ok {
-		r0 = returnFunc(ctx, orderUID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = returnFunc(ctx, trackNumber)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*domain.Order)
+			r0 = ret.Get(0).([]string)
 		}
 	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, orderUID)
+		r1 = returnFunc(ctx, trackNumber)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockOrderCache_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
-type MockOrderCache_Get_Call struct {
+// MockOrderCache_GetUIDsByTrackNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUIDsByTrackNumber'
+type MockOrderCache_GetUIDsByTrackNumber_Call struct {
 	*mock.Call
 }
 
-// Get is a helper method to define mock.On call
+// GetUIDsByTrackNumber is a helper method to define mock.On call
 //   - ctx context.Context
-//   - orderUID string
-func (_e *MockOrderCache_Expecter) Get(ctx interface{}, orderUID interface{}) *MockOrderCache_Get_Call {
-	return &MockOrderCache_Get_Call{Call: _e.mock.On("Get", ctx, orderUID)}
+//   - trackNumber string
+func (_e *MockOrderCache_Expecter) GetUIDsByTrackNumber(ctx interface{}, trackNumber interface{}) *MockOrderCache_GetUIDsByTrackNumber_Call {
+	return &MockOrderCache_GetUIDsByTrackNumber_Call{Call: _e.mock.On("GetUIDsByTrackNumber", ctx, trackNumber)}
 }
 
-func (_c *MockOrderCache_Get_Call) Run(run func(ctx context.Context, orderUID string)) *MockOrderCache_Get_Call {
+func (_c *MockOrderCache_GetUIDsByTrackNumber_Call) Run(run func(ctx context.Context, trackNumber string)) *MockOrderCache_GetUIDsByTrackNumber_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -310,12 +2614,12 @@ func (_c *MockOrderCache_Get_Call) Run(run func(ctx context.Context, orderUID st
 	return _c
 }
 
-func (_c *MockOrderCache_Get_Call) Return(order *domain.Order, err error) *MockOrderCache_Get_Call {
-	_c.Call.Return(order, err)
+func (_c *MockOrderCache_GetUIDsByTrackNumber_Call) Return(strings []string, err error) *MockOrderCache_GetUIDsByTrackNumber_Call {
+	_c.Call.Return(strings, err)
 	return _c
 }
 
-func (_c *MockOrderCache_Get_Call) RunAndReturn(run func(ctx context.Context, orderUID string) (*domain.Order, error)) *MockOrderCache_Get_Call {
+func (_c *MockOrderCache_GetUIDsByTrackNumber_Call) RunAndReturn(run func(ctx context.Context, trackNumber string) ([]string, error)) *MockOrderCache_GetUIDsByTrackNumber_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -376,3 +2680,116 @@ func (_c *MockOrderCache_Set_Call) RunAndReturn(run func(ctx context.Context, or
 	_c.Call.Return(run)
 	return _c
 }
+
+// NewMockOrderPublisher creates a new instance of MockOrderPublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderPublisher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderPublisher {
+	mock := &MockOrderPublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderPublisher is an autogenerated mock type for the OrderPublisher type
+type MockOrderPublisher struct {
+	mock.Mock
+}
+
+type MockOrderPublisher_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderPublisher) EXPECT() *MockOrderPublisher_Expecter {
+	return &MockOrderPublisher_Expecter{mock: &_m.Mock}
+}
+
+// Forget provides a mock function for the type MockOrderPublisher
+func (_mock *MockOrderPublisher) Forget(customerID string) {
+	_mock.Called(customerID)
+	return
+}
+
+// MockOrderPublisher_Forget_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Forget'
+type MockOrderPublisher_Forget_Call struct {
+	*mock.Call
+}
+
+// Forget is a helper method to define mock.On call
+//   - customerID string
+func (_e *MockOrderPublisher_Expecter) Forget(customerID interface{}) *MockOrderPublisher_Forget_Call {
+	return &MockOrderPublisher_Forget_Call{Call: _e.mock.On("Forget", customerID)}
+}
+
+func (_c *MockOrderPublisher_Forget_Call) Run(run func(customerID string)) *MockOrderPublisher_Forget_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderPublisher_Forget_Call) Return() *MockOrderPublisher_Forget_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockOrderPublisher_Forget_Call) RunAndReturn(run func(customerID string)) *MockOrderPublisher_Forget_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Publish provides a mock function for the type MockOrderPublisher
+func (_mock *MockOrderPublisher) Publish(eventType domain.EventType, order *domain.Order) {
+	_mock.Called(eventType, order)
+	return
+}
+
+// MockOrderPublisher_Publish_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Publish'
+type MockOrderPublisher_Publish_Call struct {
+	*mock.Call
+}
+
+// Publish is a helper method to define mock.On call
+//   - eventType domain.EventType
+//   - order *domain.Order
+func (_e *MockOrderPublisher_Expecter) Publish(eventType interface{}, order interface{}) *MockOrderPublisher_Publish_Call {
+	return &MockOrderPublisher_Publish_Call{Call: _e.mock.On("Publish", eventType, order)}
+}
+
+func (_c *MockOrderPublisher_Publish_Call) Run(run func(eventType domain.EventType, order *domain.Order)) *MockOrderPublisher_Publish_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 domain.EventType
+		if args[0] != nil {
+			arg0 = args[0].(domain.EventType)
+		}
+		var arg1 *domain.Order
+		if args[1] != nil {
+			arg1 = args[1].(*domain.Order)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderPublisher_Publish_Call) Return() *MockOrderPublisher_Publish_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockOrderPublisher_Publish_Call) RunAndReturn(run func(eventType domain.EventType, order *domain.Order)) *MockOrderPublisher_Publish_Call {
+	_c.Run(run)
+	return _c
+}
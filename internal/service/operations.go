@@ -8,6 +8,7 @@ import (
 	"github.com/Killazius/L0/internal/repository"
 	"github.com/Killazius/L0/pkg/validate"
 	"go.uber.org/zap"
+	"time"
 )
 
 func (s *Service) GetOrder(ctx context.Context, uid string) (*domain.Order, error) {
@@ -21,34 +22,91 @@ func (s *Service) GetOrder(ctx context.Context, uid string) (*domain.Order, erro
 		if !errors.Is(err, repository.ErrOrderNotFound) {
 			zap.L().Warn("cache error, falling back to database", zap.String("order_uid", uid), zap.Error(err))
 		}
+		return s.getFromRepository(ctx, uid)
+	}
+	zap.L().Info("from cache", zap.String("uid", uid))
+	return order, nil
+}
 
-		order, err = s.repo.Get(ctx, uid)
-		if err != nil {
-			switch {
-			case errors.Is(err, repository.ErrOrderNotFound):
-				return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, uid)
-			case errors.Is(err, repository.ErrDeliveryNotFound),
-				errors.Is(err, repository.ErrPaymentNotFound),
-				errors.Is(err, repository.ErrItemsNotFound):
-				return nil, fmt.Errorf("%w: incomplete data for order %s", ErrInvalidOrderData, uid)
-			default:
-				return nil, fmt.Errorf("failed to get order from database: %w", err)
-			}
-		}
-		s.wg.Go(func() {
-			cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheTimeout)
-			defer cancel()
-			if err := s.cache.Set(cacheCtx, order); err != nil && !errors.Is(err, context.Canceled) {
-				zap.L().Warn("failed to cache order", zap.String("order_uid", uid), zap.Error(err))
-			}
-		})
-		zap.L().Info("from database", zap.String("uid", uid))
+// GetOrderETag returns the entity tag of the order. The tag is read from the
+// cache when present, so conditional requests usually skip decoding the order.
+// Otherwise the order is read from the database and returned with its tag, so
+// callers need not read it again when the tag does not match.
+func (s *Service) GetOrderETag(ctx context.Context, uid string) (string, *domain.Order, error) {
+	cacheCtx, cancel := context.WithTimeout(ctx, cacheTimeout)
+	defer cancel()
+	etag, err := s.cache.GetETag(cacheCtx, uid)
+	if err == nil {
+		return etag, nil, nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return "", nil, fmt.Errorf("cache operation canceled: %w", err)
+	}
+	if !errors.Is(err, repository.ErrOrderNotFound) {
+		zap.L().Warn("cache error, falling back to database", zap.String("order_uid", uid), zap.Error(err))
+	}
+	order, err := s.getFromRepository(ctx, uid)
+	if err != nil {
+		return "", nil, err
+	}
+	return order.ETag(), order, nil
+}
+
+// GetOrderParts returns the order with at least the selected parts. A cached
+// order is returned whole; on a cache miss only the selected parts are read
+// from the database, and such a partial order is not cached.
+func (s *Service) GetOrderParts(ctx context.Context, uid string, parts domain.Part) (*domain.Order, error) {
+	if parts == domain.AllParts {
+		return s.GetOrder(ctx, uid)
+	}
+	cacheCtx, cancel := context.WithTimeout(ctx, cacheTimeout)
+	defer cancel()
+	order, err := s.cache.Get(cacheCtx, uid)
+	if err == nil {
 		return order, nil
 	}
-	zap.L().Info("from cache", zap.String("uid", uid))
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return nil, fmt.Errorf("cache operation canceled: %w", err)
+	}
+	if !errors.Is(err, repository.ErrOrderNotFound) {
+		zap.L().Warn("cache error, falling back to database", zap.String("order_uid", uid), zap.Error(err))
+	}
+	order, err = s.repo.GetParts(ctx, uid, parts)
+	if err != nil {
+		return nil, repositoryError(err, uid)
+	}
 	return order, nil
 }
 
+func (s *Service) getFromRepository(ctx context.Context, uid string) (*domain.Order, error) {
+	order, err := s.repo.Get(ctx, uid)
+	if err != nil {
+		return nil, repositoryError(err, uid)
+	}
+	s.wg.Go(func() {
+		cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheTimeout)
+		defer cancel()
+		if err := s.cache.Set(cacheCtx, order); err != nil && !errors.Is(err, context.Canceled) {
+			zap.L().Warn("failed to cache order", zap.String("order_uid", uid), zap.Error(err))
+		}
+	})
+	zap.L().Info("from database", zap.String("uid", uid))
+	return order, nil
+}
+
+func repositoryError(err error, uid string) error {
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, uid)
+	case errors.Is(err, repository.ErrDeliveryNotFound),
+		errors.Is(err, repository.ErrPaymentNotFound),
+		errors.Is(err, repository.ErrItemsNotFound):
+		return fmt.Errorf("%w: incomplete data for order %s", ErrInvalidOrderData, uid)
+	default:
+		return fmt.Errorf("failed to get order from database: %w", err)
+	}
+}
+
 func (s *Service) CreateOrder(ctx context.Context, order *domain.Order) error {
 	if order == nil {
 		return fmt.Errorf("%w: order is nil", ErrInvalidOrderData)
@@ -56,6 +114,12 @@ func (s *Service) CreateOrder(ctx context.Context, order *domain.Order) error {
 	if err := validate.Order(order); err != nil {
 		return fmt.Errorf("%w: %w", ErrInvalidOrderData, err)
 	}
+	if order.UpdatedAt.IsZero() {
+		order.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	}
+	if err := s.convert(ctx, order); err != nil {
+		return err
+	}
 	err := s.repo.Create(ctx, order)
 	if err != nil {
 		switch {
@@ -65,6 +129,7 @@ func (s *Service) CreateOrder(ctx context.Context, order *domain.Order) error {
 			return fmt.Errorf("failed to create order: %w", err)
 		}
 	}
+	s.publisher.Publish(domain.EventOrderCreated, order)
 	s.wg.Go(func() {
 		cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheTimeout)
 		defer cancel()
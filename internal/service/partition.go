@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// MaintainPartitions creates the monthly orders partitions of the current
+// month and the premake months after it. With a positive retainMonths it also
+// detaches the partitions of the months before the retainMonths months that
+// precede the current one, so that they can be archived. It returns the
+// number of created partitions and the names of the detached ones.
+func (s *Service) MaintainPartitions(ctx context.Context, premake, retainMonths int) (int, []string, error) {
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	created, err := s.repo.EnsurePartitions(ctx, month, month.AddDate(0, premake, 0))
+	if err != nil {
+		return 0, nil, err
+	}
+	if retainMonths <= 0 {
+		return created, nil, nil
+	}
+	detached, err := s.repo.DetachPartitions(ctx, month.AddDate(0, -retainMonths, 0))
+	if err != nil {
+		return created, nil, err
+	}
+	return created, detached, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+// WithErasureSecret sets the secret the customer hashes of erasure records are
+// keyed with and returns the service. Without one the service uses a random
+// secret, and hashes of erasures made by different processes cannot be
+// compared.
+func (s *Service) WithErasureSecret(secret string) *Service {
+	if secret != "" {
+		s.erasureSecret = []byte(secret)
+	}
+	return s
+}
+
+// ExportCustomer collects every order of the customer for a subject access request.
+// Orders are read from the database, the cache may lag behind it.
+func (s *Service) ExportCustomer(ctx context.Context, customerID string) (*domain.CustomerExport, error) {
+	orders, err := s.repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCustomerNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, customerID)
+		}
+		return nil, fmt.Errorf("failed to get customer orders: %w", err)
+	}
+	return &domain.CustomerExport{
+		CustomerID: customerID,
+		ExportedAt: time.Now().UTC(),
+		Orders:     orders,
+	}, nil
+}
+
+// EraseCustomer anonymises the personal data of the customer, evicts the
+// affected orders from the cache and drops their events from the replay buffer
+// of live subscribers. The returned record is already persisted.
+// A failed eviction does not fail the erasure: the database is the source of
+// truth and stale cache entries expire with the cache TTL.
+func (s *Service) EraseCustomer(ctx context.Context, customerID, requestedBy, reason string) (*domain.Erasure, error) {
+	erasure := domain.NewErasure(customerID, requestedBy, reason, s.erasureSecret)
+	if err := s.repo.EraseCustomer(ctx, customerID, erasure); err != nil {
+		if errors.Is(err, repository.ErrCustomerNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, customerID)
+		}
+		return nil, fmt.Errorf("failed to erase customer: %w", err)
+	}
+	s.publisher.Forget(customerID)
+
+	cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheTimeout)
+	defer cancel()
+	if err := s.cache.Delete(cacheCtx, erasure.OrderUIDs...); err != nil {
+		zap.L().Error("failed to evict erased orders from cache",
+			zap.Int64("erasure_id", erasure.ID), zap.Strings("order_uids", erasure.OrderUIDs), zap.Error(err))
+	}
+	zap.L().Info("customer erased",
+		zap.Int64("erasure_id", erasure.ID),
+		zap.String("customer_hash", erasure.CustomerHash),
+		zap.String("requested_by", erasure.RequestedBy),
+		zap.Int("orders", len(erasure.OrderUIDs)))
+	return erasure, nil
+}
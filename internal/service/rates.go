@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExchangeRates = errors.New("invalid exchange rates")
+
+// WithBaseCurrency makes the service convert the payment amounts to the
+// currency with the stored exchange rates and returns the service. Without a
+// base currency nothing is converted. Currency codes are compared in upper
+// case.
+func (s *Service) WithBaseCurrency(currency string) *Service {
+	s.baseCurrency = strings.ToUpper(currency)
+	return s
+}
+
+// ExchangeRates returns the rates in the base currency effective on the UTC
+// day of at, today when at is zero.
+func (s *Service) ExchangeRates(ctx context.Context, at time.Time) (*domain.ExchangeRates, error) {
+	if s.baseCurrency == "" {
+		return nil, fmt.Errorf("%w: no base currency configured", ErrInvalidExchangeRates)
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	day := startOfDay(at)
+	rates, err := s.repo.ExchangeRates(ctx, s.baseCurrency, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	return &domain.ExchangeRates{BaseCurrency: s.baseCurrency, Date: day, Rates: rates}, nil
+}
+
+// SaveExchangeRates saves the rates in the base currency and converts the
+// stored orders that had no rate so far. Orders converted already keep their
+// amounts. The converted orders are evicted from the cache.
+func (s *Service) SaveExchangeRates(ctx context.Context, rates []domain.ExchangeRate) (*domain.ExchangeRatesUpdate, error) {
+	if err := s.validateRates(rates); err != nil {
+		return nil, err
+	}
+	converted, err := s.repo.SaveExchangeRates(ctx, s.baseCurrency, rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save exchange rates: %w", err)
+	}
+	if len(converted) > 0 {
+		s.evict(ctx, converted...)
+	}
+	return &domain.ExchangeRatesUpdate{Saved: len(rates), Converted: len(converted)}, nil
+}
+
+// validateRates checks the rates, converts their currency codes to upper case
+// and truncates their dates to UTC days.
+func (s *Service) validateRates(rates []domain.ExchangeRate) error {
+	if s.baseCurrency == "" {
+		return fmt.Errorf("%w: no base currency configured", ErrInvalidExchangeRates)
+	}
+	if len(rates) == 0 {
+		return fmt.Errorf("%w: no rates", ErrInvalidExchangeRates)
+	}
+	type key struct {
+		currency string
+		day      time.Time
+	}
+	seen := make(map[key]bool, len(rates))
+	for i := range rates {
+		rate := &rates[i]
+		switch {
+		case !isCurrency(rate.Currency):
+			return fmt.Errorf("%w: invalid currency %q", ErrInvalidExchangeRates, rate.Currency)
+		}
+		rate.Currency = strings.ToUpper(rate.Currency)
+		switch {
+		case rate.Currency == s.baseCurrency:
+			return fmt.Errorf("%w: rate of the base currency %s", ErrInvalidExchangeRates, rate.Currency)
+		case rate.Date.IsZero():
+			return fmt.Errorf("%w: rate of %s has no date", ErrInvalidExchangeRates, rate.Currency)
+		case !rate.Rate.IsPositive():
+			return fmt.Errorf("%w: rate of %s must be positive", ErrInvalidExchangeRates, rate.Currency)
+		}
+		rate.Date = startOfDay(rate.Date)
+		k := key{currency: rate.Currency, day: rate.Date}
+		if seen[k] {
+			return fmt.Errorf("%w: duplicate rate of %s on %s", ErrInvalidExchangeRates, rate.Currency, rate.Date.Format(time.DateOnly))
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// isCurrency reports whether the code is three letters, like the currencies
+// of payments.
+func isCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// convert sets the base currency amount of the payment of the order with the
+// rate effective on the day the order was created. Without a rate the amount
+// stays unset until SaveExchangeRates brings one.
+func (s *Service) convert(ctx context.Context, order *domain.Order) error {
+	payment := &order.Payment
+	payment.ClearConversion()
+	if s.baseCurrency == "" {
+		return nil
+	}
+	currency := strings.ToUpper(payment.Currency)
+	if currency == s.baseCurrency {
+		payment.Convert(s.baseCurrency, decimal.NewFromInt(1))
+		return nil
+	}
+	rate, err := s.repo.ExchangeRate(ctx, s.baseCurrency, currency, order.DateCreated)
+	if errors.Is(err, repository.ErrRateNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	payment.Convert(s.baseCurrency, rate)
+	return nil
+}
+
+// baseTotal sets the currency of a total converted by the repository, or drops
+// the total when nothing is converted.
+func (s *Service) baseTotal(total *domain.BaseTotal) *domain.BaseTotal {
+	if s.baseCurrency == "" || total == nil {
+		return nil
+	}
+	total.Currency = s.baseCurrency
+	return total
+}
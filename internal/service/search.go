@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxSearchQueryLength = 256
+
+// SearchOrders returns a page of orders matching the full-text query, best
+// matches first. The query uses web search syntax: quoted phrases, "or" and
+// -excluded words. Search always reads the database.
+func (s *Service) SearchOrders(ctx context.Context, query string, pageSize int, pageToken string) (*domain.SearchPage, error) {
+	query = strings.TrimSpace(query)
+	switch {
+	case query == "":
+		return nil, fmt.Errorf("%w: empty search query", ErrInvalidQuery)
+	case utf8.RuneCountInString(query) > maxSearchQueryLength:
+		return nil, fmt.Errorf("%w: search query is longer than %d characters", ErrInvalidQuery, maxSearchQueryLength)
+	}
+	switch {
+	case pageSize < 0:
+		return nil, fmt.Errorf("%w: negative page size", ErrInvalidQuery)
+	case pageSize == 0:
+		pageSize = DefaultPageSize
+	case pageSize > MaxPageSize:
+		pageSize = MaxPageSize
+	}
+	page := domain.PageRequest{Limit: pageSize + 1}
+	if pageToken != "" {
+		offset, err := domain.DecodeOffset(pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+		page.Offset = offset
+	}
+
+	hits, err := s.repo.Search(ctx, query, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	result := &domain.SearchPage{Results: hits}
+	if result.Results == nil {
+		result.Results = []domain.SearchHit{}
+	}
+	// One extra order is requested to know whether there is a next page.
+	if len(hits) > pageSize {
+		result.Results = hits[:pageSize]
+		result.NextCursor = domain.EncodeOffset(page.Offset + pageSize)
+	}
+	return result, nil
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"github.com/Killazius/L0/internal/domain"
+	"github.com/shopspring/decimal"
 	"sync"
 	"time"
 )
@@ -12,6 +14,9 @@ var (
 	ErrOrderNotFound      = errors.New("order not found")
 	ErrOrderAlreadyExists = errors.New("order already exists")
 	ErrInvalidOrderData   = errors.New("invalid order data")
+	ErrCustomerNotFound   = errors.New("customer not found")
+	ErrStaleOrder         = errors.New("stale order")
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
 
 const (
@@ -20,21 +25,67 @@ const (
 
 type OrderRepository interface {
 	Create(ctx context.Context, order *domain.Order) error
+	Upsert(ctx context.Context, order *domain.Order) (bool, error)
+	Update(ctx context.Context, order *domain.Order, expectedVersion int64) error
+	Delete(ctx context.Context, orderUID string, deletedAt time.Time) (*domain.Order, error)
+	Undelete(ctx context.Context, orderUID string, restoredAt time.Time) (*domain.Order, error)
+	Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+	EnsurePartitions(ctx context.Context, from, to time.Time) (int, error)
+	DetachPartitions(ctx context.Context, before time.Time) ([]string, error)
+	History(ctx context.Context, orderUID string, until time.Time) ([]domain.HistoryEvent, error)
+	OrderAsOf(ctx context.Context, orderUID string, at time.Time) (*domain.Order, error)
 	Get(ctx context.Context, orderUID string) (*domain.Order, error)
+	GetParts(ctx context.Context, orderUID string, parts domain.Part) (*domain.Order, error)
+	GetMany(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error)
+	GetByTransaction(ctx context.Context, transaction string) (*domain.Order, error)
 	GetAll(ctx context.Context) ([]domain.Order, error)
+	List(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error)
+	ListHeaders(ctx context.Context, filter domain.OrderFilter, page domain.PageRequest) ([]domain.Order, error)
+	GetDeliveries(ctx context.Context, orderUIDs []string) (map[string]domain.Delivery, error)
+	GetPayments(ctx context.Context, orderUIDs []string) (map[string]domain.Payment, error)
+	GetItems(ctx context.Context, orderUIDs []string) (map[string][]domain.Item, error)
+	Search(ctx context.Context, query string, page domain.PageRequest) ([]domain.SearchHit, error)
+	GetByCustomer(ctx context.Context, customerID string) ([]domain.Order, error)
+	EraseCustomer(ctx context.Context, customerID string, erasure *domain.Erasure) error
+	GetCustomer(ctx context.Context, customerID string) (*domain.Customer, error)
+	CustomerStats(ctx context.Context, customerID string) (*domain.CustomerStats, error)
+	RefreshStats(ctx context.Context) (bool, error)
+	DailyStats(ctx context.Context, query domain.StatsQuery) ([]domain.DailyStats, error)
+	BrandStats(ctx context.Context, from, to time.Time) ([]domain.DailyBrands, error)
+	ExchangeRate(ctx context.Context, baseCurrency, currency string, at time.Time) (decimal.Decimal, error)
+	ExchangeRates(ctx context.Context, baseCurrency string, at time.Time) ([]domain.ExchangeRate, error)
+	SaveExchangeRates(ctx context.Context, baseCurrency string, rates []domain.ExchangeRate) ([]string, error)
 }
 
 type OrderCache interface {
 	Set(ctx context.Context, order *domain.Order) error
 	Get(ctx context.Context, orderUID string) (*domain.Order, error)
+	GetMany(ctx context.Context, orderUIDs []string) (map[string]domain.Order, error)
+	GetUIDByTransaction(ctx context.Context, transaction string) (string, error)
+	GetUIDsByTrackNumber(ctx context.Context, trackNumber string) ([]string, error)
+	GetETag(ctx context.Context, orderUID string) (string, error)
+	Delete(ctx context.Context, orderUIDs ...string) error
+}
+
+// OrderPublisher delivers order events to live subscribers. Publish must not block.
+// Forget drops the kept events of the customer's orders.
+type OrderPublisher interface {
+	Publish(eventType domain.EventType, order *domain.Order)
+	Forget(customerID string)
 }
 
 type Service struct {
-	repo  OrderRepository
-	cache OrderCache
-	wg    sync.WaitGroup
+	repo         OrderRepository
+	cache        OrderCache
+	publisher    OrderPublisher
+	baseCurrency string
+	// erasureSecret keys the customer hashes of erasure records.
+	erasureSecret []byte
+	wg            sync.WaitGroup
 }
 
-func New(repo OrderRepository, cache OrderCache) *Service {
-	return &Service{repo: repo, cache: cache}
+func New(repo OrderRepository, cache OrderCache, publisher OrderPublisher) *Service {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return &Service{repo: repo, cache: cache, publisher: publisher, erasureSecret: secret}
 }
@@ -2,13 +2,18 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/Killazius/L0/internal/lib/test"
 	"github.com/Killazius/L0/internal/repository"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Killazius/L0/internal/domain"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -130,7 +135,7 @@ func TestService_GetOrder(t *testing.T) {
 			mockCache := NewMockOrderCache(t)
 			tt.setupMocks(mockRepo, mockCache)
 
-			service := New(mockRepo, mockCache)
+			service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
 
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
@@ -152,84 +157,1364 @@ func TestService_GetOrder(t *testing.T) {
 	}
 }
 
+func TestService_GetOrderETag(t *testing.T) {
+	t.Parallel()
+
+	testOrder := test.GenerateOrder()
+	testETag := testOrder.ETag()
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockOrderRepository, *MockOrderCache)
+		expectedETag  string
+		expectedOrder *domain.Order
+		expectedError error
+	}{
+		{
+			name: "etag from cache",
+			setupMocks: func(_ *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetETag", mock.Anything, "test-uid").
+					Return(`"cached"`, nil).
+					Once()
+			},
+			expectedETag: `"cached"`,
+		},
+		{
+			name: "cache miss computes etag from database",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetETag", mock.Anything, "test-uid").
+					Return("", repository.ErrOrderNotFound).
+					Once()
+				repo.On("Get", mock.Anything, "test-uid").
+					Return(testOrder, nil).
+					Once()
+				cache.On("Set", mock.Anything, testOrder).
+					Return(nil).
+					Once()
+			},
+			expectedETag:  testETag,
+			expectedOrder: testOrder,
+		},
+		{
+			name: "order not found",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetETag", mock.Anything, "test-uid").
+					Return("", errors.New("cache error")).
+					Once()
+				repo.On("Get", mock.Anything, "test-uid").
+					Return(nil, repository.ErrOrderNotFound).
+					Once()
+			},
+			expectedError: ErrOrderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			tt.setupMocks(mockRepo, mockCache)
+
+			service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
+
+			etag, order, err := service.GetOrderETag(context.Background(), "test-uid")
+			service.wg.Wait()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Empty(t, etag)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedETag, etag)
+			}
+			assert.Equal(t, tt.expectedOrder, order, "the order is returned only when it was read")
+		})
+	}
+}
+
+func TestService_GetOrderParts(t *testing.T) {
+	t.Parallel()
+
+	testOrder := test.GenerateOrder()
+	header := *testOrder
+	header.Delivery = domain.Delivery{}
+	header.Payment = domain.Payment{}
+
+	tests := []struct {
+		name          string
+		parts         domain.Part
+		setupMocks    func(*MockOrderRepository, *MockOrderCache)
+		expectedOrder *domain.Order
+		expectedError error
+	}{
+		{
+			name:  "whole order from cache",
+			parts: domain.PartItems,
+			setupMocks: func(_ *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("Get", mock.Anything, "test-uid").
+					Return(testOrder, nil).
+					Once()
+			},
+			expectedOrder: testOrder,
+		},
+		{
+			name:  "cache miss reads selected parts without caching",
+			parts: domain.PartItems,
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("Get", mock.Anything, "test-uid").
+					Return(nil, repository.ErrOrderNotFound).
+					Once()
+				repo.On("GetParts", mock.Anything, "test-uid", domain.PartItems).
+					Return(&header, nil).
+					Once()
+			},
+			expectedOrder: &header,
+		},
+		{
+			name:  "all parts read the whole order",
+			parts: domain.AllParts,
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("Get", mock.Anything, "test-uid").
+					Return(nil, repository.ErrOrderNotFound).
+					Once()
+				repo.On("Get", mock.Anything, "test-uid").
+					Return(testOrder, nil).
+					Once()
+				cache.On("Set", mock.Anything, testOrder).
+					Return(nil).
+					Once()
+			},
+			expectedOrder: testOrder,
+		},
+		{
+			name:  "missing part",
+			parts: domain.PartPayment,
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("Get", mock.Anything, "test-uid").
+					Return(nil, repository.ErrOrderNotFound).
+					Once()
+				repo.On("GetParts", mock.Anything, "test-uid", domain.PartPayment).
+					Return(nil, repository.ErrPaymentNotFound).
+					Once()
+			},
+			expectedError: ErrInvalidOrderData,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			tt.setupMocks(mockRepo, mockCache)
+
+			service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
+
+			order, err := service.GetOrderParts(context.Background(), "test-uid", tt.parts)
+			service.wg.Wait()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, order)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedOrder, order)
+			}
+		})
+	}
+}
+
+func TestService_LookupOrders(t *testing.T) {
+	t.Parallel()
+
+	cached, stored := test.GenerateOrder(), test.GenerateOrder()
+	uids := []string{cached.OrderUID, stored.OrderUID, "missing", cached.OrderUID}
+	unique := uids[:3]
+	tooMany := make([]string, 0, MaxLookupSize+1)
+	for i := range MaxLookupSize + 1 {
+		tooMany = append(tooMany, fmt.Sprintf("uid%d", i))
+	}
+
+	tests := []struct {
+		name          string
+		orderUIDs     []string
+		setupMocks    func(*MockOrderRepository, *MockOrderCache)
+		expected      *domain.OrderLookup
+		expectedError error
+	}{
+		{
+			name:      "cache hits and database misses",
+			orderUIDs: uids,
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetMany", mock.Anything, unique).
+					Return(map[string]domain.Order{cached.OrderUID: *cached}, nil).
+					Once()
+				repo.On("GetMany", mock.Anything, []string{stored.OrderUID, "missing"}).
+					Return(map[string]domain.Order{stored.OrderUID: *stored}, nil).
+					Once()
+				cache.On("Set", mock.Anything, stored).
+					Return(nil).
+					Once()
+			},
+			expected: &domain.OrderLookup{
+				Orders:  []domain.Order{*cached, *stored},
+				Missing: []string{"missing"},
+			},
+		},
+		{
+			name:      "cache error falls back to database",
+			orderUIDs: []string{cached.OrderUID},
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetMany", mock.Anything, []string{cached.OrderUID}).
+					Return(nil, errors.New("cache error")).
+					Once()
+				repo.On("GetMany", mock.Anything, []string{cached.OrderUID}).
+					Return(map[string]domain.Order{cached.OrderUID: *cached}, nil).
+					Once()
+				cache.On("Set", mock.Anything, cached).
+					Return(nil).
+					Once()
+			},
+			expected: &domain.OrderLookup{
+				Orders:  []domain.Order{*cached},
+				Missing: []string{},
+			},
+		},
+		{
+			name:          "no order uids",
+			orderUIDs:     []string{""},
+			setupMocks:    func(_ *MockOrderRepository, _ *MockOrderCache) {},
+			expectedError: ErrInvalidQuery,
+		},
+		{
+			name:          "too many order uids",
+			orderUIDs:     tooMany,
+			setupMocks:    func(_ *MockOrderRepository, _ *MockOrderCache) {},
+			expectedError: ErrInvalidQuery,
+		},
+		{
+			name:      "incomplete order",
+			orderUIDs: []string{stored.OrderUID},
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetMany", mock.Anything, []string{stored.OrderUID}).
+					Return(map[string]domain.Order{}, nil).
+					Once()
+				repo.On("GetMany", mock.Anything, []string{stored.OrderUID}).
+					Return(nil, repository.ErrItemsNotFound).
+					Once()
+			},
+			expectedError: ErrInvalidOrderData,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			tt.setupMocks(mockRepo, mockCache)
+
+			service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
+
+			lookup, err := service.LookupOrders(context.Background(), tt.orderUIDs)
+			service.wg.Wait()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, lookup)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, lookup)
+			}
+		})
+	}
+}
+
+func TestService_ExportCustomer(t *testing.T) {
+	t.Parallel()
+
+	testOrder := test.GenerateOrder()
+
+	mockRepo := NewMockOrderRepository(t)
+	mockCache := NewMockOrderCache(t)
+	mockRepo.On("GetByCustomer", mock.Anything, testOrder.CustomerID).
+		Return([]domain.Order{*testOrder}, nil).
+		Once()
+	mockRepo.On("GetByCustomer", mock.Anything, "unknown").
+		Return(nil, repository.ErrCustomerNotFound).
+		Once()
+
+	service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
+
+	export, err := service.ExportCustomer(context.Background(), testOrder.CustomerID)
+	require.NoError(t, err)
+	assert.Equal(t, testOrder.CustomerID, export.CustomerID)
+	assert.Equal(t, []domain.Order{*testOrder}, export.Orders)
+	assert.False(t, export.ExportedAt.IsZero())
+
+	_, err = service.ExportCustomer(context.Background(), "unknown")
+	require.ErrorIs(t, err, ErrCustomerNotFound)
+}
+
+func TestService_GetCustomer(t *testing.T) {
+	t.Parallel()
+
+	customer := &domain.Customer{CustomerID: "customer", Name: "Test Testov"}
+
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("GetCustomer", mock.Anything, "customer").
+		Return(customer, nil).
+		Once()
+	mockRepo.On("GetCustomer", mock.Anything, "unknown").
+		Return(nil, repository.ErrCustomerNotFound).
+		Once()
+
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+	got, err := service.GetCustomer(context.Background(), "customer")
+	require.NoError(t, err)
+	assert.Equal(t, customer, got)
+
+	_, err = service.GetCustomer(context.Background(), "unknown")
+	require.ErrorIs(t, err, ErrCustomerNotFound)
+}
+
+func TestService_ListCustomerOrders(t *testing.T) {
+	t.Parallel()
+
+	order := *test.GenerateOrder()
+	stats := &domain.CustomerStats{
+		OrderCount:    1,
+		LifetimeValue: []domain.Amount{{Currency: order.Payment.Currency, Amount: order.Payment.Amount}},
+	}
+
+	tests := []struct {
+		name          string
+		customerID    string
+		pageToken     string
+		setupMocks    func(*MockOrderRepository)
+		expected      *domain.CustomerOrders
+		expectedError error
+	}{
+		{
+			name:       "success",
+			customerID: order.CustomerID,
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("CustomerStats", mock.Anything, order.CustomerID).
+					Return(stats, nil).
+					Once()
+				repo.On("List", mock.Anything, domain.OrderFilter{CustomerID: order.CustomerID}, domain.PageRequest{Limit: DefaultPageSize + 1}).
+					Return([]domain.Order{order}, nil).
+					Once()
+			},
+			expected: &domain.CustomerOrders{
+				CustomerID:    order.CustomerID,
+				CustomerStats: *stats,
+				Orders:        []domain.Order{order},
+			},
+		},
+		{
+			name:       "only deleted orders",
+			customerID: "customer",
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("CustomerStats", mock.Anything, "customer").
+					Return(&domain.CustomerStats{LifetimeValue: []domain.Amount{}}, nil).
+					Once()
+				repo.On("GetCustomer", mock.Anything, "customer").
+					Return(&domain.Customer{CustomerID: "customer"}, nil).
+					Once()
+				repo.On("List", mock.Anything, domain.OrderFilter{CustomerID: "customer"}, domain.PageRequest{Limit: DefaultPageSize + 1}).
+					Return([]domain.Order{}, nil).
+					Once()
+			},
+			expected: &domain.CustomerOrders{
+				CustomerID:    "customer",
+				CustomerStats: domain.CustomerStats{LifetimeValue: []domain.Amount{}},
+				Orders:        []domain.Order{},
+			},
+		},
+		{
+			name:       "customer not found",
+			customerID: "unknown",
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("CustomerStats", mock.Anything, "unknown").
+					Return(&domain.CustomerStats{LifetimeValue: []domain.Amount{}}, nil).
+					Once()
+				repo.On("GetCustomer", mock.Anything, "unknown").
+					Return(nil, repository.ErrCustomerNotFound).
+					Once()
+			},
+			expectedError: ErrCustomerNotFound,
+		},
+		{
+			name:       "invalid page token",
+			customerID: order.CustomerID,
+			pageToken:  "not a cursor",
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("CustomerStats", mock.Anything, order.CustomerID).
+					Return(stats, nil).
+					Once()
+			},
+			expectedError: ErrInvalidQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			tt.setupMocks(mockRepo)
+			service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+			page, err := service.ListCustomerOrders(context.Background(), tt.customerID, 0, tt.pageToken)
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, page)
+		})
+	}
+}
+
+func TestService_EraseCustomer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockOrderRepository, *MockOrderCache)
+		expectedError error
+	}{
+		{
+			name: "success",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				repo.On("EraseCustomer", mock.Anything, "customer", mock.AnythingOfType("*domain.Erasure")).
+					Run(func(args mock.Arguments) {
+						e := args.Get(2).(*domain.Erasure)
+						e.ID = 7
+						e.OrderUIDs = []string{"uid1", "uid2"}
+					}).
+					Return(nil).
+					Once()
+				cache.On("Delete", mock.Anything, []string{"uid1", "uid2"}).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "cache eviction failure does not fail erasure",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				repo.On("EraseCustomer", mock.Anything, "customer", mock.AnythingOfType("*domain.Erasure")).
+					Run(func(args mock.Arguments) {
+						e := args.Get(2).(*domain.Erasure)
+						e.ID = 7
+						e.OrderUIDs = []string{"uid1", "uid2"}
+					}).
+					Return(nil).
+					Once()
+				cache.On("Delete", mock.Anything, []string{"uid1", "uid2"}).
+					Return(errors.New("redis down")).
+					Once()
+			},
+		},
+		{
+			name: "customer not found",
+			setupMocks: func(repo *MockOrderRepository, _ *MockOrderCache) {
+				repo.On("EraseCustomer", mock.Anything, "customer", mock.Anything).
+					Return(repository.ErrCustomerNotFound).
+					Once()
+			},
+			expectedError: ErrCustomerNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			tt.setupMocks(mockRepo, mockCache)
+			mockPublisher := NewMockOrderPublisher(t)
+			if tt.expectedError == nil {
+				mockPublisher.On("Forget", "customer").Return().Once()
+			}
+
+			service := New(mockRepo, mockCache, mockPublisher)
+
+			erasure, err := service.EraseCustomer(context.Background(), "customer", "ops", "request #1")
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, erasure)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), erasure.ID)
+			assert.Equal(t, "ops", erasure.RequestedBy)
+			assert.Equal(t, "request #1", erasure.Reason)
+			assert.Len(t, erasure.CustomerHash, 64)
+			assert.NotContains(t, erasure.Pseudonym, "customer")
+		})
+	}
+}
+
+func TestService_EraseCustomer_KeyedHash(t *testing.T) {
+	t.Parallel()
+
+	erase := func(secret string) *domain.Erasure {
+		mockRepo := NewMockOrderRepository(t)
+		mockRepo.On("EraseCustomer", mock.Anything, "customer", mock.AnythingOfType("*domain.Erasure")).
+			Run(func(args mock.Arguments) {
+				args.Get(2).(*domain.Erasure).OrderUIDs = []string{"uid1"}
+			}).
+			Return(nil).
+			Once()
+		mockCache := NewMockOrderCache(t)
+		mockCache.On("Delete", mock.Anything, []string{"uid1"}).Return(nil).Once()
+		mockPublisher := NewMockOrderPublisher(t)
+		mockPublisher.On("Forget", "customer").Return().Once()
+		service := New(mockRepo, mockCache, mockPublisher).WithErasureSecret(secret)
+		erasure, err := service.EraseCustomer(context.Background(), "customer", "ops", "")
+		require.NoError(t, err)
+		return erasure
+	}
+
+	first, second := erase("secret"), erase("secret")
+	digest := sha256.Sum256([]byte("customer"))
+	assert.Equal(t, first.CustomerHash, second.CustomerHash)
+	assert.NotEqual(t, hex.EncodeToString(digest[:]), first.CustomerHash)
+	assert.NotEqual(t, first.CustomerHash, erase("other").CustomerHash)
+	assert.NotEqual(t, first.Pseudonym, second.Pseudonym)
+	assert.NotContains(t, first.Pseudonym, first.CustomerHash[:16])
+}
+
+func TestService_ListOrders(t *testing.T) {
+	t.Parallel()
+
+	first := *test.GenerateOrder()
+	second := *test.GenerateOrder()
+	second.DateCreated = first.DateCreated.Add(-time.Hour)
+	filter := domain.OrderFilter{CustomerID: first.CustomerID}
+
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("List", mock.Anything, filter, domain.PageRequest{Limit: 2}).
+		Return([]domain.Order{first, second}, nil).
+		Once()
+	cursor := domain.Cursor{DateCreated: first.DateCreated, OrderUID: first.OrderUID}
+	mockRepo.On("List", mock.Anything, filter, mock.MatchedBy(func(p domain.PageRequest) bool {
+		return p.Limit == 2 && p.After != nil && p.After.OrderUID == first.OrderUID && p.After.DateCreated.Equal(first.DateCreated)
+	})).
+		Return([]domain.Order{second}, nil).
+		Once()
+
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+	page, err := service.ListOrders(context.Background(), filter, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Order{first}, page.Orders)
+	assert.Equal(t, cursor.Encode(), page.NextCursor)
+
+	page, err = service.ListOrders(context.Background(), filter, 1, page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Order{second}, page.Orders)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = service.ListOrders(context.Background(), filter, 1, "not a cursor")
+	require.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = service.ListOrders(context.Background(), domain.OrderFilter{
+		CreatedFrom: first.DateCreated,
+		CreatedTo:   second.DateCreated,
+	}, 1, "")
+	require.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestService_ListOrders_TrackNumber(t *testing.T) {
+	t.Parallel()
+
+	older := *test.GenerateOrder()
+	newer := *test.GenerateOrder()
+	newer.TrackNumber = older.TrackNumber
+	newer.DateCreated = older.DateCreated.Add(time.Hour)
+	filter := domain.OrderFilter{TrackNumber: older.TrackNumber}
+
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("List", mock.Anything, filter, domain.PageRequest{Limit: DefaultPageSize + 1}).
+		Return([]domain.Order{newer, older}, nil).
+		Once()
+
+	// The cache is not consulted: its track number index can hold only some
+	// of the orders with the track number.
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+	page, err := service.ListOrders(context.Background(), filter, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Order{newer, older}, page.Orders)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestService_DailyStats(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	stats := []domain.DailyStats{{Day: from, Group: "wbpay", Currency: "USD", Orders: 1}}
+
+	tests := []struct {
+		name          string
+		query         domain.StatsQuery
+		setupMocks    func(*MockOrderRepository)
+		expectedError error
+	}{
+		{
+			name:  "success",
+			query: domain.StatsQuery{From: from.Add(time.Hour), To: to, GroupBy: domain.DimensionProvider},
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("DailyStats", mock.Anything, domain.StatsQuery{From: from, To: to, GroupBy: domain.DimensionProvider}).
+					Return(stats, nil).
+					Once()
+			},
+		},
+		{
+			name:          "unknown group",
+			query:         domain.StatsQuery{GroupBy: "customer_id"},
+			setupMocks:    func(_ *MockOrderRepository) {},
+			expectedError: ErrInvalidQuery,
+		},
+		{
+			name:          "empty range",
+			query:         domain.StatsQuery{From: to, To: to.Add(time.Hour)},
+			setupMocks:    func(_ *MockOrderRepository) {},
+			expectedError: ErrInvalidQuery,
+		},
+		{
+			name:          "range too long",
+			query:         domain.StatsQuery{From: from.AddDate(-2, 0, 0), To: to},
+			setupMocks:    func(_ *MockOrderRepository) {},
+			expectedError: ErrInvalidQuery,
+		},
+		{
+			name:  "repository error",
+			query: domain.StatsQuery{From: from, To: to},
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("DailyStats", mock.Anything, domain.StatsQuery{From: from, To: to}).
+					Return(nil, errors.New("database error")).
+					Once()
+			},
+			expectedError: errors.New("failed to get daily stats: database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			tt.setupMocks(mockRepo)
+			service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+			report, err := service.DailyStats(context.Background(), tt.query)
+			switch {
+			case errors.Is(tt.expectedError, ErrInvalidQuery):
+				require.ErrorIs(t, err, ErrInvalidQuery)
+			case tt.expectedError != nil:
+				require.EqualError(t, err, tt.expectedError.Error())
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, &domain.DailyStatsReport{From: from, To: to, GroupBy: domain.DimensionProvider, Stats: stats}, report)
+			}
+		})
+	}
+}
+
+func TestService_DailyStats_DefaultRange(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("DailyStats", mock.Anything, mock.Anything).
+		Return([]domain.DailyStats{}, nil).
+		Once()
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+	before := time.Now()
+	report, err := service.DailyStats(context.Background(), domain.StatsQuery{})
+	require.NoError(t, err)
+	assert.True(t, report.To.After(before), "today is included")
+	assert.LessOrEqual(t, report.To.Sub(before), 24*time.Hour)
+	assert.Equal(t, report.To.Truncate(24*time.Hour), report.To)
+	assert.Equal(t, report.To.AddDate(0, 0, -DefaultStatsDays), report.From)
+}
+
+func TestService_DailyStats_Totals(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC)
+	stats := []domain.DailyStats{
+		{Day: day, Group: "alpha", Currency: "EUR", Orders: 2, Items: 3, Amount: decimal.NewFromInt(100), BaseAmount: decimal.NewFromInt(113), BaseDeliveryCost: decimal.NewFromInt(11)},
+		{Day: day, Group: "alpha", Currency: "RUB", Orders: 1, Items: 1, Amount: decimal.NewFromInt(500), Unconverted: 1},
+		{Day: day, Group: "alpha", Currency: "USD", Orders: 1, Items: 2, Amount: decimal.NewFromInt(10), BaseAmount: decimal.NewFromInt(10), BaseDeliveryCost: decimal.NewFromInt(1)},
+		{Day: day, Group: "sber", Currency: "USD", Orders: 1, Items: 1, Amount: decimal.NewFromInt(20), BaseAmount: decimal.NewFromInt(20)},
+	}
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("DailyStats", mock.Anything, mock.Anything).
+		Return(stats, nil).
+		Once()
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t)).WithBaseCurrency("USD")
+
+	report, err := service.DailyStats(context.Background(), domain.StatsQuery{From: day, To: day.AddDate(0, 0, 1), GroupBy: domain.DimensionBank})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", report.BaseCurrency)
+	require.Len(t, report.Totals, 2)
+	alpha := report.Totals[0]
+	assert.Equal(t, "alpha", alpha.Group)
+	assert.Equal(t, 4, alpha.Orders)
+	assert.Equal(t, 6, alpha.Items)
+	assert.True(t, decimal.NewFromInt(123).Equal(alpha.Amount), alpha.Amount.String())
+	assert.True(t, decimal.NewFromInt(12).Equal(alpha.DeliveryCost), alpha.DeliveryCost.String())
+	assert.Equal(t, 1, alpha.Unconverted)
+	assert.Equal(t, "sber", report.Totals[1].Group)
+	assert.Equal(t, 1, report.Totals[1].Orders)
+}
+
+func TestService_TopBrands(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	day := domain.DailyBrands{Day: from}
+	day.AddBrand(domain.BrandSales{Brand: "small", Currency: "USD", Items: 1, Orders: 1, Revenue: decimal.NewFromInt(10), BaseRevenue: decimal.NewFromInt(10)})
+	day.AddBrand(domain.BrandSales{Brand: "large", Currency: "USD", Items: 5, Orders: 2, Revenue: decimal.NewFromInt(50), BaseRevenue: decimal.NewFromInt(50)})
+	day.AddBrand(domain.BrandSales{Brand: "large", Currency: "EUR", Items: 1, Orders: 1, Revenue: decimal.NewFromInt(20), BaseRevenue: decimal.NewFromInt(22)})
+	day.AddBrand(domain.BrandSales{Brand: "medium", Currency: "RUB", Items: 3, Orders: 3, Revenue: decimal.NewFromInt(30), Unconverted: 3})
+
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("BrandStats", mock.Anything, from, to).
+		Return([]domain.DailyBrands{day}, nil).
+		Once()
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t)).WithBaseCurrency("USD")
+
+	report, err := service.TopBrands(context.Background(), from, to, 2)
+	require.NoError(t, err)
+	require.Len(t, report.Days, 1)
+	brands := report.Days[0].Brands
+	require.Len(t, brands, 2)
+	assert.Equal(t, "large", brands[0].Brand)
+	assert.Equal(t, 6, brands[0].Items)
+	assert.Equal(t, []string{"EUR", "USD"}, []string{brands[0].Revenue[0].Currency, brands[0].Revenue[1].Currency})
+	assert.Equal(t, "USD", brands[0].BaseRevenue.Currency)
+	assert.True(t, decimal.NewFromInt(72).Equal(brands[0].BaseRevenue.Amount))
+	assert.Equal(t, "medium", brands[1].Brand)
+	assert.Equal(t, 3, brands[1].BaseRevenue.Unconverted)
+
+	_, err = service.TopBrands(context.Background(), from, to, -1)
+	require.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestService_GetOrderByTransaction(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	transaction := order.Payment.Transaction
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockOrderRepository, *MockOrderCache)
+		expectedError error
+	}{
+		{
+			name: "from cache index",
+			setupMocks: func(_ *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetUIDByTransaction", mock.Anything, transaction).
+					Return(order.OrderUID, nil).
+					Once()
+				cache.On("Get", mock.Anything, order.OrderUID).
+					Return(order, nil).
+					Once()
+			},
+		},
+		{
+			name: "cache miss reads database and backfills cache",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetUIDByTransaction", mock.Anything, transaction).
+					Return("", repository.ErrOrderNotFound).
+					Once()
+				repo.On("GetByTransaction", mock.Anything, transaction).
+					Return(order, nil).
+					Once()
+				cache.On("Set", mock.Anything, order).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "index points to another order",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				other := test.GenerateOrder()
+				cache.On("GetUIDByTransaction", mock.Anything, transaction).
+					Return(other.OrderUID, nil).
+					Once()
+				cache.On("Get", mock.Anything, other.OrderUID).
+					Return(other, nil).
+					Once()
+				repo.On("GetByTransaction", mock.Anything, transaction).
+					Return(order, nil).
+					Once()
+				cache.On("Set", mock.Anything, order).
+					Return(nil).
+					Once()
+			},
+		},
+		{
+			name: "not found",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				cache.On("GetUIDByTransaction", mock.Anything, transaction).
+					Return("", errors.New("cache error")).
+					Once()
+				repo.On("GetByTransaction", mock.Anything, transaction).
+					Return(nil, repository.ErrOrderNotFound).
+					Once()
+			},
+			expectedError: ErrOrderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			tt.setupMocks(mockRepo, mockCache)
+
+			service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
+
+			result, err := service.GetOrderByTransaction(context.Background(), transaction)
+			service.wg.Wait()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, order, result)
+			}
+		})
+	}
+}
+
+func TestService_SearchOrders(t *testing.T) {
+	t.Parallel()
+
+	first := domain.SearchHit{Order: *test.GenerateOrder(), Rank: 0.5, Highlights: []string{"<mark>Vivienne</mark> Sabo Mascaras"}}
+	second := domain.SearchHit{Order: *test.GenerateOrder(), Rank: 0.1}
+
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("Search", mock.Anything, "vivienne", domain.PageRequest{Limit: 2}).
+		Return([]domain.SearchHit{first, second}, nil).
+		Once()
+	mockRepo.On("Search", mock.Anything, "vivienne", domain.PageRequest{Limit: 2, Offset: 1}).
+		Return([]domain.SearchHit{second}, nil).
+		Once()
+	mockRepo.On("Search", mock.Anything, "nothing", domain.PageRequest{Limit: DefaultPageSize + 1}).
+		Return(nil, nil).
+		Once()
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+	page, err := service.SearchOrders(context.Background(), " vivienne ", 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SearchHit{first}, page.Results)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = service.SearchOrders(context.Background(), "vivienne", 1, page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SearchHit{second}, page.Results)
+	assert.Empty(t, page.NextCursor)
+
+	page, err = service.SearchOrders(context.Background(), "nothing", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SearchHit{}, page.Results)
+
+	for _, query := range []string{"", "   ", strings.Repeat("a", maxSearchQueryLength+1)} {
+		_, err = service.SearchOrders(context.Background(), query, 0, "")
+		require.ErrorIs(t, err, ErrInvalidQuery)
+	}
+	_, err = service.SearchOrders(context.Background(), "vivienne", 0, "not a token")
+	require.ErrorIs(t, err, ErrInvalidQuery)
+}
+
 func TestService_CreateOrder(t *testing.T) {
 	t.Parallel()
 
-	logger := zaptest.NewLogger(t)
-	zap.ReplaceGlobals(logger)
-	defer zap.ReplaceGlobals(zap.NewNop())
+	logger := zaptest.NewLogger(t)
+	zap.ReplaceGlobals(logger)
+	defer zap.ReplaceGlobals(zap.NewNop())
+
+	validOrder := test.GenerateOrder()
+
+	invalidOrder := &domain.Order{
+		OrderUID: "",
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockOrderRepository, *MockOrderCache)
+		order         *domain.Order
+		expectedError error
+	}{
+		{
+			name: "success",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				repo.On("Create", mock.Anything, validOrder).
+					Return(nil).
+					Once()
+				cache.On("Set", mock.Anything, validOrder).
+					Return(nil).
+					Once()
+			},
+			order:         validOrder,
+			expectedError: nil,
+		},
+		{
+			name: "invalid order data",
+			setupMocks: func(_ *MockOrderRepository, _ *MockOrderCache) {
+			},
+			order:         invalidOrder,
+			expectedError: ErrInvalidOrderData,
+		},
+		{
+			name: "nil order",
+			setupMocks: func(_ *MockOrderRepository, _ *MockOrderCache) {
+			},
+			order:         nil,
+			expectedError: ErrInvalidOrderData,
+		},
+		{
+			name: "order already exists",
+			setupMocks: func(repo *MockOrderRepository, _ *MockOrderCache) {
+				repo.On("Create", mock.Anything, validOrder).
+					Return(repository.ErrDuplicateOrder).
+					Once()
+			},
+			order:         validOrder,
+			expectedError: ErrOrderAlreadyExists,
+		},
+		{
+			name: "database error",
+			setupMocks: func(repo *MockOrderRepository, _ *MockOrderCache) {
+				repo.On("Create", mock.Anything, validOrder).
+					Return(errors.New("database error")).
+					Once()
+			},
+			order:         validOrder,
+			expectedError: errors.New("failed to create order"),
+		},
+		{
+			name: "cache set error after successful create",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
+				repo.On("Create", mock.Anything, validOrder).
+					Return(nil).
+					Once()
+				cache.On("Set", mock.Anything, validOrder).
+					Return(errors.New("cache error")).
+					Once()
+			},
+			order:         validOrder,
+			expectedError: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			tt.setupMocks(mockRepo, mockCache)
+			mockPublisher := NewMockOrderPublisher(t)
+			if tt.expectedError == nil {
+				mockPublisher.On("Publish", domain.EventOrderCreated, tt.order).
+					Return().
+					Once()
+			}
+
+			service := New(mockRepo, mockCache, mockPublisher)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := service.CreateOrder(ctx, tt.order)
+			service.wg.Wait()
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_CreateOrder_Conversion(t *testing.T) {
+	t.Parallel()
+
+	rate, one := decimal.RequireFromString("1.13"), decimal.NewFromInt(1)
+	tests := []struct {
+		name          string
+		baseCurrency  string
+		currency      string
+		setupMocks    func(*MockOrderRepository, *domain.Order)
+		expectedRate  *decimal.Decimal
+		expectedError string
+	}{
+		{
+			name:         "converted",
+			baseCurrency: "USD",
+			currency:     "EUR",
+			setupMocks: func(repo *MockOrderRepository, order *domain.Order) {
+				repo.On("ExchangeRate", mock.Anything, "USD", "EUR", order.DateCreated).
+					Return(rate, nil).
+					Once()
+			},
+			expectedRate: &rate,
+		},
+		{
+			name:         "lower case codes",
+			baseCurrency: "usd",
+			currency:     "eur",
+			setupMocks: func(repo *MockOrderRepository, order *domain.Order) {
+				repo.On("ExchangeRate", mock.Anything, "USD", "EUR", order.DateCreated).
+					Return(rate, nil).
+					Once()
+			},
+			expectedRate: &rate,
+		},
+		{
+			name:         "base currency",
+			baseCurrency: "USD",
+			currency:     "usd",
+			setupMocks:   func(*MockOrderRepository, *domain.Order) {},
+			expectedRate: &one,
+		},
+		{
+			name:         "no rate",
+			baseCurrency: "USD",
+			currency:     "RUB",
+			setupMocks: func(repo *MockOrderRepository, order *domain.Order) {
+				repo.On("ExchangeRate", mock.Anything, "USD", "RUB", order.DateCreated).
+					Return(decimal.Decimal{}, repository.ErrRateNotFound).
+					Once()
+			},
+		},
+		{
+			name:         "conversion disabled",
+			baseCurrency: "",
+			currency:     "EUR",
+			setupMocks:   func(*MockOrderRepository, *domain.Order) {},
+		},
+		{
+			name:         "rate error",
+			baseCurrency: "USD",
+			currency:     "EUR",
+			setupMocks: func(repo *MockOrderRepository, order *domain.Order) {
+				repo.On("ExchangeRate", mock.Anything, "USD", "EUR", order.DateCreated).
+					Return(decimal.Decimal{}, errors.New("database error")).
+					Once()
+			},
+			expectedError: "failed to get exchange rate: database error",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			order := test.GenerateOrder()
+			order.Payment.Currency = tt.currency
+			order.Payment.Amount = decimal.RequireFromString("100.01")
+			// Base amounts sent by clients are replaced by the computed ones.
+			order.Payment.Convert("XXX", decimal.NewFromInt(7))
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			mockPublisher := NewMockOrderPublisher(t)
+			tt.setupMocks(mockRepo, order)
+			if tt.expectedError == "" {
+				mockRepo.On("Create", mock.Anything, order).Return(nil).Once()
+				mockCache.On("Set", mock.Anything, order).Return(nil).Once()
+				mockPublisher.On("Publish", domain.EventOrderCreated, order).Return().Once()
+			}
+			service := New(mockRepo, mockCache, mockPublisher).WithBaseCurrency(tt.baseCurrency)
+
+			err := service.CreateOrder(context.Background(), order)
+			service.wg.Wait()
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			payment := order.Payment
+			if tt.expectedRate == nil {
+				assert.Empty(t, payment.BaseCurrency)
+				assert.Nil(t, payment.ExchangeRate)
+				assert.Nil(t, payment.BaseAmount)
+				return
+			}
+			assert.Equal(t, strings.ToUpper(tt.baseCurrency), payment.BaseCurrency)
+			assert.True(t, tt.expectedRate.Equal(*payment.ExchangeRate))
+			assert.True(t, payment.Amount.Mul(*tt.expectedRate).Round(2).Equal(*payment.BaseAmount), payment.BaseAmount.String())
+		})
+	}
+}
+
+func TestService_SaveExchangeRates(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC)
+	rate := func(currency string, date time.Time, value string) domain.ExchangeRate {
+		return domain.ExchangeRate{Currency: currency, Date: date, Rate: decimal.RequireFromString(value)}
+	}
 
-	validOrder := test.GenerateOrder()
+	tests := []struct {
+		name         string
+		baseCurrency string
+		rates        []domain.ExchangeRate
+	}{
+		{name: "conversion disabled", rates: []domain.ExchangeRate{rate("EUR", day, "1.13")}},
+		{name: "no rates", baseCurrency: "USD"},
+		{name: "invalid currency", baseCurrency: "USD", rates: []domain.ExchangeRate{rate("EURO", day, "1.13")}},
+		{name: "base currency", baseCurrency: "USD", rates: []domain.ExchangeRate{rate("usd", day, "1")}},
+		{name: "no date", baseCurrency: "USD", rates: []domain.ExchangeRate{rate("EUR", time.Time{}, "1.13")}},
+		{name: "zero rate", baseCurrency: "USD", rates: []domain.ExchangeRate{rate("EUR", day, "0")}},
+		{
+			name:         "duplicate",
+			baseCurrency: "USD",
+			rates:        []domain.ExchangeRate{rate("EUR", day, "1.13"), rate("eur", day.Add(time.Hour), "1.14")},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	invalidOrder := &domain.Order{
-		OrderUID: "",
+			service := New(NewMockOrderRepository(t), NewMockOrderCache(t), NewMockOrderPublisher(t)).
+				WithBaseCurrency(tt.baseCurrency)
+			_, err := service.SaveExchangeRates(context.Background(), tt.rates)
+			require.ErrorIs(t, err, ErrInvalidExchangeRates)
+		})
 	}
 
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		rates := []domain.ExchangeRate{rate("eur", day.Add(15*time.Hour), "1.13"), rate("RUB", day, "0.0133")}
+		mockRepo := NewMockOrderRepository(t)
+		mockRepo.On("SaveExchangeRates", mock.Anything, "USD", []domain.ExchangeRate{rate("EUR", day, "1.13"), rate("RUB", day, "0.0133")}).
+			Return([]string{"a", "b"}, nil).
+			Once()
+		mockCache := NewMockOrderCache(t)
+		mockCache.On("Delete", mock.Anything, []string{"a", "b"}).Return(nil).Once()
+		service := New(mockRepo, mockCache, NewMockOrderPublisher(t)).WithBaseCurrency("usd")
+
+		update, err := service.SaveExchangeRates(context.Background(), rates)
+		require.NoError(t, err)
+		assert.Equal(t, &domain.ExchangeRatesUpdate{Saved: 2, Converted: 2}, update)
+	})
+}
+
+func TestService_ExchangeRates(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC)
+	rates := []domain.ExchangeRate{{Currency: "EUR", Date: day.AddDate(0, 0, -1), Rate: decimal.RequireFromString("1.13")}}
+	mockRepo := NewMockOrderRepository(t)
+	mockRepo.On("ExchangeRates", mock.Anything, "USD", day).
+		Return(rates, nil).
+		Once()
+	service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t)).WithBaseCurrency("USD")
+
+	result, err := service.ExchangeRates(context.Background(), day.Add(10*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, &domain.ExchangeRates{BaseCurrency: "USD", Date: day, Rates: rates}, result)
+
+	_, err = New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t)).ExchangeRates(context.Background(), day)
+	require.ErrorIs(t, err, ErrInvalidExchangeRates)
+}
+
+func TestService_UpsertOrder(t *testing.T) {
+	t.Parallel()
+
 	tests := []struct {
 		name          string
-		setupMocks    func(*MockOrderRepository, *MockOrderCache)
 		order         *domain.Order
+		setupMocks    func(*MockOrderRepository, *MockOrderCache, *MockOrderPublisher, *domain.Order)
 		expectedError error
 	}{
 		{
-			name: "success",
-			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
-				repo.On("Create", mock.Anything, validOrder).
-					Return(nil).
-					Once()
-				cache.On("Set", mock.Anything, validOrder).
-					Return(nil).
-					Once()
+			name:  "created",
+			order: test.GenerateOrder(),
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache, publisher *MockOrderPublisher, order *domain.Order) {
+				repo.On("Upsert", mock.Anything, order).Return(true, nil).Once()
+				publisher.On("Publish", domain.EventOrderCreated, order).Return().Once()
+				cache.On("Set", mock.Anything, mock.Anything).Return(nil).Once()
 			},
-			order:         validOrder,
-			expectedError: nil,
 		},
 		{
-			name: "invalid order data",
-			setupMocks: func(_ *MockOrderRepository, _ *MockOrderCache) {
+			name:  "updated",
+			order: test.GenerateOrder(),
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache, publisher *MockOrderPublisher, order *domain.Order) {
+				repo.On("Upsert", mock.Anything, order).Return(false, nil).Once()
+				publisher.On("Publish", domain.EventOrderUpdated, order).Return().Once()
+				cache.On("Set", mock.Anything, mock.Anything).Return(nil).Once()
 			},
-			order:         invalidOrder,
-			expectedError: ErrInvalidOrderData,
 		},
 		{
-			name: "nil order",
-			setupMocks: func(_ *MockOrderRepository, _ *MockOrderCache) {
+			name: "version without update time",
+			order: func() *domain.Order {
+				order := test.GenerateOrder()
+				order.Version = 3
+				order.UpdatedAt = time.Time{}
+				return order
+			}(),
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache, publisher *MockOrderPublisher, order *domain.Order) {
+				repo.On("Upsert", mock.Anything, mock.MatchedBy(func(o *domain.Order) bool {
+					return !o.UpdatedAt.IsZero()
+				})).Return(false, nil).Once()
+				publisher.On("Publish", domain.EventOrderUpdated, order).Return().Once()
+				cache.On("Set", mock.Anything, mock.Anything).Return(nil).Once()
 			},
-			order:         nil,
+		},
+		{
+			name:  "stale version",
+			order: test.GenerateOrder(),
+			setupMocks: func(repo *MockOrderRepository, _ *MockOrderCache, _ *MockOrderPublisher, order *domain.Order) {
+				repo.On("Upsert", mock.Anything, order).Return(false, repository.ErrStaleOrder).Once()
+			},
+			expectedError: ErrStaleOrder,
+		},
+		{
+			name:          "invalid order data",
+			order:         &domain.Order{},
+			setupMocks:    func(_ *MockOrderRepository, _ *MockOrderCache, _ *MockOrderPublisher, _ *domain.Order) {},
 			expectedError: ErrInvalidOrderData,
 		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockCache := NewMockOrderCache(t)
+			mockPublisher := NewMockOrderPublisher(t)
+			tt.setupMocks(mockRepo, mockCache, mockPublisher, tt.order)
+			service := New(mockRepo, mockCache, mockPublisher)
+
+			err := service.UpsertOrder(context.Background(), tt.order)
+			service.wg.Wait()
+
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_UpdateOrder(t *testing.T) {
+	t.Parallel()
+
+	current := test.GenerateOrder()
+	current.Version = 3
+	etag := current.ETag()
+
+	tests := []struct {
+		name            string
+		uid             string
+		ifMatch         string
+		setupMocks      func(*MockOrderRepository, *MockOrderCache, *MockOrderPublisher)
+		expectedError   error
+		expectedVersion int64
+	}{
 		{
-			name: "order already exists",
-			setupMocks: func(repo *MockOrderRepository, _ *MockOrderCache) {
-				repo.On("Create", mock.Anything, validOrder).
-					Return(repository.ErrDuplicateOrder).
+			name:    "success",
+			uid:     current.OrderUID,
+			ifMatch: etag,
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache, publisher *MockOrderPublisher) {
+				cache.On("Get", mock.Anything, current.OrderUID).Return(current, nil).Once()
+				repo.On("Update", mock.Anything, mock.Anything, int64(3)).
+					Run(func(args mock.Arguments) {
+						args.Get(1).(*domain.Order).Version = 4
+					}).
+					Return(nil).
 					Once()
+				publisher.On("Publish", domain.EventOrderUpdated, mock.Anything).Return().Once()
+				cache.On("Set", mock.Anything, mock.Anything).Return(nil).Once()
 			},
-			order:         validOrder,
-			expectedError: ErrOrderAlreadyExists,
+			expectedVersion: 4,
 		},
 		{
-			name: "database error",
-			setupMocks: func(repo *MockOrderRepository, _ *MockOrderCache) {
-				repo.On("Create", mock.Anything, validOrder).
-					Return(errors.New("database error")).
-					Once()
+			name:    "any version",
+			uid:     current.OrderUID,
+			ifMatch: "*",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache, publisher *MockOrderPublisher) {
+				cache.On("Get", mock.Anything, current.OrderUID).Return(current, nil).Once()
+				repo.On("Update", mock.Anything, mock.Anything, int64(3)).Return(nil).Once()
+				publisher.On("Publish", domain.EventOrderUpdated, mock.Anything).Return().Once()
+				cache.On("Set", mock.Anything, mock.Anything).Return(nil).Once()
 			},
-			order:         validOrder,
-			expectedError: errors.New("failed to create order"),
 		},
 		{
-			name: "cache set error after successful create",
-			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache) {
-				repo.On("Create", mock.Anything, validOrder).
-					Return(nil).
-					Once()
-				cache.On("Set", mock.Anything, validOrder).
-					Return(errors.New("cache error")).
-					Once()
+			name:    "etag mismatch",
+			uid:     current.OrderUID,
+			ifMatch: `"stale"`,
+			setupMocks: func(_ *MockOrderRepository, cache *MockOrderCache, _ *MockOrderPublisher) {
+				cache.On("Get", mock.Anything, current.OrderUID).Return(current, nil).Once()
 			},
-			order:         validOrder,
-			expectedError: nil,
+			expectedError: ErrPreconditionFailed,
+		},
+		{
+			name:    "concurrent update",
+			uid:     current.OrderUID,
+			ifMatch: etag,
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache, _ *MockOrderPublisher) {
+				cache.On("Get", mock.Anything, current.OrderUID).Return(current, nil).Once()
+				repo.On("Update", mock.Anything, mock.Anything, int64(3)).Return(repository.ErrVersionConflict).Once()
+				cache.On("Delete", mock.Anything, []string{current.OrderUID}).Return(nil).Once()
+			},
+			expectedError: ErrPreconditionFailed,
+		},
+		{
+			name:    "order not found",
+			uid:     current.OrderUID,
+			ifMatch: "*",
+			setupMocks: func(repo *MockOrderRepository, cache *MockOrderCache, _ *MockOrderPublisher) {
+				cache.On("Get", mock.Anything, current.OrderUID).Return(nil, repository.ErrOrderNotFound).Once()
+				repo.On("Get", mock.Anything, current.OrderUID).Return(nil, repository.ErrOrderNotFound).Once()
+			},
+			expectedError: ErrOrderNotFound,
+		},
+		{
+			name:          "order uid mismatch",
+			uid:           "other",
+			ifMatch:       "*",
+			setupMocks:    func(_ *MockOrderRepository, _ *MockOrderCache, _ *MockOrderPublisher) {},
+			expectedError: ErrInvalidOrderData,
 		},
 	}
 
@@ -240,25 +1525,247 @@ func TestService_CreateOrder(t *testing.T) {
 
 			mockRepo := NewMockOrderRepository(t)
 			mockCache := NewMockOrderCache(t)
-			tt.setupMocks(mockRepo, mockCache)
+			mockPublisher := NewMockOrderPublisher(t)
+			tt.setupMocks(mockRepo, mockCache, mockPublisher)
+			service := New(mockRepo, mockCache, mockPublisher)
 
-			service := New(mockRepo, mockCache)
+			order := test.GenerateOrder()
+			order.OrderUID = current.OrderUID
+			updated, err := service.UpdateOrder(context.Background(), tt.uid, order, tt.ifMatch)
+			service.wg.Wait()
 
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.False(t, updated.UpdatedAt.IsZero())
+			if tt.expectedVersion != 0 {
+				assert.Equal(t, tt.expectedVersion, updated.Version)
+			}
+		})
+	}
+}
 
-			err := service.CreateOrder(ctx, tt.order)
-			service.wg.Wait()
+func TestService_GetOrderHistory(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	asOf := time.Date(2021, 11, 27, 0, 0, 0, 0, time.UTC)
+	events := []domain.HistoryEvent{
+		{ID: 1, Type: domain.EventOrderCreated, Version: 1, Actor: "kafka", Diff: map[string]any{"order_uid": order.OrderUID}},
+		{ID: 2, Type: domain.EventOrderUpdated, Version: 2, Actor: "ops", Diff: map[string]any{"locale": "ru"}},
+	}
+
+	tests := []struct {
+		name          string
+		asOf          time.Time
+		setupMocks    func(*MockOrderRepository)
+		expectedError error
+		expectOrder   bool
+	}{
+		{
+			name: "all events",
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("History", mock.Anything, order.OrderUID, time.Time{}).Return(events, nil).Once()
+			},
+		},
+		{
+			name: "as of",
+			asOf: asOf,
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("History", mock.Anything, order.OrderUID, asOf).Return(events[:1], nil).Once()
+				repo.On("OrderAsOf", mock.Anything, order.OrderUID, asOf).Return(order, nil).Once()
+			},
+			expectOrder: true,
+		},
+		{
+			name: "no history",
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("History", mock.Anything, order.OrderUID, time.Time{}).Return(nil, nil).Once()
+			},
+			expectedError: ErrOrderNotFound,
+		},
+		{
+			name: "database error",
+			setupMocks: func(repo *MockOrderRepository) {
+				repo.On("History", mock.Anything, order.OrderUID, time.Time{}).Return(nil, errors.New("database error")).Once()
+			},
+			expectedError: errors.New("failed to get order history"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
+			mockRepo := NewMockOrderRepository(t)
+			tt.setupMocks(mockRepo)
+			service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+			history, err := service.GetOrderHistory(context.Background(), order.OrderUID, tt.asOf)
 			if tt.expectedError != nil {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, order.OrderUID, history.OrderUID)
+			assert.NotEmpty(t, history.Events)
+			assert.Equal(t, tt.expectOrder, history.Order != nil)
+		})
+	}
+}
+
+func TestService_DeleteOrder(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := NewMockOrderRepository(t)
+		mockCache := NewMockOrderCache(t)
+		mockPublisher := NewMockOrderPublisher(t)
+		mockRepo.On("Delete", mock.Anything, order.OrderUID, mock.Anything).Return(order, nil).Once()
+		mockCache.On("Delete", mock.Anything, []string{order.OrderUID}).Return(nil).Once()
+		mockPublisher.On("Publish", domain.EventOrderDeleted, order).Return().Once()
+		service := New(mockRepo, mockCache, mockPublisher)
+
+		require.NoError(t, service.DeleteOrder(context.Background(), order.OrderUID))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := NewMockOrderRepository(t)
+		mockRepo.On("Delete", mock.Anything, order.OrderUID, mock.Anything).Return(nil, repository.ErrOrderNotFound).Once()
+		service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+		require.ErrorIs(t, service.DeleteOrder(context.Background(), order.OrderUID), ErrOrderNotFound)
+	})
+}
+
+func TestService_RestoreOrder(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := NewMockOrderRepository(t)
+		mockCache := NewMockOrderCache(t)
+		mockPublisher := NewMockOrderPublisher(t)
+		mockRepo.On("Undelete", mock.Anything, order.OrderUID, mock.Anything).Return(order, nil).Once()
+		mockPublisher.On("Publish", domain.EventOrderRestored, order).Return().Once()
+		mockCache.On("Set", mock.Anything, mock.Anything).Return(nil).Once()
+		service := New(mockRepo, mockCache, mockPublisher)
+
+		restored, err := service.RestoreOrder(context.Background(), order.OrderUID)
+		service.wg.Wait()
+		require.NoError(t, err)
+		assert.Equal(t, order, restored)
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := NewMockOrderRepository(t)
+		mockRepo.On("Undelete", mock.Anything, order.OrderUID, mock.Anything).Return(nil, repository.ErrOrderNotFound).Once()
+		service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+		_, err := service.RestoreOrder(context.Background(), order.OrderUID)
+		require.ErrorIs(t, err, ErrOrderNotFound)
+	})
+}
+
+func TestService_PurgeDeletedOrders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		batches       []int
+		err           error
+		expectedTotal int
+	}{
+		{name: "several batches", batches: []int{2, 2, 1}, expectedTotal: 5},
+		{name: "nothing to purge", batches: []int{0}, expectedTotal: 0},
+		{name: "database error", batches: []int{2, 0}, err: errors.New("database error"), expectedTotal: 2},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			for i, n := range tt.batches {
+				var err error
+				if i == len(tt.batches)-1 {
+					err = tt.err
+				}
+				mockRepo.On("Purge", mock.Anything, mock.Anything, 2).Return(n, err).Once()
+			}
+			service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+			purged, err := service.PurgeDeletedOrders(context.Background(), time.Hour, 2)
+			if tt.err != nil {
+				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
 			}
+			assert.Equal(t, tt.expectedTotal, purged)
+		})
+	}
+}
 
-			mockRepo.AssertExpectations(t)
-			mockCache.AssertExpectations(t)
+func TestService_MaintainPartitions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		retainMonths     int
+		ensureErr        error
+		detached         []string
+		expectedDetached []string
+		expectError      bool
+	}{
+		{name: "create only", retainMonths: 0},
+		{
+			name:             "create and detach",
+			retainMonths:     2,
+			detached:         []string{"orders_y2021m11"},
+			expectedDetached: []string{"orders_y2021m11"},
+		},
+		{name: "create error", retainMonths: 2, ensureErr: errors.New("database error"), expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := NewMockOrderRepository(t)
+			mockRepo.On("EnsurePartitions", mock.Anything, month, month.AddDate(0, 3, 0)).Return(1, tt.ensureErr).Once()
+			if tt.retainMonths > 0 && tt.ensureErr == nil {
+				mockRepo.On("DetachPartitions", mock.Anything, month.AddDate(0, -tt.retainMonths, 0)).Return(tt.detached, nil).Once()
+			}
+			service := New(mockRepo, NewMockOrderCache(t), NewMockOrderPublisher(t))
+
+			created, detached, err := service.MaintainPartitions(context.Background(), 3, tt.retainMonths)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, created)
+			assert.Equal(t, tt.expectedDetached, detached)
 		})
 	}
 }
@@ -283,7 +1790,7 @@ func TestService_ContextCancellation(t *testing.T) {
 			}).
 			Once()
 
-		service := New(mockRepo, mockCache)
+		service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
 		defer cancel()
@@ -309,8 +1816,12 @@ func TestService_ContextCancellation(t *testing.T) {
 		mockCache.On("Set", mock.Anything, validOrder).
 			Return(context.Canceled).
 			Once()
+		mockPublisher := NewMockOrderPublisher(t)
+		mockPublisher.On("Publish", domain.EventOrderCreated, validOrder).
+			Return().
+			Once()
 
-		service := New(mockRepo, mockCache)
+		service := New(mockRepo, mockCache, mockPublisher)
 
 		ctx := context.Background()
 		err := service.CreateOrder(ctx, validOrder)
@@ -330,7 +1841,7 @@ func TestService_EdgeCases(t *testing.T) {
 	t.Run("nil order in CreateOrder", func(t *testing.T) {
 		t.Parallel()
 
-		service := New(NewMockOrderRepository(t), NewMockOrderCache(t))
+		service := New(NewMockOrderRepository(t), NewMockOrderCache(t), NewMockOrderPublisher(t))
 		err := service.CreateOrder(context.Background(), nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), ErrInvalidOrderData.Error())
@@ -349,7 +1860,7 @@ func TestService_EdgeCases(t *testing.T) {
 			Return(nil, repository.ErrOrderNotFound).
 			Once()
 
-		service := New(mockRepo, mockCache)
+		service := New(mockRepo, mockCache, NewMockOrderPublisher(t))
 		_, err := service.GetOrder(context.Background(), "")
 
 		require.Error(t, err)
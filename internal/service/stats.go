@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"time"
+)
+
+const (
+	DefaultStatsDays = 30
+	MaxStatsDays     = 366
+	DefaultTopBrands = 10
+	MaxTopBrands     = 100
+)
+
+// DailyStats returns the daily statistics of the orders created from
+// query.From to query.To, grouped by query.GroupBy and the currency. A zero To
+// is tomorrow and a zero From is DefaultStatsDays before To; both are
+// truncated to UTC days. When amounts are converted, the report also has the
+// totals of every day and group in the base currency.
+func (s *Service) DailyStats(ctx context.Context, query domain.StatsQuery) (*domain.DailyStatsReport, error) {
+	if !query.GroupBy.Valid() {
+		return nil, fmt.Errorf("%w: unknown group_by %q", ErrInvalidQuery, query.GroupBy)
+	}
+	var err error
+	if query.From, query.To, err = statsRange(query.From, query.To); err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.DailyStats(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily stats: %w", err)
+	}
+	report := &domain.DailyStatsReport{From: query.From, To: query.To, GroupBy: query.GroupBy, Stats: stats}
+	if s.baseCurrency != "" {
+		report.BaseCurrency = s.baseCurrency
+		report.AddTotals()
+	}
+	return report, nil
+}
+
+// TopBrands returns the limit brands with the most items sold on every day
+// from from to to, which default as in DailyStats. A zero limit is
+// DefaultTopBrands.
+func (s *Service) TopBrands(ctx context.Context, from, to time.Time, limit int) (*domain.TopBrandsReport, error) {
+	switch {
+	case limit < 0:
+		return nil, fmt.Errorf("%w: negative limit", ErrInvalidQuery)
+	case limit == 0:
+		limit = DefaultTopBrands
+	case limit > MaxTopBrands:
+		limit = MaxTopBrands
+	}
+	from, to, err := statsRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.repo.BrandStats(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand stats: %w", err)
+	}
+	for i := range days {
+		days[i].Top(limit)
+		for j := range days[i].Brands {
+			brand := &days[i].Brands[j]
+			brand.BaseRevenue = s.baseTotal(brand.BaseRevenue)
+		}
+	}
+	return &domain.TopBrandsReport{From: from, To: to, Days: days}, nil
+}
+
+// RefreshStats recomputes the statistics from the orders. It reports false
+// when another instance is already doing it.
+func (s *Service) RefreshStats(ctx context.Context) (bool, error) {
+	refreshed, err := s.repo.RefreshStats(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh stats: %w", err)
+	}
+	return refreshed, nil
+}
+
+func statsRange(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = time.Now().UTC().AddDate(0, 0, 1)
+	}
+	to = startOfDay(to)
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -DefaultStatsDays)
+	}
+	from = startOfDay(from)
+	switch {
+	case !from.Before(to):
+		return from, to, fmt.Errorf("%w: from must be before to", ErrInvalidQuery)
+	case from.AddDate(0, 0, MaxStatsDays).Before(to):
+		return from, to, fmt.Errorf("%w: at most %d days", ErrInvalidQuery, MaxStatsDays)
+	}
+	return from, to, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/repository"
+	"github.com/Killazius/L0/pkg/validate"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// UpsertOrder creates the order or replaces the stored one if the order is
+// newer, see domain.Order.IsNewerThan. Older or repeated versions fail with
+// ErrStaleOrder. A versioned order without an update time is stamped with the
+// current time; an order with neither still never replaces a stored one.
+func (s *Service) UpsertOrder(ctx context.Context, order *domain.Order) error {
+	if order == nil {
+		return fmt.Errorf("%w: order is nil", ErrInvalidOrderData)
+	}
+	if err := validate.Order(order); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOrderData, err)
+	}
+	if order.Version > 0 && order.UpdatedAt.IsZero() {
+		order.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	}
+	if err := s.convert(ctx, order); err != nil {
+		return err
+	}
+	created, err := s.repo.Upsert(ctx, order)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrStaleOrder):
+			return fmt.Errorf("%w: %w", ErrStaleOrder, err)
+		default:
+			return fmt.Errorf("failed to upsert order: %w", err)
+		}
+	}
+	if created {
+		s.publisher.Publish(domain.EventOrderCreated, order)
+	} else {
+		s.publisher.Publish(domain.EventOrderUpdated, order)
+	}
+	s.cacheOrders(ctx, []domain.Order{*order})
+	return nil
+}
+
+// UpdateOrder replaces the order if ifMatch, an If-Match header value, matches
+// the entity tag of the current order, and returns the order with its new
+// version. The tag is checked against the order clients read, the cached one,
+// and the stored version guards against a concurrent update; either mismatch
+// fails with ErrPreconditionFailed.
+func (s *Service) UpdateOrder(ctx context.Context, uid string, order *domain.Order, ifMatch string) (*domain.Order, error) {
+	if order == nil {
+		return nil, fmt.Errorf("%w: order is nil", ErrInvalidOrderData)
+	}
+	if order.OrderUID != uid {
+		return nil, fmt.Errorf("%w: order_uid %q does not match %q", ErrInvalidOrderData, order.OrderUID, uid)
+	}
+	if err := validate.Order(order); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOrderData, err)
+	}
+
+	current, err := s.GetOrder(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !ifMatchSatisfied(ifMatch, current.ETag()) {
+		return nil, fmt.Errorf("%w: order %s has changed", ErrPreconditionFailed, uid)
+	}
+	// Orders cached before versioning have no version, they are stored as 1.
+	expected := max(current.Version, 1)
+
+	if err := s.convert(ctx, order); err != nil {
+		return nil, err
+	}
+	order.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	if err := s.repo.Update(ctx, order, expected); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			// The cached order is out of date, the next read refreshes it.
+			s.evict(ctx, uid)
+			return nil, fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+		case errors.Is(err, repository.ErrOrderNotFound):
+			return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, uid)
+		default:
+			return nil, fmt.Errorf("failed to update order: %w", err)
+		}
+	}
+	s.publisher.Publish(domain.EventOrderUpdated, order)
+	s.cacheOrders(ctx, []domain.Order{*order})
+	return order, nil
+}
+
+func (s *Service) evict(ctx context.Context, uids ...string) {
+	cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheTimeout)
+	defer cancel()
+	if err := s.cache.Delete(cacheCtx, uids...); err != nil {
+		zap.L().Warn("failed to evict orders", zap.Strings("order_uids", uids), zap.Error(err))
+	}
+}
+
+// ifMatchSatisfied reports whether the ETag satisfies an If-Match header value
+// using the strong comparison defined in RFC 9110.
+func ifMatchSatisfied(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
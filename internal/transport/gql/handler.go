@@ -0,0 +1,98 @@
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/go-chi/render"
+	"github.com/graph-gophers/graphql-go"
+	gqllog "github.com/graph-gophers/graphql-go/log"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+const maxRequestBytes = 1 << 20
+
+//go:embed schema.graphql
+var schemaSDL string
+
+//go:embed graphiql.html
+var graphiqlPage []byte
+
+type OrderService interface {
+	GetOrder(ctx context.Context, uid string) (*domain.Order, error)
+	ListOrderHeaders(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string) (*domain.OrderPage, error)
+	GetDeliveries(ctx context.Context, orderUIDs []string) (map[string]domain.Delivery, error)
+	GetPayments(ctx context.Context, orderUIDs []string) (map[string]domain.Payment, error)
+	GetItems(ctx context.Context, orderUIDs []string) (map[string][]domain.Item, error)
+}
+
+// Handler serves GraphQL queries over orders. Authentication and rate limiting
+// are applied by the REST router in front of it.
+type Handler struct {
+	log           *zap.SugaredLogger
+	service       OrderService
+	schema        *graphql.Schema
+	maxComplexity int
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func NewHandler(log *zap.SugaredLogger, service OrderService, cfg config.GraphQLConfig) (*Handler, error) {
+	schema, err := graphql.ParseSchema(schemaSDL, &resolver{log: log, service: service},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(cfg.MaxDepth),
+		graphql.MaxQueryLength(maxRequestBytes),
+		graphql.Logger(gqllog.LoggerFunc(func(_ context.Context, value any) {
+			log.Errorw("panic in graphql resolver", "panic", value)
+		})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse graphql schema: %w", err)
+	}
+	return &Handler{
+		log:           log,
+		service:       service,
+		schema:        schema,
+		maxComplexity: cfg.MaxComplexity,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.NewErrorResponse("invalid request", http.StatusBadRequest, "The body must be a JSON GraphQL request"))
+		return
+	}
+	if req.Query == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.NewErrorResponse("invalid request", http.StatusBadRequest, "The query is required"))
+		return
+	}
+
+	ctx := withLoaders(r.Context(), newLoaders(h.service))
+	ctx = withBudget(ctx, h.maxComplexity)
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	if len(resp.Errors) > 0 {
+		h.log.Infow("graphql query failed", "operation", req.OperationName, "errors", resp.Errors)
+	}
+	render.JSON(w, r, resp)
+}
+
+// GraphiQL serves the GraphiQL editor for the endpoint. The page loads the
+// editor from a CDN, so it is meant for development only.
+func GraphiQL() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(graphiqlPage)
+	}
+}
@@ -0,0 +1,176 @@
+package gql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/test"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type gqlResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newHandler(t *testing.T, svc OrderService, cfg config.GraphQLConfig) *Handler {
+	t.Helper()
+	if cfg.MaxDepth == 0 {
+		cfg.MaxDepth = 8
+	}
+	if cfg.MaxComplexity == 0 {
+		cfg.MaxComplexity = 2500
+	}
+	h, err := NewHandler(zap.NewNop().Sugar(), svc, cfg)
+	require.NoError(t, err)
+	return h
+}
+
+func exec(t *testing.T, h http.Handler, role auth.Role, query string, variables map[string]any) gqlResponse {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{Subject: "test", Role: role}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp gqlResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestHandler_Orders_BatchesParts(t *testing.T) {
+	t.Parallel()
+
+	o1, o2 := test.GenerateOrder(), test.GenerateOrder()
+	headers := []domain.Order{*o1, *o2}
+	for i := range headers {
+		headers[i].Delivery = domain.Delivery{}
+		headers[i].Payment = domain.Payment{}
+		headers[i].Items = nil
+	}
+	uids := []string{o1.OrderUID, o2.OrderUID}
+
+	svc := NewMockOrderService(t)
+	svc.On("ListOrderHeaders", mock.Anything, domain.OrderFilter{CustomerID: "c1"}, 2, "").
+		Return(&domain.OrderPage{Orders: headers, NextCursor: "next"}, nil).
+		Once()
+	svc.On("GetItems", mock.Anything, mock.MatchedBy(func(keys []string) bool {
+		return assert.ElementsMatch(t, uids, keys)
+	})).
+		Return(map[string][]domain.Item{o1.OrderUID: o1.Items, o2.OrderUID: o2.Items}, nil).
+		Once()
+	svc.On("GetPayments", mock.Anything, mock.MatchedBy(func(keys []string) bool {
+		return assert.ElementsMatch(t, uids, keys)
+	})).
+		Return(map[string]domain.Payment{o1.OrderUID: o1.Payment, o2.OrderUID: o2.Payment}, nil).
+		Once()
+
+	resp := exec(t, newHandler(t, svc, config.GraphQLConfig{}), auth.RoleViewer, `
+		query($customer: String) {
+			orders(filter: {customerId: $customer}, first: 2) {
+				nodes { orderUid items { name price } payment { amount currency } }
+				pageInfo { hasNextPage endCursor }
+			}
+		}`, map[string]any{"customer": "c1"})
+	require.Empty(t, resp.Errors)
+
+	orders := resp.Data["orders"].(map[string]any)
+	nodes := orders["nodes"].([]any)
+	require.Len(t, nodes, 2)
+	first := nodes[0].(map[string]any)
+	assert.Equal(t, o1.OrderUID, first["orderUid"])
+	assert.Equal(t, o1.Payment.Amount.String(), first["payment"].(map[string]any)["amount"])
+	assert.Len(t, first["items"], len(o1.Items))
+	assert.Equal(t, map[string]any{"hasNextPage": true, "endCursor": "next"}, orders["pageInfo"])
+}
+
+func TestHandler_Order(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	svc := NewMockOrderService(t)
+	svc.On("GetOrder", mock.Anything, order.OrderUID).Return(order, nil).Twice()
+	svc.On("GetOrder", mock.Anything, "missing").Return(nil, service.ErrOrderNotFound).Once()
+	h := newHandler(t, svc, config.GraphQLConfig{})
+	query := `query($uid: String!) { order(uid: $uid) { orderUid delivery { phone city } } }`
+
+	resp := exec(t, h, auth.RoleViewer, query, map[string]any{"uid": order.OrderUID})
+	require.Empty(t, resp.Errors)
+	delivery := resp.Data["order"].(map[string]any)["delivery"].(map[string]any)
+	assert.NotEqual(t, order.Delivery.Phone, delivery["phone"], "viewer gets masked data")
+	assert.Equal(t, order.Delivery.City, delivery["city"])
+
+	resp = exec(t, h, auth.RoleSupport, query, map[string]any{"uid": order.OrderUID})
+	require.Empty(t, resp.Errors)
+	delivery = resp.Data["order"].(map[string]any)["delivery"].(map[string]any)
+	assert.Equal(t, order.Delivery.Phone, delivery["phone"])
+
+	resp = exec(t, h, auth.RoleViewer, query, map[string]any{"uid": "missing"})
+	require.Empty(t, resp.Errors)
+	assert.Nil(t, resp.Data["order"])
+}
+
+func TestHandler_Limits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		cfg   config.GraphQLConfig
+		query string
+		error string
+	}{
+		{
+			name:  "complexity",
+			cfg:   config.GraphQLConfig{MaxComplexity: 100},
+			query: `{ orders(first: 50) { nodes { orderUid trackNumber items { name } } } }`,
+			error: "complexity limit",
+		},
+		{
+			name:  "depth",
+			cfg:   config.GraphQLConfig{MaxDepth: 2},
+			query: `{ orders { nodes { payment { amount } } } }`,
+			error: "exceeds max depth",
+		},
+		{
+			name:  "unknown field",
+			query: `{ orders { nodes { secret } } }`,
+			error: "Cannot query field",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := exec(t, newHandler(t, NewMockOrderService(t), tt.cfg), auth.RoleAdmin, tt.query, nil)
+			require.NotEmpty(t, resp.Errors)
+			assert.Contains(t, resp.Errors[0].Message, tt.error)
+		})
+	}
+}
+
+func TestHandler_InvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	h := newHandler(t, NewMockOrderService(t), config.GraphQLConfig{})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString("not json"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req.WithContext(context.Background()))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
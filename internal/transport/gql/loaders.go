@@ -0,0 +1,69 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/dataloader"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	loaderWait     = 2 * time.Millisecond
+	loaderMaxBatch = 100
+)
+
+var ErrTooComplex = errors.New("query is too complex")
+
+// loaders batch the parts of orders listed in one request, so a page of orders
+// costs one query per selected part instead of one per order.
+type loaders struct {
+	deliveries *dataloader.Loader[string, domain.Delivery]
+	payments   *dataloader.Loader[string, domain.Payment]
+	items      *dataloader.Loader[string, []domain.Item]
+}
+
+func newLoaders(service OrderService) *loaders {
+	return &loaders{
+		deliveries: dataloader.New(service.GetDeliveries, loaderWait, loaderMaxBatch),
+		payments:   dataloader.New(service.GetPayments, loaderWait, loaderMaxBatch),
+		items:      dataloader.New(service.GetItems, loaderWait, loaderMaxBatch),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// budget is the complexity left for a request. Resolvers charge it before they
+// load anything, so a rejected query does not reach the database.
+type budget struct {
+	left atomic.Int64
+}
+
+type budgetKey struct{}
+
+func withBudget(ctx context.Context, limit int) context.Context {
+	b := &budget{}
+	b.left.Store(int64(limit))
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+func charge(ctx context.Context, cost int) error {
+	b, ok := ctx.Value(budgetKey{}).(*budget)
+	if !ok {
+		return nil
+	}
+	if b.left.Add(-int64(cost)) < 0 {
+		return fmt.Errorf("%w: the complexity limit is exceeded, request fewer orders or fields", ErrTooComplex)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/Killazius/L0/pkg/mask"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+var errInternal = errors.New("internal error")
+
+type resolver struct {
+	log     *zap.SugaredLogger
+	service OrderService
+}
+
+func (r *resolver) Order(ctx context.Context, args struct{ UID string }) (*orderResolver, error) {
+	if err := charge(ctx, 1+len(graphql.SelectedFieldNames(ctx))); err != nil {
+		return nil, err
+	}
+	order, err := r.service.GetOrder(ctx, args.UID)
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			return nil, nil
+		}
+		return nil, r.publicError(err, "order_uid", args.UID)
+	}
+	return &orderResolver{order: order, full: true}, nil
+}
+
+type orderFilterInput struct {
+	CustomerID      *string
+	DeliveryService *string
+	CreatedFrom     *graphql.Time
+	CreatedTo       *graphql.Time
+}
+
+type ordersArgs struct {
+	Filter *orderFilterInput
+	First  int32
+	After  *string
+}
+
+func (r *resolver) Orders(ctx context.Context, args ordersArgs) (*connectionResolver, error) {
+	first := min(int(args.First), service.MaxPageSize)
+	if first == 0 {
+		first = service.DefaultPageSize
+	}
+	nodeFields := 0
+	for _, name := range graphql.SelectedFieldNames(ctx) {
+		if strings.HasPrefix(name, "nodes.") {
+			nodeFields++
+		}
+	}
+	if err := charge(ctx, 1+max(first, 0)*(1+nodeFields)); err != nil {
+		return nil, err
+	}
+
+	var filter domain.OrderFilter
+	if f := args.Filter; f != nil {
+		filter.CustomerID = deref(f.CustomerID)
+		filter.DeliveryService = deref(f.DeliveryService)
+		if f.CreatedFrom != nil {
+			filter.CreatedFrom = f.CreatedFrom.Time
+		}
+		if f.CreatedTo != nil {
+			filter.CreatedTo = f.CreatedTo.Time
+		}
+	}
+	page, err := r.service.ListOrderHeaders(ctx, filter, first, deref(args.After))
+	if err != nil {
+		return nil, r.publicError(err)
+	}
+
+	// Parts selected for the nodes are queued at once, so the whole page is
+	// loaded in one batch per part.
+	orderUIDs := make([]string, 0, len(page.Orders))
+	for _, order := range page.Orders {
+		orderUIDs = append(orderUIDs, order.OrderUID)
+	}
+	l := loadersFrom(ctx)
+	if graphql.HasSelectedField(ctx, "nodes.delivery") {
+		l.deliveries.Prime(ctx, orderUIDs...)
+	}
+	if graphql.HasSelectedField(ctx, "nodes.payment") {
+		l.payments.Prime(ctx, orderUIDs...)
+	}
+	if graphql.HasSelectedField(ctx, "nodes.items") {
+		l.items.Prime(ctx, orderUIDs...)
+	}
+
+	nodes := make([]*orderResolver, 0, len(page.Orders))
+	for i := range page.Orders {
+		nodes = append(nodes, &orderResolver{order: &page.Orders[i]})
+	}
+	return &connectionResolver{nodes: nodes, endCursor: page.NextCursor}, nil
+}
+
+// publicError hides internal errors from the response, GraphQL returns every
+// resolver error message to the client.
+func (r *resolver) publicError(err error, keysAndValues ...any) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery), errors.Is(err, service.ErrInvalidOrderData):
+		return err
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	default:
+		r.log.Errorw("graphql resolver failed", append(keysAndValues, "error", err)...)
+		return errInternal
+	}
+}
+
+type connectionResolver struct {
+	nodes     []*orderResolver
+	endCursor string
+}
+
+func (c *connectionResolver) Nodes() []*orderResolver {
+	return c.nodes
+}
+
+func (c *connectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{endCursor: c.endCursor}
+}
+
+type pageInfoResolver struct {
+	endCursor string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.endCursor != ""
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	if p.endCursor == "" {
+		return nil
+	}
+	return &p.endCursor
+}
+
+// orderResolver resolves a full order, or the header of a listed order whose
+// parts are loaded through the request loaders.
+type orderResolver struct {
+	order *domain.Order
+	full  bool
+}
+
+func (o *orderResolver) OrderUid() string          { return o.order.OrderUID }
+func (o *orderResolver) TrackNumber() string       { return o.order.TrackNumber }
+func (o *orderResolver) Entry() string             { return o.order.Entry }
+func (o *orderResolver) Locale() string            { return o.order.Locale }
+func (o *orderResolver) InternalSignature() string { return o.order.InternalSignature }
+func (o *orderResolver) CustomerId() string        { return o.order.CustomerID }
+func (o *orderResolver) DeliveryService() string   { return o.order.DeliveryService }
+func (o *orderResolver) Shardkey() string          { return o.order.ShardKey }
+func (o *orderResolver) SmId() int32               { return int32(o.order.SmID) }
+func (o *orderResolver) OofShard() string          { return o.order.OofShard }
+
+func (o *orderResolver) DateCreated() graphql.Time {
+	return graphql.Time{Time: o.order.DateCreated}
+}
+
+func (o *orderResolver) UpdatedAt() *graphql.Time {
+	if o.order.UpdatedAt.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: o.order.UpdatedAt}
+}
+
+func (o *orderResolver) Delivery(ctx context.Context) (*deliveryResolver, error) {
+	delivery := o.order.Delivery
+	if !o.full {
+		var err error
+		delivery, err = load(ctx, loadersFrom(ctx).deliveries.Load, o.order.OrderUID, "delivery")
+		if err != nil {
+			return nil, err
+		}
+	}
+	principal, _ := auth.FromContext(ctx)
+	if !principal.Role.Allows(auth.RoleSupport) {
+		delivery = mask.Masked(delivery)
+	}
+	return &deliveryResolver{delivery: delivery}, nil
+}
+
+func (o *orderResolver) Payment(ctx context.Context) (*paymentResolver, error) {
+	if o.full {
+		return &paymentResolver{payment: o.order.Payment}, nil
+	}
+	payment, err := load(ctx, loadersFrom(ctx).payments.Load, o.order.OrderUID, "payment")
+	if err != nil {
+		return nil, err
+	}
+	return &paymentResolver{payment: payment}, nil
+}
+
+func (o *orderResolver) Items(ctx context.Context) ([]*itemResolver, error) {
+	items := o.order.Items
+	if !o.full {
+		var err error
+		items, err = load(ctx, loadersFrom(ctx).items.Load, o.order.OrderUID, "items")
+		if err != nil {
+			return nil, err
+		}
+	}
+	resolvers := make([]*itemResolver, 0, len(items))
+	for _, item := range items {
+		resolvers = append(resolvers, &itemResolver{item: item})
+	}
+	return resolvers, nil
+}
+
+func load[V any](ctx context.Context, fn func(context.Context, string) (V, bool, error), orderUID, part string) (V, error) {
+	value, found, err := fn(ctx, orderUID)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			zap.L().Error("failed to load order part", zap.String("order_uid", orderUID), zap.String("part", part), zap.Error(err))
+		}
+		return value, errInternal
+	}
+	if !found {
+		return value, fmt.Errorf("%s of order %s not found", part, orderUID)
+	}
+	return value, nil
+}
+
+type deliveryResolver struct {
+	delivery domain.Delivery
+}
+
+func (d *deliveryResolver) Name() string    { return d.delivery.Name }
+func (d *deliveryResolver) Phone() string   { return d.delivery.Phone }
+func (d *deliveryResolver) Zip() string     { return d.delivery.Zip }
+func (d *deliveryResolver) City() string    { return d.delivery.City }
+func (d *deliveryResolver) Address() string { return d.delivery.Address }
+func (d *deliveryResolver) Region() string  { return d.delivery.Region }
+func (d *deliveryResolver) Email() string   { return d.delivery.Email }
+
+type paymentResolver struct {
+	payment domain.Payment
+}
+
+func (p *paymentResolver) Transaction() string  { return p.payment.Transaction }
+func (p *paymentResolver) RequestId() string    { return p.payment.RequestID }
+func (p *paymentResolver) Currency() string     { return p.payment.Currency }
+func (p *paymentResolver) Provider() string     { return p.payment.Provider }
+func (p *paymentResolver) Amount() string       { return p.payment.Amount.String() }
+func (p *paymentResolver) Bank() string         { return p.payment.Bank }
+func (p *paymentResolver) DeliveryCost() string { return p.payment.DeliveryCost.String() }
+func (p *paymentResolver) GoodsTotal() int32    { return int32(p.payment.GoodsTotal) }
+func (p *paymentResolver) CustomFee() int32     { return int32(p.payment.CustomFee) }
+
+// BaseCurrency, ExchangeRate and BaseAmount are null while the amount is not
+// converted to the base currency.
+func (p *paymentResolver) BaseCurrency() *string {
+	if p.payment.BaseCurrency == "" {
+		return nil
+	}
+	return &p.payment.BaseCurrency
+}
+
+func (p *paymentResolver) ExchangeRate() *string { return decimalString(p.payment.ExchangeRate) }
+func (p *paymentResolver) BaseAmount() *string   { return decimalString(p.payment.BaseAmount) }
+
+func decimalString(d *decimal.Decimal) *string {
+	if d == nil {
+		return nil
+	}
+	s := d.String()
+	return &s
+}
+
+func (p *paymentResolver) PaymentDt() graphql.Time {
+	return graphql.Time{Time: time.Unix(p.payment.PaymentDt, 0).UTC()}
+}
+
+type itemResolver struct {
+	item domain.Item
+}
+
+func (i *itemResolver) ChrtId() int32       { return int32(i.item.ChrtID) }
+func (i *itemResolver) TrackNumber() string { return i.item.TrackNumber }
+func (i *itemResolver) Price() string       { return i.item.Price.String() }
+func (i *itemResolver) Rid() string         { return i.item.Rid }
+func (i *itemResolver) Name() string        { return i.item.Name }
+func (i *itemResolver) Sale() int32         { return int32(i.item.Sale) }
+func (i *itemResolver) Size() string        { return i.item.Size }
+func (i *itemResolver) TotalPrice() string  { return i.item.TotalPrice.String() }
+func (i *itemResolver) NmId() int32         { return int32(i.item.NmID) }
+func (i *itemResolver) Brand() string       { return i.item.Brand }
+func (i *itemResolver) Status() int32       { return int32(i.item.Status) }
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+// EraseRequest is the body of an erasure request
+// @Description Erasure request
+type EraseRequest struct {
+	Reason string `json:"reason" example:"GDPR art. 17 request #42"`
+}
+
+// GetCustomer godoc
+// @Summary Get customer by ID
+// @Description Get the contact details of a customer and the address book of every delivery address used in their orders, most recently used first
+// @Tags customers
+// @Produce  json
+// @Param customer_id path string true "Customer ID"
+// @Success 200 {object} domain.Customer "Customer"
+// @Failure 400 {object} response.ErrorResponse "Invalid customer ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 404 {object} response.ErrorResponse "Customer not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /customers/{customer_id} [get]
+func (h *Handler) GetCustomer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		customerID := chi.URLParam(r, "customer_id")
+		if customerID == "" {
+			h.log.Info("customer id is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("customer ID is required", http.StatusBadRequest, "Customer ID parameter is missing"))
+			return
+		}
+		customer, err := h.service.GetCustomer(r.Context(), customerID)
+		if err != nil {
+			h.customerError(w, r, err)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		render.JSON(w, r, customer)
+	}
+}
+
+// ListCustomerOrders godoc
+// @Summary List orders of a customer
+// @Description List the orders of a customer, newest first, with the order count and the lifetime value per currency and in the base currency of all of them. Deleted orders are not counted
+// @Tags customers
+// @Produce  json
+// @Param customer_id path string true "Customer ID"
+// @Param page_size query int false "Page size, at most 100" default(20)
+// @Param page_token query string false "next_cursor of the previous page"
+// @Success 200 {object} domain.CustomerOrders "Page of orders with aggregates"
+// @Failure 400 {object} response.ErrorResponse "Invalid query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 404 {object} response.ErrorResponse "Customer not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /customers/{customer_id}/orders [get]
+func (h *Handler) ListCustomerOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		customerID := chi.URLParam(r, "customer_id")
+		if customerID == "" {
+			h.log.Info("customer id is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("customer ID is required", http.StatusBadRequest, "Customer ID parameter is missing"))
+			return
+		}
+		query := r.URL.Query()
+		pageSize, err := intParam(query, "page_size")
+		if err != nil {
+			h.log.Infow("invalid list query", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		page, err := h.service.ListCustomerOrders(r.Context(), customerID, pageSize, query.Get("page_token"))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidQuery) {
+				h.log.Infow("invalid list query", "error", err)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+				return
+			}
+			h.customerError(w, r, err)
+			return
+		}
+		orders := make([]domain.Order, 0, len(page.Orders))
+		for i := range page.Orders {
+			orders = append(orders, *orderView(r.Context(), &page.Orders[i]))
+		}
+		page.Orders = orders
+		w.Header().Set("Cache-Control", "no-store")
+		render.JSON(w, r, page)
+	}
+}
+
+// ExportCustomer godoc
+// @Summary Export customer data
+// @Description Export all orders of a customer as a JSON bundle (subject access request)
+// @Tags privacy
+// @Produce  json
+// @Param customer_id path string true "Customer ID"
+// @Success 200 {object} domain.CustomerExport "Customer data"
+// @Failure 400 {object} response.ErrorResponse "Invalid customer ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 404 {object} response.ErrorResponse "Customer not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/customers/{customer_id}/export [get]
+func (h *Handler) ExportCustomer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		customerID := chi.URLParam(r, "customer_id")
+		if customerID == "" {
+			h.log.Info("customer id is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("customer ID is required", http.StatusBadRequest, "Customer ID parameter is missing"))
+			return
+		}
+		principal, _ := auth.FromContext(r.Context())
+		log := h.log.With("requested_by", principal.Subject)
+
+		export, err := h.service.ExportCustomer(r.Context(), customerID)
+		if err != nil {
+			h.customerError(w, r, err)
+			return
+		}
+		log.Infow("customer data exported", "orders", len(export.Orders))
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "customer-"+customerID+".json"))
+		render.JSON(w, r, export)
+	}
+}
+
+// EraseCustomer godoc
+// @Summary Erase customer data
+// @Description Anonymise personal data of all orders of a customer and return the erasure audit record
+// @Tags privacy
+// @Accept  json
+// @Produce  json
+// @Param customer_id path string true "Customer ID"
+// @Param request body EraseRequest false "Erasure reason"
+// @Success 200 {object} domain.Erasure "Erasure record"
+// @Failure 400 {object} response.ErrorResponse "Invalid request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 404 {object} response.ErrorResponse "Customer not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/customers/{customer_id}/erase [post]
+func (h *Handler) EraseCustomer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		customerID := chi.URLParam(r, "customer_id")
+		if customerID == "" {
+			h.log.Info("customer id is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("customer ID is required", http.StatusBadRequest, "Customer ID parameter is missing"))
+			return
+		}
+		var req EraseRequest
+		if r.ContentLength != 0 {
+			if err := render.DecodeJSON(r.Body, &req); err != nil {
+				h.log.Infow("invalid erase request", "error", err)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.NewErrorResponse("invalid request body", http.StatusBadRequest, err.Error()))
+				return
+			}
+		}
+		principal, _ := auth.FromContext(r.Context())
+
+		erasure, err := h.service.EraseCustomer(r.Context(), customerID, principal.Subject, req.Reason)
+		if err != nil {
+			h.customerError(w, r, err)
+			return
+		}
+		h.log.Infow("customer erased", "erasure_id", erasure.ID, "requested_by", principal.Subject)
+		w.Header().Set("Cache-Control", "no-store")
+		render.JSON(w, r, erasure)
+	}
+}
+
+func (h *Handler) customerError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrCustomerNotFound) {
+		h.log.Info("customer not found")
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.NewErrorResponse("customer not found", http.StatusNotFound, "No orders were found for the customer"))
+		return
+	}
+	h.log.Errorw("internal server error", "error", err)
+	render.Status(r, http.StatusInternalServerError)
+	render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to process the customer request"))
+}
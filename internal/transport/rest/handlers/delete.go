@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+// DeleteOrder godoc
+// @Summary Delete order
+// @Description Soft-delete the order. It is no longer returned by any endpoint, can be restored and is purged after the retention window
+// @Tags orders
+// @Param order_uid path string true "Order UID"
+// @Success 204 "Order deleted"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /order/{order_uid} [delete]
+func (h *Handler) DeleteOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orderUID := chi.URLParam(r, "order_uid")
+		log := h.log.With("order_uid", orderUID)
+
+		if err := h.service.DeleteOrder(r.Context(), orderUID); err != nil {
+			switch {
+			case errors.Is(err, service.ErrOrderNotFound):
+				log.Info("order not found")
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.NewErrorResponse("order not found", http.StatusNotFound, "The requested order was not found in the system"))
+			default:
+				log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to delete the order"))
+			}
+			return
+		}
+		log.Info("order deleted")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RestoreOrder godoc
+// @Summary Restore order
+// @Description Restore a deleted order that has not been purged yet
+// @Tags orders
+// @Produce  json
+// @Param order_uid path string true "Order UID"
+// @Success 200 {object} domain.Order "Restored order"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "No deleted order"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /order/{order_uid}:restore [post]
+func (h *Handler) RestoreOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orderUID := chi.URLParam(r, "order_uid")
+		log := h.log.With("order_uid", orderUID)
+
+		order, err := h.service.RestoreOrder(r.Context(), orderUID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrOrderNotFound):
+				log.Info("deleted order not found")
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.NewErrorResponse("order not found", http.StatusNotFound, "No deleted order with this UID"))
+			default:
+				log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to restore the order"))
+			}
+			return
+		}
+		log.Infow("order restored", "version", order.Version)
+		render.JSON(w, r, order)
+	}
+}
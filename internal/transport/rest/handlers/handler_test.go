@@ -2,18 +2,29 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/auth"
 	"github.com/Killazius/L0/internal/domain"
 	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/lib/broadcast"
+	"github.com/Killazius/L0/internal/lib/test"
 	"github.com/Killazius/L0/internal/service"
 	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandler_GetOrder(t *testing.T) {
@@ -106,7 +117,7 @@ func TestHandler_GetOrder(t *testing.T) {
 			tt.setupMock(mockService)
 
 			logger := zap.NewNop().Sugar()
-			handler := New(logger, mockService)
+			handler := New(logger, mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
 
 			req, err := http.NewRequest("GET", "/order/"+tt.orderUID, nil)
 			require.NoError(t, err)
@@ -143,7 +154,7 @@ func TestHandler_GetOrder_ChiContext(t *testing.T) {
 		Once()
 
 	logger := zap.NewNop().Sugar()
-	handler := New(logger, mockService)
+	handler := New(logger, mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
 
 	req, err := http.NewRequest("GET", "/order/test-uid", nil)
 	require.NoError(t, err)
@@ -166,7 +177,7 @@ func TestHandler_GetOrder_WithoutChiContext(t *testing.T) {
 	mockService := NewMockOrderService(t)
 
 	logger := zap.NewNop().Sugar()
-	handler := New(logger, mockService)
+	handler := New(logger, mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
 
 	req, err := http.NewRequest("GET", "/order/test-uid", nil)
 	require.NoError(t, err)
@@ -187,7 +198,7 @@ func TestHandler_GetOrder_ContextCancellation(t *testing.T) {
 		Once()
 
 	logger := zap.NewNop().Sugar()
-	handler := New(logger, mockService)
+	handler := New(logger, mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
 
 	req, err := http.NewRequest("GET", "/order/test-uid", nil)
 	require.NoError(t, err)
@@ -204,3 +215,1360 @@ func TestHandler_GetOrder_ContextCancellation(t *testing.T) {
 	assert.Contains(t, rr.Body.String(), "internal server error")
 	mockService.AssertExpectations(t)
 }
+
+func TestHandler_GetOrder_Conditional(t *testing.T) {
+	t.Parallel()
+
+	testOrder := &domain.Order{
+		OrderUID:  "test-uid",
+		UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	// Callers without a principal get the masked view and its tag.
+	etag := domain.MaskedETag(testOrder.ETag())
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+	}{
+		{
+			name:    "matching etag from cache",
+			headers: map[string]string{"If-None-Match": etag},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrderETag", mock.Anything, "test-uid").
+					Return(testOrder.ETag(), nil, nil).
+					Once()
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:    "weak etag in list",
+			headers: map[string]string{"If-None-Match": `"other", W/` + etag},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrderETag", mock.Anything, "test-uid").
+					Return(testOrder.ETag(), nil, nil).
+					Once()
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:    "stale etag",
+			headers: map[string]string{"If-None-Match": `"stale"`},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrderETag", mock.Anything, "test-uid").
+					Return(testOrder.ETag(), nil, nil).
+					Once()
+				mockService.On("GetOrder", mock.Anything, "test-uid").
+					Return(testOrder, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "stale etag reuses the order read for it",
+			headers: map[string]string{"If-None-Match": `"stale"`},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrderETag", mock.Anything, "test-uid").
+					Return(testOrder.ETag(), testOrder, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "full view etag does not match the masked view",
+			headers: map[string]string{"If-None-Match": testOrder.ETag()},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrderETag", mock.Anything, "test-uid").
+					Return(testOrder.ETag(), testOrder, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "etag lookup error falls back to order",
+			headers: map[string]string{"If-None-Match": etag},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrderETag", mock.Anything, "test-uid").
+					Return("", nil, errors.New("cache error")).
+					Once()
+				mockService.On("GetOrder", mock.Anything, "test-uid").
+					Return(testOrder, nil).
+					Once()
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:    "not modified since",
+			headers: map[string]string{"If-Modified-Since": testOrder.UpdatedAt.Format(http.TimeFormat)},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrder", mock.Anything, "test-uid").
+					Return(testOrder, nil).
+					Once()
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:    "modified since",
+			headers: map[string]string{"If-Modified-Since": testOrder.UpdatedAt.Add(-time.Hour).Format(http.TimeFormat)},
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("GetOrder", mock.Anything, "test-uid").
+					Return(testOrder, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req, err := http.NewRequest("GET", "/order/test-uid", nil)
+			require.NoError(t, err)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("order_uid", "test-uid")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.GetOrder()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+			assert.Equal(t, orderCacheControl, rr.Header().Get("Cache-Control"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+			} else {
+				assert.Equal(t, "Wed, 01 May 2024 12:00:00 GMT", rr.Header().Get("Last-Modified"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetOrder_RoleView(t *testing.T) {
+	t.Parallel()
+
+	testOrder := &domain.Order{
+		OrderUID: "test-uid",
+		Delivery: domain.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		role      auth.Role
+		expectPII bool
+	}{
+		{name: "viewer", role: auth.RoleViewer, expectPII: false},
+		{name: "support", role: auth.RoleSupport, expectPII: true},
+		{name: "admin", role: auth.RoleAdmin, expectPII: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			mockService.On("GetOrder", mock.Anything, "test-uid").
+				Return(testOrder, nil).
+				Once()
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req, err := http.NewRequest("GET", "/order/test-uid", nil)
+			require.NoError(t, err)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("order_uid", "test-uid")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = auth.WithPrincipal(ctx, auth.Principal{Subject: "test", Role: tt.role})
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			handler.GetOrder()(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			body := rr.Body.String()
+			assert.Contains(t, body, `"city":"Kiryat Mozkin"`)
+			if tt.expectPII {
+				assert.Contains(t, body, `"email":"test@gmail.com"`)
+				assert.Contains(t, body, `"phone":"+9720000000"`)
+			} else {
+				assert.NotContains(t, body, "test@gmail.com")
+				assert.NotContains(t, body, "Test Testov")
+				assert.NotContains(t, body, "Ploshad Mira 15")
+				assert.Contains(t, body, `"email":"t***@gmail.com"`)
+				assert.Contains(t, body, `"name":"T*** T***"`)
+			}
+			assert.Equal(t, "Test Testov", testOrder.Delivery.Name)
+			if tt.expectPII {
+				assert.Equal(t, testOrder.ETag(), rr.Header().Get("ETag"))
+			} else {
+				assert.Equal(t, domain.MaskedETag(testOrder.ETag()), rr.Header().Get("ETag"), "the masked view has its own tag")
+			}
+		})
+	}
+}
+
+func TestHandler_GetOrder_Projection(t *testing.T) {
+	t.Parallel()
+
+	testOrder := test.GenerateOrder()
+
+	tests := []struct {
+		name       string
+		query      string
+		parts      domain.Part
+		expectKeys []string
+		expectItem []string
+	}{
+		{
+			name:       "fields with item names",
+			query:      "fields=order_uid,track_number,items.name",
+			parts:      domain.PartItems,
+			expectKeys: []string{"order_uid", "track_number", "items"},
+			expectItem: []string{"name"},
+		},
+		{
+			name:       "include only",
+			query:      "include=payment",
+			parts:      domain.PartPayment,
+			expectKeys: []string{"order_uid", "track_number", "entry", "payment", "locale", "internal_signature", "customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard"},
+		},
+		{
+			name:       "include overrides item fields",
+			query:      "fields=order_uid,items.name&include=items",
+			parts:      domain.PartItems,
+			expectKeys: []string{"order_uid", "items"},
+			expectItem: []string{"chrt_id", "track_number", "price", "rid", "name", "sale", "size", "total_price", "nm_id", "brand", "status"},
+		},
+		{
+			name:       "no parts",
+			query:      "fields=order_uid",
+			expectKeys: []string{"order_uid"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			mockService.On("GetOrderParts", mock.Anything, testOrder.OrderUID, tt.parts).
+				Return(testOrder, nil).
+				Once()
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("GET", "/order/"+testOrder.OrderUID+"?"+tt.query, nil)
+			req.Header.Set("If-None-Match", `"stale"`)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("order_uid", testOrder.OrderUID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.GetOrder()(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.NotEmpty(t, rr.Header().Get("ETag"))
+			var body map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.ElementsMatch(t, tt.expectKeys, slices.Collect(maps.Keys(body)))
+			if tt.expectItem != nil {
+				var items []map[string]any
+				require.NoError(t, json.Unmarshal(body["items"], &items))
+				require.Len(t, items, len(testOrder.Items))
+				assert.ElementsMatch(t, tt.expectItem, slices.Collect(maps.Keys(items[0])))
+			}
+		})
+	}
+}
+
+func TestHandler_GetOrder_InvalidProjection(t *testing.T) {
+	t.Parallel()
+
+	for _, query := range []string{"fields=secret", "include=customer", "fields=items.secret", "fields=order_uid.name"} {
+		handler := New(zap.NewNop().Sugar(), NewMockOrderService(t), NewMockOrderStream(t), NewMockOrderTracker(t))
+		req := httptest.NewRequest("GET", "/order/test-uid?"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("order_uid", "test-uid")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		rr := httptest.NewRecorder()
+		handler.GetOrder()(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestHandler_LookupOrders(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			body: `{"order_uids":["` + order.OrderUID + `","missing"]}`,
+			setupMock: func(m *MockOrderService) {
+				m.On("LookupOrders", mock.Anything, []string{order.OrderUID, "missing"}).
+					Return(&domain.OrderLookup{Orders: []domain.Order{*order}, Missing: []string{"missing"}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"missing":["missing"]`,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"order_uids":`,
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"invalid request body"`,
+		},
+		{
+			name: "too many uids",
+			body: `{"order_uids":["a"]}`,
+			setupMock: func(m *MockOrderService) {
+				m.On("LookupOrders", mock.Anything, []string{"a"}).
+					Return(nil, service.ErrInvalidQuery).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"invalid request"`,
+		},
+		{
+			name: "internal error",
+			body: `{"order_uids":["a"]}`,
+			setupMock: func(m *MockOrderService) {
+				m.On("LookupOrders", mock.Anything, []string{"a"}).
+					Return(nil, errors.New("database error")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"internal server error"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("POST", "/orders/lookup", strings.NewReader(tt.body))
+			req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{Subject: "test", Role: auth.RoleViewer}))
+			rr := httptest.NewRecorder()
+			handler.LookupOrders()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), order.OrderUID)
+				assert.NotContains(t, rr.Body.String(), order.Delivery.Email, "viewer gets masked data")
+			}
+		})
+	}
+}
+
+func TestHandler_ListOrders(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "by track number",
+			query: "track_number=" + order.TrackNumber,
+			setupMock: func(m *MockOrderService) {
+				m.On("ListOrders", mock.Anything, domain.OrderFilter{TrackNumber: order.TrackNumber}, 0, "").
+					Return(&domain.OrderPage{Orders: []domain.Order{*order}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"order_uid":"` + order.OrderUID + `"`,
+		},
+		{
+			name:  "by item ids with paging",
+			query: "chrt_id=9934930&nm_id=2389212&page_size=5&page_token=abc",
+			setupMock: func(m *MockOrderService) {
+				m.On("ListOrders", mock.Anything, domain.OrderFilter{ChrtID: 9934930, NmID: 2389212}, 5, "abc").
+					Return(&domain.OrderPage{Orders: []domain.Order{}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid chrt_id",
+			query:          "chrt_id=abc",
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid created_from",
+			query:          "created_from=yesterday",
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid page token",
+			query: "page_token=bad",
+			setupMock: func(m *MockOrderService) {
+				m.On("ListOrders", mock.Anything, domain.OrderFilter{}, 0, "bad").
+					Return(nil, service.ErrInvalidQuery).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			rr := httptest.NewRecorder()
+			handler.ListOrders()(rr, httptest.NewRequest("GET", "/orders?"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+			assert.NotContains(t, rr.Body.String(), order.Delivery.Email, "viewer gets masked data")
+		})
+	}
+}
+
+func TestHandler_DailyStats(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "success",
+			query: "from=2021-11-01&to=2021-12-01&group_by=bank",
+			setupMock: func(m *MockOrderService) {
+				m.On("DailyStats", mock.Anything, domain.StatsQuery{From: from, To: to, GroupBy: domain.DimensionBank}).
+					Return(&domain.DailyStatsReport{
+						From: from, To: to, GroupBy: domain.DimensionBank,
+						Stats: []domain.DailyStats{{Day: from, Group: "alpha", Currency: "USD", Orders: 2}},
+					}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"group":"alpha"`,
+		},
+		{
+			name:           "invalid date",
+			query:          "from=2021-11-01T00:00:00Z",
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid group",
+			query: "group_by=customer_id",
+			setupMock: func(m *MockOrderService) {
+				m.On("DailyStats", mock.Anything, domain.StatsQuery{GroupBy: "customer_id"}).
+					Return(nil, service.ErrInvalidQuery).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "internal error",
+			setupMock: func(m *MockOrderService) {
+				m.On("DailyStats", mock.Anything, domain.StatsQuery{}).
+					Return(nil, errors.New("database down")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			rr := httptest.NewRecorder()
+			handler.DailyStats()(rr, httptest.NewRequest("GET", "/stats/daily?"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_TopBrands(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "success",
+			query: "from=2021-11-01&limit=3",
+			setupMock: func(m *MockOrderService) {
+				m.On("TopBrands", mock.Anything, from, time.Time{}, 3).
+					Return(&domain.TopBrandsReport{
+						From: from,
+						Days: []domain.DailyBrands{{Day: from, Brands: []domain.BrandStats{{Brand: "Vivienne Sabo", Items: 4}}}},
+					}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"brand":"Vivienne Sabo"`,
+		},
+		{
+			name:           "invalid limit",
+			query:          "limit=-1",
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			rr := httptest.NewRecorder()
+			handler.TopBrands()(rr, httptest.NewRequest("GET", "/stats/brands?"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_ExchangeRates(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "success",
+			query: "date=2021-11-26",
+			setupMock: func(m *MockOrderService) {
+				m.On("ExchangeRates", mock.Anything, day).
+					Return(&domain.ExchangeRates{
+						BaseCurrency: "USD",
+						Date:         day,
+						Rates:        []domain.ExchangeRate{{Currency: "EUR", Date: day, Rate: decimal.RequireFromString("1.13")}},
+					}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"currency":"EUR"`,
+		},
+		{
+			name:           "invalid date",
+			query:          "date=26.11.2021",
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "conversion disabled",
+			setupMock: func(m *MockOrderService) {
+				m.On("ExchangeRates", mock.Anything, time.Time{}).
+					Return(nil, service.ErrInvalidExchangeRates).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "internal error",
+			setupMock: func(m *MockOrderService) {
+				m.On("ExchangeRates", mock.Anything, time.Time{}).
+					Return(nil, errors.New("database down")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			rr := httptest.NewRecorder()
+			handler.ExchangeRates()(rr, httptest.NewRequest("GET", "/admin/exchange-rates?"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_SaveExchangeRates(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC)
+	isEUR := mock.MatchedBy(func(rates []domain.ExchangeRate) bool {
+		return len(rates) == 1 && rates[0].Currency == "EUR" && rates[0].Date.Equal(day) &&
+			rates[0].Rate.Equal(decimal.RequireFromString("1.13"))
+	})
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `[{"date": "2021-11-26", "currency": "EUR", "rate": "1.13"}]`,
+			setupMock: func(m *MockOrderService) {
+				m.On("SaveExchangeRates", mock.Anything, isEUR).
+					Return(&domain.ExchangeRatesUpdate{Saved: 1, Converted: 3}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"converted":3`,
+		},
+		{
+			name:        "csv",
+			contentType: "text/csv; charset=utf-8",
+			body:        "date,currency,rate\n2021-11-26,EUR,1.13\n",
+			setupMock: func(m *MockOrderService) {
+				m.On("SaveExchangeRates", mock.Anything, isEUR).
+					Return(&domain.ExchangeRatesUpdate{Saved: 1}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"saved":1`,
+		},
+		{
+			name:           "malformed body",
+			contentType:    "application/json",
+			body:           `{"date": "2021-11-26"`,
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid rates",
+			contentType: "application/json",
+			body:        `[{"date": "2021-11-26", "currency": "EUR", "rate": "1.13"}]`,
+			setupMock: func(m *MockOrderService) {
+				m.On("SaveExchangeRates", mock.Anything, isEUR).
+					Return(nil, fmt.Errorf("%w: rate of the base currency EUR", service.ErrInvalidExchangeRates)).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "base currency",
+		},
+		{
+			name:        "internal error",
+			contentType: "application/json",
+			body:        `[{"date": "2021-11-26", "currency": "EUR", "rate": "1.13"}]`,
+			setupMock: func(m *MockOrderService) {
+				m.On("SaveExchangeRates", mock.Anything, isEUR).
+					Return(nil, errors.New("database down")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("PUT", "/admin/exchange-rates", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+			handler.SaveExchangeRates()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_GetOrderByTransaction(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusOK},
+		{name: "not found", err: service.ErrOrderNotFound, expectedStatus: http.StatusNotFound},
+		{name: "internal error", err: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			if tt.err != nil {
+				mockService.On("GetOrderByTransaction", mock.Anything, "txn1").Return(nil, tt.err).Once()
+			} else {
+				mockService.On("GetOrderByTransaction", mock.Anything, "txn1").Return(order, nil).Once()
+			}
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("GET", "/orders/by-transaction/txn1", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("transaction", "txn1")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+			handler.GetOrderByTransaction()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.err == nil {
+				assert.Contains(t, rr.Body.String(), `"order_uid":"`+order.OrderUID+`"`)
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateOrder(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	body, err := json.Marshal(order)
+	require.NoError(t, err)
+	updated := *order
+	updated.Version = 2
+	etag := updated.ETag()
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", ifMatch: `"v1"`, body: string(body), expectedStatus: http.StatusOK},
+		{name: "missing if-match", body: string(body), expectedStatus: http.StatusPreconditionRequired},
+		{name: "invalid body", ifMatch: `"v1"`, body: `{"order_uid":`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid order", ifMatch: `"v1"`, body: string(body), err: service.ErrInvalidOrderData, expectedStatus: http.StatusBadRequest},
+		{name: "not found", ifMatch: `"v1"`, body: string(body), err: service.ErrOrderNotFound, expectedStatus: http.StatusNotFound},
+		{name: "changed", ifMatch: `"v1"`, body: string(body), err: service.ErrPreconditionFailed, expectedStatus: http.StatusPreconditionFailed},
+		{name: "internal error", ifMatch: `"v1"`, body: string(body), err: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			switch {
+			case tt.err != nil:
+				mockService.On("UpdateOrder", mock.Anything, order.OrderUID, mock.Anything, tt.ifMatch).Return(nil, tt.err).Once()
+			case tt.expectedStatus == http.StatusOK:
+				mockService.On("UpdateOrder", mock.Anything, order.OrderUID, mock.Anything, tt.ifMatch).Return(&updated, nil).Once()
+			}
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("PUT", "/order/"+order.OrderUID, strings.NewReader(tt.body))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("order_uid", order.OrderUID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+			handler.UpdateOrder()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, etag, rr.Header().Get("ETag"))
+				assert.Contains(t, rr.Body.String(), `"version":2`)
+			}
+		})
+	}
+}
+
+func TestHandler_GetOrderHistory(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	asOf := time.Date(2021, 11, 27, 0, 0, 0, 0, time.UTC)
+	history := &domain.OrderHistory{
+		OrderUID: order.OrderUID,
+		Order:    order,
+		Events:   []domain.HistoryEvent{{ID: 1, Type: domain.EventOrderCreated, Version: 1, Actor: "kafka"}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "success",
+			query: "?as_of=2021-11-27T00:00:00Z",
+			setupMock: func(m *MockOrderService) {
+				m.On("GetOrderHistory", mock.Anything, order.OrderUID, asOf).Return(history, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"actor":"kafka"`,
+		},
+		{
+			name:           "invalid as_of",
+			query:          "?as_of=yesterday",
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"invalid query"`,
+		},
+		{
+			name: "no history",
+			setupMock: func(m *MockOrderService) {
+				m.On("GetOrderHistory", mock.Anything, order.OrderUID, time.Time{}).Return(nil, service.ErrOrderNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"order history not found"`,
+		},
+		{
+			name: "internal error",
+			setupMock: func(m *MockOrderService) {
+				m.On("GetOrderHistory", mock.Anything, order.OrderUID, time.Time{}).Return(nil, errors.New("database error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"internal server error"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("GET", "/order/"+order.OrderUID+"/history"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("order_uid", order.OrderUID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+			handler.GetOrderHistory()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_DeleteOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusNoContent},
+		{name: "not found", err: service.ErrOrderNotFound, expectedStatus: http.StatusNotFound},
+		{name: "internal error", err: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			mockService.On("DeleteOrder", mock.Anything, "b563feb7b2b84b6test").Return(tt.err).Once()
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("DELETE", "/order/b563feb7b2b84b6test", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("order_uid", "b563feb7b2b84b6test")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+			handler.DeleteOrder()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_RestoreOrder(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusOK},
+		{name: "not deleted", err: service.ErrOrderNotFound, expectedStatus: http.StatusNotFound},
+		{name: "internal error", err: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			if tt.err != nil {
+				mockService.On("RestoreOrder", mock.Anything, order.OrderUID).Return(nil, tt.err).Once()
+			} else {
+				mockService.On("RestoreOrder", mock.Anything, order.OrderUID).Return(order, nil).Once()
+			}
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("POST", "/order/"+order.OrderUID+":restore", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("order_uid", order.OrderUID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+			handler.RestoreOrder()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.err == nil {
+				assert.Contains(t, rr.Body.String(), `"order_uid":"`+order.OrderUID+`"`)
+			}
+		})
+	}
+}
+
+func TestHandler_SearchOrders(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	mockService := NewMockOrderService(t)
+	mockService.On("SearchOrders", mock.Anything, "mascaras", 10, "").
+		Return(&domain.SearchPage{Results: []domain.SearchHit{{Order: *order, Rank: 0.1, Highlights: []string{"<mark>Mascaras</mark>"}}}}, nil).
+		Once()
+	mockService.On("SearchOrders", mock.Anything, "", 0, "").
+		Return(nil, service.ErrInvalidQuery).
+		Once()
+	handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+	rr := httptest.NewRecorder()
+	handler.SearchOrders()(rr, httptest.NewRequest("GET", "/orders/search?q=mascaras&page_size=10", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"order_uid":"`+order.OrderUID+`"`)
+	assert.Contains(t, rr.Body.String(), `"highlights":["\u003cmark\u003eMascaras\u003c/mark\u003e"]`)
+
+	rr = httptest.NewRecorder()
+	handler.SearchOrders()(rr, httptest.NewRequest("GET", "/orders/search", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.SearchOrders()(rr, httptest.NewRequest("GET", "/orders/search?q=x&page_size=-1", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandler_GetCustomer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			setupMock: func(m *MockOrderService) {
+				m.On("GetCustomer", mock.Anything, "customer").
+					Return(&domain.Customer{CustomerID: "customer", Name: "Test Testov"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name":"Test Testov"`,
+		},
+		{
+			name: "customer not found",
+			setupMock: func(m *MockOrderService) {
+				m.On("GetCustomer", mock.Anything, "customer").
+					Return(nil, service.ErrCustomerNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			setupMock: func(m *MockOrderService) {
+				m.On("GetCustomer", mock.Anything, "customer").
+					Return(nil, errors.New("database down")).
+					Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("GET", "/customers/customer", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("customer_id", "customer")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.GetCustomer()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_ListCustomerOrders(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "success",
+			query: "page_size=5&page_token=abc",
+			setupMock: func(m *MockOrderService) {
+				m.On("ListCustomerOrders", mock.Anything, "customer", 5, "abc").
+					Return(&domain.CustomerOrders{
+						CustomerID: "customer",
+						CustomerStats: domain.CustomerStats{
+							OrderCount:    1,
+							LifetimeValue: []domain.Amount{{Currency: "USD", Amount: order.Payment.Amount}},
+						},
+						Orders: []domain.Order{*order},
+					}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"order_count":1`,
+		},
+		{
+			name:           "invalid page size",
+			query:          "page_size=abc",
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid page token",
+			query: "page_token=bad",
+			setupMock: func(m *MockOrderService) {
+				m.On("ListCustomerOrders", mock.Anything, "customer", 0, "bad").
+					Return(nil, service.ErrInvalidQuery).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "customer not found",
+			setupMock: func(m *MockOrderService) {
+				m.On("ListCustomerOrders", mock.Anything, "customer", 0, "").
+					Return(nil, service.ErrCustomerNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req := httptest.NewRequest("GET", "/customers/customer/orders?"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("customer_id", "customer")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.ListCustomerOrders()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestHandler_EraseCustomer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockOrderService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: `{"reason":"request #1"}`,
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("EraseCustomer", mock.Anything, "customer", "ops", "request #1").
+					Return(&domain.Erasure{ID: 1, RequestedBy: "ops", OrderUIDs: []string{"uid1"}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "customer not found",
+			setupMock: func(mockService *MockOrderService) {
+				mockService.On("EraseCustomer", mock.Anything, "customer", "ops", "").
+					Return(nil, service.ErrCustomerNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"reason":`,
+			setupMock:      func(_ *MockOrderService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockService := NewMockOrderService(t)
+			tt.setupMock(mockService)
+			handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), NewMockOrderTracker(t))
+
+			req, err := http.NewRequest("POST", "/admin/customers/customer/erase", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("customer_id", "customer")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = auth.WithPrincipal(ctx, auth.Principal{Subject: "ops", Role: auth.RoleAdmin})
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			handler.EraseCustomer()(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+				assert.Contains(t, rr.Body.String(), `"requested_by":"ops"`)
+			}
+		})
+	}
+}
+
+func TestHandler_StreamOrders(t *testing.T) {
+	t.Parallel()
+
+	newOrder := func(uid, customer string) *domain.Order {
+		return &domain.Order{
+			OrderUID:        uid,
+			CustomerID:      customer,
+			DeliveryService: "meest",
+			Delivery:        domain.Delivery{Name: "Test Testov", Email: "test@gmail.com"},
+		}
+	}
+
+	b := broadcast.New(10, 10)
+	b.Publish(domain.EventOrderCreated, newOrder("replayed", "c1"))
+	b.Publish(domain.EventOrderCreated, newOrder("other-customer", "c2"))
+
+	filter := broadcast.Filter{CustomerID: "c1"}
+	sub, missed := b.Subscribe(filter, 1)
+	mockStream := NewMockOrderStream(t)
+	mockStream.On("Subscribe", filter, uint64(1)).
+		Return(sub, missed).
+		Once()
+	handler := New(zap.NewNop().Sugar(), NewMockOrderService(t), mockStream, NewMockOrderTracker(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = auth.WithPrincipal(ctx, auth.Principal{Subject: "dashboard", Role: auth.RoleViewer})
+	req, err := http.NewRequestWithContext(ctx, "GET", "/orders/stream?customer_id=c1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+	rr := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.StreamOrders()(rr, req)
+	}()
+	b.Publish(domain.EventOrderCreated, newOrder("live", "c1"))
+	require.Eventually(t, func() bool { return len(sub.Events()) == 0 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	body := rr.Body.String()
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Contains(t, body, "retry: 3000\n\n")
+	assert.Equal(t, 2, strings.Count(body, "event: order.created\n"))
+	assert.Contains(t, body, `"order_uid":"replayed"`)
+	assert.Contains(t, body, `"order_uid":"live"`)
+	assert.NotContains(t, body, "other-customer")
+	assert.NotContains(t, body, "test@gmail.com")
+	assert.Less(t, strings.Index(body, "replayed"), strings.Index(body, `"order_uid":"live"`))
+}
+
+func TestHandler_StreamOrders_InvalidLastEventID(t *testing.T) {
+	t.Parallel()
+
+	handler := New(zap.NewNop().Sugar(), NewMockOrderService(t), NewMockOrderStream(t), NewMockOrderTracker(t))
+	req, err := http.NewRequest("GET", "/orders/stream?last_event_id=abc", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.StreamOrders()(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandler_TrackOrders(t *testing.T) {
+	t.Parallel()
+
+	known := &domain.Order{OrderUID: "known", Delivery: domain.Delivery{Email: "test@gmail.com"}}
+	mockService := NewMockOrderService(t)
+	mockService.On("GetOrder", mock.Anything, "known").
+		Return(known, nil).
+		Once()
+	mockService.On("GetOrder", mock.Anything, "pending").
+		Return(nil, service.ErrOrderNotFound).
+		Once()
+
+	tracker := broadcast.NewTracker(broadcast.New(10, 10), 10, 2)
+	mockTracker := NewMockOrderTracker(t)
+	mockTracker.On("Watch").
+		Return(tracker.Watch()).
+		Once()
+	handler := New(zap.NewNop().Sugar(), mockService, NewMockOrderStream(t), mockTracker)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := auth.WithPrincipal(r.Context(), auth.Principal{Subject: "dashboard", Role: auth.RoleViewer})
+		handler.TrackOrders()(w, r.WithContext(ctx))
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	read := func() TrackMessage {
+		var msg TrackMessage
+		require.NoError(t, conn.ReadJSON(&msg))
+		return msg
+	}
+
+	require.NoError(t, conn.WriteJSON(TrackRequest{Action: "subscribe", OrderUIDs: []string{"known", "pending"}}))
+	assert.Equal(t, TrackMessage{Type: "subscribed", OrderUIDs: []string{"known", "pending"}}, read())
+	msg := read()
+	assert.Equal(t, "order", msg.Type)
+	require.NotNil(t, msg.Order)
+	assert.Equal(t, "t***@gmail.com", msg.Order.Delivery.Email)
+	assert.Equal(t, TrackMessage{Type: "pending", OrderUID: "pending"}, read())
+
+	require.NoError(t, conn.WriteJSON(TrackRequest{Action: "subscribe", OrderUIDs: []string{"third"}}))
+	assert.Equal(t, "error", read().Type, "watcher is limited to two orders")
+
+	tracker.Dispatch(domain.OrderEvent{ID: 1, Type: domain.EventOrderCreated, Order: &domain.Order{OrderUID: "pending"}})
+	msg = read()
+	assert.Equal(t, "event", msg.Type)
+	assert.Equal(t, "pending", msg.OrderUID)
+	require.NotNil(t, msg.Event)
+	assert.Equal(t, uint64(1), msg.Event.ID)
+
+	require.NoError(t, conn.WriteJSON(TrackRequest{Action: "unsubscribe", OrderUIDs: []string{"pending"}}))
+	assert.Equal(t, "unsubscribed", read().Type)
+
+	handler.CloseStreams()
+	var closeMsg TrackMessage
+	err = conn.ReadJSON(&closeMsg)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "unexpected error: %v", err)
+}
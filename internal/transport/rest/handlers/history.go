@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+// GetOrderHistory godoc
+// @Summary Get order history
+// @Description Get every recorded change of the order with its actor and diff, a JSON merge patch. With as_of only the changes up to that time are returned together with the order as it was then. Contact fields of the delivery are recorded masked and are never recoverable, whatever the role
+// @Tags orders
+// @Produce  json
+// @Param order_uid path string true "Order UID"
+// @Param as_of query string false "Point in time, RFC 3339" example(2021-11-26T06:22:19Z)
+// @Success 200 {object} domain.OrderHistory "Order history"
+// @Failure 400 {object} response.ErrorResponse "Invalid query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "No history"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /order/{order_uid}/history [get]
+func (h *Handler) GetOrderHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orderUID := chi.URLParam(r, "order_uid")
+		log := h.log.With("order_uid", orderUID)
+
+		asOf, err := timeParam(r.URL.Query(), "as_of")
+		if err != nil {
+			log.Infow("invalid history query", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		history, err := h.service.GetOrderHistory(r.Context(), orderUID, asOf)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrOrderNotFound):
+				log.Info("order history not found")
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.NewErrorResponse("order history not found", http.StatusNotFound, "No changes were recorded for the order"))
+			default:
+				log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to get the order history"))
+			}
+			return
+		}
+		log.Infow("success get order history", "events", len(history.Events))
+		render.JSON(w, r, history)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ListOrders godoc
+// @Summary List orders
+// @Description List orders matching the filters, newest first. Support agents find orders by track number or by the chrt_id/nm_id of an item
+// @Tags orders
+// @Produce  json
+// @Param track_number query string false "Track number" example(WBILMTESTTRACK)
+// @Param chrt_id query int false "Item chrt_id" example(9934930)
+// @Param nm_id query int false "Item nm_id" example(2389212)
+// @Param customer_id query string false "Customer ID"
+// @Param delivery_service query string false "Delivery service"
+// @Param created_from query string false "Created at or after, RFC 3339" example(2021-11-01T00:00:00Z)
+// @Param created_to query string false "Created before, RFC 3339" example(2021-12-01T00:00:00Z)
+// @Param page_size query int false "Page size, at most 100" default(20)
+// @Param page_token query string false "next_cursor of the previous page"
+// @Success 200 {object} domain.OrderPage "Page of orders"
+// @Failure 400 {object} response.ErrorResponse "Invalid query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders [get]
+func (h *Handler) ListOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter, pageSize, err := parseListQuery(query)
+		if err != nil {
+			h.log.Infow("invalid list query", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		page, err := h.service.ListOrders(r.Context(), filter, pageSize, query.Get("page_token"))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrInvalidQuery):
+				h.log.Infow("invalid list query", "error", err)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+			default:
+				h.log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to list the orders"))
+			}
+			return
+		}
+
+		orders := make([]domain.Order, 0, len(page.Orders))
+		for i := range page.Orders {
+			orders = append(orders, *orderView(r.Context(), &page.Orders[i]))
+		}
+		page.Orders = orders
+		render.JSON(w, r, page)
+	}
+}
+
+func parseListQuery(query url.Values) (domain.OrderFilter, int, error) {
+	filter := domain.OrderFilter{
+		CustomerID:      query.Get("customer_id"),
+		DeliveryService: query.Get("delivery_service"),
+		TrackNumber:     query.Get("track_number"),
+	}
+	var err error
+	if filter.ChrtID, err = intParam(query, "chrt_id"); err != nil {
+		return filter, 0, err
+	}
+	if filter.NmID, err = intParam(query, "nm_id"); err != nil {
+		return filter, 0, err
+	}
+	if filter.CreatedFrom, err = timeParam(query, "created_from"); err != nil {
+		return filter, 0, err
+	}
+	if filter.CreatedTo, err = timeParam(query, "created_to"); err != nil {
+		return filter, 0, err
+	}
+	pageSize, err := intParam(query, "page_size")
+	if err != nil {
+		return filter, 0, err
+	}
+	return filter, pageSize, nil
+}
+
+func intParam(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return n, nil
+}
+
+func timeParam(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time", name)
+	}
+	return t, nil
+}
+
+// GetOrderByTransaction godoc
+// @Summary Get order by payment transaction
+// @Description Get the order paid with the payment transaction
+// @Tags orders
+// @Produce  json
+// @Param transaction path string true "Payment transaction"
+// @Success 200 {object} domain.Order "Order details"
+// @Failure 400 {object} response.ErrorResponse "Invalid transaction"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/by-transaction/{transaction} [get]
+func (h *Handler) GetOrderByTransaction() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transaction := chi.URLParam(r, "transaction")
+		if transaction == "" {
+			h.log.Info("transaction is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("transaction is required", http.StatusBadRequest, "Transaction parameter is missing"))
+			return
+		}
+		log := h.log.With("transaction", transaction)
+
+		order, err := h.service.GetOrderByTransaction(r.Context(), transaction)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrOrderNotFound):
+				log.Info("order not found")
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.NewErrorResponse("order not found", http.StatusNotFound, "No order was paid with the transaction"))
+			case errors.Is(err, service.ErrInvalidOrderData):
+				log.Warnw("invalid order data", "error", err)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.NewErrorResponse("invalid order data", http.StatusBadRequest, "The order data is incomplete"))
+			default:
+				log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to get the order"))
+			}
+			return
+		}
+		log.Infow("success get order by transaction", "order_uid", order.OrderUID)
+		render.JSON(w, r, orderView(r.Context(), order))
+	}
+}
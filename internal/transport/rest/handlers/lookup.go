@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+const maxLookupBodyBytes = 1 << 20
+
+// LookupRequest is the body of a bulk lookup
+// @Description Bulk order lookup request
+type LookupRequest struct {
+	OrderUIDs []string `json:"order_uids" example:"b563feb7b2b84b6test,a1b2c3d4e5f6test"`
+}
+
+// LookupOrders godoc
+// @Summary Look up orders
+// @Description Get up to 1000 orders by UID in one request. Orders that do not exist are listed in missing
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param request body LookupRequest true "Order UIDs"
+// @Success 200 {object} domain.OrderLookup "Found orders and missing UIDs"
+// @Failure 400 {object} response.ErrorResponse "Invalid request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/lookup [post]
+func (h *Handler) LookupOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req LookupRequest
+		if err := render.DecodeJSON(http.MaxBytesReader(w, r.Body, maxLookupBodyBytes), &req); err != nil {
+			h.log.Infow("invalid lookup request", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid request body", http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		lookup, err := h.service.LookupOrders(r.Context(), req.OrderUIDs)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrInvalidQuery):
+				h.log.Infow("invalid lookup request", "error", err)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.NewErrorResponse("invalid request", http.StatusBadRequest, err.Error()))
+			default:
+				h.log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to look up the orders"))
+			}
+			return
+		}
+
+		orders := make([]domain.Order, 0, len(lookup.Orders))
+		for i := range lookup.Orders {
+			orders = append(orders, *orderView(r.Context(), &lookup.Orders[i]))
+		}
+		lookup.Orders = orders
+		h.log.Infow("orders looked up", "found", len(lookup.Orders), "missing", len(lookup.Missing))
+		render.JSON(w, r, lookup)
+	}
+}
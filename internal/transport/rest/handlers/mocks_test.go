@@ -6,8 +6,10 @@ package handlers
 
 import (
 	"context"
+	"time"
 
 	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/broadcast"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -38,47 +40,1340 @@ func (_m *MockOrderService) EXPECT() *MockOrderService_Expecter {
 	return &MockOrderService_Expecter{mock: &_m.Mock}
 }
 
+// DailyStats provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) DailyStats(ctx context.Context, query domain.StatsQuery) (*domain.DailyStatsReport, error) {
+	ret := _mock.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DailyStats")
+	}
+
+	var r0 *domain.DailyStatsReport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.StatsQuery) (*domain.DailyStatsReport, error)); ok {
+		return returnFunc(ctx, query)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.StatsQuery) *domain.DailyStatsReport); ok {
+		r0 = returnFunc(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DailyStatsReport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.StatsQuery) error); ok {
+		r1 = returnFunc(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_DailyStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DailyStats'
+type MockOrderService_DailyStats_Call struct {
+	*mock.Call
+}
+
+// DailyStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query domain.StatsQuery
+func (_e *MockOrderService_Expecter) DailyStats(ctx interface{}, query interface{}) *MockOrderService_DailyStats_Call {
+	return &MockOrderService_DailyStats_Call{Call: _e.mock.On("DailyStats", ctx, query)}
+}
+
+func (_c *MockOrderService_DailyStats_Call) Run(run func(ctx context.Context, query domain.StatsQuery)) *MockOrderService_DailyStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.StatsQuery
+		if args[1] != nil {
+			arg1 = args[1].(domain.StatsQuery)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_DailyStats_Call) Return(dailyStatsReport *domain.DailyStatsReport, err error) *MockOrderService_DailyStats_Call {
+	_c.Call.Return(dailyStatsReport, err)
+	return _c
+}
+
+func (_c *MockOrderService_DailyStats_Call) RunAndReturn(run func(ctx context.Context, query domain.StatsQuery) (*domain.DailyStatsReport, error)) *MockOrderService_DailyStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteOrder provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) DeleteOrder(ctx context.Context, uid string) error {
+	ret := _mock.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrder")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderService_DeleteOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteOrder'
+type MockOrderService_DeleteOrder_Call struct {
+	*mock.Call
+}
+
+// DeleteOrder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uid string
+func (_e *MockOrderService_Expecter) DeleteOrder(ctx interface{}, uid interface{}) *MockOrderService_DeleteOrder_Call {
+	return &MockOrderService_DeleteOrder_Call{Call: _e.mock.On("DeleteOrder", ctx, uid)}
+}
+
+func (_c *MockOrderService_DeleteOrder_Call) Run(run func(ctx context.Context, uid string)) *MockOrderService_DeleteOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_DeleteOrder_Call) Return(err error) *MockOrderService_DeleteOrder_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderService_DeleteOrder_Call) RunAndReturn(run func(ctx context.Context, uid string) error) *MockOrderService_DeleteOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EraseCustomer provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) EraseCustomer(ctx context.Context, customerID string, requestedBy string, reason string) (*domain.Erasure, error) {
+	ret := _mock.Called(ctx, customerID, requestedBy, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseCustomer")
+	}
+
+	var r0 *domain.Erasure
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) (*domain.Erasure, error)); ok {
+		return returnFunc(ctx, customerID, requestedBy, reason)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) *domain.Erasure); ok {
+		r0 = returnFunc(ctx, customerID, requestedBy, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Erasure)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = returnFunc(ctx, customerID, requestedBy, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_EraseCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EraseCustomer'
+type MockOrderService_EraseCustomer_Call struct {
+	*mock.Call
+}
+
+// EraseCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+//   - requestedBy string
+//   - reason string
+func (_e *MockOrderService_Expecter) EraseCustomer(ctx interface{}, customerID interface{}, requestedBy interface{}, reason interface{}) *MockOrderService_EraseCustomer_Call {
+	return &MockOrderService_EraseCustomer_Call{Call: _e.mock.On("EraseCustomer", ctx, customerID, requestedBy, reason)}
+}
+
+func (_c *MockOrderService_EraseCustomer_Call) Run(run func(ctx context.Context, customerID string, requestedBy string, reason string)) *MockOrderService_EraseCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_EraseCustomer_Call) Return(erasure *domain.Erasure, err error) *MockOrderService_EraseCustomer_Call {
+	_c.Call.Return(erasure, err)
+	return _c
+}
+
+func (_c *MockOrderService_EraseCustomer_Call) RunAndReturn(run func(ctx context.Context, customerID string, requestedBy string, reason string) (*domain.Erasure, error)) *MockOrderService_EraseCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExchangeRates provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ExchangeRates(ctx context.Context, at time.Time) (*domain.ExchangeRates, error) {
+	ret := _mock.Called(ctx, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExchangeRates")
+	}
+
+	var r0 *domain.ExchangeRates
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) (*domain.ExchangeRates, error)); ok {
+		return returnFunc(ctx, at)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) *domain.ExchangeRates); ok {
+		r0 = returnFunc(ctx, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ExchangeRates)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = returnFunc(ctx, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ExchangeRates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExchangeRates'
+type MockOrderService_ExchangeRates_Call struct {
+	*mock.Call
+}
+
+// ExchangeRates is a helper method to define mock.On call
+//   - ctx context.Context
+//   - at time.Time
+func (_e *MockOrderService_Expecter) ExchangeRates(ctx interface{}, at interface{}) *MockOrderService_ExchangeRates_Call {
+	return &MockOrderService_ExchangeRates_Call{Call: _e.mock.On("ExchangeRates", ctx, at)}
+}
+
+func (_c *MockOrderService_ExchangeRates_Call) Run(run func(ctx context.Context, at time.Time)) *MockOrderService_ExchangeRates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ExchangeRates_Call) Return(exchangeRates *domain.ExchangeRates, err error) *MockOrderService_ExchangeRates_Call {
+	_c.Call.Return(exchangeRates, err)
+	return _c
+}
+
+func (_c *MockOrderService_ExchangeRates_Call) RunAndReturn(run func(ctx context.Context, at time.Time) (*domain.ExchangeRates, error)) *MockOrderService_ExchangeRates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportCustomer provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ExportCustomer(ctx context.Context, customerID string) (*domain.CustomerExport, error) {
+	ret := _mock.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportCustomer")
+	}
+
+	var r0 *domain.CustomerExport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.CustomerExport, error)); ok {
+		return returnFunc(ctx, customerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.CustomerExport); ok {
+		r0 = returnFunc(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CustomerExport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ExportCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportCustomer'
+type MockOrderService_ExportCustomer_Call struct {
+	*mock.Call
+}
+
+// ExportCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+func (_e *MockOrderService_Expecter) ExportCustomer(ctx interface{}, customerID interface{}) *MockOrderService_ExportCustomer_Call {
+	return &MockOrderService_ExportCustomer_Call{Call: _e.mock.On("ExportCustomer", ctx, customerID)}
+}
+
+func (_c *MockOrderService_ExportCustomer_Call) Run(run func(ctx context.Context, customerID string)) *MockOrderService_ExportCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ExportCustomer_Call) Return(customerExport *domain.CustomerExport, err error) *MockOrderService_ExportCustomer_Call {
+	_c.Call.Return(customerExport, err)
+	return _c
+}
+
+func (_c *MockOrderService_ExportCustomer_Call) RunAndReturn(run func(ctx context.Context, customerID string) (*domain.CustomerExport, error)) *MockOrderService_ExportCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCustomer provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetCustomer(ctx context.Context, customerID string) (*domain.Customer, error) {
+	ret := _mock.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomer")
+	}
+
+	var r0 *domain.Customer
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Customer, error)); ok {
+		return returnFunc(ctx, customerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Customer); ok {
+		r0 = returnFunc(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Customer)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCustomer'
+type MockOrderService_GetCustomer_Call struct {
+	*mock.Call
+}
+
+// GetCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+func (_e *MockOrderService_Expecter) GetCustomer(ctx interface{}, customerID interface{}) *MockOrderService_GetCustomer_Call {
+	return &MockOrderService_GetCustomer_Call{Call: _e.mock.On("GetCustomer", ctx, customerID)}
+}
+
+func (_c *MockOrderService_GetCustomer_Call) Run(run func(ctx context.Context, customerID string)) *MockOrderService_GetCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetCustomer_Call) Return(customer *domain.Customer, err error) *MockOrderService_GetCustomer_Call {
+	_c.Call.Return(customer, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetCustomer_Call) RunAndReturn(run func(ctx context.Context, customerID string) (*domain.Customer, error)) *MockOrderService_GetCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetOrder provides a mock function for the type MockOrderService
 func (_mock *MockOrderService) GetOrder(ctx context.Context, uid string) (*domain.Order, error) {
 	ret := _mock.Called(ctx, uid)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetOrder")
+		panic("no return value specified for GetOrder")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Order, error)); ok {
+		return returnFunc(ctx, uid)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Order); ok {
+		r0 = returnFunc(ctx, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrder'
+type MockOrderService_GetOrder_Call struct {
+	*mock.Call
+}
+
+// GetOrder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uid string
+func (_e *MockOrderService_Expecter) GetOrder(ctx interface{}, uid interface{}) *MockOrderService_GetOrder_Call {
+	return &MockOrderService_GetOrder_Call{Call: _e.mock.On("GetOrder", ctx, uid)}
+}
+
+func (_c *MockOrderService_GetOrder_Call) Run(run func(ctx context.Context, uid string)) *MockOrderService_GetOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrder_Call) Return(order *domain.Order, err error) *MockOrderService_GetOrder_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrder_Call) RunAndReturn(run func(ctx context.Context, uid string) (*domain.Order, error)) *MockOrderService_GetOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderByTransaction provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrderByTransaction(ctx context.Context, transaction string) (*domain.Order, error) {
+	ret := _mock.Called(ctx, transaction)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderByTransaction")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Order, error)); ok {
+		return returnFunc(ctx, transaction)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Order); ok {
+		r0 = returnFunc(ctx, transaction)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, transaction)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrderByTransaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderByTransaction'
+type MockOrderService_GetOrderByTransaction_Call struct {
+	*mock.Call
+}
+
+// GetOrderByTransaction is a helper method to define mock.On call
+//   - ctx context.Context
+//   - transaction string
+func (_e *MockOrderService_Expecter) GetOrderByTransaction(ctx interface{}, transaction interface{}) *MockOrderService_GetOrderByTransaction_Call {
+	return &MockOrderService_GetOrderByTransaction_Call{Call: _e.mock.On("GetOrderByTransaction", ctx, transaction)}
+}
+
+func (_c *MockOrderService_GetOrderByTransaction_Call) Run(run func(ctx context.Context, transaction string)) *MockOrderService_GetOrderByTransaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderByTransaction_Call) Return(order *domain.Order, err error) *MockOrderService_GetOrderByTransaction_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderByTransaction_Call) RunAndReturn(run func(ctx context.Context, transaction string) (*domain.Order, error)) *MockOrderService_GetOrderByTransaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderETag provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrderETag(ctx context.Context, uid string) (string, *domain.Order, error) {
+	ret := _mock.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderETag")
+	}
+
+	var r0 string
+	var r1 *domain.Order
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, *domain.Order, error)); ok {
+		return returnFunc(ctx, uid)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, uid)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) *domain.Order); ok {
+		r1 = returnFunc(ctx, uid)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = returnFunc(ctx, uid)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockOrderService_GetOrderETag_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderETag'
+type MockOrderService_GetOrderETag_Call struct {
+	*mock.Call
+}
+
+// GetOrderETag is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uid string
+func (_e *MockOrderService_Expecter) GetOrderETag(ctx interface{}, uid interface{}) *MockOrderService_GetOrderETag_Call {
+	return &MockOrderService_GetOrderETag_Call{Call: _e.mock.On("GetOrderETag", ctx, uid)}
+}
+
+func (_c *MockOrderService_GetOrderETag_Call) Run(run func(ctx context.Context, uid string)) *MockOrderService_GetOrderETag_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderETag_Call) Return(s string, order *domain.Order, err error) *MockOrderService_GetOrderETag_Call {
+	_c.Call.Return(s, order, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderETag_Call) RunAndReturn(run func(ctx context.Context, uid string) (string, *domain.Order, error)) *MockOrderService_GetOrderETag_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderHistory provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrderHistory(ctx context.Context, uid string, asOf time.Time) (*domain.OrderHistory, error) {
+	ret := _mock.Called(ctx, uid, asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderHistory")
+	}
+
+	var r0 *domain.OrderHistory
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) (*domain.OrderHistory, error)); ok {
+		return returnFunc(ctx, uid, asOf)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) *domain.OrderHistory); ok {
+		r0 = returnFunc(ctx, uid, asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.OrderHistory)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, uid, asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrderHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderHistory'
+type MockOrderService_GetOrderHistory_Call struct {
+	*mock.Call
+}
+
+// GetOrderHistory is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uid string
+//   - asOf time.Time
+func (_e *MockOrderService_Expecter) GetOrderHistory(ctx interface{}, uid interface{}, asOf interface{}) *MockOrderService_GetOrderHistory_Call {
+	return &MockOrderService_GetOrderHistory_Call{Call: _e.mock.On("GetOrderHistory", ctx, uid, asOf)}
+}
+
+func (_c *MockOrderService_GetOrderHistory_Call) Run(run func(ctx context.Context, uid string, asOf time.Time)) *MockOrderService_GetOrderHistory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderHistory_Call) Return(orderHistory *domain.OrderHistory, err error) *MockOrderService_GetOrderHistory_Call {
+	_c.Call.Return(orderHistory, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderHistory_Call) RunAndReturn(run func(ctx context.Context, uid string, asOf time.Time) (*domain.OrderHistory, error)) *MockOrderService_GetOrderHistory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderParts provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrderParts(ctx context.Context, uid string, parts domain.Part) (*domain.Order, error) {
+	ret := _mock.Called(ctx, uid, parts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderParts")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, domain.Part) (*domain.Order, error)); ok {
+		return returnFunc(ctx, uid, parts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, domain.Part) *domain.Order); ok {
+		r0 = returnFunc(ctx, uid, parts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, domain.Part) error); ok {
+		r1 = returnFunc(ctx, uid, parts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrderParts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderParts'
+type MockOrderService_GetOrderParts_Call struct {
+	*mock.Call
+}
+
+// GetOrderParts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uid string
+//   - parts domain.Part
+func (_e *MockOrderService_Expecter) GetOrderParts(ctx interface{}, uid interface{}, parts interface{}) *MockOrderService_GetOrderParts_Call {
+	return &MockOrderService_GetOrderParts_Call{Call: _e.mock.On("GetOrderParts", ctx, uid, parts)}
+}
+
+func (_c *MockOrderService_GetOrderParts_Call) Run(run func(ctx context.Context, uid string, parts domain.Part)) *MockOrderService_GetOrderParts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 domain.Part
+		if args[2] != nil {
+			arg2 = args[2].(domain.Part)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderParts_Call) Return(order *domain.Order, err error) *MockOrderService_GetOrderParts_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderParts_Call) RunAndReturn(run func(ctx context.Context, uid string, parts domain.Part) (*domain.Order, error)) *MockOrderService_GetOrderParts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCustomerOrders provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ListCustomerOrders(ctx context.Context, customerID string, pageSize int, pageToken string) (*domain.CustomerOrders, error) {
+	ret := _mock.Called(ctx, customerID, pageSize, pageToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCustomerOrders")
+	}
+
+	var r0 *domain.CustomerOrders
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) (*domain.CustomerOrders, error)); ok {
+		return returnFunc(ctx, customerID, pageSize, pageToken)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) *domain.CustomerOrders); ok {
+		r0 = returnFunc(ctx, customerID, pageSize, pageToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CustomerOrders)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int, string) error); ok {
+		r1 = returnFunc(ctx, customerID, pageSize, pageToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ListCustomerOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCustomerOrders'
+type MockOrderService_ListCustomerOrders_Call struct {
+	*mock.Call
+}
+
+// ListCustomerOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+//   - pageSize int
+//   - pageToken string
+func (_e *MockOrderService_Expecter) ListCustomerOrders(ctx interface{}, customerID interface{}, pageSize interface{}, pageToken interface{}) *MockOrderService_ListCustomerOrders_Call {
+	return &MockOrderService_ListCustomerOrders_Call{Call: _e.mock.On("ListCustomerOrders", ctx, customerID, pageSize, pageToken)}
+}
+
+func (_c *MockOrderService_ListCustomerOrders_Call) Run(run func(ctx context.Context, customerID string, pageSize int, pageToken string)) *MockOrderService_ListCustomerOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ListCustomerOrders_Call) Return(customerOrders *domain.CustomerOrders, err error) *MockOrderService_ListCustomerOrders_Call {
+	_c.Call.Return(customerOrders, err)
+	return _c
+}
+
+func (_c *MockOrderService_ListCustomerOrders_Call) RunAndReturn(run func(ctx context.Context, customerID string, pageSize int, pageToken string) (*domain.CustomerOrders, error)) *MockOrderService_ListCustomerOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOrders provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ListOrders(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string) (*domain.OrderPage, error) {
+	ret := _mock.Called(ctx, filter, pageSize, pageToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 *domain.OrderPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.OrderFilter, int, string) (*domain.OrderPage, error)); ok {
+		return returnFunc(ctx, filter, pageSize, pageToken)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.OrderFilter, int, string) *domain.OrderPage); ok {
+		r0 = returnFunc(ctx, filter, pageSize, pageToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.OrderPage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.OrderFilter, int, string) error); ok {
+		r1 = returnFunc(ctx, filter, pageSize, pageToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ListOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrders'
+type MockOrderService_ListOrders_Call struct {
+	*mock.Call
+}
+
+// ListOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.OrderFilter
+//   - pageSize int
+//   - pageToken string
+func (_e *MockOrderService_Expecter) ListOrders(ctx interface{}, filter interface{}, pageSize interface{}, pageToken interface{}) *MockOrderService_ListOrders_Call {
+	return &MockOrderService_ListOrders_Call{Call: _e.mock.On("ListOrders", ctx, filter, pageSize, pageToken)}
+}
+
+func (_c *MockOrderService_ListOrders_Call) Run(run func(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string)) *MockOrderService_ListOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(domain.OrderFilter)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ListOrders_Call) Return(orderPage *domain.OrderPage, err error) *MockOrderService_ListOrders_Call {
+	_c.Call.Return(orderPage, err)
+	return _c
+}
+
+func (_c *MockOrderService_ListOrders_Call) RunAndReturn(run func(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string) (*domain.OrderPage, error)) *MockOrderService_ListOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LookupOrders provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) LookupOrders(ctx context.Context, orderUIDs []string) (*domain.OrderLookup, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LookupOrders")
+	}
+
+	var r0 *domain.OrderLookup
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (*domain.OrderLookup, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) *domain.OrderLookup); ok {
+		r0 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.OrderLookup)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderUIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_LookupOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LookupOrders'
+type MockOrderService_LookupOrders_Call struct {
+	*mock.Call
+}
+
+// LookupOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUIDs []string
+func (_e *MockOrderService_Expecter) LookupOrders(ctx interface{}, orderUIDs interface{}) *MockOrderService_LookupOrders_Call {
+	return &MockOrderService_LookupOrders_Call{Call: _e.mock.On("LookupOrders", ctx, orderUIDs)}
+}
+
+func (_c *MockOrderService_LookupOrders_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderService_LookupOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_LookupOrders_Call) Return(orderLookup *domain.OrderLookup, err error) *MockOrderService_LookupOrders_Call {
+	_c.Call.Return(orderLookup, err)
+	return _c
+}
+
+func (_c *MockOrderService_LookupOrders_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) (*domain.OrderLookup, error)) *MockOrderService_LookupOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreOrder provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) RestoreOrder(ctx context.Context, uid string) (*domain.Order, error) {
+	ret := _mock.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreOrder")
+	}
+
+	var r0 *domain.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Order, error)); ok {
+		return returnFunc(ctx, uid)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Order); ok {
+		r0 = returnFunc(ctx, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_RestoreOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreOrder'
+type MockOrderService_RestoreOrder_Call struct {
+	*mock.Call
+}
+
+// RestoreOrder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - uid string
+func (_e *MockOrderService_Expecter) RestoreOrder(ctx interface{}, uid interface{}) *MockOrderService_RestoreOrder_Call {
+	return &MockOrderService_RestoreOrder_Call{Call: _e.mock.On("RestoreOrder", ctx, uid)}
+}
+
+func (_c *MockOrderService_RestoreOrder_Call) Run(run func(ctx context.Context, uid string)) *MockOrderService_RestoreOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_RestoreOrder_Call) Return(order *domain.Order, err error) *MockOrderService_RestoreOrder_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_RestoreOrder_Call) RunAndReturn(run func(ctx context.Context, uid string) (*domain.Order, error)) *MockOrderService_RestoreOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveExchangeRates provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) SaveExchangeRates(ctx context.Context, rates []domain.ExchangeRate) (*domain.ExchangeRatesUpdate, error) {
+	ret := _mock.Called(ctx, rates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveExchangeRates")
+	}
+
+	var r0 *domain.ExchangeRatesUpdate
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []domain.ExchangeRate) (*domain.ExchangeRatesUpdate, error)); ok {
+		return returnFunc(ctx, rates)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []domain.ExchangeRate) *domain.ExchangeRatesUpdate); ok {
+		r0 = returnFunc(ctx, rates)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ExchangeRatesUpdate)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []domain.ExchangeRate) error); ok {
+		r1 = returnFunc(ctx, rates)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_SaveExchangeRates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveExchangeRates'
+type MockOrderService_SaveExchangeRates_Call struct {
+	*mock.Call
+}
+
+// SaveExchangeRates is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rates []domain.ExchangeRate
+func (_e *MockOrderService_Expecter) SaveExchangeRates(ctx interface{}, rates interface{}) *MockOrderService_SaveExchangeRates_Call {
+	return &MockOrderService_SaveExchangeRates_Call{Call: _e.mock.On("SaveExchangeRates", ctx, rates)}
+}
+
+func (_c *MockOrderService_SaveExchangeRates_Call) Run(run func(ctx context.Context, rates []domain.ExchangeRate)) *MockOrderService_SaveExchangeRates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []domain.ExchangeRate
+		if args[1] != nil {
+			arg1 = args[1].([]domain.ExchangeRate)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_SaveExchangeRates_Call) Return(exchangeRatesUpdate *domain.ExchangeRatesUpdate, err error) *MockOrderService_SaveExchangeRates_Call {
+	_c.Call.Return(exchangeRatesUpdate, err)
+	return _c
+}
+
+func (_c *MockOrderService_SaveExchangeRates_Call) RunAndReturn(run func(ctx context.Context, rates []domain.ExchangeRate) (*domain.ExchangeRatesUpdate, error)) *MockOrderService_SaveExchangeRates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchOrders provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) SearchOrders(ctx context.Context, query string, pageSize int, pageToken string) (*domain.SearchPage, error) {
+	ret := _mock.Called(ctx, query, pageSize, pageToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchOrders")
+	}
+
+	var r0 *domain.SearchPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) (*domain.SearchPage, error)); ok {
+		return returnFunc(ctx, query, pageSize, pageToken)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, string) *domain.SearchPage); ok {
+		r0 = returnFunc(ctx, query, pageSize, pageToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SearchPage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int, string) error); ok {
+		r1 = returnFunc(ctx, query, pageSize, pageToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_SearchOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchOrders'
+type MockOrderService_SearchOrders_Call struct {
+	*mock.Call
+}
+
+// SearchOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - pageSize int
+//   - pageToken string
+func (_e *MockOrderService_Expecter) SearchOrders(ctx interface{}, query interface{}, pageSize interface{}, pageToken interface{}) *MockOrderService_SearchOrders_Call {
+	return &MockOrderService_SearchOrders_Call{Call: _e.mock.On("SearchOrders", ctx, query, pageSize, pageToken)}
+}
+
+func (_c *MockOrderService_SearchOrders_Call) Run(run func(ctx context.Context, query string, pageSize int, pageToken string)) *MockOrderService_SearchOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_SearchOrders_Call) Return(searchPage *domain.SearchPage, err error) *MockOrderService_SearchOrders_Call {
+	_c.Call.Return(searchPage, err)
+	return _c
+}
+
+func (_c *MockOrderService_SearchOrders_Call) RunAndReturn(run func(ctx context.Context, query string, pageSize int, pageToken string) (*domain.SearchPage, error)) *MockOrderService_SearchOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TopBrands provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) TopBrands(ctx context.Context, from time.Time, to time.Time, limit int) (*domain.TopBrandsReport, error) {
+	ret := _mock.Called(ctx, from, to, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopBrands")
+	}
+
+	var r0 *domain.TopBrandsReport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int) (*domain.TopBrandsReport, error)); ok {
+		return returnFunc(ctx, from, to, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int) *domain.TopBrandsReport); ok {
+		r0 = returnFunc(ctx, from, to, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TopBrandsReport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, int) error); ok {
+		r1 = returnFunc(ctx, from, to, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_TopBrands_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TopBrands'
+type MockOrderService_TopBrands_Call struct {
+	*mock.Call
+}
+
+// TopBrands is a helper method to define mock.On call
+//   - ctx context.Context
+//   - from time.Time
+//   - to time.Time
+//   - limit int
+func (_e *MockOrderService_Expecter) TopBrands(ctx interface{}, from interface{}, to interface{}, limit interface{}) *MockOrderService_TopBrands_Call {
+	return &MockOrderService_TopBrands_Call{Call: _e.mock.On("TopBrands", ctx, from, to, limit)}
+}
+
+func (_c *MockOrderService_TopBrands_Call) Run(run func(ctx context.Context, from time.Time, to time.Time, limit int)) *MockOrderService_TopBrands_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_TopBrands_Call) Return(topBrandsReport *domain.TopBrandsReport, err error) *MockOrderService_TopBrands_Call {
+	_c.Call.Return(topBrandsReport, err)
+	return _c
+}
+
+func (_c *MockOrderService_TopBrands_Call) RunAndReturn(run func(ctx context.Context, from time.Time, to time.Time, limit int) (*domain.TopBrandsReport, error)) *MockOrderService_TopBrands_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateOrder provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) UpdateOrder(ctx context.Context, uid string, order *domain.Order, ifMatch string) (*domain.Order, error) {
+	ret := _mock.Called(ctx, uid, order, ifMatch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateOrder")
 	}
 
 	var r0 *domain.Order
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*domain.Order, error)); ok {
-		return returnFunc(ctx, uid)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *domain.Order, string) (*domain.Order, error)); ok {
+		return returnFunc(ctx, uid, order, ifMatch)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *domain.Order); ok {
-		r0 = returnFunc(ctx, uid)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *domain.Order, string) *domain.Order); ok {
+		r0 = returnFunc(ctx, uid, order, ifMatch)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Order)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, uid)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *domain.Order, string) error); ok {
+		r1 = returnFunc(ctx, uid, order, ifMatch)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockOrderService_GetOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrder'
-type MockOrderService_GetOrder_Call struct {
+// MockOrderService_UpdateOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateOrder'
+type MockOrderService_UpdateOrder_Call struct {
 	*mock.Call
 }
 
-// GetOrder is a helper method to define mock.On call
+// UpdateOrder is a helper method to define mock.On call
 //   - ctx context.Context
 //   - uid string
-func (_e *MockOrderService_Expecter) GetOrder(ctx interface{}, uid interface{}) *MockOrderService_GetOrder_Call {
-	return &MockOrderService_GetOrder_Call{Call: _e.mock.On("GetOrder", ctx, uid)}
+//   - order *domain.Order
+//   - ifMatch string
+func (_e *MockOrderService_Expecter) UpdateOrder(ctx interface{}, uid interface{}, order interface{}, ifMatch interface{}) *MockOrderService_UpdateOrder_Call {
+	return &MockOrderService_UpdateOrder_Call{Call: _e.mock.On("UpdateOrder", ctx, uid, order, ifMatch)}
 }
 
-func (_c *MockOrderService_GetOrder_Call) Run(run func(ctx context.Context, uid string)) *MockOrderService_GetOrder_Call {
+func (_c *MockOrderService_UpdateOrder_Call) Run(run func(ctx context.Context, uid string, order *domain.Order, ifMatch string)) *MockOrderService_UpdateOrder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -88,20 +1383,200 @@ func (_c *MockOrderService_GetOrder_Call) Run(run func(ctx context.Context, uid
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 *domain.Order
+		if args[2] != nil {
+			arg2 = args[2].(*domain.Order)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockOrderService_GetOrder_Call) Return(order *domain.Order, err error) *MockOrderService_GetOrder_Call {
-	_c.Call.Return(order, err)
+func (_c *MockOrderService_UpdateOrder_Call) Return(order1 *domain.Order, err error) *MockOrderService_UpdateOrder_Call {
+	_c.Call.Return(order1, err)
 	return _c
 }
 
-func (_c *MockOrderService_GetOrder_Call) RunAndReturn(run func(ctx context.Context, uid string) (*domain.Order, error)) *MockOrderService_GetOrder_Call {
+func (_c *MockOrderService_UpdateOrder_Call) RunAndReturn(run func(ctx context.Context, uid string, order *domain.Order, ifMatch string) (*domain.Order, error)) *MockOrderService_UpdateOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOrderStream creates a new instance of MockOrderStream. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderStream(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderStream {
+	mock := &MockOrderStream{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderStream is an autogenerated mock type for the OrderStream type
+type MockOrderStream struct {
+	mock.Mock
+}
+
+type MockOrderStream_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderStream) EXPECT() *MockOrderStream_Expecter {
+	return &MockOrderStream_Expecter{mock: &_m.Mock}
+}
+
+// Subscribe provides a mock function for the type MockOrderStream
+func (_mock *MockOrderStream) Subscribe(filter broadcast.Filter, lastEventID uint64) (*broadcast.Subscription, []domain.OrderEvent) {
+	ret := _mock.Called(filter, lastEventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 *broadcast.Subscription
+	var r1 []domain.OrderEvent
+	if returnFunc, ok := ret.Get(0).(func(broadcast.Filter, uint64) (*broadcast.Subscription, []domain.OrderEvent)); ok {
+		return returnFunc(filter, lastEventID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(broadcast.Filter, uint64) *broadcast.Subscription); ok {
+		r0 = returnFunc(filter, lastEventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*broadcast.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(broadcast.Filter, uint64) []domain.OrderEvent); ok {
+		r1 = returnFunc(filter, lastEventID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]domain.OrderEvent)
+		}
+	}
+	return r0, r1
+}
+
+// MockOrderStream_Subscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subscribe'
+type MockOrderStream_Subscribe_Call struct {
+	*mock.Call
+}
+
+// Subscribe is a helper method to define mock.On call
+//   - filter broadcast.Filter
+//   - lastEventID uint64
+func (_e *MockOrderStream_Expecter) Subscribe(filter interface{}, lastEventID interface{}) *MockOrderStream_Subscribe_Call {
+	return &MockOrderStream_Subscribe_Call{Call: _e.mock.On("Subscribe", filter, lastEventID)}
+}
+
+func (_c *MockOrderStream_Subscribe_Call) Run(run func(filter broadcast.Filter, lastEventID uint64)) *MockOrderStream_Subscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 broadcast.Filter
+		if args[0] != nil {
+			arg0 = args[0].(broadcast.Filter)
+		}
+		var arg1 uint64
+		if args[1] != nil {
+			arg1 = args[1].(uint64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderStream_Subscribe_Call) Return(subscription *broadcast.Subscription, orderEvents []domain.OrderEvent) *MockOrderStream_Subscribe_Call {
+	_c.Call.Return(subscription, orderEvents)
+	return _c
+}
+
+func (_c *MockOrderStream_Subscribe_Call) RunAndReturn(run func(filter broadcast.Filter, lastEventID uint64) (*broadcast.Subscription, []domain.OrderEvent)) *MockOrderStream_Subscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOrderTracker creates a new instance of MockOrderTracker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderTracker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderTracker {
+	mock := &MockOrderTracker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderTracker is an autogenerated mock type for the OrderTracker type
+type MockOrderTracker struct {
+	mock.Mock
+}
+
+type MockOrderTracker_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderTracker) EXPECT() *MockOrderTracker_Expecter {
+	return &MockOrderTracker_Expecter{mock: &_m.Mock}
+}
+
+// Watch provides a mock function for the type MockOrderTracker
+func (_mock *MockOrderTracker) Watch() *broadcast.Watcher {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Watch")
+	}
+
+	var r0 *broadcast.Watcher
+	if returnFunc, ok := ret.Get(0).(func() *broadcast.Watcher); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*broadcast.Watcher)
+		}
+	}
+	return r0
+}
+
+// MockOrderTracker_Watch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Watch'
+type MockOrderTracker_Watch_Call struct {
+	*mock.Call
+}
+
+// Watch is a helper method to define mock.On call
+func (_e *MockOrderTracker_Expecter) Watch() *MockOrderTracker_Watch_Call {
+	return &MockOrderTracker_Watch_Call{Call: _e.mock.On("Watch")}
+}
+
+func (_c *MockOrderTracker_Watch_Call) Run(run func()) *MockOrderTracker_Watch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockOrderTracker_Watch_Call) Return(watcher *broadcast.Watcher) *MockOrderTracker_Watch_Call {
+	_c.Call.Return(watcher)
+	return _c
+}
+
+func (_c *MockOrderTracker_Watch_Call) RunAndReturn(run func() *broadcast.Watcher) *MockOrderTracker_Watch_Call {
 	_c.Call.Return(run)
 	return _c
 }
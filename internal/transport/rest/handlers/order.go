@@ -3,31 +3,72 @@ package handlers
 import (
 	"context"
 	"errors"
+	"github.com/Killazius/L0/internal/auth"
 	"github.com/Killazius/L0/internal/domain"
 	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/lib/broadcast"
 	"github.com/Killazius/L0/internal/service"
+	"github.com/Killazius/L0/pkg/mask"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"go.uber.org/zap"
 	"net/http"
+	"time"
 )
 
 type OrderService interface {
 	GetOrder(ctx context.Context, uid string) (*domain.Order, error)
+	GetOrderParts(ctx context.Context, uid string, parts domain.Part) (*domain.Order, error)
+	GetOrderETag(ctx context.Context, uid string) (string, *domain.Order, error)
+	UpdateOrder(ctx context.Context, uid string, order *domain.Order, ifMatch string) (*domain.Order, error)
+	GetOrderHistory(ctx context.Context, uid string, asOf time.Time) (*domain.OrderHistory, error)
+	DeleteOrder(ctx context.Context, uid string) error
+	RestoreOrder(ctx context.Context, uid string) (*domain.Order, error)
+	LookupOrders(ctx context.Context, orderUIDs []string) (*domain.OrderLookup, error)
+	ListOrders(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string) (*domain.OrderPage, error)
+	GetOrderByTransaction(ctx context.Context, transaction string) (*domain.Order, error)
+	SearchOrders(ctx context.Context, query string, pageSize int, pageToken string) (*domain.SearchPage, error)
+	ExportCustomer(ctx context.Context, customerID string) (*domain.CustomerExport, error)
+	EraseCustomer(ctx context.Context, customerID, requestedBy, reason string) (*domain.Erasure, error)
+	GetCustomer(ctx context.Context, customerID string) (*domain.Customer, error)
+	ListCustomerOrders(ctx context.Context, customerID string, pageSize int, pageToken string) (*domain.CustomerOrders, error)
+	DailyStats(ctx context.Context, query domain.StatsQuery) (*domain.DailyStatsReport, error)
+	TopBrands(ctx context.Context, from, to time.Time, limit int) (*domain.TopBrandsReport, error)
+	ExchangeRates(ctx context.Context, at time.Time) (*domain.ExchangeRates, error)
+	SaveExchangeRates(ctx context.Context, rates []domain.ExchangeRate) (*domain.ExchangeRatesUpdate, error)
+}
+
+type OrderStream interface {
+	Subscribe(filter broadcast.Filter, lastEventID uint64) (*broadcast.Subscription, []domain.OrderEvent)
 }
 
 type Handler struct {
-	log     *zap.SugaredLogger
-	service OrderService
+	log          *zap.SugaredLogger
+	service      OrderService
+	stream       OrderStream
+	tracker      OrderTracker
+	streams      context.Context
+	closeStreams context.CancelFunc
 }
 
-func New(log *zap.SugaredLogger, service OrderService) *Handler {
+func New(log *zap.SugaredLogger, service OrderService, stream OrderStream, tracker OrderTracker) *Handler {
+	streams, closeStreams := context.WithCancel(context.Background())
 	return &Handler{
-		log:     log,
-		service: service,
+		log:          log,
+		service:      service,
+		stream:       stream,
+		tracker:      tracker,
+		streams:      streams,
+		closeStreams: closeStreams,
 	}
 }
 
+// CloseStreams ends the long-lived SSE and WebSocket responses. The server
+// calls it on shutdown, which otherwise would wait for them until its timeout.
+func (h *Handler) CloseStreams() {
+	h.closeStreams()
+}
+
 // GetOrder godoc
 // @Summary Get order by UID
 // @Description Get order details by order UID
@@ -35,10 +76,22 @@ func New(log *zap.SugaredLogger, service OrderService) *Handler {
 // @Accept  json
 // @Produce  json
 // @Param order_uid path string true "Order UID"
+// @Param fields query string false "Comma separated order fields, fields of a part as items.name" example(order_uid,track_number,items.name)
+// @Param include query string false "Comma separated parts returned in full: delivery, payment, items" example(items,payment)
+// @Param If-None-Match header string false "ETag of a cached representation"
+// @Param If-Modified-Since header string false "Date of a cached representation"
 // @Success 200 {object} domain.Order "Order details"
+// @Header 200 {string} ETag "Entity tag of the order view, the masked and the full view have different tags"
+// @Header 200 {string} Last-Modified "Time of the last order update"
+// @Success 304 "Order not modified"
 // @Failure 400 {object} response.ErrorResponse "Invalid order UID"
 // @Failure 404 {object} response.ErrorResponse "Order not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /order/{order_uid} [get]
 func (h *Handler) GetOrder() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -51,7 +104,37 @@ func (h *Handler) GetOrder() http.HandlerFunc {
 		}
 		log := h.log.With("order_uid", orderUID)
 
-		order, err := h.service.GetOrder(r.Context(), orderUID)
+		query := r.URL.Query()
+		projection, err := domain.ParseProjection(query.Get("fields"), query.Get("include"))
+		if err != nil {
+			log.Infow("invalid projection", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid projection", http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		// The cached tag belongs to the whole order, so it only answers
+		// requests without a projection. An order read to compute the tag is
+		// reused when the tag does not match.
+		var order *domain.Order
+		if match := r.Header.Get("If-None-Match"); match != "" && projection.IsFull() {
+			etag, loaded, err := h.service.GetOrderETag(r.Context(), orderUID)
+			if etag = viewETag(r.Context(), etag); err == nil && etagMatches(match, etag) {
+				log.Info("order not modified")
+				setCacheHeaders(w, etag, time.Time{})
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			order = loaded
+		}
+
+		switch {
+		case order != nil:
+		case projection.IsFull():
+			order, err = h.service.GetOrder(r.Context(), orderUID)
+		default:
+			order, err = h.service.GetOrderParts(r.Context(), orderUID, projection.Parts())
+		}
 		if err != nil {
 			switch {
 			case errors.Is(err, service.ErrOrderNotFound):
@@ -75,7 +158,54 @@ func (h *Handler) GetOrder() http.HandlerFunc {
 			render.JSON(w, r, response.NewErrorResponse("order not found", http.StatusNotFound, "The requested order was not found in the system"))
 			return
 		}
+		etag := viewETag(r.Context(), order.ETag())
+		order = orderView(r.Context(), order)
+		var body any = order
+		if !projection.IsFull() {
+			body, etag, err = projectOrder(projection, order, etag)
+			if err != nil {
+				log.Errorw("failed to project order", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "The requested order was not found in the system"))
+				return
+			}
+		}
+		setCacheHeaders(w, etag, order.UpdatedAt)
+		if notModified(r, etag, order.UpdatedAt) {
+			log.Info("order not modified")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
 		log.Info("success get order")
-		render.JSON(w, r, order)
+		render.JSON(w, r, body)
+	}
+}
+
+// projectOrder returns the selected fields of the order with their entity tag,
+// derived from etag, the tag of the order view.
+func projectOrder(projection domain.Projection, order *domain.Order, etag string) (map[string]any, string, error) {
+	body, err := projection.Apply(order)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, projection.ETag(etag), nil
+}
+
+// orderView masks customer personal data for callers below the support role.
+func orderView(ctx context.Context, order *domain.Order) *domain.Order {
+	principal, _ := auth.FromContext(ctx)
+	if principal.Role.Allows(auth.RoleSupport) {
+		return order
+	}
+	return mask.Masked(order)
+}
+
+// viewETag returns the entity tag of the order view orderView returns for the
+// caller, given the tag of the whole order.
+func viewETag(ctx context.Context, etag string) string {
+	principal, _ := auth.FromContext(ctx)
+	if principal.Role.Allows(auth.RoleSupport) {
+		return etag
 	}
+	return domain.MaskedETag(etag)
 }
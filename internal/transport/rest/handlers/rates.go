@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/lib/rates"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/render"
+	"mime"
+	"net/http"
+)
+
+const maxRatesBodyBytes = 4 << 20
+
+// ExchangeRates godoc
+// @Summary Get exchange rates
+// @Description Rates in the base currency effective on a UTC day, the latest rate of every currency dated on or before it
+// @Tags exchange rates
+// @Produce  json
+// @Param date query string false "Day, YYYY-MM-DD; today by default" example(2021-11-26)
+// @Success 200 {object} domain.ExchangeRates "Exchange rates"
+// @Failure 400 {object} response.ErrorResponse "Invalid query or no base currency"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/exchange-rates [get]
+func (h *Handler) ExchangeRates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		date, err := dateParam(r.URL.Query(), "date")
+		if err != nil {
+			h.log.Infow("invalid exchange rates query", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+			return
+		}
+		exchangeRates, err := h.service.ExchangeRates(r.Context(), date)
+		if err != nil {
+			h.ratesError(w, r, err)
+			return
+		}
+		render.JSON(w, r, exchangeRates)
+	}
+}
+
+// SaveExchangeRates godoc
+// @Summary Save exchange rates
+// @Description Save dated rates in the base currency, replacing the rates of the same currencies and days, and convert the stored orders that had no rate so far. The body is a JSON array, dates as YYYY-MM-DD or RFC 3339, or with Content-Type text/csv the rows date,currency,rate
+// @Tags exchange rates
+// @Accept  json
+// @Accept  text/csv
+// @Produce  json
+// @Param request body []domain.ExchangeRate true "Exchange rates"
+// @Success 200 {object} domain.ExchangeRatesUpdate "Saved rates and converted orders"
+// @Failure 400 {object} response.ErrorResponse "Invalid rates or no base currency"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/exchange-rates [put]
+func (h *Handler) SaveExchangeRates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parse := rates.ParseJSON
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+			parse = rates.ParseCSV
+		}
+		exchangeRates, err := parse(http.MaxBytesReader(w, r.Body, maxRatesBodyBytes))
+		if err != nil {
+			h.log.Infow("invalid exchange rates", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid request body", http.StatusBadRequest, err.Error()))
+			return
+		}
+		update, err := h.service.SaveExchangeRates(r.Context(), exchangeRates)
+		if err != nil {
+			h.ratesError(w, r, err)
+			return
+		}
+		h.log.Infow("exchange rates saved", "saved", update.Saved, "converted", update.Converted)
+		render.JSON(w, r, update)
+	}
+}
+
+func (h *Handler) ratesError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrInvalidExchangeRates) {
+		h.log.Infow("invalid exchange rates", "error", err)
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.NewErrorResponse("invalid exchange rates", http.StatusBadRequest, err.Error()))
+		return
+	}
+	h.log.Errorw("internal server error", "error", err)
+	render.Status(r, http.StatusInternalServerError)
+	render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to process the exchange rates"))
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+// SearchOrders godoc
+// @Summary Search orders
+// @Description Full-text search over customer name, city, address, item brand and name, best matches first. Names and addresses of encrypted deliveries are not searchable
+// @Tags orders
+// @Produce  json
+// @Param q query string true "Web search query: words, quoted phrases, or, -word" example(vivienne mascaras)
+// @Param page_size query int false "Page size, at most 100" default(20)
+// @Param page_token query string false "next_cursor of the previous page"
+// @Success 200 {object} domain.SearchPage "Ranked results with highlights"
+// @Failure 400 {object} response.ErrorResponse "Invalid query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/search [get]
+func (h *Handler) SearchOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		pageSize, err := intParam(query, "page_size")
+		if err != nil {
+			h.log.Infow("invalid search query", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		page, err := h.service.SearchOrders(r.Context(), query.Get("q"), pageSize, query.Get("page_token"))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrInvalidQuery):
+				h.log.Infow("invalid search query", "error", err)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+			default:
+				h.log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to search the orders"))
+			}
+			return
+		}
+		for i := range page.Results {
+			page.Results[i].Order = *orderView(r.Context(), &page.Results[i].Order)
+		}
+		h.log.Infow("orders searched", "results", len(page.Results))
+		render.JSON(w, r, page)
+	}
+}
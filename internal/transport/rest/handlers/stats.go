@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/render"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DailyStats godoc
+// @Summary Daily order statistics
+// @Description Order count, sums of amount and delivery cost and average basket size (items per order) of every UTC day, per currency and optionally per group, with the amounts converted to the base currency and their totals across currencies. Deleted orders are not counted; orders without an exchange rate are counted as unconverted. The statistics are refreshed periodically and lag behind the orders
+// @Tags stats
+// @Produce  json
+// @Param from query string false "First day, YYYY-MM-DD; 30 days before to by default" example(2021-11-01)
+// @Param to query string false "Day after the last one, YYYY-MM-DD; tomorrow by default" example(2021-12-01)
+// @Param group_by query string false "Group by" Enums(provider, bank, delivery_service, currency, locale)
+// @Success 200 {object} domain.DailyStatsReport "Daily statistics"
+// @Failure 400 {object} response.ErrorResponse "Invalid query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /stats/daily [get]
+func (h *Handler) DailyStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		from, to, err := statsRangeParams(query)
+		if err != nil {
+			h.statsError(w, r, fmt.Errorf("%w: %w", service.ErrInvalidQuery, err))
+			return
+		}
+		report, err := h.service.DailyStats(r.Context(), domain.StatsQuery{
+			From:    from,
+			To:      to,
+			GroupBy: domain.StatsDimension(query.Get("group_by")),
+		})
+		if err != nil {
+			h.statsError(w, r, err)
+			return
+		}
+		render.JSON(w, r, report)
+	}
+}
+
+// TopBrands godoc
+// @Summary Top brands by day
+// @Description Brands with the most items sold on every UTC day, with the orders and the revenue per currency and in the base currency. Deleted orders are not counted. The statistics are refreshed periodically and lag behind the orders
+// @Tags stats
+// @Produce  json
+// @Param from query string false "First day, YYYY-MM-DD; 30 days before to by default" example(2021-11-01)
+// @Param to query string false "Day after the last one, YYYY-MM-DD; tomorrow by default" example(2021-12-01)
+// @Param limit query int false "Brands per day, at most 100" default(10)
+// @Success 200 {object} domain.TopBrandsReport "Top brands"
+// @Failure 400 {object} response.ErrorResponse "Invalid query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /stats/brands [get]
+func (h *Handler) TopBrands() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		from, to, err := statsRangeParams(query)
+		if err != nil {
+			h.statsError(w, r, fmt.Errorf("%w: %w", service.ErrInvalidQuery, err))
+			return
+		}
+		limit, err := intParam(query, "limit")
+		if err != nil {
+			h.statsError(w, r, fmt.Errorf("%w: %w", service.ErrInvalidQuery, err))
+			return
+		}
+		report, err := h.service.TopBrands(r.Context(), from, to, limit)
+		if err != nil {
+			h.statsError(w, r, err)
+			return
+		}
+		render.JSON(w, r, report)
+	}
+}
+
+func (h *Handler) statsError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrInvalidQuery) {
+		h.log.Infow("invalid stats query", "error", err)
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.NewErrorResponse("invalid query", http.StatusBadRequest, err.Error()))
+		return
+	}
+	h.log.Errorw("internal server error", "error", err)
+	render.Status(r, http.StatusInternalServerError)
+	render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to get the statistics"))
+}
+
+func statsRangeParams(query url.Values) (time.Time, time.Time, error) {
+	from, err := dateParam(query, "from")
+	if err != nil {
+		return from, time.Time{}, err
+	}
+	to, err := dateParam(query, "to")
+	return from, to, err
+}
+
+func dateParam(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a date, YYYY-MM-DD", name)
+	}
+	return t, nil
+}
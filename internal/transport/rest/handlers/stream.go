@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/lib/broadcast"
+	"github.com/go-chi/render"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	heartbeatInterval  = 15 * time.Second
+	streamWriteTimeout = 10 * time.Second
+	streamRetry        = 3 * time.Second
+)
+
+// StreamOrders godoc
+// @Summary Stream order changes
+// @Description Server-Sent Events stream of order changes with a domain.OrderEvent as data. The event type is order.created for a new order,
+// @Description order.updated for a replaced one, order.deleted for a soft-deleted one and order.restored for a restored one.
+// @Description A client resumes after a reconnect with the Last-Event-ID header (or last_event_id query parameter);
+// @Description only the events still in the replay buffer are delivered. Clients that cannot keep up are disconnected.
+// @Tags orders
+// @Produce  text/event-stream
+// @Param customer_id query string false "Only orders of the customer"
+// @Param delivery_service query string false "Only orders of the delivery service"
+// @Param last_event_id query integer false "Resume after this event id"
+// @Param Last-Event-ID header integer false "Resume after this event id"
+// @Success 200 {object} domain.OrderEvent "Event stream"
+// @Failure 400 {object} response.ErrorResponse "Invalid event id"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/stream [get]
+func (h *Handler) StreamOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lastEventID, err := parseLastEventID(r)
+		if err != nil {
+			h.log.Infow("invalid last event id", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid last event id", http.StatusBadRequest, err.Error()))
+			return
+		}
+		filter := broadcast.Filter{
+			CustomerID:      r.URL.Query().Get("customer_id"),
+			DeliveryService: r.URL.Query().Get("delivery_service"),
+		}
+		log := h.log.With("customer_id", filter.CustomerID, "delivery_service", filter.DeliveryService)
+
+		rc := http.NewResponseController(w)
+		sub, missed := h.stream.Subscribe(filter, lastEventID)
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		// The server write timeout is meant for regular responses, a stream gets
+		// a deadline per write instead, so a stalled client is still cut off.
+		send := func(format string, args ...any) bool {
+			if err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return false
+			}
+			if _, err := fmt.Fprintf(w, format, args...); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+		sendEvent := func(event domain.OrderEvent) bool {
+			event.Order = orderView(r.Context(), event.Order)
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Errorw("failed to marshal order event", "event_id", event.ID, "error", err)
+				return true
+			}
+			return send("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+
+		if !send("retry: %d\n\n", streamRetry.Milliseconds()) {
+			return
+		}
+		for _, event := range missed {
+			if !sendEvent(event) {
+				return
+			}
+		}
+		log.Infow("order stream opened", "replayed", len(missed))
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				log.Info("order stream closed by client")
+				return
+			case <-h.streams.Done():
+				return
+			case event, ok := <-sub.Events():
+				if !ok {
+					if sub.Dropped() {
+						log.Warn("order stream dropped, client is too slow")
+					}
+					return
+				}
+				if !sendEvent(event) {
+					return
+				}
+			case <-heartbeat.C:
+				if !send(": heartbeat\n\n") {
+					return
+				}
+			}
+		}
+	}
+}
+
+func parseLastEventID(r *http.Request) (uint64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
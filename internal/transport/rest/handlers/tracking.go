@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/broadcast"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"time"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = 2 * wsPingInterval
+	wsReadLimit    = 16 << 10
+	wsOutBuffer    = 16
+)
+
+type OrderTracker interface {
+	Watch() *broadcast.Watcher
+}
+
+// TrackRequest is a message from a tracking client
+// @Description WebSocket client message
+type TrackRequest struct {
+	Action    string   `json:"action" enums:"subscribe,unsubscribe" example:"subscribe"`
+	OrderUIDs []string `json:"order_uids" example:"b563feb7b2b84b6test"`
+}
+
+// TrackMessage is a message to a tracking client. Type is one of
+//   - order: the current state of a subscribed order
+//   - pending: the order does not exist yet, it is pushed when it arrives
+//   - event: a change of a subscribed order
+//   - subscribed, unsubscribed: acknowledgement of a request
+//   - error: an invalid request, the connection stays open
+//
+// @Description WebSocket server message
+type TrackMessage struct {
+	Type      string             `json:"type" example:"event"`
+	OrderUID  string             `json:"order_uid,omitempty" example:"b563feb7b2b84b6test"`
+	OrderUIDs []string           `json:"order_uids,omitempty"`
+	Order     *domain.Order      `json:"order,omitempty"`
+	Event     *domain.OrderEvent `json:"event,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// TrackOrders godoc
+// @Summary Track orders live
+// @Description WebSocket endpoint. The client sends TrackRequest messages to subscribe to order UIDs and receives TrackMessage messages:
+// @Description the current state of every subscribed order, then every change of it. Browsers pass credentials in the api_key or access_token query parameter.
+// @Tags orders
+// @Param api_key query string false "API key for clients that cannot set headers"
+// @Param access_token query string false "Bearer token for clients that cannot set headers"
+// @Success 101 {object} TrackMessage "Switching protocols"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/ws [get]
+func (h *Handler) TrackOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			h.log.Infow("websocket upgrade failed", "error", err)
+			return
+		}
+		defer conn.Close()
+
+		watcher := h.tracker.Watch()
+		defer watcher.Close()
+
+		// A hijacked connection is not closed by the server shutdown, so the
+		// handler closes it itself when the streams are closed.
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(h.streams, cancel)
+		defer stop()
+		out := make(chan TrackMessage, wsOutBuffer)
+		writerDone := make(chan struct{})
+		go func() {
+			defer close(writerDone)
+			// Closing the connection unblocks the read loop below.
+			defer conn.Close()
+			defer cancel()
+			h.writeTracking(ctx, conn, watcher, out)
+		}()
+
+		conn.SetReadLimit(wsReadLimit)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		h.log.Info("tracking connection opened")
+		for {
+			var req TrackRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) && ctx.Err() == nil {
+					h.log.Infow("tracking connection closed", "error", err)
+				}
+				break
+			}
+			if !h.handleTrackRequest(ctx, watcher, req, out) {
+				break
+			}
+		}
+		cancel()
+		<-writerDone
+	}
+}
+
+func (h *Handler) handleTrackRequest(ctx context.Context, watcher *broadcast.Watcher, req TrackRequest, out chan<- TrackMessage) bool {
+	send := func(msg TrackMessage) bool {
+		select {
+		case out <- msg:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	switch req.Action {
+	case "subscribe":
+		if err := watcher.Add(req.OrderUIDs...); err != nil {
+			return send(TrackMessage{Type: "error", Error: err.Error()})
+		}
+		if !send(TrackMessage{Type: "subscribed", OrderUIDs: req.OrderUIDs}) {
+			return false
+		}
+		// The current state is read after the watcher is registered, so an order
+		// arriving in between is pushed as an event and never missed.
+		for _, uid := range req.OrderUIDs {
+			order, err := h.service.GetOrder(ctx, uid)
+			switch {
+			case err == nil:
+				if !send(TrackMessage{Type: "order", OrderUID: uid, Order: orderView(ctx, order)}) {
+					return false
+				}
+			case errors.Is(err, service.ErrOrderNotFound):
+				if !send(TrackMessage{Type: "pending", OrderUID: uid}) {
+					return false
+				}
+			default:
+				h.log.Warnw("failed to get tracked order", "order_uid", uid, "error", err)
+				if !send(TrackMessage{Type: "error", OrderUID: uid, Error: "failed to get order"}) {
+					return false
+				}
+			}
+		}
+		return true
+	case "unsubscribe":
+		watcher.Remove(req.OrderUIDs...)
+		return send(TrackMessage{Type: "unsubscribed", OrderUIDs: req.OrderUIDs})
+	default:
+		return send(TrackMessage{Type: "error", Error: "unknown action " + req.Action})
+	}
+}
+
+// writeTracking is the only writer of the connection, as gorilla/websocket
+// requires. It pushes responses, order events and pings.
+func (h *Handler) writeTracking(ctx context.Context, conn *websocket.Conn, watcher *broadcast.Watcher, out <-chan TrackMessage) {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	write := func(msg TrackMessage) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		return conn.WriteJSON(msg) == nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			return
+		case msg := <-out:
+			if !write(msg) {
+				return
+			}
+		case event, ok := <-watcher.Events():
+			if !ok {
+				h.log.Warn("tracking connection dropped, client is too slow")
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too slow"), time.Now().Add(time.Second))
+				return
+			}
+			event.Order = orderView(ctx, event.Order)
+			if !write(TrackMessage{Type: "event", OrderUID: event.Order.OrderUID, Event: &event}) {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"net/http"
+)
+
+const maxOrderBodyBytes = 1 << 20
+
+// UpdateOrder godoc
+// @Summary Update order
+// @Description Replace the order. If-Match must hold the ETag of the order as last read, or *, so concurrent changes are not overwritten
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param order_uid path string true "Order UID"
+// @Param If-Match header string true "ETag of the order being replaced"
+// @Param order body domain.Order true "New order"
+// @Success 200 {object} domain.Order "Updated order"
+// @Header 200 {string} ETag "Entity tag of the updated order"
+// @Failure 400 {object} response.ErrorResponse "Invalid order"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 412 {object} response.ErrorResponse "Order has changed"
+// @Failure 428 {object} response.ErrorResponse "If-Match is missing"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /order/{order_uid} [put]
+func (h *Handler) UpdateOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orderUID := chi.URLParam(r, "order_uid")
+		log := h.log.With("order_uid", orderUID)
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			log.Info("if-match is missing")
+			render.Status(r, http.StatusPreconditionRequired)
+			render.JSON(w, r, response.NewErrorResponse("precondition required", http.StatusPreconditionRequired, "If-Match header is required"))
+			return
+		}
+
+		var order domain.Order
+		if err := render.DecodeJSON(http.MaxBytesReader(w, r.Body, maxOrderBodyBytes), &order); err != nil {
+			log.Infow("invalid order body", "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.NewErrorResponse("invalid request body", http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		updated, err := h.service.UpdateOrder(r.Context(), orderUID, &order, ifMatch)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrInvalidOrderData):
+				log.Infow("invalid order data", "error", err)
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.NewErrorResponse("invalid order data", http.StatusBadRequest, err.Error()))
+			case errors.Is(err, service.ErrOrderNotFound):
+				log.Info("order not found")
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.NewErrorResponse("order not found", http.StatusNotFound, "The requested order was not found in the system"))
+			case errors.Is(err, service.ErrPreconditionFailed):
+				log.Infow("order has changed", "error", err)
+				render.Status(r, http.StatusPreconditionFailed)
+				render.JSON(w, r, response.NewErrorResponse("precondition failed", http.StatusPreconditionFailed, "The order has changed since it was read"))
+			default:
+				log.Errorw("internal server error", "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.NewErrorResponse("internal server error", http.StatusInternalServerError, "Failed to update the order"))
+			}
+			return
+		}
+
+		w.Header().Set("ETag", updated.ETag())
+		log.Infow("order updated", "version", updated.Version)
+		render.JSON(w, r, updated)
+	}
+}
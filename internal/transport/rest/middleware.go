@@ -1,7 +1,12 @@
 package rest
 
 import (
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/api/response"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 	"net/http"
 	"time"
@@ -32,3 +37,67 @@ func logMiddleware(log *zap.SugaredLogger) func(next http.Handler) http.Handler
 		})
 	}
 }
+
+func authMiddleware(a Authenticator, log *zap.SugaredLogger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := a.Authenticate(r)
+			if err != nil {
+				log.Infow("authentication failed",
+					"path", r.URL.Path,
+					"request_id", middleware.GetReqID(r.Context()),
+					"error", err,
+				)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="orders"`)
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.NewErrorResponse("unauthorized", http.StatusUnauthorized, "A valid API key or bearer token is required"))
+				return
+			}
+			ctx := domain.WithActor(auth.WithPrincipal(r.Context(), principal), principal.Subject)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func requireRole(role auth.Role) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok || !principal.Role.Allows(role) {
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, response.NewErrorResponse("forbidden", http.StatusForbidden, "The "+string(role)+" role is required"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// websocketCredentials lets browsers authenticate WebSocket handshakes, which
+// cannot carry custom headers: the api_key and access_token query parameters
+// are moved into the headers read by the authenticator.
+func websocketCredentials(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		query := r.URL.Query()
+		key, token := query.Get("api_key"), query.Get("access_token")
+		if key == "" && token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.Clone(r.Context())
+		if key != "" && r.Header.Get(auth.APIKeyHeader) == "" {
+			r.Header.Set(auth.APIKeyHeader, key)
+		}
+		if token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		query.Del("api_key")
+		query.Del("access_token")
+		r.URL.RawQuery = query.Encode()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -5,11 +5,113 @@
 package rest
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/lib/ratelimit"
 	mock "github.com/stretchr/testify/mock"
 )
 
+// NewMockRateLimiter creates a new instance of MockRateLimiter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRateLimiter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRateLimiter {
+	mock := &MockRateLimiter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRateLimiter is an autogenerated mock type for the RateLimiter type
+type MockRateLimiter struct {
+	mock.Mock
+}
+
+type MockRateLimiter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRateLimiter) EXPECT() *MockRateLimiter_Expecter {
+	return &MockRateLimiter_Expecter{mock: &_m.Mock}
+}
+
+// Allow provides a mock function for the type MockRateLimiter
+func (_mock *MockRateLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	ret := _mock.Called(ctx, key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Allow")
+	}
+
+	var r0 ratelimit.Result
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) (ratelimit.Result, error)); ok {
+		return returnFunc(ctx, key, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) ratelimit.Result); ok {
+		r0 = returnFunc(ctx, key, limit)
+	} else {
+		r0 = ret.Get(0).(ratelimit.Result)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, ratelimit.Limit) error); ok {
+		r1 = returnFunc(ctx, key, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRateLimiter_Allow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Allow'
+type MockRateLimiter_Allow_Call struct {
+	*mock.Call
+}
+
+// Allow is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - limit ratelimit.Limit
+func (_e *MockRateLimiter_Expecter) Allow(ctx interface{}, key interface{}, limit interface{}) *MockRateLimiter_Allow_Call {
+	return &MockRateLimiter_Allow_Call{Call: _e.mock.On("Allow", ctx, key, limit)}
+}
+
+func (_c *MockRateLimiter_Allow_Call) Run(run func(ctx context.Context, key string, limit ratelimit.Limit)) *MockRateLimiter_Allow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 ratelimit.Limit
+		if args[2] != nil {
+			arg2 = args[2].(ratelimit.Limit)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRateLimiter_Allow_Call) Return(result ratelimit.Result, err error) *MockRateLimiter_Allow_Call {
+	_c.Call.Return(result, err)
+	return _c
+}
+
+func (_c *MockRateLimiter_Allow_Call) RunAndReturn(run func(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)) *MockRateLimiter_Allow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockHandler creates a new instance of MockHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockHandler(t interface {
@@ -37,12 +139,45 @@ func (_m *MockHandler) EXPECT() *MockHandler_Expecter {
 	return &MockHandler_Expecter{mock: &_m.Mock}
 }
 
-// GetOrder provides a mock function for the type MockHandler
-func (_mock *MockHandler) GetOrder() http.HandlerFunc {
+// CloseStreams provides a mock function for the type MockHandler
+func (_mock *MockHandler) CloseStreams() {
+	_mock.Called()
+	return
+}
+
+// MockHandler_CloseStreams_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CloseStreams'
+type MockHandler_CloseStreams_Call struct {
+	*mock.Call
+}
+
+// CloseStreams is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) CloseStreams() *MockHandler_CloseStreams_Call {
+	return &MockHandler_CloseStreams_Call{Call: _e.mock.On("CloseStreams")}
+}
+
+func (_c *MockHandler_CloseStreams_Call) Run(run func()) *MockHandler_CloseStreams_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_CloseStreams_Call) Return() *MockHandler_CloseStreams_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockHandler_CloseStreams_Call) RunAndReturn(run func()) *MockHandler_CloseStreams_Call {
+	_c.Run(run)
+	return _c
+}
+
+// DailyStats provides a mock function for the type MockHandler
+func (_mock *MockHandler) DailyStats() http.HandlerFunc {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetOrder")
+		panic("no return value specified for DailyStats")
 	}
 
 	var r0 http.HandlerFunc
@@ -56,29 +191,944 @@ func (_mock *MockHandler) GetOrder() http.HandlerFunc {
 	return r0
 }
 
-// MockHandler_GetOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrder'
-type MockHandler_GetOrder_Call struct {
+// MockHandler_DailyStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DailyStats'
+type MockHandler_DailyStats_Call struct {
 	*mock.Call
 }
 
-// GetOrder is a helper method to define mock.On call
-func (_e *MockHandler_Expecter) GetOrder() *MockHandler_GetOrder_Call {
-	return &MockHandler_GetOrder_Call{Call: _e.mock.On("GetOrder")}
+// DailyStats is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) DailyStats() *MockHandler_DailyStats_Call {
+	return &MockHandler_DailyStats_Call{Call: _e.mock.On("DailyStats")}
 }
 
-func (_c *MockHandler_GetOrder_Call) Run(run func()) *MockHandler_GetOrder_Call {
+func (_c *MockHandler_DailyStats_Call) Run(run func()) *MockHandler_DailyStats_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *MockHandler_GetOrder_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_GetOrder_Call {
+func (_c *MockHandler_DailyStats_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_DailyStats_Call {
 	_c.Call.Return(handlerFunc)
 	return _c
 }
 
-func (_c *MockHandler_GetOrder_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_GetOrder_Call {
+func (_c *MockHandler_DailyStats_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_DailyStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteOrder provides a mock function for the type MockHandler
+func (_mock *MockHandler) DeleteOrder() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrder")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_DeleteOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteOrder'
+type MockHandler_DeleteOrder_Call struct {
+	*mock.Call
+}
+
+// DeleteOrder is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) DeleteOrder() *MockHandler_DeleteOrder_Call {
+	return &MockHandler_DeleteOrder_Call{Call: _e.mock.On("DeleteOrder")}
+}
+
+func (_c *MockHandler_DeleteOrder_Call) Run(run func()) *MockHandler_DeleteOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_DeleteOrder_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_DeleteOrder_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_DeleteOrder_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_DeleteOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EraseCustomer provides a mock function for the type MockHandler
+func (_mock *MockHandler) EraseCustomer() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseCustomer")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_EraseCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EraseCustomer'
+type MockHandler_EraseCustomer_Call struct {
+	*mock.Call
+}
+
+// EraseCustomer is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) EraseCustomer() *MockHandler_EraseCustomer_Call {
+	return &MockHandler_EraseCustomer_Call{Call: _e.mock.On("EraseCustomer")}
+}
+
+func (_c *MockHandler_EraseCustomer_Call) Run(run func()) *MockHandler_EraseCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_EraseCustomer_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_EraseCustomer_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_EraseCustomer_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_EraseCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExchangeRates provides a mock function for the type MockHandler
+func (_mock *MockHandler) ExchangeRates() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExchangeRates")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_ExchangeRates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExchangeRates'
+type MockHandler_ExchangeRates_Call struct {
+	*mock.Call
+}
+
+// ExchangeRates is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) ExchangeRates() *MockHandler_ExchangeRates_Call {
+	return &MockHandler_ExchangeRates_Call{Call: _e.mock.On("ExchangeRates")}
+}
+
+func (_c *MockHandler_ExchangeRates_Call) Run(run func()) *MockHandler_ExchangeRates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_ExchangeRates_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_ExchangeRates_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_ExchangeRates_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_ExchangeRates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportCustomer provides a mock function for the type MockHandler
+func (_mock *MockHandler) ExportCustomer() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportCustomer")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_ExportCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportCustomer'
+type MockHandler_ExportCustomer_Call struct {
+	*mock.Call
+}
+
+// ExportCustomer is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) ExportCustomer() *MockHandler_ExportCustomer_Call {
+	return &MockHandler_ExportCustomer_Call{Call: _e.mock.On("ExportCustomer")}
+}
+
+func (_c *MockHandler_ExportCustomer_Call) Run(run func()) *MockHandler_ExportCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_ExportCustomer_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_ExportCustomer_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_ExportCustomer_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_ExportCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCustomer provides a mock function for the type MockHandler
+func (_mock *MockHandler) GetCustomer() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomer")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_GetCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCustomer'
+type MockHandler_GetCustomer_Call struct {
+	*mock.Call
+}
+
+// GetCustomer is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) GetCustomer() *MockHandler_GetCustomer_Call {
+	return &MockHandler_GetCustomer_Call{Call: _e.mock.On("GetCustomer")}
+}
+
+func (_c *MockHandler_GetCustomer_Call) Run(run func()) *MockHandler_GetCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_GetCustomer_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_GetCustomer_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_GetCustomer_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_GetCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrder provides a mock function for the type MockHandler
+func (_mock *MockHandler) GetOrder() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrder")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_GetOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrder'
+type MockHandler_GetOrder_Call struct {
+	*mock.Call
+}
+
+// GetOrder is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) GetOrder() *MockHandler_GetOrder_Call {
+	return &MockHandler_GetOrder_Call{Call: _e.mock.On("GetOrder")}
+}
+
+func (_c *MockHandler_GetOrder_Call) Run(run func()) *MockHandler_GetOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_GetOrder_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_GetOrder_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_GetOrder_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_GetOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderByTransaction provides a mock function for the type MockHandler
+func (_mock *MockHandler) GetOrderByTransaction() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderByTransaction")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_GetOrderByTransaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderByTransaction'
+type MockHandler_GetOrderByTransaction_Call struct {
+	*mock.Call
+}
+
+// GetOrderByTransaction is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) GetOrderByTransaction() *MockHandler_GetOrderByTransaction_Call {
+	return &MockHandler_GetOrderByTransaction_Call{Call: _e.mock.On("GetOrderByTransaction")}
+}
+
+func (_c *MockHandler_GetOrderByTransaction_Call) Run(run func()) *MockHandler_GetOrderByTransaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_GetOrderByTransaction_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_GetOrderByTransaction_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_GetOrderByTransaction_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_GetOrderByTransaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderHistory provides a mock function for the type MockHandler
+func (_mock *MockHandler) GetOrderHistory() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderHistory")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_GetOrderHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderHistory'
+type MockHandler_GetOrderHistory_Call struct {
+	*mock.Call
+}
+
+// GetOrderHistory is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) GetOrderHistory() *MockHandler_GetOrderHistory_Call {
+	return &MockHandler_GetOrderHistory_Call{Call: _e.mock.On("GetOrderHistory")}
+}
+
+func (_c *MockHandler_GetOrderHistory_Call) Run(run func()) *MockHandler_GetOrderHistory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_GetOrderHistory_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_GetOrderHistory_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_GetOrderHistory_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_GetOrderHistory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCustomerOrders provides a mock function for the type MockHandler
+func (_mock *MockHandler) ListCustomerOrders() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCustomerOrders")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_ListCustomerOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCustomerOrders'
+type MockHandler_ListCustomerOrders_Call struct {
+	*mock.Call
+}
+
+// ListCustomerOrders is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) ListCustomerOrders() *MockHandler_ListCustomerOrders_Call {
+	return &MockHandler_ListCustomerOrders_Call{Call: _e.mock.On("ListCustomerOrders")}
+}
+
+func (_c *MockHandler_ListCustomerOrders_Call) Run(run func()) *MockHandler_ListCustomerOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_ListCustomerOrders_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_ListCustomerOrders_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_ListCustomerOrders_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_ListCustomerOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOrders provides a mock function for the type MockHandler
+func (_mock *MockHandler) ListOrders() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_ListOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrders'
+type MockHandler_ListOrders_Call struct {
+	*mock.Call
+}
+
+// ListOrders is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) ListOrders() *MockHandler_ListOrders_Call {
+	return &MockHandler_ListOrders_Call{Call: _e.mock.On("ListOrders")}
+}
+
+func (_c *MockHandler_ListOrders_Call) Run(run func()) *MockHandler_ListOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_ListOrders_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_ListOrders_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_ListOrders_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_ListOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LookupOrders provides a mock function for the type MockHandler
+func (_mock *MockHandler) LookupOrders() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LookupOrders")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_LookupOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LookupOrders'
+type MockHandler_LookupOrders_Call struct {
+	*mock.Call
+}
+
+// LookupOrders is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) LookupOrders() *MockHandler_LookupOrders_Call {
+	return &MockHandler_LookupOrders_Call{Call: _e.mock.On("LookupOrders")}
+}
+
+func (_c *MockHandler_LookupOrders_Call) Run(run func()) *MockHandler_LookupOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_LookupOrders_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_LookupOrders_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_LookupOrders_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_LookupOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreOrder provides a mock function for the type MockHandler
+func (_mock *MockHandler) RestoreOrder() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreOrder")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_RestoreOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreOrder'
+type MockHandler_RestoreOrder_Call struct {
+	*mock.Call
+}
+
+// RestoreOrder is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) RestoreOrder() *MockHandler_RestoreOrder_Call {
+	return &MockHandler_RestoreOrder_Call{Call: _e.mock.On("RestoreOrder")}
+}
+
+func (_c *MockHandler_RestoreOrder_Call) Run(run func()) *MockHandler_RestoreOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_RestoreOrder_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_RestoreOrder_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_RestoreOrder_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_RestoreOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveExchangeRates provides a mock function for the type MockHandler
+func (_mock *MockHandler) SaveExchangeRates() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveExchangeRates")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_SaveExchangeRates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveExchangeRates'
+type MockHandler_SaveExchangeRates_Call struct {
+	*mock.Call
+}
+
+// SaveExchangeRates is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) SaveExchangeRates() *MockHandler_SaveExchangeRates_Call {
+	return &MockHandler_SaveExchangeRates_Call{Call: _e.mock.On("SaveExchangeRates")}
+}
+
+func (_c *MockHandler_SaveExchangeRates_Call) Run(run func()) *MockHandler_SaveExchangeRates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_SaveExchangeRates_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_SaveExchangeRates_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_SaveExchangeRates_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_SaveExchangeRates_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchOrders provides a mock function for the type MockHandler
+func (_mock *MockHandler) SearchOrders() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchOrders")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_SearchOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchOrders'
+type MockHandler_SearchOrders_Call struct {
+	*mock.Call
+}
+
+// SearchOrders is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) SearchOrders() *MockHandler_SearchOrders_Call {
+	return &MockHandler_SearchOrders_Call{Call: _e.mock.On("SearchOrders")}
+}
+
+func (_c *MockHandler_SearchOrders_Call) Run(run func()) *MockHandler_SearchOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_SearchOrders_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_SearchOrders_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_SearchOrders_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_SearchOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StreamOrders provides a mock function for the type MockHandler
+func (_mock *MockHandler) StreamOrders() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamOrders")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_StreamOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StreamOrders'
+type MockHandler_StreamOrders_Call struct {
+	*mock.Call
+}
+
+// StreamOrders is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) StreamOrders() *MockHandler_StreamOrders_Call {
+	return &MockHandler_StreamOrders_Call{Call: _e.mock.On("StreamOrders")}
+}
+
+func (_c *MockHandler_StreamOrders_Call) Run(run func()) *MockHandler_StreamOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_StreamOrders_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_StreamOrders_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_StreamOrders_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_StreamOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TopBrands provides a mock function for the type MockHandler
+func (_mock *MockHandler) TopBrands() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopBrands")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_TopBrands_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TopBrands'
+type MockHandler_TopBrands_Call struct {
+	*mock.Call
+}
+
+// TopBrands is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) TopBrands() *MockHandler_TopBrands_Call {
+	return &MockHandler_TopBrands_Call{Call: _e.mock.On("TopBrands")}
+}
+
+func (_c *MockHandler_TopBrands_Call) Run(run func()) *MockHandler_TopBrands_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_TopBrands_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_TopBrands_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_TopBrands_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_TopBrands_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TrackOrders provides a mock function for the type MockHandler
+func (_mock *MockHandler) TrackOrders() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrackOrders")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_TrackOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TrackOrders'
+type MockHandler_TrackOrders_Call struct {
+	*mock.Call
+}
+
+// TrackOrders is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) TrackOrders() *MockHandler_TrackOrders_Call {
+	return &MockHandler_TrackOrders_Call{Call: _e.mock.On("TrackOrders")}
+}
+
+func (_c *MockHandler_TrackOrders_Call) Run(run func()) *MockHandler_TrackOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_TrackOrders_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_TrackOrders_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_TrackOrders_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_TrackOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateOrder provides a mock function for the type MockHandler
+func (_mock *MockHandler) UpdateOrder() http.HandlerFunc {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateOrder")
+	}
+
+	var r0 http.HandlerFunc
+	if returnFunc, ok := ret.Get(0).(func() http.HandlerFunc); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(http.HandlerFunc)
+		}
+	}
+	return r0
+}
+
+// MockHandler_UpdateOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateOrder'
+type MockHandler_UpdateOrder_Call struct {
+	*mock.Call
+}
+
+// UpdateOrder is a helper method to define mock.On call
+func (_e *MockHandler_Expecter) UpdateOrder() *MockHandler_UpdateOrder_Call {
+	return &MockHandler_UpdateOrder_Call{Call: _e.mock.On("UpdateOrder")}
+}
+
+func (_c *MockHandler_UpdateOrder_Call) Run(run func()) *MockHandler_UpdateOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockHandler_UpdateOrder_Call) Return(handlerFunc http.HandlerFunc) *MockHandler_UpdateOrder_Call {
+	_c.Call.Return(handlerFunc)
+	return _c
+}
+
+func (_c *MockHandler_UpdateOrder_Call) RunAndReturn(run func() http.HandlerFunc) *MockHandler_UpdateOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockAuthenticator creates a new instance of MockAuthenticator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAuthenticator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAuthenticator {
+	mock := &MockAuthenticator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAuthenticator is an autogenerated mock type for the Authenticator type
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+type MockAuthenticator_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAuthenticator) EXPECT() *MockAuthenticator_Expecter {
+	return &MockAuthenticator_Expecter{mock: &_m.Mock}
+}
+
+// Authenticate provides a mock function for the type MockAuthenticator
+func (_mock *MockAuthenticator) Authenticate(r *http.Request) (auth.Principal, error) {
+	ret := _mock.Called(r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 auth.Principal
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(*http.Request) (auth.Principal, error)); ok {
+		return returnFunc(r)
+	}
+	if returnFunc, ok := ret.Get(0).(func(*http.Request) auth.Principal); ok {
+		r0 = returnFunc(r)
+	} else {
+		r0 = ret.Get(0).(auth.Principal)
+	}
+	if returnFunc, ok := ret.Get(1).(func(*http.Request) error); ok {
+		r1 = returnFunc(r)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthenticator_Authenticate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Authenticate'
+type MockAuthenticator_Authenticate_Call struct {
+	*mock.Call
+}
+
+// Authenticate is a helper method to define mock.On call
+//   - r *http.Request
+func (_e *MockAuthenticator_Expecter) Authenticate(r interface{}) *MockAuthenticator_Authenticate_Call {
+	return &MockAuthenticator_Authenticate_Call{Call: _e.mock.On("Authenticate", r)}
+}
+
+func (_c *MockAuthenticator_Authenticate_Call) Run(run func(r *http.Request)) *MockAuthenticator_Authenticate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 *http.Request
+		if args[0] != nil {
+			arg0 = args[0].(*http.Request)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuthenticator_Authenticate_Call) Return(principal auth.Principal, err error) *MockAuthenticator_Authenticate_Call {
+	_c.Call.Return(principal, err)
+	return _c
+}
+
+func (_c *MockAuthenticator_Authenticate_Call) RunAndReturn(run func(r *http.Request) (auth.Principal, error)) *MockAuthenticator_Authenticate_Call {
 	_c.Call.Return(run)
 	return _c
 }
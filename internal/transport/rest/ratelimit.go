@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"context"
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/lib/api/response"
+	"github.com/Killazius/L0/internal/lib/ratelimit"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+}
+
+// ipRoute names the bucket every client IP has across all routes.
+const ipRoute = "client_ip"
+
+// rateLimit limits requests to the named route per client. It must run after
+// authMiddleware: authenticated callers are keyed by their principal, so every
+// API key or token subject gets its own bucket, anonymous ones by client IP.
+// Limiter errors let the request through, the limiter must not take the API down.
+func rateLimit(l RateLimiter, cfg config.RateLimitConfig, route string, log *zap.SugaredLogger) func(next http.Handler) http.Handler {
+	return limitBy(l, cfg, route, clientKey, log)
+}
+
+// ipRateLimit limits requests per client IP. It runs before authMiddleware, so
+// requests rejected with 401 or 403 still use up the bucket and credentials
+// cannot be guessed at the full rate of the route limits.
+func ipRateLimit(l RateLimiter, cfg config.RateLimitConfig, log *zap.SugaredLogger) func(next http.Handler) http.Handler {
+	return limitBy(l, cfg, ipRoute, ipKey, log)
+}
+
+func limitBy(l RateLimiter, cfg config.RateLimitConfig, route string, key func(*http.Request) string, log *zap.SugaredLogger) func(next http.Handler) http.Handler {
+	if l == nil || !cfg.Enabled {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	routeLimit := cfg.Route(route)
+	limit := ratelimit.Limit{Rate: routeLimit.Rate, Burst: routeLimit.Burst}
+	policy := strconv.Itoa(limit.Burst) + ";w=" + strconv.Itoa(int(math.Ceil(limit.Window().Seconds())))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := key(r)
+			res, err := l.Allow(r.Context(), route+":"+client, limit)
+			if err != nil {
+				log.Warnw("rate limiter failed, request allowed",
+					"route", route,
+					"request_id", middleware.GetReqID(r.Context()),
+					"error", err,
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("RateLimit-Policy", policy)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", seconds(res.Reset))
+			if !res.Allowed {
+				log.Infow("rate limit exceeded",
+					"route", route,
+					"client", client,
+					"request_id", middleware.GetReqID(r.Context()),
+				)
+				w.Header().Set("Retry-After", seconds(res.RetryAfter))
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, response.NewErrorResponse("too many requests", http.StatusTooManyRequests, "Rate limit exceeded, retry after "+seconds(res.RetryAfter)+"s"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientKey(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok && p.Method != auth.MethodAnonymous && p.Subject != "" {
+		return p.Method + ":" + p.Subject
+	}
+	return ipKey(r)
+}
+
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds rounds up, so clients never retry too early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
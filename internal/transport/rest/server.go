@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Killazius/L0/internal/auth"
 	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/transport/gql"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -19,37 +21,119 @@ type Server struct {
 
 type Handler interface {
 	GetOrder() http.HandlerFunc
+	UpdateOrder() http.HandlerFunc
+	GetOrderHistory() http.HandlerFunc
+	DeleteOrder() http.HandlerFunc
+	RestoreOrder() http.HandlerFunc
+	LookupOrders() http.HandlerFunc
+	ListOrders() http.HandlerFunc
+	GetOrderByTransaction() http.HandlerFunc
+	SearchOrders() http.HandlerFunc
+	ExportCustomer() http.HandlerFunc
+	EraseCustomer() http.HandlerFunc
+	GetCustomer() http.HandlerFunc
+	ListCustomerOrders() http.HandlerFunc
+	DailyStats() http.HandlerFunc
+	TopBrands() http.HandlerFunc
+	ExchangeRates() http.HandlerFunc
+	SaveExchangeRates() http.HandlerFunc
+	StreamOrders() http.HandlerFunc
+	TrackOrders() http.HandlerFunc
+	CloseStreams()
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (auth.Principal, error)
 }
 
 func NewServer(
 	log *zap.SugaredLogger,
 	handler Handler,
+	authenticator Authenticator,
+	limiter RateLimiter,
+	graphQL http.Handler,
 	cfg config.HTTPConfig,
 ) *Server {
 
+	server := &http.Server{
+		Addr:         cfg.GetAddr(),
+		ReadTimeout:  cfg.Timeout,
+		WriteTimeout: cfg.Timeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		Handler:      registerRoutes(handler, authenticator, limiter, graphQL, log, cfg),
+	}
+	server.RegisterOnShutdown(handler.CloseStreams)
 	return &Server{
-		log: log,
-		server: &http.Server{
-			Addr:         cfg.GetAddr(),
-			ReadTimeout:  cfg.Timeout,
-			WriteTimeout: cfg.Timeout,
-			IdleTimeout:  cfg.IdleTimeout,
-			Handler:      registerRoutes(handler, log, cfg.Port),
-		},
+		log:    log,
+		server: server,
 	}
 }
 
-func registerRoutes(h Handler, log *zap.SugaredLogger, port string) *chi.Mux {
+// registerRoutes mounts the GraphQL endpoint only when graphQL is not nil.
+// Every API route is limited per client IP before authentication and per
+// principal and route after it.
+func registerRoutes(h Handler, a Authenticator, l RateLimiter, graphQL http.Handler, log *zap.SugaredLogger, cfg config.HTTPConfig) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.URLFormat)
 	r.Use(logMiddleware(log))
 	r.Use(middleware.Recoverer)
 
+	perIP := ipRateLimit(l, cfg.RateLimit, log)
 	r.Route("/order", func(r chi.Router) {
-		r.Get("/{order_uid}", h.GetOrder())
+		r.Use(perIP)
+		r.Use(authMiddleware(a, log))
+		r.With(requireRole(auth.RoleViewer), rateLimit(l, cfg.RateLimit, "get_order", log)).Get("/{order_uid}", h.GetOrder())
+		r.With(requireRole(auth.RoleAdmin), rateLimit(l, cfg.RateLimit, "update_order", log)).Put("/{order_uid}", h.UpdateOrder())
+		r.With(requireRole(auth.RoleSupport), rateLimit(l, cfg.RateLimit, "order_history", log)).Get("/{order_uid}/history", h.GetOrderHistory())
+		r.With(requireRole(auth.RoleAdmin), rateLimit(l, cfg.RateLimit, "delete_order", log)).Delete("/{order_uid}", h.DeleteOrder())
+		r.With(requireRole(auth.RoleAdmin), rateLimit(l, cfg.RateLimit, "restore_order", log)).Post("/{order_uid}:restore", h.RestoreOrder())
+	})
+	r.Route("/orders", func(r chi.Router) {
+		r.Use(perIP)
+		r.Use(websocketCredentials)
+		r.Use(authMiddleware(a, log))
+		r.Use(requireRole(auth.RoleViewer))
+		r.With(rateLimit(l, cfg.RateLimit, "stream_orders", log)).Get("/stream", h.StreamOrders())
+		r.With(rateLimit(l, cfg.RateLimit, "track_orders", log)).Get("/ws", h.TrackOrders())
+		r.With(rateLimit(l, cfg.RateLimit, "lookup_orders", log)).Post("/lookup", h.LookupOrders())
+		r.With(rateLimit(l, cfg.RateLimit, "list_orders", log)).Get("/", h.ListOrders())
+		r.With(rateLimit(l, cfg.RateLimit, "order_by_transaction", log)).Get("/by-transaction/{transaction}", h.GetOrderByTransaction())
+		// Search matches customer names and addresses, so it is not open to viewers.
+		r.With(requireRole(auth.RoleSupport), rateLimit(l, cfg.RateLimit, "search_orders", log)).Get("/search", h.SearchOrders())
 	})
-	swaggerURL := fmt.Sprintf("http://localhost:%s/swagger/doc.json", port)
+	// Customers hold contact details and addresses, so they are not open to viewers.
+	r.Route("/customers", func(r chi.Router) {
+		r.Use(perIP)
+		r.Use(authMiddleware(a, log))
+		r.Use(requireRole(auth.RoleSupport))
+		r.With(rateLimit(l, cfg.RateLimit, "get_customer", log)).Get("/{customer_id}", h.GetCustomer())
+		r.With(rateLimit(l, cfg.RateLimit, "list_customer_orders", log)).Get("/{customer_id}/orders", h.ListCustomerOrders())
+	})
+	r.Route("/stats", func(r chi.Router) {
+		r.Use(perIP)
+		r.Use(authMiddleware(a, log))
+		r.Use(requireRole(auth.RoleViewer))
+		r.With(rateLimit(l, cfg.RateLimit, "daily_stats", log)).Get("/daily", h.DailyStats())
+		r.With(rateLimit(l, cfg.RateLimit, "top_brands", log)).Get("/brands", h.TopBrands())
+	})
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(perIP)
+		r.Use(authMiddleware(a, log))
+		r.Use(requireRole(auth.RoleAdmin))
+		r.With(rateLimit(l, cfg.RateLimit, "export_customer", log)).Get("/customers/{customer_id}/export", h.ExportCustomer())
+		r.With(rateLimit(l, cfg.RateLimit, "erase_customer", log)).Post("/customers/{customer_id}/erase", h.EraseCustomer())
+		r.With(rateLimit(l, cfg.RateLimit, "exchange_rates", log)).Get("/exchange-rates", h.ExchangeRates())
+		r.With(rateLimit(l, cfg.RateLimit, "save_exchange_rates", log)).Put("/exchange-rates", h.SaveExchangeRates())
+	})
+	if graphQL != nil {
+		r.With(perIP, authMiddleware(a, log), requireRole(auth.RoleViewer), rateLimit(l, cfg.RateLimit, "graphql", log)).
+			Post("/graphql", graphQL.ServeHTTP)
+		if cfg.GraphQL.GraphiQL {
+			r.Get("/graphiql", gql.GraphiQL())
+		}
+	}
+	swaggerURL := fmt.Sprintf("http://localhost:%s/swagger/doc.json", cfg.Port)
 	r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL(swaggerURL)))
 
 	r.Handle("/*", http.FileServer(http.Dir("./static")))
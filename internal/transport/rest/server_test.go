@@ -2,18 +2,49 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/Killazius/L0/internal/auth"
 	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/ratelimit"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
+// expectRoutes registers the handlers that are not under test.
+func expectRoutes(m *MockHandler) {
+	notImplemented := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	})
+	m.On("UpdateOrder").Return(notImplemented).Once()
+	m.On("GetOrderHistory").Return(notImplemented).Once()
+	m.On("DeleteOrder").Return(notImplemented).Once()
+	m.On("RestoreOrder").Return(notImplemented).Once()
+	m.On("ExportCustomer").Return(notImplemented).Once()
+	m.On("EraseCustomer").Return(notImplemented).Once()
+	m.On("ExchangeRates").Return(notImplemented).Once()
+	m.On("SaveExchangeRates").Return(notImplemented).Once()
+	m.On("GetCustomer").Return(notImplemented).Once()
+	m.On("ListCustomerOrders").Return(notImplemented).Once()
+	m.On("DailyStats").Return(notImplemented).Once()
+	m.On("TopBrands").Return(notImplemented).Once()
+	m.On("StreamOrders").Return(notImplemented).Once()
+	m.On("TrackOrders").Return(notImplemented).Once()
+	m.On("LookupOrders").Return(notImplemented).Once()
+	m.On("ListOrders").Return(notImplemented).Once()
+	m.On("GetOrderByTransaction").Return(notImplemented).Once()
+	m.On("SearchOrders").Return(notImplemented).Once()
+	m.On("CloseStreams").Return().Maybe()
+}
+
 func TestNewServer(t *testing.T) {
 	t.Parallel()
 
@@ -24,6 +55,7 @@ func TestNewServer(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
 
 	cfg := config.HTTPConfig{
 		Host:        "localhost",
@@ -32,7 +64,7 @@ func TestNewServer(t *testing.T) {
 		IdleTimeout: 60 * time.Second,
 	}
 
-	server := NewServer(logger, mockHandler, cfg)
+	server := NewServer(logger, mockHandler, NewMockAuthenticator(t), NewMockRateLimiter(t), nil, cfg)
 
 	assert.NotNil(t, server)
 	assert.Equal(t, "localhost:8080", server.Addr())
@@ -50,13 +82,14 @@ func TestServer_Addr(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
 
 	cfg := config.HTTPConfig{
 		Host: "127.0.0.1",
 		Port: "9090",
 	}
 
-	server := NewServer(logger, mockHandler, cfg)
+	server := NewServer(logger, mockHandler, NewMockAuthenticator(t), NewMockRateLimiter(t), nil, cfg)
 
 	assert.Equal(t, "127.0.0.1:9090", server.Addr())
 	mockHandler.AssertExpectations(t)
@@ -72,8 +105,14 @@ func TestRegisterRoutes(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
+
+	mockAuth := NewMockAuthenticator(t)
+	mockAuth.On("Authenticate", mock.Anything).
+		Return(auth.Principal{Subject: "test", Role: auth.RoleViewer}, nil).
+		Once()
 
-	router := registerRoutes(mockHandler, logger, "8080")
+	router := registerRoutes(mockHandler, mockAuth, NewMockRateLimiter(t), nil, logger, config.HTTPConfig{Port: "8080"})
 
 	tests := []struct {
 		name     string
@@ -157,13 +196,14 @@ func TestServer_Close(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
 
 	cfg := config.HTTPConfig{
 		Host: "localhost",
 		Port: "0",
 	}
 
-	server := NewServer(logger, mockHandler, cfg)
+	server := NewServer(logger, mockHandler, NewMockAuthenticator(t), NewMockRateLimiter(t), nil, cfg)
 
 	go func() {
 		err := server.Run()
@@ -192,13 +232,14 @@ func TestServer_Run_Error(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
 
 	cfg := config.HTTPConfig{
 		Host: "localhost",
 		Port: "abc",
 	}
 
-	server := NewServer(logger, mockHandler, cfg)
+	server := NewServer(logger, mockHandler, NewMockAuthenticator(t), NewMockRateLimiter(t), nil, cfg)
 
 	err := server.Run()
 	require.Error(t, err)
@@ -215,13 +256,14 @@ func TestServer_MustRun(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
 
 	cfg := config.HTTPConfig{
 		Host: "localhost",
 		Port: "0",
 	}
 
-	server := NewServer(logger, mockHandler, cfg)
+	server := NewServer(logger, mockHandler, NewMockAuthenticator(t), NewMockRateLimiter(t), nil, cfg)
 
 	assert.NotPanics(t, func() {
 		go server.MustRun()
@@ -245,8 +287,14 @@ func TestRegisterRoutes_OrderRoute(t *testing.T) {
 		}
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
 
-	router := registerRoutes(mockHandler, logger, "8080")
+	mockAuth := NewMockAuthenticator(t)
+	mockAuth.On("Authenticate", mock.Anything).
+		Return(auth.Principal{Subject: "test", Role: auth.RoleViewer}, nil).
+		Once()
+
+	router := registerRoutes(mockHandler, mockAuth, NewMockRateLimiter(t), nil, logger, config.HTTPConfig{Port: "8080"})
 
 	req, err := http.NewRequest("GET", "/order/12345", nil)
 	require.NoError(t, err)
@@ -264,6 +312,189 @@ func TestRegisterRoutes_OrderRoute(t *testing.T) {
 	mockHandler.AssertExpectations(t)
 }
 
+func TestRegisterRoutes_SecondaryLookups(t *testing.T) {
+	t.Parallel()
+
+	mockHandler := NewMockHandler(t)
+	mockHandler.On("GetOrder").Return(http.HandlerFunc(nil)).Once()
+	expectRoutes(mockHandler)
+
+	mockAuth := NewMockAuthenticator(t)
+	mockAuth.On("Authenticate", mock.Anything).
+		Return(auth.Principal{Subject: "test", Role: auth.RoleViewer}, nil)
+
+	router := registerRoutes(mockHandler, mockAuth, NewMockRateLimiter(t), nil, zap.NewNop().Sugar(), config.HTTPConfig{Port: "8080"})
+
+	for _, path := range []string{"/orders?track_number=WBILMTESTTRACK", "/orders?nm_id=2389212", "/orders/by-transaction/b563feb7b2b84b6test"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusNotImplemented, rr.Code, path)
+	}
+}
+
+func TestRegisterRoutes_SearchRequiresSupport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		role     auth.Role
+		expected int
+	}{
+		{role: auth.RoleViewer, expected: http.StatusForbidden},
+		{role: auth.RoleSupport, expected: http.StatusNotImplemented},
+	}
+	for _, tt := range tests {
+		mockHandler := NewMockHandler(t)
+		mockHandler.On("GetOrder").Return(http.HandlerFunc(nil)).Once()
+		expectRoutes(mockHandler)
+		mockAuth := NewMockAuthenticator(t)
+		mockAuth.On("Authenticate", mock.Anything).
+			Return(auth.Principal{Subject: "test", Role: tt.role}, nil).
+			Once()
+
+		router := registerRoutes(mockHandler, mockAuth, NewMockRateLimiter(t), nil, zap.NewNop().Sugar(), config.HTTPConfig{Port: "8080"})
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/orders/search?q=mascaras", nil))
+		assert.Equal(t, tt.expected, rr.Code, tt.role)
+	}
+}
+
+func TestRegisterRoutes_UpdateRequiresAdmin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		role     auth.Role
+		expected int
+	}{
+		{role: auth.RoleSupport, expected: http.StatusForbidden},
+		{role: auth.RoleAdmin, expected: http.StatusNotImplemented},
+	}
+	for _, tt := range tests {
+		mockHandler := NewMockHandler(t)
+		mockHandler.On("GetOrder").Return(http.HandlerFunc(nil)).Once()
+		expectRoutes(mockHandler)
+		mockAuth := NewMockAuthenticator(t)
+		mockAuth.On("Authenticate", mock.Anything).
+			Return(auth.Principal{Subject: "test", Role: tt.role}, nil).
+			Once()
+
+		router := registerRoutes(mockHandler, mockAuth, NewMockRateLimiter(t), nil, zap.NewNop().Sugar(), config.HTTPConfig{Port: "8080"})
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("PUT", "/order/b563feb7b2b84b6test", nil))
+		assert.Equal(t, tt.expected, rr.Code, tt.role)
+	}
+}
+
+func TestRegisterRoutes_DeleteAndRestore(t *testing.T) {
+	t.Parallel()
+
+	mockHandler := NewMockHandler(t)
+	mockHandler.On("GetOrder").Return(http.HandlerFunc(nil)).Once()
+	expectRoutes(mockHandler)
+	router := registerRoutes(mockHandler, NewMockAuthenticator(t), NewMockRateLimiter(t), nil, zap.NewNop().Sugar(), config.HTTPConfig{Port: "8080"})
+
+	for _, route := range []struct{ method, path string }{
+		{method: "GET", path: "/order/b563feb7b2b84b6test"},
+		{method: "DELETE", path: "/order/b563feb7b2b84b6test"},
+		{method: "POST", path: "/order/b563feb7b2b84b6test:restore"},
+	} {
+		rctx := chi.NewRouteContext()
+		require.True(t, router.Match(rctx, route.method, route.path), route.path)
+		assert.Equal(t, "b563feb7b2b84b6test", rctx.URLParam("order_uid"), route.path)
+	}
+}
+
+func TestRegisterRoutes_RestoreRateLimit(t *testing.T) {
+	t.Parallel()
+
+	mockHandler := NewMockHandler(t)
+	mockHandler.On("GetOrder").Return(http.HandlerFunc(nil)).Once()
+	expectRoutes(mockHandler)
+	mockAuth := NewMockAuthenticator(t)
+	mockAuth.On("Authenticate", mock.Anything).
+		Return(auth.Principal{Subject: "test", Role: auth.RoleAdmin, Method: auth.MethodAPIKey}, nil).
+		Once()
+	// Restores have their own bucket, so deletes cannot use it up.
+	limiter := NewMockRateLimiter(t)
+	limiter.On("Allow", mock.Anything, "client_ip:ip:192.0.2.1", ratelimit.Limit{Rate: 20, Burst: 40}).
+		Return(ratelimit.Result{Allowed: true, Limit: 40, Remaining: 39}, nil).
+		Once()
+	limiter.On("Allow", mock.Anything, "restore_order:api_key:test", ratelimit.Limit{Rate: 1, Burst: 10}).
+		Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9}, nil).
+		Once()
+	cfg := config.HTTPConfig{Port: "8080", RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Rate:    20,
+		Burst:   40,
+		Routes:  map[string]config.RouteLimit{"restore_order": {Rate: 1, Burst: 10}},
+	}}
+
+	router := registerRoutes(mockHandler, mockAuth, limiter, nil, zap.NewNop().Sugar(), cfg)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/order/b563feb7b2b84b6test:restore", nil))
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
+
+func TestRegisterRoutes_IPRateLimitBeforeAuth(t *testing.T) {
+	t.Parallel()
+
+	mockHandler := NewMockHandler(t)
+	mockHandler.On("GetOrder").Return(http.HandlerFunc(nil)).Once()
+	expectRoutes(mockHandler)
+	// The IP bucket is checked first, so guessed credentials are never looked at.
+	limiter := NewMockRateLimiter(t)
+	limiter.On("Allow", mock.Anything, "client_ip:ip:192.0.2.1", ratelimit.Limit{Rate: 5, Burst: 10}).
+		Return(ratelimit.Result{Allowed: false, Limit: 10, RetryAfter: time.Second}, nil).
+		Once()
+	cfg := config.HTTPConfig{Port: "8080", RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Rate:    20,
+		Burst:   40,
+		Routes:  map[string]config.RouteLimit{"client_ip": {Rate: 5, Burst: 10}},
+	}}
+
+	router := registerRoutes(mockHandler, NewMockAuthenticator(t), limiter, nil, zap.NewNop().Sugar(), cfg)
+	req := httptest.NewRequest("GET", "/order/b563feb7b2b84b6test", nil)
+	req.Header.Set("X-API-Key", "guess")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+}
+
+func TestRegisterRoutes_TransactionRateLimit(t *testing.T) {
+	t.Parallel()
+
+	mockHandler := NewMockHandler(t)
+	mockHandler.On("GetOrder").Return(http.HandlerFunc(nil)).Once()
+	expectRoutes(mockHandler)
+	mockAuth := NewMockAuthenticator(t)
+	mockAuth.On("Authenticate", mock.Anything).
+		Return(auth.Principal{Subject: "test", Role: auth.RoleViewer, Method: auth.MethodAPIKey}, nil).
+		Once()
+	// Transaction lookups have their own bucket, apart from get_order.
+	limiter := NewMockRateLimiter(t)
+	limiter.On("Allow", mock.Anything, "client_ip:ip:192.0.2.1", ratelimit.Limit{Rate: 20, Burst: 40}).
+		Return(ratelimit.Result{Allowed: true, Limit: 40, Remaining: 39}, nil).
+		Once()
+	limiter.On("Allow", mock.Anything, "order_by_transaction:api_key:test", ratelimit.Limit{Rate: 2, Burst: 4}).
+		Return(ratelimit.Result{Allowed: true, Limit: 4, Remaining: 3}, nil).
+		Once()
+	cfg := config.HTTPConfig{Port: "8080", RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Rate:    20,
+		Burst:   40,
+		Routes: map[string]config.RouteLimit{
+			"get_order":            {Rate: 50, Burst: 100},
+			"order_by_transaction": {Rate: 2, Burst: 4},
+		},
+	}}
+
+	router := registerRoutes(mockHandler, mockAuth, limiter, nil, zap.NewNop().Sugar(), cfg)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/orders/by-transaction/b563feb7b2b84b6test", nil))
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
+
 func TestServer_Close_WithTimeout(t *testing.T) {
 	t.Parallel()
 
@@ -274,13 +505,14 @@ func TestServer_Close_WithTimeout(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockHandler.On("GetOrder").Return(handlerFunc).Once()
+	expectRoutes(mockHandler)
 
 	cfg := config.HTTPConfig{
 		Host: "localhost",
 		Port: "0",
 	}
 
-	server := NewServer(logger, mockHandler, cfg)
+	server := NewServer(logger, mockHandler, NewMockAuthenticator(t), NewMockRateLimiter(t), nil, cfg)
 
 	listenErr := make(chan error, 1)
 	go func() {
@@ -305,3 +537,205 @@ func TestServer_Close_WithTimeout(t *testing.T) {
 
 	mockHandler.AssertExpectations(t)
 }
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		principal      auth.Principal
+		authErr        error
+		required       auth.Role
+		expectedStatus int
+	}{
+		{
+			name:           "viewer allowed",
+			principal:      auth.Principal{Subject: "dashboard", Role: auth.RoleViewer},
+			required:       auth.RoleViewer,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "admin allowed on viewer route",
+			principal:      auth.Principal{Subject: "ops", Role: auth.RoleAdmin},
+			required:       auth.RoleViewer,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "viewer forbidden on admin route",
+			principal:      auth.Principal{Subject: "dashboard", Role: auth.RoleViewer},
+			required:       auth.RoleAdmin,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unauthenticated",
+			authErr:        auth.ErrUnauthenticated,
+			required:       auth.RoleViewer,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockAuth := NewMockAuthenticator(t)
+			mockAuth.On("Authenticate", mock.Anything).
+				Return(tt.principal, tt.authErr).
+				Once()
+
+			var (
+				seen  auth.Principal
+				actor string
+			)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen, _ = auth.FromContext(r.Context())
+				actor = domain.ActorFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := authMiddleware(mockAuth, zap.NewNop().Sugar())(requireRole(tt.required)(next))
+
+			req, err := http.NewRequest("GET", "/order/test", nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.principal, seen)
+				assert.Equal(t, tt.principal.Subject, actor)
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.RateLimitConfig{
+		Enabled: true,
+		Rate:    1,
+		Burst:   10,
+		Routes:  map[string]config.RouteLimit{"get_order": {Rate: 2, Burst: 4}},
+	}
+	limit := ratelimit.Limit{Rate: 2, Burst: 4}
+
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		setupMock      func(*MockRateLimiter)
+		expectedStatus int
+		expectedRetry  string
+	}{
+		{
+			name:      "allowed api key client",
+			principal: &auth.Principal{Subject: "dashboard", Role: auth.RoleViewer, Method: auth.MethodAPIKey},
+			setupMock: func(l *MockRateLimiter) {
+				l.On("Allow", mock.Anything, "get_order:api_key:dashboard", limit).
+					Return(ratelimit.Result{Allowed: true, Limit: 4, Remaining: 3, Reset: 500 * time.Millisecond}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "anonymous client keyed by ip",
+			principal: &auth.Principal{Role: auth.RoleAdmin, Method: auth.MethodAnonymous},
+			setupMock: func(l *MockRateLimiter) {
+				l.On("Allow", mock.Anything, "get_order:ip:192.0.2.1", limit).
+					Return(ratelimit.Result{Allowed: true, Limit: 4, Remaining: 3, Reset: 500 * time.Millisecond}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "limit exceeded",
+			principal: &auth.Principal{Subject: "dashboard", Role: auth.RoleViewer, Method: auth.MethodAPIKey},
+			setupMock: func(l *MockRateLimiter) {
+				l.On("Allow", mock.Anything, "get_order:api_key:dashboard", limit).
+					Return(ratelimit.Result{Allowed: false, Limit: 4, Remaining: 0, Reset: 2 * time.Second, RetryAfter: 300 * time.Millisecond}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedRetry:  "1",
+		},
+		{
+			name: "limiter error fails open",
+			setupMock: func(l *MockRateLimiter) {
+				l.On("Allow", mock.Anything, "get_order:ip:192.0.2.1", limit).
+					Return(ratelimit.Result{}, errors.New("redis down")).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := NewMockRateLimiter(t)
+			tt.setupMock(limiter)
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := rateLimit(limiter, cfg, "get_order", zap.NewNop().Sugar())(next)
+
+			req := httptest.NewRequest("GET", "/order/test", nil)
+			req.RemoteAddr = "192.0.2.1:4321"
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), *tt.principal))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedRetry, rr.Header().Get("Retry-After"))
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+				assert.Equal(t, "2", rr.Header().Get("RateLimit-Reset"))
+				assert.Equal(t, "4;w=2", rr.Header().Get("RateLimit-Policy"))
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := rateLimit(NewMockRateLimiter(t), config.RateLimitConfig{Enabled: false}, "get_order", zap.NewNop().Sugar())(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/order/test", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+}
+
+func TestWebsocketCredentials(t *testing.T) {
+	t.Parallel()
+
+	var got *http.Request
+	handler := websocketCredentials(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+
+	req := httptest.NewRequest("GET", "/orders/ws?api_key=secret&access_token=jwt&x=1", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.NotNil(t, got)
+	assert.Equal(t, "secret", got.Header.Get(auth.APIKeyHeader))
+	assert.Equal(t, "Bearer jwt", got.Header.Get("Authorization"))
+	assert.Equal(t, "x=1", got.URL.RawQuery)
+
+	plain := httptest.NewRequest("GET", "/orders/stream?api_key=secret", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), plain)
+	assert.Empty(t, got.Header.Get(auth.APIKeyHeader), "only websocket handshakes take credentials from the query")
+}
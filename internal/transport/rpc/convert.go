@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"fmt"
+	orderv1 "github.com/Killazius/L0/api/order/v1"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func toProtoOrder(o *domain.Order) *orderv1.Order {
+	items := make([]*orderv1.Item, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, &orderv1.Item{
+			ChrtId:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       item.Price.String(),
+			Rid:         item.Rid,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  item.TotalPrice.String(),
+			NmId:        int64(item.NmID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		})
+	}
+	return &orderv1.Order{
+		OrderUid:    o.OrderUID,
+		TrackNumber: o.TrackNumber,
+		Entry:       o.Entry,
+		Delivery: &orderv1.Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+		},
+		Payment: &orderv1.Payment{
+			Transaction:  o.Payment.Transaction,
+			RequestId:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       o.Payment.Amount.String(),
+			PaymentDt:    o.Payment.PaymentDt,
+			Bank:         o.Payment.Bank,
+			DeliveryCost: o.Payment.DeliveryCost.String(),
+			GoodsTotal:   int64(o.Payment.GoodsTotal),
+			CustomFee:    int64(o.Payment.CustomFee),
+		},
+		Items:             items,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.ShardKey,
+		SmId:              int64(o.SmID),
+		DateCreated:       toProtoTime(o.DateCreated),
+		OofShard:          o.OofShard,
+		UpdatedAt:         toProtoTime(o.UpdatedAt),
+	}
+}
+
+// fromProtoOrder converts a request order. Only the decimal amounts can fail,
+// everything else is checked by the service validation.
+func fromProtoOrder(o *orderv1.Order) (*domain.Order, error) {
+	payment := o.GetPayment()
+	amount, err := parseDecimal("payment.amount", payment.GetAmount())
+	if err != nil {
+		return nil, err
+	}
+	deliveryCost, err := parseDecimal("payment.delivery_cost", payment.GetDeliveryCost())
+	if err != nil {
+		return nil, err
+	}
+	items := make([]domain.Item, 0, len(o.GetItems()))
+	for i, item := range o.GetItems() {
+		price, err := parseDecimal(fmt.Sprintf("items[%d].price", i), item.GetPrice())
+		if err != nil {
+			return nil, err
+		}
+		totalPrice, err := parseDecimal(fmt.Sprintf("items[%d].total_price", i), item.GetTotalPrice())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, domain.Item{
+			ChrtID:      int(item.GetChrtId()),
+			TrackNumber: item.GetTrackNumber(),
+			Price:       price,
+			Rid:         item.GetRid(),
+			Name:        item.GetName(),
+			Sale:        int(item.GetSale()),
+			Size:        item.GetSize(),
+			TotalPrice:  totalPrice,
+			NmID:        int(item.GetNmId()),
+			Brand:       item.GetBrand(),
+			Status:      int(item.GetStatus()),
+		})
+	}
+	delivery := o.GetDelivery()
+	return &domain.Order{
+		OrderUID:    o.GetOrderUid(),
+		TrackNumber: o.GetTrackNumber(),
+		Entry:       o.GetEntry(),
+		Delivery: domain.Delivery{
+			Name:    delivery.GetName(),
+			Phone:   delivery.GetPhone(),
+			Zip:     delivery.GetZip(),
+			City:    delivery.GetCity(),
+			Address: delivery.GetAddress(),
+			Region:  delivery.GetRegion(),
+			Email:   delivery.GetEmail(),
+		},
+		Payment: domain.Payment{
+			Transaction:  payment.GetTransaction(),
+			RequestID:    payment.GetRequestId(),
+			Currency:     payment.GetCurrency(),
+			Provider:     payment.GetProvider(),
+			Amount:       amount,
+			PaymentDt:    payment.GetPaymentDt(),
+			Bank:         payment.GetBank(),
+			DeliveryCost: deliveryCost,
+			GoodsTotal:   int(payment.GetGoodsTotal()),
+			CustomFee:    int(payment.GetCustomFee()),
+		},
+		Items:             items,
+		Locale:            o.GetLocale(),
+		InternalSignature: o.GetInternalSignature(),
+		CustomerID:        o.GetCustomerId(),
+		DeliveryService:   o.GetDeliveryService(),
+		ShardKey:          o.GetShardkey(),
+		SmID:              int(o.GetSmId()),
+		DateCreated:       fromProtoTime(o.GetDateCreated()),
+		OofShard:          o.GetOofShard(),
+		UpdatedAt:         fromProtoTime(o.GetUpdatedAt()),
+	}, nil
+}
+
+func toProtoEvent(e domain.OrderEvent) *orderv1.OrderEvent {
+	return &orderv1.OrderEvent{
+		Id:    e.ID,
+		Type:  string(e.Type),
+		Time:  toProtoTime(e.Time),
+		Order: toProtoOrder(e.Order),
+	}
+}
+
+func parseDecimal(field, value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%s is not a decimal: %q", field, value)
+	}
+	return d, nil
+}
+
+func toProtoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromProtoTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
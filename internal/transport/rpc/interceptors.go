@@ -0,0 +1,129 @@
+package rpc
+
+import (
+	"context"
+	orderv1 "github.com/Killazius/L0/api/order/v1"
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/domain"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+// methodRoles lists the role required by every order method. Methods missing
+// here, health checks and reflection, do not require authentication.
+var methodRoles = map[string]auth.Role{
+	orderv1.OrderService_GetOrder_FullMethodName:    auth.RoleViewer,
+	orderv1.OrderService_ListOrders_FullMethodName:  auth.RoleViewer,
+	orderv1.OrderService_WatchOrders_FullMethodName: auth.RoleViewer,
+	orderv1.OrderService_CreateOrder_FullMethodName: auth.RoleAdmin,
+}
+
+func unaryLogging(log *zap.SugaredLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		log.Infow("call completed",
+			"method", info.FullMethod,
+			"code", status.Code(err).String(),
+			"duration", time.Since(start).String(),
+		)
+		return resp, err
+	}
+}
+
+func streamLogging(log *zap.SugaredLogger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		log.Infow("stream completed",
+			"method", info.FullMethod,
+			"code", status.Code(err).String(),
+			"duration", time.Since(start).String(),
+		)
+		return err
+	}
+}
+
+func unaryRecovery(log *zap.SugaredLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				log.Errorw("panic in grpc handler", "method", info.FullMethod, "panic", p)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func streamRecovery(log *zap.SugaredLogger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				log.Errorw("panic in grpc handler", "method", info.FullMethod, "panic", p)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func unaryAuth(a Authenticator, log *zap.SugaredLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, a, info.FullMethod, log)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuth(a Authenticator, log *zap.SugaredLogger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), a, info.FullMethod, log)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize reads the credentials from the x-api-key or authorization metadata,
+// the same values the REST API reads from headers.
+func authorize(ctx context.Context, a Authenticator, method string, log *zap.SugaredLogger) (context.Context, error) {
+	role, ok := methodRoles[method]
+	if !ok {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	principal, err := a.AuthenticateCredentials(first(md, strings.ToLower(auth.APIKeyHeader)), first(md, "authorization"))
+	if err != nil {
+		log.Infow("authentication failed", "method", method, "error", err)
+		return nil, status.Error(codes.Unauthenticated, "a valid API key or bearer token is required")
+	}
+	if !principal.Role.Allows(role) {
+		return nil, status.Errorf(codes.PermissionDenied, "the %s role is required", role)
+	}
+	return domain.WithActor(auth.WithPrincipal(ctx, principal), principal.Subject), nil
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
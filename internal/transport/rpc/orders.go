@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	orderv1 "github.com/Killazius/L0/api/order/v1"
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/broadcast"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/Killazius/L0/pkg/mask"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type orderServer struct {
+	orderv1.UnimplementedOrderServiceServer
+	log     *zap.SugaredLogger
+	service OrderService
+	stream  OrderStream
+	streams context.Context
+}
+
+func (s *orderServer) GetOrder(ctx context.Context, req *orderv1.GetOrderRequest) (*orderv1.GetOrderResponse, error) {
+	if req.GetOrderUid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_uid is required")
+	}
+	order, err := s.service.GetOrder(ctx, req.GetOrderUid())
+	if err != nil {
+		return nil, s.toStatus(err, "order_uid", req.GetOrderUid())
+	}
+	return &orderv1.GetOrderResponse{Order: toProtoOrder(orderView(ctx, order))}, nil
+}
+
+func (s *orderServer) ListOrders(ctx context.Context, req *orderv1.ListOrdersRequest) (*orderv1.ListOrdersResponse, error) {
+	filter := domain.OrderFilter{
+		CustomerID:      req.GetCustomerId(),
+		DeliveryService: req.GetDeliveryService(),
+		CreatedFrom:     fromProtoTime(req.GetCreatedFrom()),
+		CreatedTo:       fromProtoTime(req.GetCreatedTo()),
+	}
+	page, err := s.service.ListOrders(ctx, filter, int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	orders := make([]*orderv1.Order, 0, len(page.Orders))
+	for i := range page.Orders {
+		orders = append(orders, toProtoOrder(orderView(ctx, &page.Orders[i])))
+	}
+	return &orderv1.ListOrdersResponse{Orders: orders, NextPageToken: page.NextCursor}, nil
+}
+
+func (s *orderServer) CreateOrder(ctx context.Context, req *orderv1.CreateOrderRequest) (*orderv1.CreateOrderResponse, error) {
+	if req.GetOrder() == nil {
+		return nil, status.Error(codes.InvalidArgument, "order is required")
+	}
+	order, err := fromProtoOrder(req.GetOrder())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.service.CreateOrder(ctx, order); err != nil {
+		return nil, s.toStatus(err, "order_uid", order.OrderUID)
+	}
+	return &orderv1.CreateOrderResponse{Order: toProtoOrder(order)}, nil
+}
+
+func (s *orderServer) WatchOrders(req *orderv1.WatchOrdersRequest, stream grpc.ServerStreamingServer[orderv1.OrderEvent]) error {
+	ctx := stream.Context()
+	filter := broadcast.Filter{
+		CustomerID:      req.GetCustomerId(),
+		DeliveryService: req.GetDeliveryService(),
+	}
+	sub, missed := s.stream.Subscribe(filter, req.GetLastEventId())
+	defer sub.Close()
+
+	send := func(event domain.OrderEvent) error {
+		event.Order = orderView(ctx, event.Order)
+		return stream.Send(toProtoEvent(event))
+	}
+	for _, event := range missed {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.streams.Done():
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Dropped() {
+					s.log.Warnw("order watch dropped, client is too slow", "customer_id", filter.CustomerID)
+					return status.Error(codes.ResourceExhausted, "client is too slow, resume with last_event_id")
+				}
+				return status.Error(codes.Unavailable, "stream closed")
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus maps service errors to status codes, the same way the REST
+// handlers map them to HTTP statuses.
+func (s *orderServer) toStatus(err error, keysAndValues ...any) error {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		return status.Error(codes.NotFound, "order not found")
+	case errors.Is(err, service.ErrOrderAlreadyExists):
+		return status.Error(codes.AlreadyExists, "order already exists")
+	case errors.Is(err, service.ErrInvalidOrderData), errors.Is(err, service.ErrInvalidQuery):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		s.log.Errorw("internal error", append(keysAndValues, "error", err)...)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+// orderView masks customer personal data for callers below the support role.
+func orderView(ctx context.Context, order *domain.Order) *domain.Order {
+	principal, _ := auth.FromContext(ctx)
+	if principal.Role.Allows(auth.RoleSupport) {
+		return order
+	}
+	return mask.Masked(order)
+}
@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	orderv1 "github.com/Killazius/L0/api/order/v1"
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/broadcast"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"net"
+)
+
+type OrderService interface {
+	GetOrder(ctx context.Context, uid string) (*domain.Order, error)
+	ListOrders(ctx context.Context, filter domain.OrderFilter, pageSize int, pageToken string) (*domain.OrderPage, error)
+	CreateOrder(ctx context.Context, order *domain.Order) error
+}
+
+type OrderStream interface {
+	Subscribe(filter broadcast.Filter, lastEventID uint64) (*broadcast.Subscription, []domain.OrderEvent)
+}
+
+type Authenticator interface {
+	AuthenticateCredentials(key, authorization string) (auth.Principal, error)
+}
+
+// Server serves the order.v1.OrderService gRPC API next to the REST API.
+type Server struct {
+	server *grpc.Server
+	health *health.Server
+	log    *zap.SugaredLogger
+	addr   string
+	// streams is canceled on shutdown, WatchOrders streams would otherwise
+	// keep GracefulStop waiting until the clients go away.
+	streams      context.Context
+	closeStreams context.CancelFunc
+}
+
+func NewServer(
+	log *zap.SugaredLogger,
+	service OrderService,
+	stream OrderStream,
+	authenticator Authenticator,
+	cfg config.GRPCConfig,
+) *Server {
+	streams, closeStreams := context.WithCancel(context.Background())
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			unaryLogging(log),
+			unaryRecovery(log),
+			unaryAuth(authenticator, log),
+		),
+		grpc.ChainStreamInterceptor(
+			streamLogging(log),
+			streamRecovery(log),
+			streamAuth(authenticator, log),
+		),
+	)
+	orderv1.RegisterOrderServiceServer(server, &orderServer{
+		log:     log,
+		service: service,
+		stream:  stream,
+		streams: streams,
+	})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(orderv1.OrderService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	if cfg.Reflection {
+		reflection.Register(server)
+	}
+
+	return &Server{
+		server:       server,
+		health:       healthServer,
+		log:          log,
+		addr:         cfg.GetAddr(),
+		streams:      streams,
+		closeStreams: closeStreams,
+	}
+}
+
+func (s *Server) MustRun() {
+	if err := s.Run(); err != nil {
+		s.log.Fatalw("failed to run gRPC server", "error", err)
+	}
+}
+
+func (s *Server) Run() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(lis)
+}
+
+func (s *Server) Serve(lis net.Listener) error {
+	s.log.Infow("grpc server started", "addr", lis.Addr().String())
+	err := s.server.Serve(lis)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// Close reports NOT_SERVING to health checks, ends the order streams and waits
+// for in-flight calls. When ctx is done first the remaining calls are aborted.
+func (s *Server) Close(ctx context.Context) error {
+	s.health.Shutdown()
+	s.closeStreams()
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) Addr() string {
+	return s.addr
+}
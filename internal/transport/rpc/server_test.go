@@ -0,0 +1,206 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	orderv1 "github.com/Killazius/L0/api/order/v1"
+	"github.com/Killazius/L0/internal/auth"
+	"github.com/Killazius/L0/internal/config"
+	"github.com/Killazius/L0/internal/domain"
+	"github.com/Killazius/L0/internal/lib/broadcast"
+	"github.com/Killazius/L0/internal/lib/test"
+	"github.com/Killazius/L0/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	viewerKey = "viewer-key"
+	adminKey  = "admin-key"
+)
+
+func newTestServer(t *testing.T, svc OrderService, stream OrderStream) (*Server, orderv1.OrderServiceClient, *grpc.ClientConn) {
+	t.Helper()
+
+	authenticator, err := auth.New(config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "viewer", Hash: auth.HashAPIKey(viewerKey), Role: string(auth.RoleViewer)},
+			{Name: "admin", Hash: auth.HashAPIKey(adminKey), Role: string(auth.RoleAdmin)},
+		},
+	})
+	require.NoError(t, err)
+
+	server := NewServer(zap.NewNop().Sugar(), svc, stream, authenticator, config.GRPCConfig{Reflection: true})
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Close(ctx)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return server, orderv1.NewOrderServiceClient(conn), conn
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestServer_GetOrder(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	svc := NewMockOrderService(t)
+	svc.On("GetOrder", mock.Anything, order.OrderUID).Return(order, nil)
+	svc.On("GetOrder", mock.Anything, "missing").Return(nil, service.ErrOrderNotFound).Once()
+	_, client, _ := newTestServer(t, svc, NewMockOrderStream(t))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		orderUID string
+		code     codes.Code
+	}{
+		{name: "unauthenticated", ctx: context.Background(), orderUID: order.OrderUID, code: codes.Unauthenticated},
+		{name: "invalid key", ctx: withKey("wrong"), orderUID: order.OrderUID, code: codes.Unauthenticated},
+		{name: "empty uid", ctx: withKey(viewerKey), orderUID: "", code: codes.InvalidArgument},
+		{name: "not found", ctx: withKey(viewerKey), orderUID: "missing", code: codes.NotFound},
+		{name: "viewer", ctx: withKey(viewerKey), orderUID: order.OrderUID, code: codes.OK},
+		{name: "admin", ctx: withKey(adminKey), orderUID: order.OrderUID, code: codes.OK},
+	}
+	for _, tt := range tests {
+		resp, err := client.GetOrder(tt.ctx, &orderv1.GetOrderRequest{OrderUid: tt.orderUID})
+		require.Equal(t, tt.code, status.Code(err), tt.name)
+		if tt.code != codes.OK {
+			continue
+		}
+		assert.Equal(t, order.OrderUID, resp.GetOrder().GetOrderUid(), tt.name)
+		assert.Equal(t, order.Payment.Amount.String(), resp.GetOrder().GetPayment().GetAmount(), tt.name)
+		if tt.name == "viewer" {
+			assert.NotEqual(t, order.Delivery.Phone, resp.GetOrder().GetDelivery().GetPhone(), "viewer gets masked data")
+		} else {
+			assert.Equal(t, order.Delivery.Phone, resp.GetOrder().GetDelivery().GetPhone())
+		}
+	}
+}
+
+func TestServer_ListOrders(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewMockOrderService(t)
+	svc.On("ListOrders", mock.Anything, domain.OrderFilter{CustomerID: "c1", CreatedFrom: from}, 10, "").
+		Return(&domain.OrderPage{Orders: []domain.Order{*order}, NextCursor: "next"}, nil).
+		Once()
+	svc.On("ListOrders", mock.Anything, domain.OrderFilter{}, 0, "bad").
+		Return(nil, service.ErrInvalidQuery).
+		Once()
+	_, client, _ := newTestServer(t, svc, NewMockOrderStream(t))
+
+	req := &orderv1.ListOrdersRequest{CustomerId: "c1", PageSize: 10, CreatedFrom: toProtoTime(from)}
+	resp, err := client.ListOrders(withKey(viewerKey), req)
+	require.NoError(t, err)
+	require.Len(t, resp.GetOrders(), 1)
+	assert.Equal(t, order.OrderUID, resp.GetOrders()[0].GetOrderUid())
+	assert.Equal(t, "next", resp.GetNextPageToken())
+
+	_, err = client.ListOrders(withKey(viewerKey), &orderv1.ListOrdersRequest{PageToken: "bad"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_CreateOrder(t *testing.T) {
+	t.Parallel()
+
+	order := test.GenerateOrder()
+	svc := NewMockOrderService(t)
+	svc.On("CreateOrder", mock.Anything, mock.MatchedBy(func(o *domain.Order) bool {
+		return o.OrderUID == order.OrderUID && o.Payment.Amount.Equal(order.Payment.Amount) && o.DateCreated.Equal(order.DateCreated)
+	})).Return(nil).Once()
+	svc.On("CreateOrder", mock.Anything, mock.Anything).Return(service.ErrOrderAlreadyExists).Once()
+	_, client, _ := newTestServer(t, svc, NewMockOrderStream(t))
+
+	req := &orderv1.CreateOrderRequest{Order: toProtoOrder(order)}
+	_, err := client.CreateOrder(withKey(viewerKey), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	resp, err := client.CreateOrder(withKey(adminKey), req)
+	require.NoError(t, err)
+	assert.Equal(t, order.OrderUID, resp.GetOrder().GetOrderUid())
+
+	_, err = client.CreateOrder(withKey(adminKey), req)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	bad := toProtoOrder(order)
+	bad.Payment.Amount = "ten"
+	_, err = client.CreateOrder(withKey(adminKey), &orderv1.CreateOrderRequest{Order: bad})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_WatchOrders(t *testing.T) {
+	t.Parallel()
+
+	b := broadcast.New(10, 10)
+	server, client, _ := newTestServer(t, NewMockOrderService(t), b)
+
+	first := test.GenerateOrder()
+	b.Publish(domain.EventOrderCreated, first)
+	ctx, cancel := context.WithCancel(withKey(adminKey))
+	defer cancel()
+	stream, err := client.WatchOrders(ctx, &orderv1.WatchOrdersRequest{LastEventId: 1})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, first.OrderUID, event.GetOrder().GetOrderUid(), "missed events are replayed")
+	assert.Equal(t, string(domain.EventOrderCreated), event.GetType())
+
+	require.Eventually(t, func() bool { return b.Subscribers() == 1 }, time.Second, time.Millisecond)
+	second := test.GenerateOrder()
+	b.Publish(domain.EventOrderCreated, second)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, second.OrderUID, event.GetOrder().GetOrderUid())
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), time.Second)
+	defer closeCancel()
+	require.NoError(t, server.Close(closeCtx), "open streams do not block shutdown")
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_Health(t *testing.T) {
+	t.Parallel()
+
+	server, _, conn := newTestServer(t, NewMockOrderService(t), NewMockOrderStream(t))
+	health := healthpb.NewHealthClient(conn)
+
+	resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: orderv1.OrderService_ServiceDesc.ServiceName})
+	require.NoError(t, err, "health checks do not require authentication")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	require.NoError(t, server.Close(context.Background()))
+}
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE deliveries
+    ALTER COLUMN name TYPE TEXT,
+    ALTER COLUMN phone TYPE TEXT,
+    ALTER COLUMN zip TYPE TEXT,
+    ALTER COLUMN email TYPE TEXT,
+    ADD COLUMN key_id VARCHAR(64),
+    ADD COLUMN wrapped_key BYTEA;
+
+CREATE INDEX idx_deliveries_key_id ON deliveries (key_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_deliveries_key_id;
+ALTER TABLE deliveries
+    DROP COLUMN IF EXISTS wrapped_key,
+    DROP COLUMN IF EXISTS key_id,
+    ALTER COLUMN name TYPE VARCHAR(255),
+    ALTER COLUMN phone TYPE VARCHAR(50),
+    ALTER COLUMN zip TYPE VARCHAR(20),
+    ALTER COLUMN email TYPE VARCHAR(255);
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_orders_customer_id ON orders (customer_id);
+
+CREATE TABLE customer_erasures (
+                                   id BIGSERIAL PRIMARY KEY,
+                                   customer_hash CHAR(64) NOT NULL,
+                                   pseudonym VARCHAR(255) NOT NULL,
+                                   order_uids TEXT[] NOT NULL,
+                                   requested_by VARCHAR(255) NOT NULL,
+                                   reason TEXT,
+                                   erased_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_customer_erasures_customer_hash ON customer_erasures (customer_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS customer_erasures;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_orders_date_created_order_uid ON orders (date_created DESC, order_uid DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_date_created_order_uid;
+-- +goose StatementEnd
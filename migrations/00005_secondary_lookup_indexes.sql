@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_orders_track_number ON orders (track_number);
+CREATE INDEX idx_payments_transaction ON payments (transaction);
+CREATE INDEX idx_items_chrt_id ON items (chrt_id);
+CREATE INDEX idx_items_nm_id ON items (nm_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_items_nm_id;
+DROP INDEX IF EXISTS idx_items_chrt_id;
+DROP INDEX IF EXISTS idx_payments_transaction;
+DROP INDEX IF EXISTS idx_orders_track_number;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Name and address are only indexed while they are stored in plain text: an
+-- encrypted delivery (key_id is set) holds ciphertext that has no searchable
+-- words, and indexing the plain text would store it next to the ciphertext.
+ALTER TABLE deliveries ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', city || ' ' || CASE WHEN key_id IS NULL THEN name || ' ' || address ELSE '' END)
+) STORED;
+
+ALTER TABLE items ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', brand || ' ' || name)
+) STORED;
+
+CREATE INDEX idx_deliveries_search_vector ON deliveries USING GIN (search_vector);
+CREATE INDEX idx_items_search_vector ON items USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_items_search_vector;
+DROP INDEX IF EXISTS idx_deliveries_search_vector;
+ALTER TABLE items DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE order_events (
+                              id BIGSERIAL PRIMARY KEY,
+                              order_uid VARCHAR(255) NOT NULL,
+                              type VARCHAR(32) NOT NULL,
+                              version BIGINT NOT NULL,
+                              actor VARCHAR(255) NOT NULL,
+                              diff JSONB NOT NULL,
+                              occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_events_order_uid ON order_events (order_uid, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_events;
+-- +goose StatementEnd
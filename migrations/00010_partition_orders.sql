@@ -0,0 +1,249 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Orders and items are range partitioned by month of date_created. A
+-- partitioned table can only have unique keys that include the partition key,
+-- so order_uid alone is no longer unique: the repository serializes the
+-- inserts of an order UID instead. Deliveries, payments and items no longer
+-- reference orders either, a foreign key would keep old partitions from being
+-- detached; the repository deletes them together with their order.
+-- create_order_partition creates the partition of parent named parent_suffix
+-- for the month from lower_bound to upper_bound. Rows of the month that were
+-- stored in the default partition while the month had none, or after its
+-- partition was detached and archived, are moved into the new partition;
+-- otherwise creating it would fail on them.
+CREATE FUNCTION create_order_partition(parent TEXT, suffix TEXT, lower_bound TIMESTAMP WITH TIME ZONE, upper_bound TIMESTAMP WITH TIME ZONE) RETURNS VOID AS $$
+DECLARE
+    default_partition TEXT := parent || '_default';
+    stored BOOLEAN;
+    columns TEXT;
+BEGIN
+    EXECUTE format('SELECT EXISTS (SELECT 1 FROM %I WHERE date_created >= %L AND date_created < %L)',
+        default_partition, lower_bound, upper_bound) INTO stored;
+    IF NOT stored THEN
+        EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+            parent || '_' || suffix, parent, lower_bound, upper_bound);
+        RETURN;
+    END IF;
+
+    -- Generated columns are computed again on insert.
+    SELECT string_agg(quote_ident(attname), ', ' ORDER BY attnum) INTO columns
+    FROM pg_attribute
+    WHERE attrelid = parent::regclass AND attnum > 0 AND NOT attisdropped AND attgenerated = '';
+
+    EXECUTE format('ALTER TABLE %I DETACH PARTITION %I', parent, default_partition);
+    EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+        parent || '_' || suffix, parent, lower_bound, upper_bound);
+    EXECUTE format('WITH moved AS (DELETE FROM %I WHERE date_created >= %L AND date_created < %L RETURNING %s) '
+        'INSERT INTO %I (%s) SELECT %s FROM moved',
+        default_partition, lower_bound, upper_bound, columns, parent, columns, columns);
+    EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I DEFAULT', parent, default_partition);
+    RAISE NOTICE 'moved rows of %_% out of %', parent, suffix, default_partition;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION ensure_order_partitions(month_from DATE, month_to DATE) RETURNS INTEGER AS $$
+DECLARE
+    month_start DATE := date_trunc('month', month_from)::date;
+    lower_bound TIMESTAMP WITH TIME ZONE;
+    upper_bound TIMESTAMP WITH TIME ZONE;
+    suffix TEXT;
+    created INTEGER := 0;
+BEGIN
+    WHILE month_start <= month_to LOOP
+        suffix := to_char(month_start, '"y"YYYY"m"MM');
+        lower_bound := month_start::timestamp AT TIME ZONE 'UTC';
+        upper_bound := (month_start + INTERVAL '1 month')::timestamp AT TIME ZONE 'UTC';
+        -- A detached partition keeps its name until it is archived, its month
+        -- is not created again. Its new rows go to the default partition.
+        IF to_regclass('orders_' || suffix) IS NULL THEN
+            PERFORM create_order_partition('orders', suffix, lower_bound, upper_bound);
+            created := created + 1;
+        END IF;
+        IF to_regclass('items_' || suffix) IS NULL THEN
+            PERFORM create_order_partition('items', suffix, lower_bound, upper_bound);
+        END IF;
+        month_start := (month_start + INTERVAL '1 month')::date;
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE deliveries DROP CONSTRAINT IF EXISTS deliveries_order_uid_fkey;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_order_uid_fkey;
+
+ALTER TABLE orders RENAME TO orders_unpartitioned;
+ALTER TABLE items RENAME TO items_unpartitioned;
+
+CREATE TABLE orders (
+                        order_uid VARCHAR(255) NOT NULL,
+                        track_number VARCHAR(255) NOT NULL,
+                        entry VARCHAR(50) NOT NULL,
+                        locale VARCHAR(10) NOT NULL,
+                        internal_signature VARCHAR(255),
+                        customer_id VARCHAR(255) NOT NULL,
+                        delivery_service VARCHAR(100) NOT NULL,
+                        shardkey VARCHAR(10) NOT NULL,
+                        sm_id INTEGER NOT NULL,
+                        date_created TIMESTAMP WITH TIME ZONE NOT NULL,
+                        oof_shard VARCHAR(10) NOT NULL,
+                        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                        updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                        version BIGINT NOT NULL DEFAULT 1,
+                        deleted_at TIMESTAMP WITH TIME ZONE
+) PARTITION BY RANGE (date_created);
+
+CREATE TABLE items (
+                       id INTEGER NOT NULL DEFAULT nextval('items_id_seq'),
+                       order_uid VARCHAR(255) NOT NULL,
+                       chrt_id BIGINT NOT NULL,
+                       track_number VARCHAR(255) NOT NULL,
+                       price NUMERIC(12, 2) NOT NULL,
+                       rid VARCHAR(255) NOT NULL,
+                       name VARCHAR(255) NOT NULL,
+                       sale INTEGER NOT NULL,
+                       size VARCHAR(50) NOT NULL,
+                       total_price NUMERIC(12, 2) NOT NULL,
+                       nm_id BIGINT NOT NULL,
+                       brand VARCHAR(255) NOT NULL,
+                       status INTEGER NOT NULL,
+                       created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                       date_created TIMESTAMP WITH TIME ZONE NOT NULL,
+                       search_vector tsvector GENERATED ALWAYS AS (
+                           to_tsvector('simple', brand || ' ' || name)
+                       ) STORED
+) PARTITION BY RANGE (date_created);
+
+ALTER SEQUENCE items_id_seq OWNED BY items.id;
+
+CREATE TABLE orders_default PARTITION OF orders DEFAULT;
+CREATE TABLE items_default PARTITION OF items DEFAULT;
+
+-- Months are created for at most a year back, older orders stay in the
+-- default partition.
+SELECT ensure_order_partitions(
+    (GREATEST(
+        COALESCE((SELECT min(date_created) FROM orders_unpartitioned), CURRENT_TIMESTAMP),
+        CURRENT_TIMESTAMP - INTERVAL '1 year'
+    ) AT TIME ZONE 'UTC')::date,
+    (CURRENT_TIMESTAMP AT TIME ZONE 'UTC' + INTERVAL '3 months')::date
+);
+
+INSERT INTO orders (
+    order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service,
+    shardkey, sm_id, date_created, oof_shard, created_at, updated_at, version, deleted_at
+)
+SELECT order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service,
+       shardkey, sm_id, date_created, oof_shard, created_at, updated_at, version, deleted_at
+FROM orders_unpartitioned;
+
+INSERT INTO items (
+    id, order_uid, chrt_id, track_number, price, rid, name,
+    sale, size, total_price, nm_id, brand, status, created_at, date_created
+)
+SELECT i.id, i.order_uid, i.chrt_id, i.track_number, i.price, i.rid, i.name,
+       i.sale, i.size, i.total_price, i.nm_id, i.brand, i.status, i.created_at, o.date_created
+FROM items_unpartitioned i
+JOIN orders_unpartitioned o ON o.order_uid = i.order_uid;
+
+DROP TABLE items_unpartitioned;
+DROP TABLE orders_unpartitioned;
+
+ALTER TABLE orders ADD PRIMARY KEY (order_uid, date_created);
+ALTER TABLE items ADD PRIMARY KEY (id, date_created);
+
+CREATE INDEX idx_orders_order_uid ON orders (order_uid);
+CREATE INDEX idx_orders_date_created_order_uid ON orders (date_created DESC, order_uid DESC);
+CREATE INDEX idx_orders_track_number ON orders (track_number);
+CREATE INDEX idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX idx_orders_deleted_at ON orders (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_items_order_uid ON items (order_uid);
+CREATE INDEX idx_items_chrt_id ON items (chrt_id);
+CREATE INDEX idx_items_nm_id ON items (nm_id);
+CREATE INDEX idx_items_search_vector ON items USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Detached partitions are left as they are, their rows are not restored.
+ALTER TABLE orders RENAME TO orders_partitioned;
+ALTER TABLE items RENAME TO items_partitioned;
+ALTER TABLE orders_partitioned RENAME CONSTRAINT orders_pkey TO orders_partitioned_pkey;
+ALTER TABLE items_partitioned RENAME CONSTRAINT items_pkey TO items_partitioned_pkey;
+
+CREATE TABLE orders (
+                        order_uid VARCHAR(255) PRIMARY KEY,
+                        track_number VARCHAR(255) NOT NULL,
+                        entry VARCHAR(50) NOT NULL,
+                        locale VARCHAR(10) NOT NULL,
+                        internal_signature VARCHAR(255),
+                        customer_id VARCHAR(255) NOT NULL,
+                        delivery_service VARCHAR(100) NOT NULL,
+                        shardkey VARCHAR(10) NOT NULL,
+                        sm_id INTEGER NOT NULL,
+                        date_created TIMESTAMP WITH TIME ZONE NOT NULL,
+                        oof_shard VARCHAR(10) NOT NULL,
+                        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                        updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                        version BIGINT NOT NULL DEFAULT 1,
+                        deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+INSERT INTO orders (
+    order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service,
+    shardkey, sm_id, date_created, oof_shard, created_at, updated_at, version, deleted_at
+)
+SELECT order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service,
+       shardkey, sm_id, date_created, oof_shard, created_at, updated_at, version, deleted_at
+FROM orders_partitioned;
+
+CREATE TABLE items (
+                       id INTEGER PRIMARY KEY DEFAULT nextval('items_id_seq'),
+                       order_uid VARCHAR(255) NOT NULL REFERENCES orders(order_uid) ON DELETE CASCADE,
+                       chrt_id BIGINT NOT NULL,
+                       track_number VARCHAR(255) NOT NULL,
+                       price NUMERIC(12, 2) NOT NULL,
+                       rid VARCHAR(255) NOT NULL,
+                       name VARCHAR(255) NOT NULL,
+                       sale INTEGER NOT NULL,
+                       size VARCHAR(50) NOT NULL,
+                       total_price NUMERIC(12, 2) NOT NULL,
+                       nm_id BIGINT NOT NULL,
+                       brand VARCHAR(255) NOT NULL,
+                       status INTEGER NOT NULL,
+                       created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                       search_vector tsvector GENERATED ALWAYS AS (
+                           to_tsvector('simple', brand || ' ' || name)
+                       ) STORED
+);
+
+ALTER SEQUENCE items_id_seq OWNED BY items.id;
+
+INSERT INTO items (
+    id, order_uid, chrt_id, track_number, price, rid, name,
+    sale, size, total_price, nm_id, brand, status, created_at
+)
+SELECT id, order_uid, chrt_id, track_number, price, rid, name,
+       sale, size, total_price, nm_id, brand, status, created_at
+FROM items_partitioned
+WHERE order_uid IN (SELECT order_uid FROM orders);
+
+DROP TABLE items_partitioned;
+DROP TABLE orders_partitioned;
+DROP FUNCTION IF EXISTS ensure_order_partitions(DATE, DATE);
+DROP FUNCTION IF EXISTS create_order_partition(TEXT, TEXT, TIMESTAMP WITH TIME ZONE, TIMESTAMP WITH TIME ZONE);
+
+DELETE FROM deliveries WHERE order_uid NOT IN (SELECT order_uid FROM orders);
+DELETE FROM payments WHERE order_uid NOT IN (SELECT order_uid FROM orders);
+ALTER TABLE deliveries ADD CONSTRAINT deliveries_order_uid_fkey
+    FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
+ALTER TABLE payments ADD CONSTRAINT payments_order_uid_fkey
+    FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
+
+CREATE INDEX idx_orders_date_created_order_uid ON orders (date_created DESC, order_uid DESC);
+CREATE INDEX idx_orders_track_number ON orders (track_number);
+CREATE INDEX idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX idx_orders_deleted_at ON orders (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_items_chrt_id ON items (chrt_id);
+CREATE INDEX idx_items_nm_id ON items (nm_id);
+CREATE INDEX idx_items_search_vector ON items USING GIN (search_vector);
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE customers (
+                           customer_id VARCHAR(255) PRIMARY KEY,
+                           first_order_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                           last_order_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                           created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The address book of a customer. The contact fields are encrypted like the
+-- ones of deliveries and bound to order_uid, the order the entry was taken
+-- from, so existing deliveries are copied without decrypting them.
+CREATE TABLE customer_addresses (
+                                    id BIGSERIAL PRIMARY KEY,
+                                    customer_id VARCHAR(255) NOT NULL REFERENCES customers (customer_id) ON DELETE CASCADE,
+                                    order_uid VARCHAR(255) NOT NULL,
+                                    name TEXT NOT NULL,
+                                    phone TEXT NOT NULL,
+                                    zip TEXT NOT NULL,
+                                    city VARCHAR(100) NOT NULL,
+                                    address TEXT NOT NULL,
+                                    region VARCHAR(100) NOT NULL,
+                                    email TEXT NOT NULL,
+                                    key_id VARCHAR(64),
+                                    wrapped_key BYTEA,
+                                    first_used_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                    last_used_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_customer_addresses_customer_id ON customer_addresses (customer_id, last_used_at DESC);
+CREATE INDEX idx_customer_addresses_key_id ON customer_addresses (key_id);
+
+INSERT INTO customers (customer_id, first_order_at, last_order_at)
+SELECT customer_id, min(date_created), max(date_created)
+FROM orders
+GROUP BY customer_id;
+
+-- Equal plain text deliveries become one entry. Encrypted ones have their own
+-- data keys and stay apart, the repository merges them when it reads them.
+-- Deliveries of erased customers hold no address.
+INSERT INTO customer_addresses (
+    customer_id, order_uid, name, phone, zip, city, address, region, email,
+    key_id, wrapped_key, first_used_at, last_used_at
+)
+SELECT o.customer_id, min(o.order_uid), d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+       d.key_id, d.wrapped_key, min(o.date_created), max(o.date_created)
+FROM deliveries d
+JOIN orders o ON o.order_uid = d.order_uid
+WHERE d.key_id IS NOT NULL OR d.name <> 'erased'
+GROUP BY o.customer_id, d.name, d.phone, d.zip, d.city, d.address, d.region, d.email, d.key_id, d.wrapped_key;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS customer_addresses;
+DROP TABLE IF EXISTS customers;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Daily aggregates of the orders that are not deleted, by the UTC day they
+-- were created. Sums are per currency. The unique indexes allow
+-- REFRESH MATERIALIZED VIEW CONCURRENTLY, which does not block readers.
+CREATE MATERIALIZED VIEW stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       p.provider,
+       p.bank,
+       o.delivery_service,
+       p.currency,
+       o.locale,
+       count(*) AS orders,
+       sum(p.amount) AS amount,
+       sum(p.delivery_cost) AS delivery_cost,
+       coalesce(sum(i.items), 0) AS items
+FROM orders o
+JOIN payments p ON p.order_uid = o.order_uid
+LEFT JOIN (
+    SELECT order_uid, count(*) AS items
+    FROM items
+    GROUP BY order_uid
+) i ON i.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+CREATE UNIQUE INDEX idx_stats_daily ON stats_daily (day, provider, bank, delivery_service, currency, locale);
+
+CREATE MATERIALIZED VIEW stats_daily_brands AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       i.brand,
+       p.currency,
+       count(*) AS items,
+       count(DISTINCT o.order_uid) AS orders,
+       sum(i.total_price) AS revenue
+FROM orders o
+JOIN payments p ON p.order_uid = o.order_uid
+JOIN items i ON i.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX idx_stats_daily_brands ON stats_daily_brands (day, brand, currency);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP MATERIALIZED VIEW IF EXISTS stats_daily_brands;
+DROP MATERIALIZED VIEW IF EXISTS stats_daily;
+-- +goose StatementEnd
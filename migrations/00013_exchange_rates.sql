@@ -0,0 +1,116 @@
+-- +goose Up
+-- +goose StatementBegin
+-- rate is the price of one unit of currency in base_currency, effective from
+-- rate_date until the next rate of the currency.
+CREATE TABLE exchange_rates (
+                                base_currency VARCHAR(3) NOT NULL,
+                                currency VARCHAR(3) NOT NULL,
+                                rate_date DATE NOT NULL,
+                                rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+                                updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                PRIMARY KEY (base_currency, currency, rate_date)
+);
+
+-- The amount of the payment in base_currency, converted with exchange_rate
+-- when the order was stored. NULL until a rate of the currency is known.
+ALTER TABLE payments
+    ADD COLUMN base_currency VARCHAR(3),
+    ADD COLUMN exchange_rate NUMERIC(20, 10),
+    ADD COLUMN base_amount NUMERIC(14, 2);
+
+DROP MATERIALIZED VIEW stats_daily;
+CREATE MATERIALIZED VIEW stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       p.provider,
+       p.bank,
+       o.delivery_service,
+       p.currency,
+       o.locale,
+       count(*) AS orders,
+       sum(p.amount) AS amount,
+       sum(p.delivery_cost) AS delivery_cost,
+       coalesce(sum(i.items), 0) AS items,
+       coalesce(sum(p.base_amount), 0) AS base_amount,
+       coalesce(sum(round(p.delivery_cost * p.exchange_rate, 2)), 0) AS base_delivery_cost,
+       count(*) FILTER (WHERE p.base_amount IS NULL) AS unconverted
+FROM orders o
+JOIN payments p ON p.order_uid = o.order_uid
+LEFT JOIN (
+    SELECT order_uid, count(*) AS items
+    FROM items
+    GROUP BY order_uid
+) i ON i.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+CREATE UNIQUE INDEX idx_stats_daily ON stats_daily (day, provider, bank, delivery_service, currency, locale);
+
+DROP MATERIALIZED VIEW stats_daily_brands;
+CREATE MATERIALIZED VIEW stats_daily_brands AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       i.brand,
+       p.currency,
+       count(*) AS items,
+       count(DISTINCT o.order_uid) AS orders,
+       sum(i.total_price) AS revenue,
+       coalesce(sum(round(i.total_price * p.exchange_rate, 2)), 0) AS base_revenue,
+       count(DISTINCT o.order_uid) FILTER (WHERE p.exchange_rate IS NULL) AS unconverted
+FROM orders o
+JOIN payments p ON p.order_uid = o.order_uid
+JOIN items i ON i.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX idx_stats_daily_brands ON stats_daily_brands (day, brand, currency);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP MATERIALIZED VIEW IF EXISTS stats_daily_brands;
+DROP MATERIALIZED VIEW IF EXISTS stats_daily;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS base_amount,
+    DROP COLUMN IF EXISTS exchange_rate,
+    DROP COLUMN IF EXISTS base_currency;
+
+DROP TABLE IF EXISTS exchange_rates;
+
+CREATE MATERIALIZED VIEW stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       p.provider,
+       p.bank,
+       o.delivery_service,
+       p.currency,
+       o.locale,
+       count(*) AS orders,
+       sum(p.amount) AS amount,
+       sum(p.delivery_cost) AS delivery_cost,
+       coalesce(sum(i.items), 0) AS items
+FROM orders o
+JOIN payments p ON p.order_uid = o.order_uid
+LEFT JOIN (
+    SELECT order_uid, count(*) AS items
+    FROM items
+    GROUP BY order_uid
+) i ON i.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3, 4, 5, 6;
+
+CREATE UNIQUE INDEX idx_stats_daily ON stats_daily (day, provider, bank, delivery_service, currency, locale);
+
+CREATE MATERIALIZED VIEW stats_daily_brands AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       i.brand,
+       p.currency,
+       count(*) AS items,
+       count(DISTINCT o.order_uid) AS orders,
+       sum(i.total_price) AS revenue
+FROM orders o
+JOIN payments p ON p.order_uid = o.order_uid
+JOIN items i ON i.order_uid = o.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX idx_stats_daily_brands ON stats_daily_brands (day, brand, currency);
+-- +goose StatementEnd
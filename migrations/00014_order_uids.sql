@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The partitioned orders table cannot have order_uid alone as a unique key,
+-- so every stored order UID is also kept here, written in the transaction
+-- that inserts the order. Its primary key rejects a second order with the UID
+-- even in another partition, whatever the caller locked.
+CREATE TABLE order_uids (
+                            order_uid VARCHAR(255) PRIMARY KEY
+);
+
+INSERT INTO order_uids (order_uid)
+SELECT DISTINCT order_uid FROM orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE order_uids;
+-- +goose StatementEnd
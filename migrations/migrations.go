@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations of the database, so the
+// migrator and the service run them without the directory on disk.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
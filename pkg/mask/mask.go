@@ -0,0 +1,198 @@
+package mask
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TagName is the struct tag that marks a string field as personal data.
+// The tag value selects the rule used to redact it, e.g. `mask:"email"`.
+const TagName = "mask"
+
+const (
+	RuleName   = "name"
+	RuleEmail  = "email"
+	RulePhone  = "phone"
+	RuleRedact = "redact"
+)
+
+const placeholder = "***"
+
+var sensitiveTypes sync.Map
+
+// Masked returns a deep copy of v in which every field tagged with `mask` is
+// redacted. Values without tagged fields are returned unchanged.
+func Masked[T any](v T) T {
+	masked, ok := Value(v).(T)
+	if !ok {
+		return v
+	}
+	return masked
+}
+
+// Value is the untyped variant of Masked, used where the static type is unknown,
+// for example for fields passed to a logger.
+func Value(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !Sensitive(rv.Type()) {
+		return v
+	}
+	return maskValue(rv).Interface()
+}
+
+// String redacts s according to rule.
+func String(rule, s string) string {
+	if s == "" {
+		return s
+	}
+	switch rule {
+	case RuleName:
+		words := strings.Fields(s)
+		for i, w := range words {
+			r, _ := utf8.DecodeRuneInString(w)
+			words[i] = string(r) + placeholder
+		}
+		return strings.Join(words, " ")
+	case RuleEmail:
+		local, domain, ok := strings.Cut(s, "@")
+		if !ok || local == "" {
+			return placeholder
+		}
+		r, _ := utf8.DecodeRuneInString(local)
+		return string(r) + placeholder + "@" + domain
+	case RulePhone:
+		var b strings.Builder
+		digits := 0
+		for _, r := range s {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		seen := 0
+		for _, r := range s {
+			switch {
+			case unicode.IsDigit(r):
+				seen++
+				if digits-seen < 2 {
+					b.WriteRune(r)
+				} else {
+					b.WriteByte('*')
+				}
+			case r == '+':
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	default:
+		return placeholder
+	}
+}
+
+// Sensitive reports whether values of type t contain fields tagged with `mask`.
+func Sensitive(t reflect.Type) bool {
+	if cached, ok := sensitiveTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := sensitive(t, map[reflect.Type]bool{})
+	sensitiveTypes.Store(t, result)
+	return result
+}
+
+// sensitive does not cache intermediate results: a type reached through a cycle
+// is reported as not sensitive until the outer call finishes scanning it.
+func sensitive(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	var result bool
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		result = sensitive(t.Elem(), visiting)
+	case reflect.Map:
+		result = sensitive(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if _, ok := f.Tag.Lookup(TagName); ok && f.Type.Kind() == reflect.String {
+				result = true
+				break
+			}
+			if sensitive(f.Type, visiting) {
+				result = true
+				break
+			}
+		}
+	default:
+		result = false
+	}
+	return result
+}
+
+func maskValue(v reflect.Value) reflect.Value {
+	t := v.Type()
+	if !Sensitive(t) {
+		return v
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(maskValue(v.Elem()))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(maskValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(maskValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), maskValue(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if rule, ok := f.Tag.Lookup(TagName); ok && f.Type.Kind() == reflect.String {
+				out.Field(i).SetString(String(rule, v.Field(i).String()))
+				continue
+			}
+			out.Field(i).Set(maskValue(v.Field(i)))
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,80 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contact struct {
+	Name  string `mask:"name"`
+	Email string `mask:"email"`
+	Phone string `mask:"phone"`
+	Note  string `mask:"redact"`
+	City  string
+}
+
+type holder struct {
+	Contact  contact
+	Contacts []contact
+	ByID     map[string]*contact
+	Next     *holder
+	ID       int
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rule     string
+		in       string
+		expected string
+	}{
+		{rule: RuleName, in: "Test Testov", expected: "T*** T***"},
+		{rule: RuleName, in: "Иван", expected: "И***"},
+		{rule: RuleEmail, in: "test@gmail.com", expected: "t***@gmail.com"},
+		{rule: RuleEmail, in: "not-an-email", expected: "***"},
+		{rule: RulePhone, in: "+9720000012", expected: "+********12"},
+		{rule: RuleRedact, in: "Ploshad Mira 15", expected: "***"},
+		{rule: "unknown", in: "secret", expected: "***"},
+		{rule: RuleName, in: "", expected: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, String(tt.rule, tt.in), "%s(%q)", tt.rule, tt.in)
+	}
+}
+
+func TestMasked(t *testing.T) {
+	t.Parallel()
+
+	c := contact{Name: "Test Testov", Email: "test@gmail.com", Phone: "+9720000000", Note: "x", City: "Haifa"}
+	in := &holder{
+		Contact:  c,
+		Contacts: []contact{c},
+		ByID:     map[string]*contact{"a": &c},
+		Next:     &holder{Contact: c},
+		ID:       7,
+	}
+
+	out := Masked(in)
+
+	expected := contact{Name: "T*** T***", Email: "t***@gmail.com", Phone: "+********00", Note: "***", City: "Haifa"}
+	assert.Equal(t, expected, out.Contact)
+	assert.Equal(t, []contact{expected}, out.Contacts)
+	assert.Equal(t, expected, *out.ByID["a"])
+	assert.Equal(t, expected, out.Next.Contact)
+	assert.Equal(t, 7, out.ID)
+
+	assert.Equal(t, "Test Testov", in.Contact.Name, "input must not be modified")
+	assert.Equal(t, "test@gmail.com", in.ByID["a"].Email, "input must not be modified")
+}
+
+func TestValue_NotSensitive(t *testing.T) {
+	t.Parallel()
+
+	type plain struct{ Name string }
+	p := &plain{Name: "Test"}
+	assert.Same(t, p, Value(p))
+	assert.Nil(t, Value(nil))
+	assert.Nil(t, Masked[*holder](nil))
+}